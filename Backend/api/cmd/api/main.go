@@ -7,11 +7,15 @@ import (
 	"database/sql"
 	"log"
 	"os"
+	"strconv"
+	"time"
 
+	"github.com/Hoxanfox/TurnyChain/Backend/api/internal/domain"
 	"github.com/Hoxanfox/TurnyChain/Backend/api/internal/handler"
 	"github.com/Hoxanfox/TurnyChain/Backend/api/internal/repository"
 	"github.com/Hoxanfox/TurnyChain/Backend/api/internal/router"
 	"github.com/Hoxanfox/TurnyChain/Backend/api/internal/service"
+	"github.com/Hoxanfox/TurnyChain/Backend/api/internal/utils"
 	wshub "github.com/Hoxanfox/TurnyChain/Backend/api/internal/websocket"
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/cors"
@@ -30,43 +34,200 @@ func main() {
 	}
 	defer db.Close()
 
+	// Moneda del restaurante (decimales con los que se redondean los totales)
+	if currency := os.Getenv("CURRENCY"); currency != "" {
+		if err := domain.SetDefaultCurrency(currency); err != nil {
+			log.Fatalf("Error en CURRENCY: %v", err)
+		}
+	}
+
+	// Porcentaje del subtotal a partir del cual un cupón necesita aprobación del admin
+	if threshold := os.Getenv("DISCOUNT_APPROVAL_THRESHOLD"); threshold != "" {
+		percent, err := domain.ParsePercent(threshold)
+		if err != nil || percent < 0 {
+			log.Fatalf("Error en DISCOUNT_APPROVAL_THRESHOLD: %q no es un porcentaje válido", threshold)
+		}
+		service.SetDiscountApprovalThreshold(percent)
+	}
+
+	// Los precios del menú incluyen el impuesto (true, por defecto) o se suma al cobrar (false)
+	if included := os.Getenv("PRICES_INCLUDE_TAX"); included != "" {
+		value, err := strconv.ParseBool(included)
+		if err != nil {
+			log.Fatalf("Error en PRICES_INCLUDE_TAX: %q no es un booleano válido", included)
+		}
+		service.SetPricesIncludeTax(value)
+	}
+
+	// Claves de cifrado de las facturas notarizadas. Fuera de desarrollo (APP_ENV=development)
+	// no se arranca sin una clave válida.
+	devMode := os.Getenv("APP_ENV") == "development"
+	keyRing, err := utils.LoadEncryptionKeys(devMode)
+	if err != nil {
+		log.Fatalf("Error en las claves de cifrado de facturas: %v", err)
+	}
+	utils.SetKeyRing(keyRing)
+	if keyRing.ActiveKeyID() == utils.DevEncryptionKeyID {
+		log.Printf("⚠️ Cifrando facturas con la clave de desarrollo: no usar en producción")
+	}
+	log.Printf("🔐 Clave de cifrado de facturas activa: %s (cargadas: %v)", keyRing.ActiveKeyID(), keyRing.KeyIDs())
+
 	wsHub := wshub.NewHub()
 	go wsHub.Run()
 
-	// --- INICIALIZAR BLOCKCHAIN ---
-	rpcURL := os.Getenv("BLOCKCHAIN_RPC_URL")
-	privateKey := os.Getenv("WALLET_PRIVATE_KEY")
-	contractAddr := os.Getenv("CONTRACT_ADDRESS")
-
-	// Creamos el servicio (si faltan variables, devolverá nil y logs de advertencia, pero no crashea)
-	blockchainService := service.NewBlockchainService(rpcURL, privateKey, contractAddr)
+	// --- INICIALIZAR BACKEND DE NOTARIZACIÓN ---
+	// ethereum (contrato InvoiceNotary), file (log local encadenado por hashes) o memory.
+	// Por defecto ethereum si hay BLOCKCHAIN_RPC_URL y file si no.
+	notaryLogPath := os.Getenv("NOTARY_LOG_PATH")
+	if notaryLogPath == "" {
+		notaryLogPath = "./data/notary-chain.jsonl"
+	}
+	blockchainService, err := service.NewNotaryBackend(service.NotaryConfig{
+		Backend:         os.Getenv("NOTARY_BACKEND"),
+		RPCURL:          os.Getenv("BLOCKCHAIN_RPC_URL"),
+		PrivateKey:      os.Getenv("WALLET_PRIVATE_KEY"),
+		ContractAddress: os.Getenv("CONTRACT_ADDRESS"),
+		LogPath:         notaryLogPath,
+	})
+	if err != nil {
+		log.Fatalf("Error en el backend de notarización: %v", err)
+	}
+	log.Printf("⛓️ Backend de notarización: %s", blockchainService.Name())
 	// -----------------------------
 
+	// Encabezado de los recibos y explorador de bloques para el QR de verificación (solo
+	// las transacciones de Ethereum existen en un explorador)
+	explorerURL := os.Getenv("BLOCKCHAIN_EXPLORER_URL")
+	if blockchainService.Name() != service.NotaryBackendEthereum {
+		explorerURL = ""
+	}
+	service.SetReceiptConfig(os.Getenv("RESTAURANT_NAME"), explorerURL)
+
 	// Repositorios
 	userRepo := repository.NewUserRepository(db)
 	menuRepo := repository.NewMenuRepository(db)
 	orderRepo := repository.NewOrderRepository(db)
+	orderEventRepo := repository.NewOrderEventRepository(db)
+	orderPaymentRepo := repository.NewOrderPaymentRepository(db)
+	orderCancellationRepo := repository.NewOrderCancellationRepository(db)
+	orderNotarizationRepo := repository.NewOrderNotarizationRepository(db)
+	notarizationBatchRepo := repository.NewNotarizationBatchRepository(db)
+	chainIndexRepo := repository.NewChainIndexRepository(db)
 	tableRepo := repository.NewTableRepository(db)
 	categoryRepo := repository.NewCategoryRepository(db)
 	ingredientRepo := repository.NewIngredientRepository(db)
 	accompanimentRepo := repository.NewAccompanimentRepository(db)
 	stationRepo := repository.NewStationRepository(db)
 	printerRepo := repository.NewPrinterRepository(db)
+	printJobRepo := repository.NewPrintJobRepository(db)
+	kdsTicketRepo := repository.NewKDSTicketRepository(db)
+	serviceChargeRepo := repository.NewServiceChargeRepository(db)
+	reportRepo := repository.NewReportRepository(db)
+	promotionRepo := repository.NewPromotionRepository(db)
+	auditorKeyRepo := repository.NewAuditorKeyRepository(db)
 
 	// Servicios
 	userService := service.NewUserService(userRepo)
 	authService := service.NewAuthService(userRepo)
 	menuService := service.NewMenuService(menuRepo, wsHub)
-
-	// MODIFICADO: Pasamos blockchainService, menuRepo, ingredientRepo y accompanimentRepo
-	orderService := service.NewOrderService(orderRepo, tableRepo, menuRepo, ingredientRepo, accompanimentRepo, wsHub, blockchainService)
 	tableService := service.NewTableService(tableRepo)
 	categoryService := service.NewCategoryService(categoryRepo)
 	ingredientService := service.NewIngredientService(ingredientRepo)
 	accompanimentService := service.NewAccompanimentService(accompanimentRepo)
 	stationService := service.NewStationService(stationRepo)
-	printerService := service.NewPrinterService(printerRepo)
-	kitchenTicketService := service.NewKitchenTicketService(orderRepo, printerRepo, stationRepo)
+	// Los PDFs de las impresoras tipo "pdf" se guardan fuera de ./uploads, que se sirve sin
+	// autenticación en /api/static: solo se descargan por los endpoints protegidos
+	printerDispatcher := service.NewPrinterDispatcher("./data/tickets")
+	printQueueService := service.NewPrintQueueService(printJobRepo, printerRepo, printerDispatcher, wsHub)
+	kdsService := service.NewKDSService(kdsTicketRepo, wsHub)
+	serviceChargeService := service.NewServiceChargeService(serviceChargeRepo, wsHub)
+	reportService := service.NewReportService(reportRepo)
+	promotionService := service.NewPromotionService(promotionRepo, wsHub)
+	kitchenTicketService := service.NewKitchenTicketService(orderRepo, stationRepo, printQueueService, printerDispatcher, kdsService)
+	notarizationService := service.NewNotarizationService(orderNotarizationRepo, notarizationBatchRepo, orderRepo, blockchainService, wsHub)
+
+	// Modo de notarización: "single" (una transacción por factura, por defecto) o "batch"
+	// (lotes anclados con una raíz Merkle)
+	switch mode := os.Getenv("NOTARIZATION_MODE"); mode {
+	case "", "single":
+	case "batch":
+		window := 5 * time.Minute
+		if value := os.Getenv("NOTARIZATION_BATCH_WINDOW"); value != "" {
+			parsed, err := time.ParseDuration(value)
+			if err != nil || parsed <= 0 {
+				log.Fatalf("Error en NOTARIZATION_BATCH_WINDOW: %q no es una duración válida", value)
+			}
+			window = parsed
+		}
+		maxSize := 256
+		if value := os.Getenv("NOTARIZATION_BATCH_SIZE"); value != "" {
+			parsed, err := strconv.Atoi(value)
+			if err != nil || parsed <= 0 {
+				log.Fatalf("Error en NOTARIZATION_BATCH_SIZE: %q no es un entero positivo", value)
+			}
+			maxSize = parsed
+		}
+		notarizationService.EnableBatching(window, maxSize)
+	default:
+		log.Fatalf("Error en NOTARIZATION_MODE: %q no es válido (single o batch)", mode)
+	}
+
+	// MODIFICADO: Pasamos notarizationService, menuRepo, ingredientRepo, accompanimentRepo y kitchenTicketService
+	orderService := service.NewOrderService(orderRepo, tableRepo, menuRepo, ingredientRepo, accompanimentRepo, orderEventRepo, orderPaymentRepo, serviceChargeRepo, promotionRepo, orderCancellationRepo, wsHub, notarizationService, kitchenTicketService)
+
+	// El bump del KDS actualiza los items de la estación en la orden
+	kdsService.SetItemsUpdater(orderService)
+
+	auditService := service.NewAuditService(orderNotarizationRepo, auditorKeyRepo, notarizationService)
+
+	// Indexador de eventos InvoiceSecured (activo salvo NOTARY_INDEXER=false). Empieza en
+	// NOTARY_INDEXER_START_BLOCK (el bloque del despliegue del contrato evita recorrer toda
+	// la cadena) y consulta NOTARY_INDEXER_BLOCK_RANGE bloques por petición
+	var chainIndexerService *service.ChainIndexerService
+	indexerEnabled := true
+	if value := os.Getenv("NOTARY_INDEXER"); value != "" {
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			log.Fatalf("Error en NOTARY_INDEXER: %q no es un booleano válido", value)
+		}
+		indexerEnabled = parsed
+	}
+	if indexerEnabled {
+		var startBlock int64
+		if value := os.Getenv("NOTARY_INDEXER_START_BLOCK"); value != "" {
+			parsed, err := strconv.ParseInt(value, 10, 64)
+			if err != nil || parsed < 0 {
+				log.Fatalf("Error en NOTARY_INDEXER_START_BLOCK: %q no es un bloque válido", value)
+			}
+			startBlock = parsed
+		}
+		blockRange := int64(service.DefaultChainIndexerRange)
+		if value := os.Getenv("NOTARY_INDEXER_BLOCK_RANGE"); value != "" {
+			parsed, err := strconv.ParseInt(value, 10, 64)
+			if err != nil || parsed <= 0 {
+				log.Fatalf("Error en NOTARY_INDEXER_BLOCK_RANGE: %q no es un entero positivo", value)
+			}
+			blockRange = parsed
+		}
+		chainIndexerService, err = service.NewChainIndexerService(chainIndexRepo, blockchainService, startBlock, blockRange)
+		if err != nil {
+			log.Fatalf("Error en el indexador de eventos: %v", err)
+		}
+	}
+	printerMonitorService := service.NewPrinterMonitorService(printerRepo, wsHub)
+	printerService := service.NewPrinterService(printerRepo, printerDispatcher, printerMonitorService)
+	receiptService := service.NewReceiptService(orderService, printerRepo, printerDispatcher)
+
+	// Worker de la cola de impresión (reintentos y failover)
+	go printQueueService.Run()
+	// Monitor de conectividad de impresoras
+	go printerMonitorService.Run()
+	// Worker del outbox de notarización (reintentos, confirmación y recuperación al arrancar)
+	go notarizationService.Run()
+	// Indexador de los eventos InvoiceSecured de la cadena
+	if chainIndexerService != nil {
+		go chainIndexerService.Run()
+	}
 
 	// Handlers
 	userHandler := handler.NewUserHandler(userService)
@@ -81,6 +242,14 @@ func main() {
 	stationHandler := handler.NewStationHandler(stationService)
 	printerHandler := handler.NewPrinterHandler(printerService)
 	kitchenTicketHandler := handler.NewKitchenTicketHandler(kitchenTicketService)
+	printJobHandler := handler.NewPrintJobHandler(printQueueService)
+	kdsHandler := handler.NewKDSHandler(kdsService)
+	serviceChargeHandler := handler.NewServiceChargeHandler(serviceChargeService)
+	reportHandler := handler.NewReportHandler(reportService)
+	promotionHandler := handler.NewPromotionHandler(promotionService)
+	receiptHandler := handler.NewReceiptHandler(receiptService)
+	auditHandler := handler.NewAuditHandler(auditService)
+	chainIndexHandler := handler.NewChainIndexHandler(chainIndexerService)
 
 	app := fiber.New()
 	app.Use(cors.New())
@@ -92,7 +261,7 @@ func main() {
 	}
 	app.Static("/api/static", uploadsDir)
 
-	router.SetupRoutes(app, authHandler, userHandler, menuHandler, orderHandler, tableHandler, categoryHandler, ingredientHandler, accompanimentHandler, wsHandler, stationHandler, printerHandler, kitchenTicketHandler)
+	router.SetupRoutes(app, authHandler, userHandler, menuHandler, orderHandler, tableHandler, categoryHandler, ingredientHandler, accompanimentHandler, wsHandler, stationHandler, printerHandler, kitchenTicketHandler, printJobHandler, kdsHandler, serviceChargeHandler, reportHandler, promotionHandler, receiptHandler, auditHandler, chainIndexHandler)
 
 	log.Println("Iniciando servidor en el puerto 8080...")
 	if err := app.Listen(":8080"); err != nil {
@@ -8,5 +8,5 @@ import "github.com/google/uuid"
 type Accompaniment struct {
 	ID    uuid.UUID `json:"id" db:"id"`
 	Name  string    `json:"name" db:"name"`
-	Price float64   `json:"price" db:"price"`
+	Price Money     `json:"price" db:"price"`
 }
\ No newline at end of file
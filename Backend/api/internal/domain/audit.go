@@ -0,0 +1,77 @@
+// =================================================================
+// Audit Domain Types
+// Facturas notarizadas descifradas para auditores externos, con los
+// datos para comprobarlas contra la cadena
+// =================================================================
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Formatos de exportación de facturas
+const (
+	AuditFormatJSON = "json"
+	AuditFormatCSV  = "csv"
+)
+
+// AuditInvoice es una factura notarizada tal como se registró, junto con su prueba
+type AuditInvoice struct {
+	OrderID     uuid.UUID  `json:"order_id"`
+	Backend     string     `json:"backend"`
+	Mode        string     `json:"mode"`         // "single" (factura en el evento) o "batch" (prueba Merkle)
+	InvoiceHash string     `json:"invoice_hash"` // Hash registrado en order_notarizations
+	TxHash      string     `json:"tx_hash"`
+	BlockNumber int64      `json:"block_number"`
+	ConfirmedAt *time.Time `json:"confirmed_at,omitempty"`
+	BatchID     *uuid.UUID `json:"batch_id,omitempty"`
+	MerkleRoot  string     `json:"merkle_root,omitempty"`
+	MerkleProof []string   `json:"merkle_proof,omitempty"`
+	ProofValid  *bool      `json:"proof_valid,omitempty"` // La prueba Merkle lleva de la factura a merkle_root
+	KeyID       string     `json:"key_id,omitempty"`      // Clave con la que se cifró la factura (vacío: formato sin versión)
+
+	// PayloadValid: el hash de la factura descifrada corresponde a su contenido y al registrado
+	PayloadValid bool               `json:"payload_valid"`
+	Invoice      *BlockchainInvoice `json:"invoice,omitempty"`  // Factura descifrada (sin envelope)
+	Envelope     *InvoiceEnvelope   `json:"envelope,omitempty"` // Factura cifrada para el auditor (con envelope)
+	Error        string             `json:"error,omitempty"`    // Motivo por el que no se pudo leer la factura
+}
+
+// InvoiceEnvelope es la factura (JSON) cifrada con AES-256-GCM bajo una clave de un solo
+// uso, que a su vez va cifrada con RSA-OAEP (SHA-256) para la llave pública del auditor.
+// Todos los campos binarios van en Base64.
+type InvoiceEnvelope struct {
+	Algorithm      string `json:"algorithm"`
+	KeyFingerprint string `json:"key_fingerprint"` // Llave del auditor que puede abrirlo
+	EncryptedKey   string `json:"encrypted_key"`
+	Nonce          string `json:"nonce"`
+	Ciphertext     string `json:"ciphertext"`
+}
+
+// AuditInvoiceList es una página de facturas notarizadas en [From, To)
+type AuditInvoiceList struct {
+	From           time.Time      `json:"from"`
+	To             time.Time      `json:"to"`
+	Total          int            `json:"total"`
+	Limit          int            `json:"limit"`
+	Offset         int            `json:"offset"`
+	KeyFingerprint string         `json:"key_fingerprint,omitempty"` // Llave de los envelopes, si se pidieron
+	Invoices       []AuditInvoice `json:"invoices"`
+}
+
+// AuditorKey es la llave pública RSA (PEM) registrada para un auditor
+type AuditorKey struct {
+	UserID      uuid.UUID  `json:"user_id" db:"user_id"`
+	PublicKey   string     `json:"public_key" db:"public_key"`
+	Fingerprint string     `json:"fingerprint" db:"fingerprint"`
+	GrantedBy   *uuid.UUID `json:"granted_by,omitempty" db:"granted_by"`
+	CreatedAt   time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at" db:"updated_at"`
+}
+
+// SetAuditorKeyRequest es el cuerpo de PUT /api/audit/auditors/:id/key
+type SetAuditorKeyRequest struct {
+	PublicKey string `json:"public_key"`
+}
@@ -16,15 +16,22 @@ import (
 // BlockchainInvoice representa una factura optimizada para blockchain
 // Solo contiene la información esencial para certificar la transacción
 type BlockchainInvoice struct {
-	OrderID     uuid.UUID        `json:"order_id"`
-	WaiterID    uuid.UUID        `json:"waiter_id"`
-	WaiterName  string           `json:"waiter_name"`
-	CashierID   *uuid.UUID       `json:"cashier_id,omitempty"`
-	TableNumber int              `json:"table_number"`
-	Total       float64          `json:"total"`
-	Items       []BlockchainItem `json:"items"`
-	Timestamp   time.Time        `json:"timestamp"`
-	Hash        string           `json:"hash"` // Hash para verificación de integridad
+	OrderID       uuid.UUID        `json:"order_id"`
+	InvoiceNumber *int64           `json:"invoice_number,omitempty"` // Consecutivo fiscal (se asigna al pagar)
+	WaiterID      uuid.UUID        `json:"waiter_id"`
+	WaiterName    string           `json:"waiter_name"`
+	CashierID     *uuid.UUID       `json:"cashier_id,omitempty"`
+	TableNumber   int              `json:"table_number"`
+	Subtotal      Money            `json:"subtotal"`
+	Discount      Money            `json:"discount"`
+	ServiceCharge Money            `json:"service_charge"`
+	Tax           Money            `json:"tax"`
+	TaxBreakdown  TaxBreakdown     `json:"tax_breakdown"`
+	Total         Money            `json:"total"`
+	Tip           Money            `json:"tip"` // Propina del mesero, no incluida en el total
+	Items         []BlockchainItem `json:"items"`
+	Timestamp     time.Time        `json:"timestamp"`
+	Hash          string           `json:"hash"` // Hash para verificación de integridad
 }
 
 // BlockchainItem representa un item de menú optimizado para blockchain
@@ -33,7 +40,7 @@ type BlockchainItem struct {
 	MenuItemID                uuid.UUID   `json:"menu_item_id"`
 	MenuItemName              string      `json:"menu_item_name"`
 	Quantity                  int         `json:"quantity"`
-	PriceAtOrder              float64     `json:"price_at_order"`
+	PriceAtOrder              Money       `json:"price_at_order"`
 	ActiveIngredientsIDs      []uuid.UUID `json:"active_ingredients_ids,omitempty"`      // Ingredientes que SÍ lleva
 	SelectedAccompanimentsIDs []uuid.UUID `json:"selected_accompaniments_ids,omitempty"` // Acompañamientos que SÍ lleva
 	Notes                     *string     `json:"notes,omitempty"`
@@ -68,14 +75,21 @@ func CreateBlockchainInvoice(order *Order) *BlockchainInvoice {
 	}
 
 	invoice := &BlockchainInvoice{
-		OrderID:     order.ID,
-		WaiterID:    order.WaiterID,
-		WaiterName:  order.WaiterName,
-		CashierID:   order.CashierID,
-		TableNumber: order.TableNumber,
-		Total:       order.Total,
-		Items:       items,
-		Timestamp:   order.UpdatedAt,
+		OrderID:       order.ID,
+		InvoiceNumber: order.InvoiceNumber,
+		WaiterID:      order.WaiterID,
+		WaiterName:    order.WaiterName,
+		CashierID:     order.CashierID,
+		TableNumber:   order.TableNumber,
+		Subtotal:      order.Subtotal,
+		Discount:      order.Discount,
+		ServiceCharge: order.ServiceCharge,
+		Tax:           order.Tax,
+		TaxBreakdown:  order.TaxBreakdown,
+		Total:         order.Total,
+		Tip:           order.Tip,
+		Items:         items,
+		Timestamp:     order.UpdatedAt,
 	}
 
 	// Calcular hash para integridad
@@ -0,0 +1,103 @@
+// =================================================================
+// Chain Index Domain Types
+// Eventos InvoiceSecured indexados desde la cadena y su comparación
+// con las órdenes pagadas
+// =================================================================
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ChainInvoiceEvent es un evento InvoiceSecured indexado
+type ChainInvoiceEvent struct {
+	ID             uuid.UUID  `json:"id" db:"id"`
+	Source         string     `json:"source" db:"source"`               // Backend (y contrato) del que se leyó
+	OrderID        *uuid.UUID `json:"order_id,omitempty" db:"order_id"` // nil si no se pudo saber de qué orden es
+	OrderIDHash    string     `json:"order_id_hash" db:"order_id_hash"` // keccak256 del ID de la orden (topic indexado)
+	TxHash         string     `json:"tx_hash" db:"tx_hash"`
+	LogIndex       int        `json:"log_index" db:"log_index"`
+	BlockNumber    int64      `json:"block_number" db:"block_number"`
+	BlockHash      string     `json:"block_hash" db:"block_hash"`
+	BlockTimestamp time.Time  `json:"block_timestamp" db:"block_timestamp"`
+	CreatedAt      time.Time  `json:"created_at" db:"created_at"`
+	OrderStatus    *string    `json:"order_status,omitempty" db:"order_status"` // Estado actual de la orden (nil si no existe)
+}
+
+// ChainIndexCursor es el último bloque indexado de una fuente
+type ChainIndexCursor struct {
+	Source        string    `json:"source" db:"source"`
+	LastBlock     int64     `json:"last_block" db:"last_block"`
+	LastBlockHash string    `json:"last_block_hash" db:"last_block_hash"`
+	UpdatedAt     time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// ChainIndexerStatus es el avance del indexador
+type ChainIndexerStatus struct {
+	Source        string     `json:"source"`
+	StartBlock    int64      `json:"start_block"`
+	LastBlock     *int64     `json:"last_block,omitempty"` // nil: aún no indexó nada
+	LastBlockHash string     `json:"last_block_hash,omitempty"`
+	HeadBlock     *int64     `json:"head_block,omitempty"` // Último bloque de la cadena
+	Lag           *int64     `json:"lag,omitempty"`        // Bloques por indexar
+	Events        int        `json:"events"`
+	Reorgs        int        `json:"reorgs"` // Reorganizaciones detectadas desde el arranque
+	LastError     string     `json:"last_error,omitempty"`
+	LastRunAt     *time.Time `json:"last_run_at,omitempty"`
+}
+
+// ChainInvoiceEventList es una página de eventos indexados
+type ChainInvoiceEventList struct {
+	Total  int                 `json:"total"`
+	Limit  int                 `json:"limit"`
+	Offset int                 `json:"offset"`
+	Events []ChainInvoiceEvent `json:"events"`
+}
+
+// Motivos de una diferencia entre las órdenes pagadas y la cadena
+const (
+	// Orden pagada sin evento
+	ReconcileNotNotarized = "not_notarized"    // Sin notarización o pendiente/fallida
+	ReconcileNotIndexed   = "not_indexed_yet"  // Enviada o confirmada después del último bloque indexado
+	ReconcileOtherBackend = "other_backend"    // Notarizada con otro backend que el indexado
+	ReconcileMissing      = "missing_on_chain" // Confirmada en un bloque ya indexado pero sin evento
+
+	// Evento sin orden pagada
+	ReconcileUnknownOrder  = "unknown_order"   // No se pudo saber de qué orden es
+	ReconcileOrderNotFound = "order_not_found" // La orden no existe en la BD
+	ReconcileOrderNotPaid  = "order_not_paid"  // La orden existe pero no está pagada
+)
+
+// ReconciliationOrder es una orden pagada sin evento InvoiceSecured indexado
+type ReconciliationOrder struct {
+	OrderID            uuid.UUID           `json:"order_id" db:"order_id"`
+	InvoiceNumber      *int64              `json:"invoice_number,omitempty" db:"invoice_number"`
+	TableNumber        int                 `json:"table_number" db:"table_number"`
+	Total              Money               `json:"total" db:"total"`
+	UpdatedAt          time.Time           `json:"updated_at" db:"updated_at"`
+	NotarizationStatus *NotarizationStatus `json:"notarization_status,omitempty" db:"notarization_status"`
+	Backend            *string             `json:"backend,omitempty" db:"backend"`
+	TxHash             *string             `json:"tx_hash,omitempty" db:"tx_hash"`
+	BlockNumber        *int64              `json:"block_number,omitempty" db:"block_number"`
+	ConfirmedAt        *time.Time          `json:"confirmed_at,omitempty" db:"confirmed_at"`
+	Reason             string              `json:"reason"`
+}
+
+// ReconciliationEvent es un evento indexado sin orden pagada
+type ReconciliationEvent struct {
+	ChainInvoiceEvent
+	Reason string `json:"reason"`
+}
+
+// ChainReconciliation compara las órdenes pagadas con los eventos indexados de la fuente actual
+type ChainReconciliation struct {
+	Source                       string                `json:"source"`
+	LastBlock                    *int64                `json:"last_block,omitempty"`
+	PaidWithoutRecordTotal       int                   `json:"paid_without_record_total"`
+	PaidWithoutRecord            []ReconciliationOrder `json:"paid_without_record"`
+	RecordsWithoutPaidOrderTotal int                   `json:"records_without_paid_order_total"`
+	RecordsWithoutPaidOrder      []ReconciliationEvent `json:"records_without_paid_order"`
+	GeneratedAt                  time.Time             `json:"generated_at"`
+}
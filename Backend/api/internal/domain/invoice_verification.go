@@ -0,0 +1,80 @@
+// =================================================================
+// Invoice Verification Domain Model
+// Resultado de comparar la factura notarizada en blockchain con la
+// orden guardada en la base de datos
+// =================================================================
+package domain
+
+import (
+	"bytes"
+	"encoding/json"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// VerificationStatus define el resultado de la verificación de una factura
+type VerificationStatus string
+
+const (
+	VerificationValid        VerificationStatus = "valid"         // La orden coincide con la factura notarizada
+	VerificationAltered      VerificationStatus = "altered"       // La orden cambió después de notarizarse
+	VerificationNotNotarized VerificationStatus = "not_notarized" // No hay evento en el contrato para la orden (o su lote)
+	VerificationInvalid      VerificationStatus = "invalid"       // La factura notarizada no se puede descifrar o no es íntegra
+)
+
+// InvoiceVerification compara la factura registrada en el contrato con la que se obtiene
+// hoy de la orden
+type InvoiceVerification struct {
+	OrderID          uuid.UUID          `json:"order_id"`
+	Status           VerificationStatus `json:"status"`
+	Backend          string             `json:"backend"` // Backend de notarización consultado
+	Mode             string             `json:"mode"`    // "single" (factura en el evento) o "batch" (prueba Merkle)
+	Altered          bool               `json:"altered"`
+	CurrentHash      string             `json:"current_hash"`                // Hash de la factura recalculada desde la BD
+	NotarizedHash    string             `json:"notarized_hash,omitempty"`    // Hash de la factura descifrada del evento
+	PayloadValid     bool               `json:"payload_valid"`               // El hash del evento corresponde a su contenido
+	TxHash           string             `json:"tx_hash,omitempty"`           // Transacción del evento verificado
+	BlockNumber      int64              `json:"block_number,omitempty"`      // Bloque del evento verificado
+	NotarizedAt      *time.Time         `json:"notarized_at,omitempty"`      // block.timestamp del evento
+	Events           int                `json:"events"`                      // Eventos encontrados (InvoiceSecured o BatchAnchored del lote)
+	MerkleRoot       string             `json:"merkle_root,omitempty"`       // Raíz anclada del lote
+	KeyID            string             `json:"key_id,omitempty"`            // Clave con la que se cifró la factura (vacío: formato sin versión)
+	ProofValid       *bool              `json:"proof_valid,omitempty"`       // La prueba Merkle lleva de la factura a la raíz
+	ChangedFields    []string           `json:"changed_fields,omitempty"`    // Campos de la factura que difieren
+	Error            string             `json:"error,omitempty"`             // Motivo de un resultado 'invalid'
+	NotarizedInvoice *BlockchainInvoice `json:"notarized_invoice,omitempty"` // Factura tal como quedó notarizada
+	VerifiedAt       time.Time          `json:"verified_at"`
+}
+
+// ChangedFields devuelve los campos (nombres JSON) en los que la factura difiere de other,
+// sin contar el hash
+func (bi *BlockchainInvoice) ChangedFields(other *BlockchainInvoice) []string {
+	mine, theirs := invoiceFields(bi), invoiceFields(other)
+
+	changed := make([]string, 0)
+	for field, value := range mine {
+		if field == "hash" {
+			continue
+		}
+		if !bytes.Equal(value, theirs[field]) {
+			changed = append(changed, field)
+		}
+	}
+	for field := range theirs {
+		if _, ok := mine[field]; !ok && field != "hash" {
+			changed = append(changed, field)
+		}
+	}
+	sort.Strings(changed)
+	return changed
+}
+
+// invoiceFields separa la factura en sus campos JSON
+func invoiceFields(bi *BlockchainInvoice) map[string]json.RawMessage {
+	data, _ := json.Marshal(bi)
+	fields := make(map[string]json.RawMessage)
+	_ = json.Unmarshal(data, &fields)
+	return fields
+}
@@ -0,0 +1,73 @@
+// =================================================================
+// KDS Ticket Domain Model
+// Tickets de cocina mostrados en pantalla (Kitchen Display System)
+// =================================================================
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// KDSTicketStatus define los estados de un ticket en la pantalla de la estación
+type KDSTicketStatus string
+
+const (
+	KDSTicketNew        KDSTicketStatus = "new"         // Recién llegado a la estación
+	KDSTicketInProgress KDSTicketStatus = "in_progress" // En preparación
+	KDSTicketReady      KDSTicketStatus = "ready"       // Listo para servir
+	KDSTicketVoided     KDSTicketStatus = "voided"      // La orden se anuló antes de terminarlo
+)
+
+// Next devuelve el estado siguiente al hacer "bump" sobre el ticket
+func (s KDSTicketStatus) Next() (KDSTicketStatus, bool) {
+	switch s {
+	case KDSTicketNew:
+		return KDSTicketInProgress, true
+	case KDSTicketInProgress:
+		return KDSTicketReady, true
+	}
+	return s, false
+}
+
+// ItemStatus es el estado que toman los items de la estación cuando el ticket pasa a este
+// estado ("" si no tiene equivalente)
+func (s KDSTicketStatus) ItemStatus() string {
+	switch s {
+	case KDSTicketNew:
+		return ItemStatusPending
+	case KDSTicketInProgress:
+		return ItemStatusPreparing
+	case KDSTicketReady:
+		return ItemStatusReady
+	}
+	return ""
+}
+
+// KDSTicket es un KitchenTicket con su estado de preparación en una estación
+type KDSTicket struct {
+	ID          uuid.UUID       `json:"id" db:"id"`
+	OrderID     uuid.UUID       `json:"order_id" db:"order_id"`
+	StationID   uuid.UUID       `json:"station_id" db:"station_id"`
+	StationName string          `json:"station_name" db:"station_name"`
+	Status      KDSTicketStatus `json:"status" db:"status"`
+	Ticket      KitchenTicket   `json:"ticket" db:"ticket"`
+	CreatedAt   time.Time       `json:"created_at" db:"created_at"`
+	StartedAt   *time.Time      `json:"started_at,omitempty" db:"started_at"`
+	ReadyAt     *time.Time      `json:"ready_at,omitempty" db:"ready_at"`
+	UpdatedAt   time.Time       `json:"updated_at" db:"updated_at"`
+}
+
+// BumpKDSTicketRequest permite fijar un estado explícito; vacío avanza al siguiente
+type BumpKDSTicketRequest struct {
+	Status KDSTicketStatus `json:"status"`
+}
+
+// OrderStationsReady se envía al mesero cuando todas las estaciones terminaron su parte
+type OrderStationsReady struct {
+	OrderID     uuid.UUID   `json:"order_id"`
+	OrderNumber string      `json:"order_number"`
+	TableNumber int         `json:"table_number"`
+	Tickets     []KDSTicket `json:"tickets"`
+}
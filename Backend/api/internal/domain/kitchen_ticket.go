@@ -14,6 +14,7 @@ type KitchenTicket struct {
 	OrderID      uuid.UUID           `json:"order_id"`
 	OrderNumber  string              `json:"order_number"` // Ej: "ORD-001"
 	TableNumber  int                 `json:"table_number"`
+	WaiterID     uuid.UUID           `json:"waiter_id"`
 	WaiterName   string              `json:"waiter_name"`
 	StationID    uuid.UUID           `json:"station_id"`
 	StationName  string              `json:"station_name"`
@@ -21,8 +22,17 @@ type KitchenTicket struct {
 	CreatedAt    time.Time           `json:"created_at"`
 	OrderType    string              `json:"order_type"` // "mesa", "llevar", "domicilio"
 	SpecialNotes string              `json:"special_notes,omitempty"`
+	IsReprint    bool                `json:"is_reprint,omitempty"`
+	IsUpdate     bool                `json:"is_update,omitempty"` // Ticket de modificación (solo cambios)
+	IsVoid       bool                `json:"is_void,omitempty"`   // Ticket de anulación de la orden completa
 }
 
+// Tipos de cambio en un ticket de modificación
+const (
+	TicketChangeAdd    = "ADD"    // Items agregados a la orden
+	TicketChangeCancel = "CANCEL" // Items retirados de la orden
+)
+
 // KitchenTicketItem representa un item dentro del ticket de cocina
 type KitchenTicketItem struct {
 	MenuItemName   string          `json:"menu_item_name"`
@@ -30,6 +40,7 @@ type KitchenTicketItem struct {
 	Notes          string          `json:"notes,omitempty"`
 	Customizations *Customizations `json:"customizations,omitempty"`
 	IsTakeout      bool            `json:"is_takeout"`
+	Change         string          `json:"change,omitempty"` // "ADD" o "CANCEL" en tickets de modificación
 }
 
 // StationTicketsResponse agrupa todos los tickets por estación para una orden
@@ -51,11 +62,21 @@ type PrintResponse struct {
 	TicketsSent  int               `json:"tickets_sent"`
 	FailedPrints []FailedPrintInfo `json:"failed_prints,omitempty"`
 	Tickets      []KitchenTicket   `json:"tickets"` // Para debugging
+	Jobs         []PrintJob        `json:"jobs,omitempty"`
 }
 
 // FailedPrintInfo contiene info de impresiones fallidas
 type FailedPrintInfo struct {
-	StationName string `json:"station_name"`
-	PrinterName string `json:"printer_name"`
-	Error       string `json:"error"`
+	StationName string     `json:"station_name"`
+	PrinterName string     `json:"printer_name"`
+	Error       string     `json:"error"`
+	JobID       *uuid.UUID `json:"job_id,omitempty"` // Trabajo en cola que se reintentará
+}
+
+// KitchenTicketFile describe un ticket PDF generado por una impresora tipo "pdf"
+type KitchenTicketFile struct {
+	FileName  string    `json:"file_name"`
+	Size      int64     `json:"size"`
+	CreatedAt time.Time `json:"created_at"`
+	URL       string    `json:"url"`
 }
@@ -9,9 +9,10 @@ type MenuItem struct {
 	ID             uuid.UUID       `json:"id" db:"id"`
 	Name           string          `json:"name" db:"name"`
 	Description    string          `json:"description" db:"description"`
-	Price          float64         `json:"price" db:"price"`
+	Price          Money           `json:"price" db:"price"`
 	CategoryID     uuid.UUID       `json:"category_id" db:"category_id"`
 	CategoryName   string          `json:"category_name" db:"category_name"`
+	TaxRate        Percent         `json:"tax_rate,omitempty" db:"tax_rate"` // Impuesto de la categoría
 	IsAvailable    bool            `json:"is_available" db:"is_available"`
 	OrderCount     int             `json:"order_count" db:"order_count"`
 	Ingredients    []Ingredient    `json:"ingredients,omitempty"`
@@ -0,0 +1,299 @@
+// =================================================================
+// Money Domain Type
+// Montos en punto fijo (centavos) para precios y totales. Evita el
+// error de redondeo de float64 en sumas y en el hash de la factura
+// =================================================================
+package domain
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Money es un monto en centavos (2 decimales, igual que las columnas numeric(10, 2)).
+// En JSON se serializa como número con dos decimales (12.50) para no romper el frontend.
+type Money int64
+
+// MoneyScale es la cantidad de centavos por unidad
+const MoneyScale = 100
+
+// ErrInvalidMoney indica un monto que no se pudo interpretar
+var ErrInvalidMoney = errors.New("monto inválido")
+
+// Currency describe una moneda y cuántos decimales usa al cobrar
+type Currency struct {
+	Code     string `json:"code"`
+	Decimals int    `json:"decimals"` // 0, 1 o 2
+}
+
+// Monedas soportadas (decimales según ISO 4217)
+var currencies = map[string]Currency{
+	"COP": {Code: "COP", Decimals: 2},
+	"USD": {Code: "USD", Decimals: 2},
+	"EUR": {Code: "EUR", Decimals: 2},
+	"MXN": {Code: "MXN", Decimals: 2},
+	"CLP": {Code: "CLP", Decimals: 0},
+	"PYG": {Code: "PYG", Decimals: 0},
+}
+
+var (
+	currencyMu      sync.RWMutex
+	defaultCurrency = currencies["COP"]
+)
+
+// SetDefaultCurrency configura la moneda del restaurante (se llama al arrancar)
+func SetDefaultCurrency(code string) error {
+	currency, ok := currencies[strings.ToUpper(code)]
+	if !ok {
+		return fmt.Errorf("moneda no soportada: %s", code)
+	}
+	currencyMu.Lock()
+	defaultCurrency = currency
+	currencyMu.Unlock()
+	return nil
+}
+
+// step es el monto mínimo cobrable en la moneda, en centavos (1 con 2 decimales, 100 con 0)
+func (c Currency) step() Money {
+	step := Money(1)
+	for i := c.Decimals; i < 2; i++ {
+		step *= 10
+	}
+	return step
+}
+
+// DefaultCurrency devuelve la moneda configurada
+func DefaultCurrency() Currency {
+	currencyMu.RLock()
+	defer currencyMu.RUnlock()
+	return defaultCurrency
+}
+
+// ParseMoney interpreta un decimal en texto ("12", "12.5", "-3.75") sin pasar por float64.
+// Más de dos decimales se redondean al centavo (mitad lejos de cero).
+func ParseMoney(s string) (Money, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0, ErrInvalidMoney
+	}
+
+	negative := false
+	switch s[0] {
+	case '-':
+		negative = true
+		s = s[1:]
+	case '+':
+		s = s[1:]
+	}
+
+	intPart, fracPart, _ := strings.Cut(s, ".")
+	// Un signo o un punto solos ("-", ".") no son un monto
+	if intPart == "" && fracPart == "" {
+		return 0, fmt.Errorf("%w: %q", ErrInvalidMoney, s)
+	}
+	if intPart == "" {
+		intPart = "0"
+	}
+	if !isDigits(intPart) || (fracPart != "" && !isDigits(fracPart)) {
+		return 0, fmt.Errorf("%w: %q", ErrInvalidMoney, s)
+	}
+
+	units, err := strconv.ParseInt(intPart, 10, 64)
+	if err != nil || units > math.MaxInt64/MoneyScale-1 {
+		return 0, fmt.Errorf("%w: %q", ErrInvalidMoney, s)
+	}
+
+	// Centavos: los dos primeros decimales; el tercero decide el redondeo
+	padded := fracPart + "00"
+	cents, _ := strconv.ParseInt(padded[:2], 10, 64)
+	amount := units*MoneyScale + cents
+	if len(fracPart) > 2 && fracPart[2] >= '5' {
+		amount++
+	}
+
+	if negative {
+		amount = -amount
+	}
+	return Money(amount), nil
+}
+
+// MoneyFromFloat convierte un float64 redondeando al centavo más cercano
+func MoneyFromFloat(f float64) Money {
+	return Money(math.Round(f * MoneyScale))
+}
+
+// MoneyFromUnits crea un monto a partir de unidades enteras (pesos, dólares...)
+func MoneyFromUnits(units int64) Money {
+	return Money(units * MoneyScale)
+}
+
+// Add suma dos montos
+func (m Money) Add(other Money) Money { return m + other }
+
+// Sub resta dos montos
+func (m Money) Sub(other Money) Money { return m - other }
+
+// Mul multiplica el monto por una cantidad entera (precio × cantidad)
+func (m Money) Mul(quantity int) Money { return m * Money(quantity) }
+
+// Round redondea el monto a los decimales de la moneda (mitad lejos de cero).
+// Con 2 decimales no cambia nada; con 0 redondea a la unidad.
+func (m Money) Round(currency Currency) Money {
+	step := int64(currency.step())
+	if step == 1 {
+		return m
+	}
+	amount := int64(m)
+	remainder := amount % step
+	amount -= remainder
+	if remainder*2 >= step {
+		amount += step
+	} else if remainder*2 <= -step {
+		amount -= step
+	}
+	return Money(amount)
+}
+
+// Split divide el monto en n partes que suman exactamente el original. Cada parte
+// respeta los decimales de la moneda; los centavos sobrantes van en las primeras partes.
+func (m Money) Split(n int, currency Currency) []Money {
+	if n <= 0 {
+		return nil
+	}
+	step := currency.step()
+	units, remainder := m/step, m%step
+	base, extra := units/Money(n), units%Money(n)
+	shares := make([]Money, n)
+	for i := range shares {
+		shares[i] = base * step
+		if Money(i) < extra {
+			shares[i] += step
+		}
+	}
+	shares[n-1] += remainder
+	return shares
+}
+
+// Float64 devuelve el monto como float64 (solo para mostrar, nunca para calcular)
+func (m Money) Float64() float64 {
+	return float64(m) / MoneyScale
+}
+
+// String devuelve el monto con dos decimales ("12.50")
+func (m Money) String() string {
+	amount := int64(m)
+	sign := ""
+	if amount < 0 {
+		sign = "-"
+		amount = -amount
+	}
+	return fmt.Sprintf("%s%d.%02d", sign, amount/MoneyScale, amount%MoneyScale)
+}
+
+// MarshalJSON serializa como número JSON con dos decimales (determinista para el hash)
+func (m Money) MarshalJSON() ([]byte, error) {
+	return []byte(m.String()), nil
+}
+
+// UnmarshalJSON acepta un número (12.5) o un texto ("12.50")
+func (m *Money) UnmarshalJSON(data []byte) error {
+	s := strings.TrimSpace(string(data))
+	if s == "null" {
+		return nil
+	}
+	s = strings.Trim(s, `"`)
+	// Números en notación científica (1e3) que algunos clientes envían
+	if strings.ContainsAny(s, "eE") {
+		f, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return fmt.Errorf("%w: %q", ErrInvalidMoney, s)
+		}
+		*m = MoneyFromFloat(f)
+		return nil
+	}
+	parsed, err := ParseMoney(s)
+	if err != nil {
+		return err
+	}
+	*m = parsed
+	return nil
+}
+
+// Value guarda el monto como texto decimal; Postgres lo convierte a numeric sin pérdida
+func (m Money) Value() (driver.Value, error) {
+	return m.String(), nil
+}
+
+// Scan lee columnas numeric (llegan como []byte) y, por compatibilidad, float64 o int64
+func (m *Money) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		*m = 0
+	case []byte:
+		parsed, err := ParseMoney(string(v))
+		if err != nil {
+			return err
+		}
+		*m = parsed
+	case string:
+		parsed, err := ParseMoney(v)
+		if err != nil {
+			return err
+		}
+		*m = parsed
+	case float64:
+		*m = MoneyFromFloat(v)
+	case int64:
+		*m = MoneyFromUnits(v)
+	default:
+		return fmt.Errorf("%w: tipo %T", ErrInvalidMoney, value)
+	}
+	return nil
+}
+
+func isDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return s != ""
+}
+
+// Percent es un porcentaje con dos decimales guardado en centésimas (10.5% = 1050).
+// Se serializa igual que Money: número JSON con dos decimales (10.50).
+type Percent int64
+
+// ParsePercent interpreta un porcentaje en texto ("10", "12.5")
+func ParsePercent(s string) (Percent, error) {
+	m, err := ParseMoney(s)
+	return Percent(m), err
+}
+
+// ApplyPercent calcula el porcentaje del monto, redondeando al centavo (mitad lejos de cero)
+func (m Money) ApplyPercent(p Percent) Money {
+	product := int64(m) * int64(p)
+	const divisor = 100 * MoneyScale // porcentaje (÷100) en centésimas (÷100)
+	result, remainder := product/divisor, product%divisor
+	if remainder*2 >= divisor {
+		result++
+	} else if remainder*2 <= -divisor {
+		result--
+	}
+	return Money(result)
+}
+
+func (p Percent) String() string { return Money(p).String() }
+
+func (p Percent) MarshalJSON() ([]byte, error) { return Money(p).MarshalJSON() }
+
+func (p *Percent) UnmarshalJSON(data []byte) error { return (*Money)(p).UnmarshalJSON(data) }
+
+func (p Percent) Value() (driver.Value, error) { return Money(p).Value() }
+
+func (p *Percent) Scan(value interface{}) error { return (*Money)(p).Scan(value) }
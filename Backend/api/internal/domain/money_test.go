@@ -0,0 +1,139 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseMoney(t *testing.T) {
+	cases := []struct {
+		in   string
+		want Money
+	}{
+		{"12", 1200},
+		{"12.5", 1250},
+		{"12.50", 1250},
+		{" 7.05 ", 705},
+		{".75", 75},
+		{"3.", 300},
+		{"+4.20", 420},
+		{"-3.75", -375},
+		{"-0.5", -50},
+		// Más de dos decimales: el tercero redondea (mitad lejos de cero)
+		{"12.344", 1234},
+		{"12.345", 1235},
+		{"1.005", 101},
+		{"0.999", 100},
+		{"-1.005", -101},
+		{"-12.3449", -1234},
+	}
+	for _, tc := range cases {
+		got, err := ParseMoney(tc.in)
+		if err != nil {
+			t.Errorf("ParseMoney(%q) devolvió error: %v", tc.in, err)
+			continue
+		}
+		if got != tc.want {
+			t.Errorf("ParseMoney(%q) = %d, se esperaba %d", tc.in, got, tc.want)
+		}
+	}
+}
+
+func TestParseMoneyRejectsInvalidInput(t *testing.T) {
+	for _, in := range []string{"", "   ", "-", "+", ".", "-.", "abc", "12a", "1.2.3", "1,50", "--1", "1e3", "99999999999999999999"} {
+		if got, err := ParseMoney(in); !errors.Is(err, ErrInvalidMoney) {
+			t.Errorf("ParseMoney(%q) = %d, %v; se esperaba ErrInvalidMoney", in, got, err)
+		}
+	}
+}
+
+func TestMoneyRound(t *testing.T) {
+	cop := Currency{Code: "COP", Decimals: 2}
+	clp := Currency{Code: "CLP", Decimals: 0}
+	cases := []struct {
+		currency Currency
+		in, want Money
+	}{
+		// Con dos decimales el monto ya está en el paso de la moneda
+		{cop, 1234, 1234},
+		{cop, -1, -1},
+		// Sin decimales se redondea a la unidad (100 centavos), mitad lejos de cero
+		{clp, 1200, 1200},
+		{clp, 1249, 1200},
+		{clp, 1250, 1300},
+		{clp, 1299, 1300},
+		{clp, 49, 0},
+		{clp, 50, 100},
+		{clp, -1249, -1200},
+		{clp, -1250, -1300},
+		{clp, -50, -100},
+	}
+	for _, tc := range cases {
+		if got := tc.in.Round(tc.currency); got != tc.want {
+			t.Errorf("Money(%d).Round(%s) = %d, se esperaba %d", tc.in, tc.currency.Code, got, tc.want)
+		}
+	}
+}
+
+func TestMoneySplit(t *testing.T) {
+	cop := Currency{Code: "COP", Decimals: 2}
+	clp := Currency{Code: "CLP", Decimals: 0}
+	cases := []struct {
+		name     string
+		amount   Money
+		n        int
+		currency Currency
+		want     []Money
+	}{
+		{"exacto", 9000, 3, cop, []Money{3000, 3000, 3000}},
+		{"centavos sobrantes en las primeras partes", 10000, 3, cop, []Money{3334, 3333, 3333}},
+		{"una sola parte", 1234, 1, cop, []Money{1234}},
+		{"menos centavos que partes", 2, 3, cop, []Money{1, 1, 0}},
+		{"sin decimales reparte unidades enteras", 100000, 3, clp, []Money{33400, 33300, 33300}},
+		{"monto fuera del paso queda en la última parte", 100050, 4, clp, []Money{25000, 25000, 25000, 25050}},
+		{"cero", 0, 2, cop, []Money{0, 0}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			shares := tc.amount.Split(tc.n, tc.currency)
+			if len(shares) != tc.n {
+				t.Fatalf("Split(%d) devolvió %d partes", tc.n, len(shares))
+			}
+			var sum Money
+			for i, share := range shares {
+				sum += share
+				if share != tc.want[i] {
+					t.Errorf("parte %d = %d, se esperaba %d", i+1, share, tc.want[i])
+				}
+			}
+			if sum != tc.amount {
+				t.Errorf("las partes suman %d, el monto es %d", sum, tc.amount)
+			}
+		})
+	}
+
+	if shares := Money(1000).Split(0, cop); shares != nil {
+		t.Errorf("Split(0) = %v, se esperaba nil", shares)
+	}
+}
+
+func TestMoneyApplyPercent(t *testing.T) {
+	cases := []struct {
+		amount Money
+		pct    Percent
+		want   Money
+	}{
+		{10000, 1000, 1000}, // 10% de 100.00
+		{10000, 1050, 1050}, // 10.5% de 100.00
+		{1235, 1000, 124},   // 1.235 → 1.24
+		{1234, 1000, 123},   // 1.234 → 1.23
+		{-1235, 1000, -124}, // mitad lejos de cero también en negativos
+		{999, 0, 0},
+		{999, 10000, 999}, // 100%
+	}
+	for _, tc := range cases {
+		if got := tc.amount.ApplyPercent(tc.pct); got != tc.want {
+			t.Errorf("Money(%d).ApplyPercent(%s) = %d, se esperaba %d", tc.amount, tc.pct, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,75 @@
+// =================================================================
+// Order Notarization Domain Model
+// Registro de la notarización de la factura de una orden en blockchain
+// =================================================================
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// NotarizationStatus define los estados de la notarización de una factura
+type NotarizationStatus string
+
+const (
+	NotarizationPending   NotarizationStatus = "pending"   // En el outbox, esperando envío (o reintento)
+	NotarizationSent      NotarizationStatus = "sent"      // Transacción enviada, esperando que se mine
+	NotarizationConfirmed NotarizationStatus = "confirmed" // Transacción minada con éxito
+	NotarizationFailed    NotarizationStatus = "failed"    // Se agotaron los reintentos
+)
+
+// OrderNotarization es la prueba de qué transacción certifica la factura de una orden
+type OrderNotarization struct {
+	ID            uuid.UUID          `json:"id" db:"id"`
+	OrderID       uuid.UUID          `json:"order_id" db:"order_id"`
+	Status        NotarizationStatus `json:"status" db:"status"`
+	InvoiceHash   string             `json:"invoice_hash" db:"invoice_hash"` // Hash de la BlockchainInvoice notarizada
+	TxHash        *string            `json:"tx_hash,omitempty" db:"tx_hash"`
+	Nonce         *int64             `json:"nonce,omitempty" db:"nonce"`     // Nonce de la wallet usado en tx_hash
+	Backend       *string            `json:"backend,omitempty" db:"backend"` // Backend de notarización de tx_hash
+	BlockNumber   *int64             `json:"block_number,omitempty" db:"block_number"`
+	Attempts      int                `json:"attempts" db:"attempts"`
+	LastError     *string            `json:"last_error,omitempty" db:"last_error"`
+	NextAttemptAt time.Time          `json:"next_attempt_at" db:"next_attempt_at"` // Próximo envío o consulta del recibo
+	SentAt        *time.Time         `json:"sent_at,omitempty" db:"sent_at"`
+	ConfirmedAt   *time.Time         `json:"confirmed_at,omitempty" db:"confirmed_at"`
+
+	// Notarización por lotes: la transacción ancla la raíz Merkle del lote y la factura se
+	// prueba con merkle_proof. Sin lote, la factura cifrada viaja en su propia transacción.
+	BatchID          *uuid.UUID `json:"batch_id,omitempty" db:"batch_id"`
+	MerkleRoot       *string    `json:"merkle_root,omitempty" db:"merkle_root"`
+	MerkleProof      []string   `json:"merkle_proof,omitempty" db:"merkle_proof"`
+	EncryptedInvoice *string    `json:"-" db:"encrypted_invoice"` // Factura cifrada del lote (queda en la BD)
+
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// NotarizationBatch es un lote de facturas anclado con una sola transacción
+type NotarizationBatch struct {
+	ID            uuid.UUID          `json:"id" db:"id"`
+	MerkleRoot    string             `json:"merkle_root" db:"merkle_root"`
+	Size          int                `json:"size" db:"size"`
+	Status        NotarizationStatus `json:"status" db:"status"`
+	TxHash        *string            `json:"tx_hash,omitempty" db:"tx_hash"`
+	Nonce         *int64             `json:"nonce,omitempty" db:"nonce"`
+	Backend       *string            `json:"backend,omitempty" db:"backend"`
+	BlockNumber   *int64             `json:"block_number,omitempty" db:"block_number"`
+	Attempts      int                `json:"attempts" db:"attempts"`
+	LastError     *string            `json:"last_error,omitempty" db:"last_error"`
+	NextAttemptAt time.Time          `json:"next_attempt_at" db:"next_attempt_at"`
+	SentAt        *time.Time         `json:"sent_at,omitempty" db:"sent_at"`
+	ConfirmedAt   *time.Time         `json:"confirmed_at,omitempty" db:"confirmed_at"`
+	CreatedAt     time.Time          `json:"created_at" db:"created_at"`
+	UpdatedAt     time.Time          `json:"updated_at" db:"updated_at"`
+}
+
+// NotarizationBatchMember es la factura de una orden dentro de un lote, con su prueba
+type NotarizationBatchMember struct {
+	OrderID          uuid.UUID
+	InvoiceHash      string
+	EncryptedInvoice string
+	MerkleProof      []string
+}
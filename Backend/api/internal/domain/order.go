@@ -12,6 +12,10 @@ import (
 	"github.com/google/uuid"
 )
 
+// ErrOrderChanged indica que la orden cambió de estado (o recibió un pago) entre la validación
+// y la escritura: otra petición la modificó al mismo tiempo
+var ErrOrderChanged = errors.New("la orden cambió mientras se actualizaba, vuelve a intentarlo")
+
 // CustomizationsInput es lo que recibe el backend desde el frontend (solo IDs)
 // El frontend envía lo que NO quiere el cliente
 type CustomizationsInput struct {
@@ -50,7 +54,7 @@ type Order struct {
 	TableID     uuid.UUID   `json:"table_id" db:"table_id"`
 	TableNumber int         `json:"table_number" db:"table_number"`
 	Status      string      `json:"status" db:"status"`
-	Total       float64     `json:"total" db:"total"`
+	Total       Money       `json:"total" db:"total"` // subtotal - discount + service_charge (+ tax si los precios no lo incluyen; la propina va aparte)
 	Items       []OrderItem `json:"items"`
 	CreatedAt   time.Time   `json:"created_at" db:"created_at"`
 	UpdatedAt   time.Time   `json:"updated_at" db:"updated_at"`
@@ -63,19 +67,87 @@ type Order struct {
 	// Nuevos campos para el flujo de pago con evidencia
 	PaymentMethod    *string `json:"payment_method,omitempty" db:"payment_method"`
 	PaymentProofPath *string `json:"payment_proof_path,omitempty" db:"payment_proof_path"`
+	// Descuentos, cargo por servicio y propinas
+	Subtotal          Money   `json:"subtotal" db:"subtotal"`                       // Suma de los items
+	Discount          Money   `json:"discount" db:"discount"`                       // Descuentos aplicados (promociones y cupones)
+	ServiceChargeRate Percent `json:"service_charge_rate" db:"service_charge_rate"` // % vigente al crear la orden
+	ServiceCharge     Money   `json:"service_charge" db:"service_charge"`
+	Tip               Money   `json:"tip" db:"tip"` // Suma de las propinas recibidas con los pagos
+	// Impuestos y numeración
+	Tax           Money        `json:"tax" db:"tax"`
+	TaxBreakdown  TaxBreakdown `json:"tax_breakdown" db:"tax_breakdown"`
+	OrderNumber   int64        `json:"order_number" db:"order_number"`               // Consecutivo interno (tickets de cocina)
+	InvoiceNumber *int64       `json:"invoice_number,omitempty" db:"invoice_number"` // Consecutivo fiscal, se asigna al pasar a 'pagado'
+	// Transacción que notarizó la factura en blockchain (de order_notarizations)
+	BlockchainTxHash *string `json:"blockchain_tx_hash,omitempty" db:"blockchain_tx_hash"`
+}
+
+// OrderAmounts son los montos calculados de una orden
+type OrderAmounts struct {
+	Subtotal      Money
+	Discount      Money
+	ServiceCharge Money
+	Tax           Money
+	TaxBreakdown  TaxBreakdown
+	Total         Money
 }
 
 type OrderItem struct {
+	ID                  uuid.UUID            `json:"id" db:"id"`
 	MenuItemID          uuid.UUID            `json:"menu_item_id" db:"menu_item_id"`
 	MenuItemName        string               `json:"menu_item_name,omitempty" db:"name"`
 	Quantity            int                  `json:"quantity" db:"quantity"`
-	PriceAtOrder        float64              `json:"price_at_order" db:"price_at_order"`
+	PriceAtOrder        Money                `json:"price_at_order" db:"price_at_order"`
+	TaxRate             Percent              `json:"tax_rate" db:"tax_rate"` // Tarifa de la categoría al agregar el item
 	Notes               *string              `json:"notes,omitempty" db:"notes"`
 	Customizations      Customizations       `json:"customizations" db:"customizations"`
 	CustomizationsInput *CustomizationsInput `json:"customizations_input,omitempty" db:"-"` // Solo para input, no se guarda en BD
 	IsTakeout           bool                 `json:"is_takeout" db:"is_takeout"`            // Indica si este item específico es para llevar
+	Status              string               `json:"status" db:"status"`                    // Estado de preparación del item (pending, preparing, ready, served, voided)
 	// Campos para tickets de cocina (obtenidos por JOIN)
 	CategoryID          *uuid.UUID `json:"category_id,omitempty" db:"category_id"`
 	CategoryStationID   *uuid.UUID `json:"category_station_id,omitempty" db:"category_station_id"`
 	CategoryStationName string     `json:"category_station_name,omitempty" db:"category_station_name"`
 }
+
+// Estados de preparación de un item de la orden
+const (
+	ItemStatusPending   = "pending"   // Aún no empieza su preparación
+	ItemStatusPreparing = "preparing" // La estación lo está preparando
+	ItemStatusReady     = "ready"     // Listo para servir
+	ItemStatusServed    = "served"    // Entregado en la mesa
+	ItemStatusVoided    = "voided"    // Anulado, no cuenta para el progreso de la orden
+)
+
+// UpdateItemStatusRequest es el payload de PUT /api/orders/:id/items/:itemId/status y de
+// PUT /api/stations/:id/orders/:orderId/items/status. Anular items exige un motivo.
+type UpdateItemStatusRequest struct {
+	Status     string `json:"status"`                // pending, preparing, ready, served, voided
+	ReasonCode string `json:"reason_code,omitempty"` // Motivo de cancelación (obligatorio con "voided")
+	Notes      string `json:"notes,omitempty"`       // Obligatorio con el motivo "other"
+}
+
+// itemStatusTransitions define a qué estados puede pasar un item desde cada estado
+var itemStatusTransitions = map[string][]string{
+	ItemStatusPending:   {ItemStatusPreparing, ItemStatusReady, ItemStatusVoided},
+	ItemStatusPreparing: {ItemStatusPending, ItemStatusReady, ItemStatusVoided},
+	ItemStatusReady:     {ItemStatusPreparing, ItemStatusServed, ItemStatusVoided},
+	ItemStatusServed:    {},
+	ItemStatusVoided:    {},
+}
+
+// IsValidItemStatus indica si el estado de item existe
+func IsValidItemStatus(status string) bool {
+	_, ok := itemStatusTransitions[status]
+	return ok
+}
+
+// CanTransitionItem indica si un item puede pasar del estado from al estado to
+func CanTransitionItem(from, to string) bool {
+	for _, allowed := range itemStatusTransitions[from] {
+		if allowed == to {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,95 @@
+// =================================================================
+// Order Cancellation Domain Model
+// Cancelación (antes de cocina) y anulación (después) de órdenes
+// con motivo obligatorio
+// =================================================================
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Tipos de cancelación
+const (
+	CancellationKindCancel = "cancel" // La orden no llegó a cocina
+	CancellationKindVoid   = "void"   // La cocina ya la recibió: se imprimen tickets de anulación
+)
+
+// Motivos de cancelación
+const (
+	CancelReasonCustomerLeft      = "customer_left"      // El cliente se fue o desistió
+	CancelReasonOrderError        = "order_error"        // Error al tomar la orden
+	CancelReasonDuplicate         = "duplicate"          // Orden repetida
+	CancelReasonOutOfStock        = "out_of_stock"       // Producto agotado
+	CancelReasonCustomerComplaint = "customer_complaint" // Queja del cliente (calidad, demora)
+	CancelReasonOther             = "other"              // Otro: las notas son obligatorias
+)
+
+// cancelReasonLabels es el texto de cada motivo en los tickets de anulación
+var cancelReasonLabels = map[string]string{
+	CancelReasonCustomerLeft:      "Cliente se retiró",
+	CancelReasonOrderError:        "Error en la orden",
+	CancelReasonDuplicate:         "Orden duplicada",
+	CancelReasonOutOfStock:        "Producto agotado",
+	CancelReasonCustomerComplaint: "Queja del cliente",
+	CancelReasonOther:             "Otro",
+}
+
+// IsValidCancelReason indica si el motivo es conocido
+func IsValidCancelReason(code string) bool {
+	_, ok := cancelReasonLabels[code]
+	return ok
+}
+
+// CancelReasonLabel devuelve el texto del motivo (el código si no es conocido)
+func CancelReasonLabel(code string) string {
+	if label, ok := cancelReasonLabels[code]; ok {
+		return label
+	}
+	return code
+}
+
+// OrderCancellation registra quién canceló o anuló una orden, en qué estado estaba y por qué
+type OrderCancellation struct {
+	OrderID         uuid.UUID  `json:"order_id" db:"order_id"`
+	Kind            string     `json:"kind" db:"kind"` // "cancel" o "void"
+	ReasonCode      string     `json:"reason_code" db:"reason_code"`
+	Notes           *string    `json:"notes,omitempty" db:"notes"`
+	PreviousStatus  string     `json:"previous_status" db:"previous_status"`
+	Total           Money      `json:"total" db:"total"` // Total de la orden al cancelarla
+	CancelledBy     *uuid.UUID `json:"cancelled_by,omitempty" db:"cancelled_by"`
+	CancelledByName string     `json:"cancelled_by_name,omitempty" db:"cancelled_by_name"`
+	CreatedAt       time.Time  `json:"created_at" db:"created_at"`
+
+	// Datos de la orden para los reportes
+	OrderNumber int64     `json:"order_number,omitempty" db:"order_number"`
+	TableNumber int       `json:"table_number,omitempty" db:"table_number"`
+	WaiterID    uuid.UUID `json:"waiter_id,omitempty" db:"waiter_id"`
+	WaiterName  string    `json:"waiter_name,omitempty" db:"waiter_name"`
+}
+
+// CancelOrderRequest es el payload de POST /api/orders/:id/cancel y /void
+type CancelOrderRequest struct {
+	ReasonCode string `json:"reason_code"`
+	Notes      string `json:"notes,omitempty"` // Obligatorio con el motivo "other"
+}
+
+// CancellationReasonSummary agrupa las cancelaciones de un rango por tipo y motivo
+type CancellationReasonSummary struct {
+	Kind       string `json:"kind"`
+	ReasonCode string `json:"reason_code"`
+	Orders     int    `json:"orders"`
+	Total      Money  `json:"total"` // Suma del total de las órdenes al cancelarlas
+}
+
+// CancellationReportResponse es la respuesta de GET /api/reports/cancellations
+type CancellationReportResponse struct {
+	From          time.Time                   `json:"from"`
+	To            time.Time                   `json:"to"`
+	Cancelled     int                         `json:"cancelled"` // Órdenes canceladas antes de cocina
+	Voided        int                         `json:"voided"`    // Órdenes anuladas después de llegar a cocina
+	ByReason      []CancellationReasonSummary `json:"by_reason"`
+	Cancellations []OrderCancellation         `json:"cancellations"`
+}
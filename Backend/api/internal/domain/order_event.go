@@ -0,0 +1,42 @@
+// =================================================================
+// Order Event Domain Model
+// Historial de cambios de una orden (auditoría)
+// =================================================================
+package domain
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Tipos de evento del historial de una orden
+const (
+	OrderEventCreated           = "created"
+	OrderEventStatusChanged     = "status_changed"
+	OrderEventWaiterChanged     = "waiter_changed"
+	OrderEventItemsUpdated      = "items_updated"
+	OrderEventItemStatusChanged = "item_status_changed"
+	OrderEventPaymentUpdated    = "payment_updated"
+	OrderEventPaymentAdded      = "payment_added"
+	OrderEventDiscountApplied   = "discount_applied"
+	OrderEventDiscountRequested = "discount_requested" // Cupón pendiente de aprobación
+	OrderEventDiscountApproved  = "discount_approved"
+	OrderEventDiscountRejected  = "discount_rejected"
+	OrderEventCancelled         = "cancelled" // Cancelada antes de llegar a cocina
+	OrderEventVoided            = "voided"    // Anulada después de llegar a cocina
+)
+
+// OrderEvent registra quién cambió qué en una orden, con los valores anterior y nuevo
+type OrderEvent struct {
+	ID        uuid.UUID       `json:"id" db:"id"`
+	OrderID   uuid.UUID       `json:"order_id" db:"order_id"`
+	ActorID   *uuid.UUID      `json:"actor_id,omitempty" db:"actor_id"` // nil = cambio automático del sistema
+	ActorName string          `json:"actor_name,omitempty" db:"actor_name"`
+	ActorRole string          `json:"actor_role,omitempty" db:"actor_role"`
+	EventType string          `json:"event_type" db:"event_type"`
+	OldValue  json.RawMessage `json:"old_value,omitempty" db:"old_value"`
+	NewValue  json.RawMessage `json:"new_value,omitempty" db:"new_value"`
+	CreatedAt time.Time       `json:"created_at" db:"created_at"`
+}
@@ -0,0 +1,88 @@
+// =================================================================
+// Order Payment Domain Model
+// Pagos parciales de una orden (cuentas divididas)
+// =================================================================
+package domain
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Métodos de pago aceptados
+const (
+	PaymentMethodCash     = "efectivo"
+	PaymentMethodTransfer = "transferencia"
+	PaymentMethodCard     = "tarjeta"
+)
+
+// IsValidPaymentMethod indica si el método de pago es conocido
+func IsValidPaymentMethod(method string) bool {
+	switch method {
+	case PaymentMethodCash, PaymentMethodTransfer, PaymentMethodCard:
+		return true
+	}
+	return false
+}
+
+var (
+	// ErrPaymentExceedsBalance indica que el pago supera el saldo pendiente de la orden
+	ErrPaymentExceedsBalance = errors.New("el pago supera el saldo pendiente")
+	// ErrItemsAlreadyPaid indica que alguno de los items ya fue pagado en otro pago
+	ErrItemsAlreadyPaid = errors.New("alguno de los items ya fue pagado")
+)
+
+// OrderPayment es un pago (total o parcial) registrado sobre una orden
+type OrderPayment struct {
+	ID             uuid.UUID   `json:"id" db:"id"`
+	OrderID        uuid.UUID   `json:"order_id" db:"order_id"`
+	Amount         Money       `json:"amount" db:"amount"` // Abono a la cuenta (sin propina)
+	Tip            Money       `json:"tip" db:"tip"`
+	TipWaiterID    *uuid.UUID  `json:"tip_waiter_id,omitempty" db:"tip_waiter_id"` // Mesero de la orden al momento del pago
+	Method         string      `json:"method" db:"method"`
+	ProofPath      *string     `json:"proof_path,omitempty" db:"proof_path"`
+	ItemIDs        []uuid.UUID `json:"item_ids" db:"item_ids"` // Items cubiertos (división por items); vacío = abono al total
+	ReceivedBy     *uuid.UUID  `json:"received_by,omitempty" db:"received_by"`
+	ReceivedByName string      `json:"received_by_name,omitempty" db:"received_by_name"`
+	CreatedAt      time.Time   `json:"created_at" db:"created_at"`
+}
+
+// CreateOrderPaymentRequest es el payload de POST /api/orders/:id/payments.
+// Con item_ids el monto lo calcula el servidor a partir de esos items; sin ellos se usa amount.
+type CreateOrderPaymentRequest struct {
+	Method  string      `json:"method"`
+	Amount  *Money      `json:"amount,omitempty"`
+	Tip     *Money      `json:"tip,omitempty"` // Propina opcional, no cuenta para el saldo
+	ItemIDs []uuid.UUID `json:"item_ids,omitempty"`
+}
+
+// OrderBalanceItem indica cuánto vale cada item de la orden y si ya fue pagado por separado
+type OrderBalanceItem struct {
+	ItemID       uuid.UUID `json:"item_id"`
+	MenuItemName string    `json:"menu_item_name"`
+	Quantity     int       `json:"quantity"`
+	Amount       Money     `json:"amount"`
+	Paid         bool      `json:"paid"`
+}
+
+// OrderBalance es el estado de cuenta de una orden
+type OrderBalance struct {
+	OrderID    uuid.UUID          `json:"order_id"`
+	Status     string             `json:"status"`
+	Total      Money              `json:"total"`
+	Paid       Money              `json:"paid"`
+	BalanceDue Money              `json:"balance_due"`
+	Tips       Money              `json:"tips"`
+	Payments   []OrderPayment     `json:"payments"`
+	Items      []OrderBalanceItem `json:"items"`
+}
+
+// SplitEvenlyResponse es la división en partes iguales del saldo pendiente
+type SplitEvenlyResponse struct {
+	OrderID    uuid.UUID `json:"order_id"`
+	BalanceDue Money     `json:"balance_due"`
+	Ways       int       `json:"ways"`
+	Shares     []Money   `json:"shares"`
+}
@@ -0,0 +1,60 @@
+// =================================================================
+// Print Job Domain Model
+// Cola persistente de impresión de tickets de cocina
+// =================================================================
+package domain
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// PrintJobStatus define los estados de un trabajo de impresión
+type PrintJobStatus string
+
+const (
+	PrintJobQueued PrintJobStatus = "queued" // Pendiente de envío (o esperando reintento)
+	PrintJobSent   PrintJobStatus = "sent"   // Entregado a una impresora
+	PrintJobFailed PrintJobStatus = "failed" // Se agotaron los reintentos
+)
+
+// PrintJob representa el envío de un ticket a una estación
+type PrintJob struct {
+	ID            uuid.UUID      `json:"id" db:"id"`
+	OrderID       uuid.UUID      `json:"order_id" db:"order_id"`
+	StationID     uuid.UUID      `json:"station_id" db:"station_id"`
+	StationName   string         `json:"station_name" db:"station_name"`
+	PrinterID     *uuid.UUID     `json:"printer_id,omitempty" db:"printer_id"` // Última impresora utilizada
+	PrinterName   string         `json:"printer_name,omitempty" db:"printer_name"`
+	Ticket        KitchenTicket  `json:"ticket" db:"ticket"`
+	Status        PrintJobStatus `json:"status" db:"status"`
+	Attempts      int            `json:"attempts" db:"attempts"`
+	MaxAttempts   int            `json:"max_attempts" db:"max_attempts"`
+	LastError     *string        `json:"last_error,omitempty" db:"last_error"`
+	NextAttemptAt time.Time      `json:"next_attempt_at" db:"next_attempt_at"`
+	SentAt        *time.Time     `json:"sent_at,omitempty" db:"sent_at"`
+	CreatedAt     time.Time      `json:"created_at" db:"created_at"`
+	UpdatedAt     time.Time      `json:"updated_at" db:"updated_at"`
+}
+
+// Value serializa el ticket para guardarlo como jsonb
+func (t KitchenTicket) Value() (driver.Value, error) {
+	return json.Marshal(t)
+}
+
+// Scan deserializa el ticket desde jsonb
+func (t *KitchenTicket) Scan(value interface{}) error {
+	if value == nil {
+		*t = KitchenTicket{}
+		return nil
+	}
+	b, ok := value.([]byte)
+	if !ok {
+		return errors.New("type assertion to []byte failed")
+	}
+	return json.Unmarshal(b, t)
+}
@@ -29,6 +29,11 @@ type Printer struct {
 	StationName string      `json:"station_name,omitempty" db:"station_name"` // Join con stations
 	IsActive    bool        `json:"is_active" db:"is_active"`
 	CreatedAt   time.Time   `json:"created_at" db:"created_at"`
+	// Estado de conectividad (actualizado por el monitor de impresoras)
+	IsOnline      bool       `json:"is_online" db:"is_online"`
+	LastSeenAt    *time.Time `json:"last_seen_at,omitempty" db:"last_seen_at"`
+	LastCheckedAt *time.Time `json:"last_checked_at,omitempty" db:"last_checked_at"`
+	LatencyMs     *int       `json:"latency_ms,omitempty" db:"latency_ms"`
 }
 
 // CreatePrinterRequest es el payload para crear una impresora
@@ -49,3 +54,11 @@ type UpdatePrinterRequest struct {
 	StationID   *uuid.UUID   `json:"station_id"`
 	IsActive    *bool        `json:"is_active"`
 }
+
+// TestPrintResponse es la respuesta de una impresión de prueba
+type TestPrintResponse struct {
+	Success   bool   `json:"success"`
+	Message   string `json:"message"`
+	Error     string `json:"error,omitempty"`
+	LatencyMs int64  `json:"latency_ms"`
+}
@@ -0,0 +1,165 @@
+// =================================================================
+// Promotion Domain Model
+// Descuentos, happy hours, combos y cupones aplicados a las órdenes
+// =================================================================
+package domain
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ErrCouponExhausted indica que otra orden gastó el último uso del cupón mientras se aplicaba
+var ErrCouponExhausted = errors.New("el cupón ya no tiene usos disponibles")
+
+// Tipos de promoción
+const (
+	PromotionTypePercentage = "percentage" // Porcentaje sobre la orden o los items elegidos
+	PromotionTypeFixed      = "fixed"      // Monto fijo (por unidad si aplica a items o categorías)
+	PromotionTypeCombo      = "combo"      // Precio especial por un conjunto de productos
+)
+
+// Alcance de una promoción
+const (
+	PromotionScopeOrder    = "order"
+	PromotionScopeMenuItem = "menu_item"
+	PromotionScopeCategory = "category"
+)
+
+// Estados de un descuento aplicado a una orden
+const (
+	DiscountStatusApplied  = "applied"
+	DiscountStatusPending  = "pending_approval" // Supera el umbral y espera la aprobación de un admin
+	DiscountStatusRejected = "rejected"
+)
+
+// IsValidPromotionType indica si el tipo de promoción es conocido
+func IsValidPromotionType(promotionType string) bool {
+	switch promotionType {
+	case PromotionTypePercentage, PromotionTypeFixed, PromotionTypeCombo:
+		return true
+	}
+	return false
+}
+
+// IsValidPromotionScope indica si el alcance de la promoción es conocido
+func IsValidPromotionScope(scope string) bool {
+	switch scope {
+	case PromotionScopeOrder, PromotionScopeMenuItem, PromotionScopeCategory:
+		return true
+	}
+	return false
+}
+
+// Promotion es una regla de descuento. Sin coupon_code se aplica sola a las órdenes
+// que cumplan la ventana de tiempo; con coupon_code solo cuando se ingresa el cupón.
+type Promotion struct {
+	ID          uuid.UUID   `json:"id" db:"id"`
+	Name        string      `json:"name" db:"name"`
+	Description *string     `json:"description,omitempty" db:"description"`
+	Type        string      `json:"type" db:"type"`
+	Scope       string      `json:"scope" db:"scope"`
+	Percentage  Percent     `json:"percentage" db:"percentage"` // Solo para type = percentage
+	Amount      Money       `json:"amount" db:"amount"`         // fixed: monto a descontar; combo: precio del combo
+	TargetIDs   []uuid.UUID `json:"target_ids" db:"target_ids"` // Productos o categorías; en combos, los productos del combo
+	CouponCode  *string     `json:"coupon_code,omitempty" db:"coupon_code"`
+	// Ventana de tiempo (happy hour): días de la semana (0 = domingo) y horario HH:MM.
+	// Vacíos = sin restricción. Si end_time < start_time la ventana cruza la medianoche.
+	DaysOfWeek []int      `json:"days_of_week" db:"days_of_week"`
+	StartTime  *string    `json:"start_time,omitempty" db:"start_time"`
+	EndTime    *string    `json:"end_time,omitempty" db:"end_time"`
+	StartsAt   *time.Time `json:"starts_at,omitempty" db:"starts_at"`
+	EndsAt     *time.Time `json:"ends_at,omitempty" db:"ends_at"`
+	MaxUses    *int       `json:"max_uses,omitempty" db:"max_uses"` // Límite de usos del cupón
+	Uses       int        `json:"uses" db:"uses"`
+	IsActive   bool       `json:"is_active" db:"is_active"`
+	CreatedBy  *uuid.UUID `json:"created_by,omitempty" db:"created_by"`
+	CreatedAt  time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at" db:"updated_at"`
+}
+
+// IsActiveAt indica si la promoción está vigente en el instante t (hora local)
+func (p *Promotion) IsActiveAt(t time.Time) bool {
+	if !p.IsActive {
+		return false
+	}
+	if p.StartsAt != nil && t.Before(*p.StartsAt) {
+		return false
+	}
+	if p.EndsAt != nil && !t.Before(*p.EndsAt) {
+		return false
+	}
+
+	if len(p.DaysOfWeek) > 0 {
+		today := false
+		for _, day := range p.DaysOfWeek {
+			if day == int(t.Weekday()) {
+				today = true
+				break
+			}
+		}
+		if !today {
+			return false
+		}
+	}
+
+	if p.StartTime != nil && p.EndTime != nil {
+		clock := t.Format("15:04")
+		if *p.StartTime <= *p.EndTime {
+			return clock >= *p.StartTime && clock < *p.EndTime
+		}
+		return clock >= *p.StartTime || clock < *p.EndTime
+	}
+	return true
+}
+
+// OrderDiscount es un descuento aplicado (o pendiente de aprobación) en una orden.
+// Guarda el nombre de la promoción y quién lo aplicó y aprobó para la auditoría.
+type OrderDiscount struct {
+	ID             uuid.UUID  `json:"id" db:"id"`
+	OrderID        uuid.UUID  `json:"order_id" db:"order_id"`
+	PromotionID    *uuid.UUID `json:"promotion_id,omitempty" db:"promotion_id"`
+	PromotionName  string     `json:"promotion_name" db:"promotion_name"`
+	CouponCode     *string    `json:"coupon_code,omitempty" db:"coupon_code"`
+	Amount         Money      `json:"amount" db:"amount"`
+	Status         string     `json:"status" db:"status"`
+	AppliedBy      *uuid.UUID `json:"applied_by,omitempty" db:"applied_by"` // nil = promoción automática
+	AppliedByName  string     `json:"applied_by_name,omitempty" db:"applied_by_name"`
+	ApprovedBy     *uuid.UUID `json:"approved_by,omitempty" db:"approved_by"`
+	ApprovedByName string     `json:"approved_by_name,omitempty" db:"approved_by_name"`
+	CreatedAt      time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at" db:"updated_at"`
+}
+
+// OrderDiscountChanges son los descuentos recalculados al cambiar los items de una orden.
+// Se guardan en la misma transacción que los montos de la orden.
+type OrderDiscountChanges struct {
+	Automatic []OrderDiscount // Reemplazan a las promociones automáticas de la orden
+	Coupons   []OrderDiscount // Cupones con su monto y estado recalculados
+}
+
+// ApplyCouponRequest es el payload de POST /api/orders/:id/coupon
+type ApplyCouponRequest struct {
+	CouponCode string `json:"coupon_code"`
+}
+
+// PromotionRequest es el payload para crear o actualizar una promoción
+type PromotionRequest struct {
+	Name        string      `json:"name"`
+	Description *string     `json:"description,omitempty"`
+	Type        string      `json:"type"`
+	Scope       string      `json:"scope"`
+	Percentage  Percent     `json:"percentage"`
+	Amount      Money       `json:"amount"`
+	TargetIDs   []uuid.UUID `json:"target_ids"`
+	CouponCode  *string     `json:"coupon_code,omitempty"`
+	DaysOfWeek  []int       `json:"days_of_week"`
+	StartTime   *string     `json:"start_time,omitempty"`
+	EndTime     *string     `json:"end_time,omitempty"`
+	StartsAt    *time.Time  `json:"starts_at,omitempty"`
+	EndsAt      *time.Time  `json:"ends_at,omitempty"`
+	MaxUses     *int        `json:"max_uses,omitempty"`
+	IsActive    *bool       `json:"is_active,omitempty"` // Por defecto true
+}
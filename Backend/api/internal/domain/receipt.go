@@ -0,0 +1,44 @@
+// =================================================================
+// Receipt Domain Model
+// Recibo del cliente: detalle de la orden, impuestos, pagos y datos
+// para verificar la factura en blockchain
+// =================================================================
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Receipt reúne lo que se imprime en el recibo de una orden
+type Receipt struct {
+	RestaurantName   string          `json:"restaurant_name"`
+	Order            *Order          `json:"order"`
+	Payments         []OrderPayment  `json:"payments"`
+	Discounts        []OrderDiscount `json:"discounts"`          // Solo los aplicados
+	PricesIncludeTax bool            `json:"prices_include_tax"` // true: el impuesto ya está en los precios
+	InvoiceHash      string          `json:"invoice_hash"`       // Hash de la BlockchainInvoice de la orden
+	VerificationData string          `json:"verification_data"`  // Contenido del código QR
+	GeneratedAt      time.Time       `json:"generated_at"`
+}
+
+// Formatos del recibo
+const (
+	ReceiptFormatHTML = "html"
+	ReceiptFormatPDF  = "pdf"
+)
+
+// PrintReceiptRequest es el payload de POST /api/orders/:id/receipt/print.
+// Sin printer_id se usa la primera impresora disponible de una estación de caja.
+type PrintReceiptRequest struct {
+	PrinterID *uuid.UUID `json:"printer_id,omitempty"`
+}
+
+// PrintReceiptResponse es el resultado de imprimir un recibo
+type PrintReceiptResponse struct {
+	Success     bool   `json:"success"`
+	Message     string `json:"message"`
+	PrinterName string `json:"printer_name,omitempty"`
+	Error       string `json:"error,omitempty"`
+}
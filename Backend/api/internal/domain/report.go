@@ -0,0 +1,29 @@
+// =================================================================
+// Report Domain Model
+// Reportes de ventas por mesero (propinas aparte de las ventas)
+// =================================================================
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// WaiterReport resume las ventas y propinas de un mesero en un rango de fechas
+type WaiterReport struct {
+	WaiterID      uuid.UUID `json:"waiter_id"`
+	WaiterName    string    `json:"waiter_name"`
+	Orders        int       `json:"orders"`         // Órdenes pagadas
+	Subtotal      Money     `json:"subtotal"`       // Consumo sin cargo por servicio
+	ServiceCharge Money     `json:"service_charge"` // Cargo por servicio cobrado
+	Sales         Money     `json:"sales"`          // subtotal + service_charge
+	Tips          Money     `json:"tips"`           // Propinas atribuidas al mesero
+}
+
+// WaiterReportResponse es la respuesta de GET /api/reports/waiters
+type WaiterReportResponse struct {
+	From    time.Time      `json:"from"`
+	To      time.Time      `json:"to"`
+	Waiters []WaiterReport `json:"waiters"`
+}
@@ -0,0 +1,35 @@
+// =================================================================
+// Service Charge Domain Model
+// Porcentaje de cargo por servicio según el tipo de orden
+// =================================================================
+package domain
+
+import "time"
+
+// Tipos de orden
+const (
+	OrderTypeTable    = "mesa"
+	OrderTypeTakeout  = "llevar"
+	OrderTypeDelivery = "domicilio"
+)
+
+// IsValidOrderType indica si el tipo de orden es conocido
+func IsValidOrderType(orderType string) bool {
+	switch orderType {
+	case OrderTypeTable, OrderTypeTakeout, OrderTypeDelivery:
+		return true
+	}
+	return false
+}
+
+// ServiceChargeSetting es el cargo por servicio configurado para un tipo de orden
+type ServiceChargeSetting struct {
+	OrderType  string    `json:"order_type" db:"order_type"`
+	Percentage Percent   `json:"percentage" db:"percentage"`
+	UpdatedAt  time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// UpdateServiceChargeRequest es el payload para cambiar el porcentaje de un tipo de orden
+type UpdateServiceChargeRequest struct {
+	Percentage Percent `json:"percentage"`
+}
@@ -15,6 +15,8 @@ type Station struct {
 	Name        string    `json:"name" db:"name"`
 	Description string    `json:"description,omitempty" db:"description"`
 	IsActive    bool      `json:"is_active" db:"is_active"`
+	AutoPrint   bool      `json:"auto_print" db:"auto_print"` // Imprimir tickets automáticamente al aprobar/editar órdenes
+	IsCashier   bool      `json:"is_cashier" db:"is_cashier"` // Estación de caja: sus impresoras imprimen los recibos
 	CreatedAt   time.Time `json:"created_at" db:"created_at"`
 }
 
@@ -22,6 +24,8 @@ type Station struct {
 type CreateStationRequest struct {
 	Name        string `json:"name" binding:"required"`
 	Description string `json:"description"`
+	AutoPrint   *bool  `json:"auto_print"` // Por defecto true
+	IsCashier   *bool  `json:"is_cashier"` // Por defecto false
 }
 
 // UpdateStationRequest es el payload para actualizar una estación
@@ -29,4 +33,6 @@ type UpdateStationRequest struct {
 	Name        string `json:"name"`
 	Description string `json:"description"`
 	IsActive    *bool  `json:"is_active"`
+	AutoPrint   *bool  `json:"auto_print"`
+	IsCashier   *bool  `json:"is_cashier"`
 }
@@ -0,0 +1,49 @@
+// =================================================================
+// Tax Domain Model
+// Desglose de impuestos de la orden por tarifa (según la categoría)
+// =================================================================
+package domain
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+)
+
+// TaxLine es el impuesto de todos los items con la misma tarifa
+type TaxLine struct {
+	Rate Percent `json:"rate"` // Tarifa (19.00 = 19%)
+	Base Money   `json:"base"` // Base gravable, ya con su parte del descuento
+	Tax  Money   `json:"tax"`
+}
+
+// TaxBreakdown es el desglose de impuestos que se guarda en orders.tax_breakdown (jsonb)
+type TaxBreakdown []TaxLine
+
+func (t TaxBreakdown) Value() (driver.Value, error) {
+	if t == nil {
+		return []byte("[]"), nil
+	}
+	return json.Marshal(t)
+}
+
+func (t *TaxBreakdown) Scan(value interface{}) error {
+	if value == nil {
+		*t = TaxBreakdown{}
+		return nil
+	}
+	b, ok := value.([]byte)
+	if !ok {
+		return errors.New("type assertion to []byte failed")
+	}
+	return json.Unmarshal(b, t)
+}
+
+// Total suma el impuesto de todas las tarifas
+func (t TaxBreakdown) Total() Money {
+	var total Money
+	for _, line := range t {
+		total = total.Add(line.Tax)
+	}
+	return total
+}
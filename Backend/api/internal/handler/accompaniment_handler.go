@@ -4,6 +4,7 @@
 package handler
 
 import (
+	"github.com/Hoxanfox/TurnyChain/Backend/api/internal/domain"
 	"github.com/Hoxanfox/TurnyChain/Backend/api/internal/service"
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
@@ -12,7 +13,7 @@ import (
 type AccompanimentHandler struct { service service.AccompanimentService }
 func NewAccompanimentHandler(s service.AccompanimentService) *AccompanimentHandler { return &AccompanimentHandler{service: s} }
 
-type AccompanimentPayload struct { Name string `json:"name"`; Price float64 `json:"price"` }
+type AccompanimentPayload struct { Name string `json:"name"`; Price domain.Money `json:"price"` }
 
 func (h *AccompanimentHandler) Create(c *fiber.Ctx) error {
 	payload := new(AccompanimentPayload)
@@ -0,0 +1,199 @@
+// =================================================================
+// Audit Handler
+// Facturas notarizadas descifradas para auditores (rol auditor o
+// admin) y llaves públicas para las exportaciones cifradas
+// =================================================================
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/Hoxanfox/TurnyChain/Backend/api/internal/domain"
+	"github.com/Hoxanfox/TurnyChain/Backend/api/internal/service"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type AuditHandler struct {
+	service *service.AuditService
+}
+
+func NewAuditHandler(service *service.AuditService) *AuditHandler {
+	return &AuditHandler{service: service}
+}
+
+// ListInvoices lista las facturas notarizadas (confirmadas) en el rango, descifradas y con
+// su prueba. ?envelope=true las cifra para la llave del auditor (el admin indica ?auditor_id)
+// GET /api/audit/invoices?from=2026-10-01&to=2026-10-18&limit=100&offset=0
+func (h *AuditHandler) ListInvoices(c *fiber.Ctx) error {
+	userID, role, err := auditActor(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Invalid user ID"})
+	}
+	from, to, err := reportRange(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	envelopeFor, err := envelopeRecipient(c, userID, role)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	list, err := h.service.ListInvoices(from, to, c.QueryInt("limit"), c.QueryInt("offset"), envelopeFor)
+	if err != nil {
+		return auditError(c, err, "Error al consultar las facturas notarizadas")
+	}
+	log.Printf("🔎 [Audit] %s (%s) consultó %d facturas notarizadas del %s al %s (envelope: %t)", userID, role, len(list.Invoices), from.Format(reportDateLayout), to.AddDate(0, 0, -1).Format(reportDateLayout), envelopeFor != nil)
+	return c.JSON(list)
+}
+
+// ExportInvoices descarga las facturas notarizadas del rango en JSON o CSV, con los mismos
+// parámetros de envelope que ListInvoices
+// GET /api/audit/invoices/export?from=2026-10-01&to=2026-10-18&format=csv
+func (h *AuditHandler) ExportInvoices(c *fiber.Ctx) error {
+	userID, role, err := auditActor(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Invalid user ID"})
+	}
+	from, to, err := reportRange(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	envelopeFor, err := envelopeRecipient(c, userID, role)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	format := c.Query("format", domain.AuditFormatJSON)
+	content, contentType, count, err := h.service.ExportInvoices(from, to, format, envelopeFor)
+	if err != nil {
+		return auditError(c, err, "Error al exportar las facturas notarizadas")
+	}
+	log.Printf("📤 [Audit] %s (%s) exportó %d facturas notarizadas del %s al %s en %s (envelope: %t)", userID, role, count, from.Format(reportDateLayout), to.AddDate(0, 0, -1).Format(reportDateLayout), format, envelopeFor != nil)
+
+	fileName := fmt.Sprintf("facturas-%s-%s.%s", from.Format(reportDateLayout), to.AddDate(0, 0, -1).Format(reportDateLayout), format)
+	c.Set(fiber.HeaderContentDisposition, fmt.Sprintf("attachment; filename=\"%s\"", fileName))
+	c.Set(fiber.HeaderContentType, contentType)
+	return c.Send(content)
+}
+
+// GetAuditorKey devuelve la llave pública registrada de un auditor (el admin o el propio auditor)
+// GET /api/audit/auditors/:id/key
+func (h *AuditHandler) GetAuditorKey(c *fiber.Ctx) error {
+	userID, role, err := auditActor(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Invalid user ID"})
+	}
+	auditorID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid auditor ID"})
+	}
+	if role != service.RoleAdmin && auditorID != userID {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "Un auditor solo puede consultar su propia llave"})
+	}
+
+	key, err := h.service.GetAuditorKey(auditorID)
+	if err != nil {
+		return auditError(c, err, "Error al obtener la llave del auditor")
+	}
+	return c.JSON(key)
+}
+
+// SetAuditorKey registra o reemplaza la llave pública RSA (PEM) de un auditor (solo admin)
+// PUT /api/audit/auditors/:id/key
+func (h *AuditHandler) SetAuditorKey(c *fiber.Ctx) error {
+	userID, role, err := auditActor(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Invalid user ID"})
+	}
+	if role != service.RoleAdmin {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "Solo el administrador puede registrar llaves de auditores"})
+	}
+	auditorID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid auditor ID"})
+	}
+
+	var req domain.SetAuditorKeyRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Cannot parse JSON"})
+	}
+
+	key, err := h.service.SetAuditorKey(auditorID, userID, req.PublicKey)
+	if err != nil {
+		return auditError(c, err, "Error al registrar la llave del auditor")
+	}
+	log.Printf("🔑 [Audit] %s registró la llave %s del auditor %s", userID, key.Fingerprint, auditorID)
+	return c.JSON(key)
+}
+
+// RevokeAuditorKey borra la llave pública de un auditor (solo admin)
+// DELETE /api/audit/auditors/:id/key
+func (h *AuditHandler) RevokeAuditorKey(c *fiber.Ctx) error {
+	userID, role, err := auditActor(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Invalid user ID"})
+	}
+	if role != service.RoleAdmin {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "Solo el administrador puede revocar llaves de auditores"})
+	}
+	auditorID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid auditor ID"})
+	}
+
+	if err := h.service.RevokeAuditorKey(auditorID); err != nil {
+		return auditError(c, err, "Error al revocar la llave del auditor")
+	}
+	log.Printf("🔑 [Audit] %s revocó la llave del auditor %s", userID, auditorID)
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+func auditActor(c *fiber.Ctx) (uuid.UUID, string, error) {
+	userIDStr, _ := c.Locals("user_id").(string)
+	userID, err := uuid.Parse(userIDStr)
+	role, _ := c.Locals("user_role").(string)
+	return userID, role, err
+}
+
+// envelopeRecipient decide para qué auditor se cifran las facturas. Sin envelope ni
+// auditor_id van en claro; un auditor solo puede pedirlas cifradas para sí mismo y el admin
+// debe indicar el auditor.
+func envelopeRecipient(c *fiber.Ctx, userID uuid.UUID, role string) (*uuid.UUID, error) {
+	if value := c.Query("auditor_id"); value != "" {
+		auditorID, err := uuid.Parse(value)
+		if err != nil {
+			return nil, errors.New("Invalid auditor_id")
+		}
+		if role != service.RoleAdmin && auditorID != userID {
+			return nil, errors.New("Un auditor solo puede pedir facturas cifradas para su propia llave")
+		}
+		return &auditorID, nil
+	}
+	if !c.QueryBool("envelope") {
+		return nil, nil
+	}
+	if role == service.RoleAdmin {
+		return nil, errors.New("Indica el auditor destinatario con auditor_id")
+	}
+	return &userID, nil
+}
+
+// auditError traduce los errores del servicio de auditoría a su código HTTP
+func auditError(c *fiber.Ctx, err error, message string) error {
+	switch {
+	case errors.Is(err, service.ErrInvalidReportRange),
+		errors.Is(err, service.ErrInvalidAuditFormat),
+		errors.Is(err, service.ErrAuditExportTooLarge),
+		errors.Is(err, service.ErrInvalidAuditorKey):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	case errors.Is(err, service.ErrAuditorKeyNotFound),
+		errors.Is(err, service.ErrAuditorNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": err.Error()})
+	case errors.Is(err, service.ErrBlockchainUnavailable):
+		return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": message + ": " + err.Error()})
+}
@@ -4,6 +4,7 @@
 package handler
 
 import (
+	"github.com/Hoxanfox/TurnyChain/Backend/api/internal/domain"
 	"github.com/Hoxanfox/TurnyChain/Backend/api/internal/service"
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
@@ -13,8 +14,14 @@ type CategoryHandler struct { service service.CategoryService }
 func NewCategoryHandler(s service.CategoryService) *CategoryHandler { return &CategoryHandler{service: s} }
 
 type CategoryPayload struct {
-	Name      string  `json:"name"`
-	StationID *string `json:"station_id,omitempty"`
+	Name      string          `json:"name"`
+	StationID *string         `json:"station_id,omitempty"`
+	TaxRate   *domain.Percent `json:"tax_rate,omitempty"` // % de impuesto (0 a 100); en Update, sin él se conserva
+}
+
+// validTaxRate indica si el impuesto enviado está entre 0% y 100%
+func validTaxRate(rate *domain.Percent) bool {
+	return rate == nil || (*rate >= 0 && *rate <= 100*domain.MoneyScale)
 }
 
 func (h *CategoryHandler) Create(c *fiber.Ctx) error {
@@ -27,8 +34,11 @@ func (h *CategoryHandler) Create(c *fiber.Ctx) error {
 		if err != nil { return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid station_id"}) }
 		stationID = &sid
 	}
+	if !validTaxRate(payload.TaxRate) { return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "tax_rate must be between 0 and 100"}) }
+	var taxRate domain.Percent
+	if payload.TaxRate != nil { taxRate = *payload.TaxRate }
 
-	cat, err := h.service.Create(payload.Name, stationID)
+	cat, err := h.service.Create(payload.Name, stationID, taxRate)
 	if err != nil { return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not create category"}) }
 	return c.Status(fiber.StatusCreated).JSON(cat)
 }
@@ -51,8 +61,9 @@ func (h *CategoryHandler) Update(c *fiber.Ctx) error {
 		if err != nil { return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid station_id"}) }
 		stationID = &sid
 	}
+	if !validTaxRate(payload.TaxRate) { return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "tax_rate must be between 0 and 100"}) }
 
-	cat, err := h.service.Update(id, payload.Name, stationID)
+	cat, err := h.service.Update(id, payload.Name, stationID, payload.TaxRate)
 	if err != nil { return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not update category"}) }
 	return c.JSON(cat)
 }
@@ -0,0 +1,63 @@
+// =================================================================
+// Chain Index Handler
+// Vista desde la cadena de las facturas notarizadas: avance del
+// indexador, eventos InvoiceSecured indexados y diferencias con las
+// órdenes pagadas (admin y auditor)
+// =================================================================
+package handler
+
+import (
+	"errors"
+
+	"github.com/Hoxanfox/TurnyChain/Backend/api/internal/service"
+	"github.com/gofiber/fiber/v2"
+)
+
+type ChainIndexHandler struct {
+	service *service.ChainIndexerService
+}
+
+// NewChainIndexHandler crea el handler. Con service nil (indexador desactivado) los
+// endpoints responden 503.
+func NewChainIndexHandler(service *service.ChainIndexerService) *ChainIndexHandler {
+	return &ChainIndexHandler{service: service}
+}
+
+// GetStatus devuelve el avance del indexador: último bloque indexado, cabeza de la cadena,
+// atraso y reorganizaciones detectadas
+// GET /api/chain-index/status
+func (h *ChainIndexHandler) GetStatus(c *fiber.Ctx) error {
+	status, err := h.service.Status()
+	if err != nil {
+		return chainIndexError(c, err, "Error al consultar el indexador")
+	}
+	return c.JSON(status)
+}
+
+// ListInvoices lista los eventos InvoiceSecured indexados con el estado actual de su orden
+// GET /api/chain-index/invoices?limit=100&offset=0
+func (h *ChainIndexHandler) ListInvoices(c *fiber.Ctx) error {
+	list, err := h.service.ListEvents(c.QueryInt("limit"), c.QueryInt("offset"))
+	if err != nil {
+		return chainIndexError(c, err, "Error al consultar los eventos indexados")
+	}
+	return c.JSON(list)
+}
+
+// GetReconciliation devuelve las órdenes pagadas sin evento en la cadena y los eventos sin
+// orden pagada, con el motivo de cada diferencia
+// GET /api/chain-index/reconciliation?limit=100
+func (h *ChainIndexHandler) GetReconciliation(c *fiber.Ctx) error {
+	result, err := h.service.Reconciliation(c.QueryInt("limit"))
+	if err != nil {
+		return chainIndexError(c, err, "Error al comparar las órdenes pagadas con la cadena")
+	}
+	return c.JSON(result)
+}
+
+func chainIndexError(c *fiber.Ctx, err error, message string) error {
+	if errors.Is(err, service.ErrChainIndexerDisabled) {
+		return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": message + ": " + err.Error()})
+}
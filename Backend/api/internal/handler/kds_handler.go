@@ -0,0 +1,101 @@
+// =================================================================
+// KDS Handler
+// Endpoints de las pantallas de cocina por estación
+// =================================================================
+package handler
+
+import (
+	"errors"
+
+	"github.com/Hoxanfox/TurnyChain/Backend/api/internal/domain"
+	"github.com/Hoxanfox/TurnyChain/Backend/api/internal/service"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type KDSHandler struct {
+	service *service.KDSService
+}
+
+func NewKDSHandler(service *service.KDSService) *KDSHandler {
+	return &KDSHandler{service: service}
+}
+
+// GetQueue obtiene los tickets pendientes de una estación
+// GET /api/stations/:id/queue
+func (h *KDSHandler) GetQueue(c *fiber.Ctx) error {
+	stationID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Station ID inválido",
+		})
+	}
+
+	tickets, err := h.service.GetQueue(stationID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Error al obtener la cola de la estación: " + err.Error(),
+		})
+	}
+	return c.JSON(tickets)
+}
+
+// Bump avanza el estado de un ticket en la pantalla de la estación
+// POST /api/stations/:id/tickets/:ticketId/bump
+func (h *KDSHandler) Bump(c *fiber.Ctx) error {
+	stationID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Station ID inválido",
+		})
+	}
+	ticketID, err := uuid.Parse(c.Params("ticketId"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Ticket ID inválido",
+		})
+	}
+
+	// El body es opcional: sin status se avanza al siguiente estado
+	var req domain.BumpKDSTicketRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Cuerpo de solicitud inválido",
+			})
+		}
+	}
+
+	actorID, _ := uuid.Parse(c.Locals("user_id").(string))
+	userRole, _ := c.Locals("user_role").(string)
+
+	ticket, err := h.service.Bump(stationID, ticketID, actorID, userRole, req.Status)
+	if err != nil {
+		return bumpError(c, err)
+	}
+	return c.JSON(ticket)
+}
+
+// bumpError traduce los errores del bump: los del ticket y los de sus items en la orden
+// (validación 400, conflicto 409, no encontrado 404); el resto son errores internos
+func bumpError(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, service.ErrKDSTicketNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Ticket no encontrado en esta estación",
+		})
+	case errors.Is(err, service.ErrInvalidKDSStatus), errors.Is(err, service.ErrKDSTicketClosed),
+		errors.Is(err, service.ErrInvalidItemStatus):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	case errors.Is(err, service.ErrInvalidTransition), errors.Is(err, service.ErrOrderClosed),
+		errors.Is(err, domain.ErrOrderChanged):
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		"error": "Error al actualizar el ticket",
+	})
+}
@@ -74,3 +74,43 @@ func (h *KitchenTicketHandler) PrintKitchenTickets(c *fiber.Ctx) error {
 
 	return c.JSON(response)
 }
+
+// ListTicketPDFs lista los tickets PDF generados para una orden
+// GET /api/orders/:orderId/kitchen-tickets/pdf
+func (h *KitchenTicketHandler) ListTicketPDFs(c *fiber.Ctx) error {
+	orderID, err := uuid.Parse(c.Params("orderId"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Order ID inválido",
+		})
+	}
+
+	files, err := h.service.ListTicketPDFs(orderID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Error al listar tickets PDF: " + err.Error(),
+		})
+	}
+
+	return c.JSON(files)
+}
+
+// DownloadTicketPDF descarga un ticket PDF de una orden
+// GET /api/orders/:orderId/kitchen-tickets/pdf/:fileName
+func (h *KitchenTicketHandler) DownloadTicketPDF(c *fiber.Ctx) error {
+	orderID, err := uuid.Parse(c.Params("orderId"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Order ID inválido",
+		})
+	}
+
+	path, err := h.service.GetTicketPDFPath(orderID, c.Params("fileName"))
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.Download(path)
+}
@@ -0,0 +1,86 @@
+// =================================================================
+// Order Cancellation Handler
+// Cancelación y anulación de órdenes con motivo obligatorio
+// =================================================================
+package handler
+
+import (
+	"database/sql"
+	"errors"
+
+	"github.com/Hoxanfox/TurnyChain/Backend/api/internal/domain"
+	"github.com/Hoxanfox/TurnyChain/Backend/api/internal/service"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// CancelOrder cancela una orden que aún no llegó a cocina. El mesero puede rechazar sus
+// órdenes pendientes de aprobación; el cajero y el admin también las recibidas.
+// POST /api/orders/:id/cancel  { "reason_code": "customer_left", "notes": "..." }
+func (h *OrderHandler) CancelOrder(c *fiber.Ctx) error {
+	return h.cancelOrder(c, h.orderService.CancelOrder)
+}
+
+// VoidOrder anula una orden que la cocina ya recibió (solo admin) e imprime los tickets de
+// anulación en las estaciones
+// POST /api/orders/:id/void  { "reason_code": "customer_complaint", "notes": "..." }
+func (h *OrderHandler) VoidOrder(c *fiber.Ctx) error {
+	return h.cancelOrder(c, h.orderService.VoidOrder)
+}
+
+type cancelFunc func(orderID, actorID uuid.UUID, userRole string, req domain.CancelOrderRequest) (*domain.OrderCancellation, *domain.Order, error)
+
+func (h *OrderHandler) cancelOrder(c *fiber.Ctx, cancel cancelFunc) error {
+	orderID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid order ID"})
+	}
+	actorID, err := uuid.Parse(c.Locals("user_id").(string))
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Invalid user ID"})
+	}
+	userRole, _ := c.Locals("user_role").(string)
+
+	var req domain.CancelOrderRequest
+	if err := c.BodyParser(&req); err != nil || req.ReasonCode == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "reason_code is required"})
+	}
+
+	cancellation, order, err := cancel(orderID, actorID, userRole, req)
+	if err != nil {
+		return cancellationError(c, err, "Could not cancel order")
+	}
+	return c.JSON(fiber.Map{"cancellation": cancellation, "order": order})
+}
+
+// GetOrderCancellation devuelve el tipo, el motivo y el autor de la cancelación de la orden
+// GET /api/orders/:id/cancellation
+func (h *OrderHandler) GetOrderCancellation(c *fiber.Ctx) error {
+	orderID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid order ID"})
+	}
+	cancellation, err := h.orderService.GetOrderCancellation(orderID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "The order has no cancellation"})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not retrieve order cancellation"})
+	}
+	return c.JSON(cancellation)
+}
+
+// cancellationError traduce los errores de cancelación: motivo inválido (400), estado o rol
+// que no permiten cancelar, orden cerrada o con pagos (409) u orden inexistente (404)
+func cancellationError(c *fiber.Ctx, err error, fallback string) error {
+	switch {
+	case errors.Is(err, service.ErrInvalidCancelReason):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	case errors.Is(err, service.ErrInvalidTransition), errors.Is(err, service.ErrOrderClosed),
+		errors.Is(err, service.ErrOrderHasPayments), errors.Is(err, domain.ErrOrderChanged):
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": err.Error()})
+	case errors.Is(err, sql.ErrNoRows):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Order not found"})
+	}
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": fallback})
+}
@@ -0,0 +1,108 @@
+// =================================================================
+// Order Discount Handler
+// Cupones y aprobación de descuentos de una orden
+// =================================================================
+package handler
+
+import (
+	"database/sql"
+	"errors"
+	"strings"
+
+	"github.com/Hoxanfox/TurnyChain/Backend/api/internal/domain"
+	"github.com/Hoxanfox/TurnyChain/Backend/api/internal/service"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// ApplyCoupon aplica un cupón a la orden. Responde 201 con el descuento y la orden;
+// si el descuento queda pendiente de aprobación responde 202.
+// POST /api/orders/:id/coupon
+func (h *OrderHandler) ApplyCoupon(c *fiber.Ctx) error {
+	orderID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid order ID"})
+	}
+	actorID, err := uuid.Parse(c.Locals("user_id").(string))
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Invalid user ID"})
+	}
+	userRole, _ := c.Locals("user_role").(string)
+
+	var req domain.ApplyCouponRequest
+	if err := c.BodyParser(&req); err != nil || strings.TrimSpace(req.CouponCode) == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "coupon_code is required"})
+	}
+
+	discount, order, err := h.orderService.ApplyCoupon(orderID, actorID, userRole, req.CouponCode)
+	if err != nil {
+		return discountError(c, err, "Could not apply coupon")
+	}
+
+	status := fiber.StatusCreated
+	if discount.Status == domain.DiscountStatusPending {
+		status = fiber.StatusAccepted
+	}
+	return c.Status(status).JSON(fiber.Map{"discount": discount, "order": order})
+}
+
+// GetOrderDiscounts devuelve los descuentos de la orden (auditoría de quién los aplicó)
+// GET /api/orders/:id/discounts
+func (h *OrderHandler) GetOrderDiscounts(c *fiber.Ctx) error {
+	orderID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid order ID"})
+	}
+	discounts, err := h.orderService.GetOrderDiscounts(orderID)
+	if err != nil {
+		return discountError(c, err, "Could not retrieve order discounts")
+	}
+	return c.JSON(discounts)
+}
+
+// ApproveDiscount aprueba un descuento pendiente (solo admin)
+// PUT /api/orders/:id/discounts/:discountId/approve
+func (h *OrderHandler) ApproveDiscount(c *fiber.Ctx) error {
+	return h.reviewDiscount(c, true)
+}
+
+// RejectDiscount rechaza un descuento pendiente (solo admin)
+// PUT /api/orders/:id/discounts/:discountId/reject
+func (h *OrderHandler) RejectDiscount(c *fiber.Ctx) error {
+	return h.reviewDiscount(c, false)
+}
+
+func (h *OrderHandler) reviewDiscount(c *fiber.Ctx, approve bool) error {
+	orderID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid order ID"})
+	}
+	discountID, err := uuid.Parse(c.Params("discountId"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid discount ID"})
+	}
+	reviewerID, err := uuid.Parse(c.Locals("user_id").(string))
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Invalid user ID"})
+	}
+
+	order, err := h.orderService.ReviewDiscount(orderID, discountID, reviewerID, approve)
+	if err != nil {
+		return discountError(c, err, "Could not review discount")
+	}
+	return c.JSON(order)
+}
+
+func discountError(c *fiber.Ctx, err error, fallback string) error {
+	switch {
+	case errors.Is(err, service.ErrCouponNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": err.Error()})
+	case errors.Is(err, service.ErrCouponNotApplicable), errors.Is(err, service.ErrOrderHasPayments),
+		errors.Is(err, service.ErrOrderClosed), errors.Is(err, service.ErrDiscountNotPending),
+		errors.Is(err, domain.ErrOrderChanged), errors.Is(err, domain.ErrCouponExhausted):
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": err.Error()})
+	case errors.Is(err, sql.ErrNoRows):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Order not found"})
+	}
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": fallback})
+}
@@ -4,7 +4,9 @@
 package handler
 
 import (
+	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"os"
@@ -42,7 +44,7 @@ func (h *OrderHandler) CreateOrder(c *fiber.Ctx) error {
 	waiterID, _ := uuid.Parse(c.Locals("user_id").(string))
 	order, err := h.orderService.CreateOrder(waiterID, payload.TableNumber, payload.OrderType, payload.DeliveryAddress, payload.DeliveryPhone, payload.DeliveryNotes, payload.Items)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+		return orderItemsError(c, err, err.Error())
 	}
 	return c.Status(fiber.StatusCreated).JSON(order)
 }
@@ -72,6 +74,23 @@ func (h *OrderHandler) GetOrderByID(c *fiber.Ctx) error {
 	return c.JSON(order)
 }
 
+// GetOrderHistory devuelve el historial de cambios de una orden (más antiguo primero)
+// GET /api/orders/:id/history
+func (h *OrderHandler) GetOrderHistory(c *fiber.Ctx) error {
+	orderID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid order ID"})
+	}
+	events, err := h.orderService.GetOrderHistory(orderID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Order not found"})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not retrieve order history"})
+	}
+	return c.JSON(events)
+}
+
 type UpdateOrderStatusPayload struct {
 	Status string `json:"status"`
 }
@@ -86,9 +105,10 @@ func (h *OrderHandler) UpdateOrderStatus(c *fiber.Ctx) error {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Cannot parse JSON"})
 	}
 	userID, _ := uuid.Parse(c.Locals("user_id").(string))
-	order, err := h.orderService.UpdateOrderStatus(orderID, userID, payload.Status)
+	userRole, _ := c.Locals("user_role").(string)
+	order, err := h.orderService.UpdateOrderStatus(orderID, userID, userRole, payload.Status)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not update order status"})
+		return orderStatusError(c, err, "Could not update order status")
 	}
 	return c.JSON(order)
 }
@@ -106,13 +126,108 @@ func (h *OrderHandler) UpdateOrderItems(c *fiber.Ctx) error {
 	if err := c.BodyParser(payload); err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Cannot parse JSON"})
 	}
-	order, err := h.orderService.UpdateOrderItems(orderID, payload.Items)
+	actorID, _ := uuid.Parse(c.Locals("user_id").(string))
+	order, err := h.orderService.UpdateOrderItems(orderID, actorID, payload.Items)
+	if err != nil {
+		return orderItemsError(c, err, "Could not update order items")
+	}
+	return c.JSON(order)
+}
+
+// UpdateOrderItemStatus cambia el estado de preparación de un item
+// PUT /api/orders/:id/items/:itemId/status
+func (h *OrderHandler) UpdateOrderItemStatus(c *fiber.Ctx) error {
+	orderID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid order ID"})
+	}
+	itemID, err := uuid.Parse(c.Params("itemId"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid item ID"})
+	}
+	payload := new(domain.UpdateItemStatusRequest)
+	if err := c.BodyParser(payload); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Cannot parse JSON"})
+	}
+	actorID, _ := uuid.Parse(c.Locals("user_id").(string))
+	userRole, _ := c.Locals("user_role").(string)
+	order, err := h.orderService.UpdateOrderItemStatus(orderID, itemID, actorID, userRole, *payload)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not update order items"})
+		return itemStatusError(c, err)
 	}
 	return c.JSON(order)
 }
 
+// UpdateStationItemsStatus cambia el estado de todos los items de una estación en la orden
+// PUT /api/stations/:id/orders/:orderId/items/status
+func (h *OrderHandler) UpdateStationItemsStatus(c *fiber.Ctx) error {
+	stationID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid station ID"})
+	}
+	orderID, err := uuid.Parse(c.Params("orderId"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid order ID"})
+	}
+	payload := new(domain.UpdateItemStatusRequest)
+	if err := c.BodyParser(payload); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Cannot parse JSON"})
+	}
+	actorID, _ := uuid.Parse(c.Locals("user_id").(string))
+	userRole, _ := c.Locals("user_role").(string)
+	order, err := h.orderService.UpdateStationItemsStatus(orderID, stationID, actorID, userRole, *payload)
+	if err != nil {
+		return itemStatusError(c, err)
+	}
+	return c.JSON(order)
+}
+
+// orderStatusError traduce los errores de cambio de estado: cancelación sin motivo (400),
+// transición inválida, saldo pendiente u orden modificada en paralelo (409) u orden inexistente (404)
+func orderStatusError(c *fiber.Ctx, err error, fallback string) error {
+	switch {
+	case errors.Is(err, service.ErrCancelReasonRequired):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	case errors.Is(err, service.ErrInvalidTransition), errors.Is(err, service.ErrBalanceDue),
+		errors.Is(err, domain.ErrOrderChanged):
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": err.Error()})
+	case errors.Is(err, sql.ErrNoRows):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Order not found"})
+	}
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": fallback})
+}
+
+// orderItemsError traduce los errores de precios de los items: producto inexistente o
+// cantidad inválida (400), producto no disponible, orden cerrada, con pagos o modificada en
+// paralelo (409)
+func orderItemsError(c *fiber.Ctx, err error, fallback string) error {
+	switch {
+	case errors.Is(err, service.ErrMenuItemNotFound), errors.Is(err, service.ErrInvalidQuantity):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	case errors.Is(err, service.ErrMenuItemUnavailable), errors.Is(err, service.ErrOrderClosed),
+		errors.Is(err, service.ErrOrderHasPayments), errors.Is(err, domain.ErrOrderChanged):
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": err.Error()})
+	case errors.Is(err, sql.ErrNoRows):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Order not found"})
+	}
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": fallback})
+}
+
+// itemStatusError traduce los errores de estado de items: estado o motivo inválidos (400),
+// anulación no permitida, orden cerrada, con pagos o modificada en paralelo (409), item inexistente (404)
+func itemStatusError(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, service.ErrInvalidItemStatus), errors.Is(err, service.ErrInvalidCancelReason):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	case errors.Is(err, service.ErrInvalidTransition), errors.Is(err, service.ErrOrderClosed),
+		errors.Is(err, service.ErrOrderHasPayments), errors.Is(err, domain.ErrOrderChanged):
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": err.Error()})
+	case errors.Is(err, service.ErrOrderItemNotFound), errors.Is(err, sql.ErrNoRows):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Order item not found"})
+	}
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not update item status"})
+}
+
 func (h *OrderHandler) ManageOrder(c *fiber.Ctx) error {
 	orderID, err := uuid.Parse(c.Params("id"))
 	if err != nil {
@@ -125,9 +240,11 @@ func (h *OrderHandler) ManageOrder(c *fiber.Ctx) error {
 	if err := c.BodyParser(&payload); err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Cannot parse JSON"})
 	}
-	order, err := h.orderService.ManageOrderAsAdmin(orderID, payload.Status, payload.WaiterID)
+	userRole, _ := c.Locals("user_role").(string)
+	actorID, _ := uuid.Parse(c.Locals("user_id").(string))
+	order, err := h.orderService.ManageOrderAsAdmin(orderID, actorID, userRole, payload.Status, payload.WaiterID)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not manage order"})
+		return orderStatusError(c, err, "Could not manage order")
 	}
 	return c.JSON(order)
 }
@@ -184,12 +301,13 @@ func (h *OrderHandler) UploadPaymentProof(c *fiber.Ctx) error {
 
 	// Guardar la ruta relativa en DB
 	proofPath := "/static/proofs/" + filename
-	order, err := h.orderService.AddPaymentProof(orderID, method, proofPath)
+	actorID, _ := uuid.Parse(userID)
+	order, err := h.orderService.AddPaymentProof(orderID, actorID, userRole, method, proofPath)
 	if err != nil {
 		// Intentar limpiar el archivo si DB falla
 		_ = os.Remove(destination)
 		log.Printf("❌ [Handler] Error al actualizar orden: %v", err)
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "could not update order with proof"})
+		return orderStatusError(c, err, "could not update order with proof")
 	}
 
 	log.Printf("✅ [Handler] Comprobante procesado exitosamente para orden %s", orderID.String())
@@ -203,8 +321,9 @@ func (h *OrderHandler) UploadPaymentProof(c *fiber.Ctx) error {
 // - payment_method: 'efectivo' | 'transferencia'
 // - payment_proof: File (opcional, requerido para transferencia)
 func (h *OrderHandler) CreateOrderWithPayment(c *fiber.Ctx) error {
-	// 1. Obtener el user_id del token
+	// 1. Obtener el user_id y el rol del token
 	waiterID, _ := uuid.Parse(c.Locals("user_id").(string))
+	userRole, _ := c.Locals("user_role").(string)
 
 	// 2. Parsear order_data (JSON string)
 	orderDataStr := c.FormValue("order_data")
@@ -229,7 +348,7 @@ func (h *OrderHandler) CreateOrderWithPayment(c *fiber.Ctx) error {
 	// 4. Crear la orden primero
 	order, err := h.orderService.CreateOrder(waiterID, payload.TableNumber, payload.OrderType, payload.DeliveryAddress, payload.DeliveryPhone, payload.DeliveryNotes, payload.Items)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+		return orderItemsError(c, err, err.Error())
 	}
 
 	// 5. Manejar el archivo si es transferencia
@@ -271,13 +390,13 @@ func (h *OrderHandler) CreateOrderWithPayment(c *fiber.Ctx) error {
 	}
 
 	// 6. Actualizar la orden con los datos de pago
-	updatedOrder, err := h.orderService.AddPaymentProof(order.ID, paymentMethod, proofPath)
+	updatedOrder, err := h.orderService.AddPaymentProof(order.ID, waiterID, userRole, paymentMethod, proofPath)
 	if err != nil {
 		// Si falla, intentar limpiar el archivo
 		if proofPath != "" {
 			_ = os.Remove("./uploads" + proofPath[len("/static"):])
 		}
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "could not update order with payment data"})
+		return orderStatusError(c, err, "could not update order with payment data")
 	}
 
 	return c.Status(fiber.StatusCreated).JSON(updatedOrder)
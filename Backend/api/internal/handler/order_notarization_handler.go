@@ -0,0 +1,81 @@
+// =================================================================
+// Order Notarization Handler
+// Estado de la notarización de la factura en blockchain
+// =================================================================
+package handler
+
+import (
+	"database/sql"
+	"errors"
+
+	"github.com/Hoxanfox/TurnyChain/Backend/api/internal/service"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// GetOrderNotarization devuelve la notarización de la orden: estado, transacción,
+// bloque, hash de la factura e intentos
+// GET /api/orders/:id/notarization
+func (h *OrderHandler) GetOrderNotarization(c *fiber.Ctx) error {
+	orderID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid order ID"})
+	}
+	notarization, err := h.orderService.GetOrderNotarization(orderID)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Order not found"})
+		case errors.Is(err, service.ErrNotarizationNotFound):
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": err.Error()})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not retrieve order notarization"})
+	}
+	return c.JSON(notarization)
+}
+
+// RetryOrderNotarization vuelve a poner en cola la notarización fallida de una orden pagada.
+// Si está en curso o confirmada la devuelve sin cambios.
+// POST /api/orders/:id/notarization/retry
+func (h *OrderHandler) RetryOrderNotarization(c *fiber.Ctx) error {
+	orderID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid order ID"})
+	}
+	notarization, err := h.orderService.RetryOrderNotarization(orderID)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Order not found"})
+		case errors.Is(err, service.ErrOrderNotPaid):
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": err.Error()})
+		case errors.Is(err, service.ErrNotarizationNotFound):
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": err.Error()})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not retry order notarization"})
+	}
+	return c.Status(fiber.StatusAccepted).JSON(notarization)
+}
+
+// VerifyOrderInvoice lee del contrato la factura notarizada de la orden y reporta si la
+// orden guardada cambió desde que se notarizó
+// GET /api/orders/:id/verify
+func (h *OrderHandler) VerifyOrderInvoice(c *fiber.Ctx) error {
+	orderID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid order ID"})
+	}
+	verification, err := h.orderService.VerifyOrderInvoice(orderID)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Order not found"})
+		case errors.Is(err, service.ErrBlockchainUnavailable):
+			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": err.Error()})
+		case errors.Is(err, service.ErrNotaryBackendMismatch):
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": err.Error()})
+		}
+		return c.Status(fiber.StatusBadGateway).JSON(fiber.Map{"error": "Could not verify order invoice: " + err.Error()})
+	}
+	return c.JSON(verification)
+}
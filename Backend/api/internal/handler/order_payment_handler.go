@@ -0,0 +1,131 @@
+// =================================================================
+// Order Payment Handler
+// Pagos parciales de una orden (cuentas divididas)
+// =================================================================
+package handler
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/Hoxanfox/TurnyChain/Backend/api/internal/domain"
+	"github.com/Hoxanfox/TurnyChain/Backend/api/internal/service"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// GetOrderPayments devuelve los pagos y el saldo pendiente de la orden
+// GET /api/orders/:id/payments
+func (h *OrderHandler) GetOrderPayments(c *fiber.Ctx) error {
+	orderID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid order ID"})
+	}
+	balance, err := h.orderService.GetOrderBalance(orderID)
+	if err != nil {
+		return paymentError(c, err, "Could not retrieve order payments")
+	}
+	return c.JSON(balance)
+}
+
+// AddOrderPayment registra un pago parcial sobre la orden.
+// Acepta JSON {method, amount, tip, item_ids} o multipart/form-data con los mismos campos
+// (item_ids separados por coma) y un archivo opcional "file" como comprobante.
+// POST /api/orders/:id/payments
+func (h *OrderHandler) AddOrderPayment(c *fiber.Ctx) error {
+	orderID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid order ID"})
+	}
+
+	var req domain.CreateOrderPaymentRequest
+	var proofPath *string
+	var destination string
+	if strings.HasPrefix(string(c.Request().Header.ContentType()), fiber.MIMEMultipartForm) {
+		req.Method = c.FormValue("method")
+		if amount := c.FormValue("amount"); amount != "" {
+			parsed, err := domain.ParseMoney(amount)
+			if err != nil {
+				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid amount"})
+			}
+			req.Amount = &parsed
+		}
+		if tip := c.FormValue("tip"); tip != "" {
+			parsed, err := domain.ParseMoney(tip)
+			if err != nil {
+				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid tip"})
+			}
+			req.Tip = &parsed
+		}
+		for _, raw := range strings.Split(c.FormValue("item_ids"), ",") {
+			if raw = strings.TrimSpace(raw); raw == "" {
+				continue
+			}
+			itemID, err := uuid.Parse(raw)
+			if err != nil {
+				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid item ID"})
+			}
+			req.ItemIDs = append(req.ItemIDs, itemID)
+		}
+
+		if file, err := c.FormFile("file"); err == nil {
+			uploadDir := "./uploads/proofs"
+			if err := os.MkdirAll(uploadDir, os.ModePerm); err != nil {
+				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "could not create upload directory"})
+			}
+			filename := fmt.Sprintf("payment_%s_%d%s", orderID.String(), time.Now().UnixNano(), filepath.Ext(file.Filename))
+			destination = filepath.Join(uploadDir, filename)
+			if err := c.SaveFile(file, destination); err != nil {
+				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "could not save file"})
+			}
+			path := "/static/proofs/" + filename
+			proofPath = &path
+		}
+	} else if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Cannot parse JSON"})
+	}
+
+	actorID, _ := uuid.Parse(c.Locals("user_id").(string))
+	userRole, _ := c.Locals("user_role").(string)
+	balance, err := h.orderService.AddOrderPayment(orderID, actorID, userRole, req, proofPath)
+	if err != nil {
+		if destination != "" {
+			_ = os.Remove(destination)
+		}
+		return paymentError(c, err, "Could not register payment")
+	}
+	return c.Status(fiber.StatusCreated).JSON(balance)
+}
+
+// SplitOrderEvenly calcula la división del saldo pendiente en partes iguales
+// GET /api/orders/:id/payments/split?ways=3
+func (h *OrderHandler) SplitOrderEvenly(c *fiber.Ctx) error {
+	orderID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid order ID"})
+	}
+	split, err := h.orderService.SplitOrderEvenly(orderID, c.QueryInt("ways", 2))
+	if err != nil {
+		return paymentError(c, err, "Could not split order")
+	}
+	return c.JSON(split)
+}
+
+// paymentError traduce los errores de pagos: datos inválidos (400), conflicto con el
+// saldo o el estado de la orden (409) u orden inexistente (404)
+func paymentError(c *fiber.Ctx, err error, fallback string) error {
+	switch {
+	case errors.Is(err, service.ErrInvalidPayment), errors.Is(err, service.ErrOrderItemNotFound):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	case errors.Is(err, domain.ErrPaymentExceedsBalance), errors.Is(err, domain.ErrItemsAlreadyPaid), errors.Is(err, service.ErrOrderClosed):
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": err.Error()})
+	case errors.Is(err, sql.ErrNoRows):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Order not found"})
+	}
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": fallback})
+}
@@ -0,0 +1,68 @@
+// =================================================================
+// Print Job Handler
+// =================================================================
+package handler
+
+import (
+	"github.com/Hoxanfox/TurnyChain/Backend/api/internal/service"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type PrintJobHandler struct {
+	service *service.PrintQueueService
+}
+
+func NewPrintJobHandler(service *service.PrintQueueService) *PrintJobHandler {
+	return &PrintJobHandler{service: service}
+}
+
+// GetAll obtiene los últimos trabajos de impresión
+// GET /api/print-jobs?status=queued|sent|failed
+func (h *PrintJobHandler) GetAll(c *fiber.Ctx) error {
+	jobs, err := h.service.GetAll(c.Query("status"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Error al obtener trabajos de impresión: " + err.Error(),
+		})
+	}
+	return c.JSON(jobs)
+}
+
+// GetByOrderID obtiene los trabajos de impresión de una orden
+// GET /api/orders/:orderId/print-jobs
+func (h *PrintJobHandler) GetByOrderID(c *fiber.Ctx) error {
+	orderID, err := uuid.Parse(c.Params("orderId"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Order ID inválido",
+		})
+	}
+
+	jobs, err := h.service.GetByOrderID(orderID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Error al obtener trabajos de impresión: " + err.Error(),
+		})
+	}
+	return c.JSON(jobs)
+}
+
+// Retry vuelve a poner en cola un trabajo de impresión
+// POST /api/print-jobs/:id/retry
+func (h *PrintJobHandler) Retry(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "ID inválido",
+		})
+	}
+
+	job, err := h.service.Retry(id)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Error al reintentar impresión: " + err.Error(),
+		})
+	}
+	return c.JSON(job)
+}
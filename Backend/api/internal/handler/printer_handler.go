@@ -4,6 +4,8 @@
 package handler
 
 import (
+	"errors"
+
 	"github.com/Hoxanfox/TurnyChain/Backend/api/internal/domain"
 	"github.com/Hoxanfox/TurnyChain/Backend/api/internal/service"
 	"github.com/gofiber/fiber/v2"
@@ -150,3 +152,31 @@ func (h *PrinterHandler) Delete(c *fiber.Ctx) error {
 		"message": "Impresora eliminada correctamente",
 	})
 }
+
+// TestPrint envía una página de prueba a la impresora
+// POST /api/printers/:id/test
+func (h *PrinterHandler) TestPrint(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "ID inválido",
+		})
+	}
+
+	response, err := h.service.TestPrint(id)
+	if errors.Is(err, service.ErrPrinterNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Error al imprimir la página de prueba: " + err.Error(),
+		})
+	}
+
+	if !response.Success {
+		return c.Status(fiber.StatusBadGateway).JSON(response)
+	}
+	return c.JSON(response)
+}
@@ -0,0 +1,112 @@
+// =================================================================
+// Promotion Handler
+// =================================================================
+package handler
+
+import (
+	"errors"
+
+	"github.com/Hoxanfox/TurnyChain/Backend/api/internal/domain"
+	"github.com/Hoxanfox/TurnyChain/Backend/api/internal/service"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type PromotionHandler struct {
+	service *service.PromotionService
+}
+
+func NewPromotionHandler(service *service.PromotionService) *PromotionHandler {
+	return &PromotionHandler{service: service}
+}
+
+// GetAll obtiene todas las promociones
+// GET /api/promotions
+func (h *PromotionHandler) GetAll(c *fiber.Ctx) error {
+	promotions, err := h.service.GetAll()
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Error al obtener promociones: " + err.Error(),
+		})
+	}
+	return c.JSON(promotions)
+}
+
+// GetByID obtiene una promoción por ID
+// GET /api/promotions/:id
+func (h *PromotionHandler) GetByID(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "ID inválido"})
+	}
+	promotion, err := h.service.GetByID(id)
+	if err != nil {
+		return promotionError(c, err, "Error al obtener la promoción")
+	}
+	return c.JSON(promotion)
+}
+
+// Create crea una promoción (solo admin)
+// POST /api/promotions
+func (h *PromotionHandler) Create(c *fiber.Ctx) error {
+	createdBy, err := uuid.Parse(c.Locals("user_id").(string))
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Invalid user ID"})
+	}
+
+	var req domain.PromotionRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Datos inválidos: " + err.Error()})
+	}
+
+	promotion, err := h.service.Create(req, createdBy)
+	if err != nil {
+		return promotionError(c, err, "Error al crear la promoción")
+	}
+	return c.Status(fiber.StatusCreated).JSON(promotion)
+}
+
+// Update actualiza una promoción (solo admin)
+// PUT /api/promotions/:id
+func (h *PromotionHandler) Update(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "ID inválido"})
+	}
+
+	var req domain.PromotionRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Datos inválidos: " + err.Error()})
+	}
+
+	promotion, err := h.service.Update(id, req)
+	if err != nil {
+		return promotionError(c, err, "Error al actualizar la promoción")
+	}
+	return c.JSON(promotion)
+}
+
+// Delete elimina una promoción (solo admin)
+// DELETE /api/promotions/:id
+func (h *PromotionHandler) Delete(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "ID inválido"})
+	}
+	if err := h.service.Delete(id); err != nil {
+		return promotionError(c, err, "Error al eliminar la promoción")
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+func promotionError(c *fiber.Ctx, err error, fallback string) error {
+	switch {
+	case errors.Is(err, service.ErrInvalidPromotion):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	case errors.Is(err, service.ErrPromotionNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": err.Error()})
+	case errors.Is(err, service.ErrDuplicateCoupon):
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": fallback + ": " + err.Error()})
+}
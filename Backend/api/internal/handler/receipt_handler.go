@@ -0,0 +1,85 @@
+// =================================================================
+// Receipt Handler
+// Recibo del cliente (HTML/PDF) e impresión en caja
+// =================================================================
+package handler
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/Hoxanfox/TurnyChain/Backend/api/internal/domain"
+	"github.com/Hoxanfox/TurnyChain/Backend/api/internal/service"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type ReceiptHandler struct {
+	service *service.ReceiptService
+}
+
+func NewReceiptHandler(service *service.ReceiptService) *ReceiptHandler {
+	return &ReceiptHandler{service: service}
+}
+
+// GetReceipt devuelve el recibo de la orden. ?format=html (por defecto) o ?format=pdf
+// GET /api/orders/:id/receipt
+func (h *ReceiptHandler) GetReceipt(c *fiber.Ctx) error {
+	orderID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid order ID"})
+	}
+
+	format := c.Query("format", domain.ReceiptFormatHTML)
+	content, contentType, err := h.service.RenderReceipt(orderID, format)
+	if err != nil {
+		return receiptError(c, err, "Could not generate receipt")
+	}
+
+	if format == domain.ReceiptFormatPDF {
+		c.Set(fiber.HeaderContentDisposition, fmt.Sprintf("inline; filename=\"recibo-%s.pdf\"", orderID))
+	}
+	c.Set(fiber.HeaderContentType, contentType)
+	return c.Send(content)
+}
+
+// PrintReceipt imprime el recibo en una impresora de caja (o en la indicada con printer_id)
+// POST /api/orders/:id/receipt/print
+func (h *ReceiptHandler) PrintReceipt(c *fiber.Ctx) error {
+	orderID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid order ID"})
+	}
+
+	var req domain.PrintReceiptRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Cannot parse JSON"})
+		}
+	}
+
+	response, err := h.service.PrintReceipt(orderID, req)
+	if err != nil {
+		return receiptError(c, err, "Could not print receipt")
+	}
+	if !response.Success {
+		return c.Status(fiber.StatusBadGateway).JSON(response)
+	}
+	return c.JSON(response)
+}
+
+// receiptError traduce los errores del servicio de recibos a respuestas HTTP
+func receiptError(c *fiber.Ctx, err error, fallback string) error {
+	switch {
+	case errors.Is(err, service.ErrInvalidReceiptFormat):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	case errors.Is(err, service.ErrNoCashierPrinter):
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": err.Error()})
+	case errors.Is(err, sql.ErrNoRows):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Order not found"})
+	case err.Error() == "printer not found":
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Printer not found"})
+	}
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": fallback})
+}
@@ -0,0 +1,90 @@
+// =================================================================
+// Report Handler
+// =================================================================
+package handler
+
+import (
+	"errors"
+	"time"
+
+	"github.com/Hoxanfox/TurnyChain/Backend/api/internal/service"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// reportDateLayout es el formato de los parámetros from/to (fechas inclusivas)
+const reportDateLayout = "2006-01-02"
+
+type ReportHandler struct {
+	service *service.ReportService
+}
+
+func NewReportHandler(service *service.ReportService) *ReportHandler {
+	return &ReportHandler{service: service}
+}
+
+// GetWaiterReport obtiene ventas, cargo por servicio y propinas por mesero
+// GET /api/reports/waiters?from=2026-10-01&to=2026-10-18 (por defecto, el día de hoy)
+func (h *ReportHandler) GetWaiterReport(c *fiber.Ctx) error {
+	userID, err := uuid.Parse(c.Locals("user_id").(string))
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Invalid user ID"})
+	}
+	userRole, _ := c.Locals("user_role").(string)
+
+	from, to, err := reportRange(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	report, err := h.service.GetWaiterReport(from, to, userID, userRole)
+	if err != nil {
+		return reportError(c, err)
+	}
+	return c.JSON(report)
+}
+
+// GetCancellationReport obtiene las órdenes canceladas y anuladas con su motivo, agrupadas
+// por tipo y motivo (un mesero solo ve las suyas)
+// GET /api/reports/cancellations?from=2026-10-01&to=2026-10-18 (por defecto, el día de hoy)
+func (h *ReportHandler) GetCancellationReport(c *fiber.Ctx) error {
+	userID, err := uuid.Parse(c.Locals("user_id").(string))
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Invalid user ID"})
+	}
+	userRole, _ := c.Locals("user_role").(string)
+
+	from, to, err := reportRange(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	report, err := h.service.GetCancellationReport(from, to, userID, userRole)
+	if err != nil {
+		return reportError(c, err)
+	}
+	return c.JSON(report)
+}
+
+// reportRange lee from/to (fechas inclusivas, por defecto hoy) como el rango [from, to+1 día)
+func reportRange(c *fiber.Ctx) (time.Time, time.Time, error) {
+	today := time.Now().Format(reportDateLayout)
+	from, err := time.ParseInLocation(reportDateLayout, c.Query("from", today), time.Local)
+	if err != nil {
+		return time.Time{}, time.Time{}, errors.New("Invalid 'from' date, expected YYYY-MM-DD")
+	}
+	to, err := time.ParseInLocation(reportDateLayout, c.Query("to", today), time.Local)
+	if err != nil {
+		return time.Time{}, time.Time{}, errors.New("Invalid 'to' date, expected YYYY-MM-DD")
+	}
+	return from, to.AddDate(0, 0, 1), nil
+}
+
+func reportError(c *fiber.Ctx, err error) error {
+	if errors.Is(err, service.ErrInvalidReportRange) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		"error": "Error al generar el reporte: " + err.Error(),
+	})
+}
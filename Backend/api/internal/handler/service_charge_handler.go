@@ -0,0 +1,54 @@
+// =================================================================
+// Service Charge Handler
+// =================================================================
+package handler
+
+import (
+	"errors"
+
+	"github.com/Hoxanfox/TurnyChain/Backend/api/internal/domain"
+	"github.com/Hoxanfox/TurnyChain/Backend/api/internal/service"
+	"github.com/gofiber/fiber/v2"
+)
+
+type ServiceChargeHandler struct {
+	service *service.ServiceChargeService
+}
+
+func NewServiceChargeHandler(service *service.ServiceChargeService) *ServiceChargeHandler {
+	return &ServiceChargeHandler{service: service}
+}
+
+// GetAll obtiene el cargo por servicio de cada tipo de orden
+// GET /api/service-charges
+func (h *ServiceChargeHandler) GetAll(c *fiber.Ctx) error {
+	settings, err := h.service.GetAll()
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Error al obtener el cargo por servicio: " + err.Error(),
+		})
+	}
+	return c.JSON(settings)
+}
+
+// Update cambia el porcentaje de un tipo de orden (solo admin)
+// PUT /api/service-charges/:orderType
+func (h *ServiceChargeHandler) Update(c *fiber.Ctx) error {
+	var req domain.UpdateServiceChargeRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Datos inválidos: " + err.Error(),
+		})
+	}
+
+	setting, err := h.service.Update(c.Params("orderType"), req.Percentage)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidServiceCharge) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Error al actualizar el cargo por servicio: " + err.Error(),
+		})
+	}
+	return c.JSON(setting)
+}
@@ -23,12 +23,14 @@ func (h *WebSocketHandler) HandleConnection(c *websocket.Conn) {
 	// Extraer información del cliente desde query params
 	userID := c.Query("user_id", "unknown")
 	role := c.Query("role", "unknown")
+	stationID := c.Query("station_id") // Pantallas de cocina (KDS)
 
 	// Crear ClientInfo
 	clientInfo := &wshub.ClientInfo{
-		Conn:   c,
-		UserID: userID,
-		Role:   role,
+		Conn:      c,
+		UserID:    userID,
+		Role:      role,
+		StationID: stationID,
 	}
 
 	// Registrar el nuevo cliente en el hub
@@ -40,6 +42,9 @@ func (h *WebSocketHandler) HandleConnection(c *websocket.Conn) {
 	}()
 
 	log.Printf("🔌 Nueva conexión WebSocket establecida. UserID: %s, Role: %s", userID, role)
+	if stationID != "" {
+		log.Printf("🍳 Pantalla KDS suscrita a la estación %s", stationID)
+	}
 
 	// Bucle para mantener la conexión viva y manejar mensajes del cliente si fuera necesario
 	for {
@@ -0,0 +1,34 @@
+// =================================================================
+// Role Middleware
+// Restricciones por rol sobre las rutas protegidas (van después de
+// Protected, que deja el rol en c.Locals("user_role"))
+// =================================================================
+package middleware
+
+import (
+	"github.com/gofiber/fiber/v2"
+)
+
+// RequireRole deja pasar solo a los usuarios con alguno de los roles dados
+func RequireRole(roles ...string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		role, _ := c.Locals("user_role").(string)
+		for _, allowed := range roles {
+			if role == allowed {
+				return c.Next()
+			}
+		}
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "Your role cannot access this resource"})
+	}
+}
+
+// ReadOnlyRole impide a los usuarios del rol las peticiones que modifican datos (todo lo
+// que no sea GET o HEAD)
+func ReadOnlyRole(role string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if userRole, _ := c.Locals("user_role").(string); userRole == role && c.Method() != fiber.MethodGet && c.Method() != fiber.MethodHead {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "Your role has read-only access"})
+		}
+		return c.Next()
+	}
+}
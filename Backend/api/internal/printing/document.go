@@ -0,0 +1,273 @@
+// =================================================================
+// Printing Document
+// Representación intermedia de un ticket, independiente del formato
+// de salida (ESC/POS, texto plano, PDF...)
+// =================================================================
+package printing
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Hoxanfox/TurnyChain/Backend/api/internal/domain"
+)
+
+// DefaultColumns es el ancho en caracteres de un rollo de 80mm con la fuente A
+const DefaultColumns = 42
+
+// Mesas virtuales usadas por el servicio de órdenes para "llevar" y "domicilio"
+const (
+	takeoutTableNumber  = 9999
+	deliveryTableNumber = 9998
+)
+
+// Align define la alineación de una línea
+type Align int
+
+const (
+	AlignLeft Align = iota
+	AlignCenter
+	AlignRight
+)
+
+// TextSize define el tamaño del texto de una línea
+type TextSize int
+
+const (
+	SizeNormal       TextSize = iota
+	SizeDoubleHeight          // Doble alto, mismo ancho (no reduce columnas)
+	SizeDouble                // Doble alto y doble ancho (la mitad de columnas)
+)
+
+// Line es una línea de texto con su formato. Si QR no está vacío la línea es un
+// código QR con ese contenido (Text se ignora)
+type Line struct {
+	Text  string
+	Align Align
+	Bold  bool
+	Size  TextSize
+	QR    string
+}
+
+// Document es un ticket listo para ser codificado por cualquier encoder
+type Document struct {
+	Title   string
+	Columns int
+	Lines   []Line
+}
+
+// NewDocument crea un documento vacío con el ancho indicado
+func NewDocument(title string, columns int) *Document {
+	if columns <= 0 {
+		columns = DefaultColumns
+	}
+	return &Document{Title: title, Columns: columns}
+}
+
+// Add agrega una línea, partiéndola si excede el ancho disponible
+func (d *Document) Add(line Line) {
+	width := d.Columns
+	if line.Size == SizeDouble {
+		width = d.Columns / 2
+	}
+	for _, text := range wrap(line.Text, width) {
+		l := line
+		l.Text = text
+		d.Lines = append(d.Lines, l)
+	}
+}
+
+// Text agrega una línea normal alineada a la izquierda
+func (d *Document) Text(format string, args ...interface{}) {
+	d.Add(Line{Text: fmt.Sprintf(format, args...)})
+}
+
+// Indented agrega una línea con sangría; las líneas partidas conservan la sangría
+func (d *Document) Indented(indent int, text string) {
+	prefix := strings.Repeat(" ", indent)
+	for _, part := range wrap(text, d.Columns-indent) {
+		d.Lines = append(d.Lines, Line{Text: prefix + part})
+	}
+}
+
+// Separator agrega una línea divisoria del ancho completo
+func (d *Document) Separator() {
+	d.Lines = append(d.Lines, Line{Text: strings.Repeat("-", d.Columns)})
+}
+
+// Blank agrega una línea vacía
+func (d *Document) Blank() {
+	d.Lines = append(d.Lines, Line{})
+}
+
+// Row agrega una línea con un texto a la izquierda y otro (un monto) alineado a la derecha.
+// Si el texto de la izquierda no cabe, se parte y el monto va en la última línea.
+func (d *Document) Row(left, right string, bold bool) {
+	width := d.Columns - len([]rune(right)) - 1
+	if width < 1 {
+		d.Add(Line{Text: left, Bold: bold})
+		d.Add(Line{Text: right, Align: AlignRight, Bold: bold})
+		return
+	}
+
+	parts := wrap(left, width)
+	for _, part := range parts[:len(parts)-1] {
+		d.Lines = append(d.Lines, Line{Text: part, Bold: bold})
+	}
+	last := parts[len(parts)-1]
+	pad := d.Columns - len([]rune(last)) - len([]rune(right))
+	d.Lines = append(d.Lines, Line{Text: last + strings.Repeat(" ", pad) + right, Bold: bold})
+}
+
+// QR agrega un código QR centrado
+func (d *Document) QR(content string) {
+	d.Lines = append(d.Lines, Line{QR: content, Align: AlignCenter})
+}
+
+// KitchenTicketDocument arma el layout de un ticket de cocina para una estación
+func KitchenTicketDocument(ticket domain.KitchenTicket, columns int) *Document {
+	doc := NewDocument(fmt.Sprintf("%s - %s", ticket.OrderNumber, ticket.StationName), columns)
+
+	// --- Encabezado ---
+	doc.Add(Line{Text: strings.ToUpper(ticket.StationName), Align: AlignCenter, Bold: true, Size: SizeDouble})
+	doc.Add(Line{Text: ticket.OrderNumber, Align: AlignCenter, Bold: true, Size: SizeDoubleHeight})
+	if ticket.IsUpdate {
+		doc.Add(Line{Text: "*** MODIFICACION ***", Align: AlignCenter, Bold: true})
+	}
+	if ticket.IsVoid {
+		doc.Add(Line{Text: "*** ORDEN ANULADA ***", Align: AlignCenter, Bold: true, Size: SizeDoubleHeight})
+	}
+	if ticket.IsReprint {
+		doc.Add(Line{Text: "*** REIMPRESION ***", Align: AlignCenter, Bold: true})
+	}
+	doc.Separator()
+
+	switch ticket.OrderType {
+	case "llevar":
+		doc.Add(Line{Text: "PARA LLEVAR", Bold: true, Size: SizeDoubleHeight})
+	case "domicilio":
+		doc.Add(Line{Text: "DOMICILIO", Bold: true, Size: SizeDoubleHeight})
+	default:
+		if ticket.TableNumber != takeoutTableNumber && ticket.TableNumber != deliveryTableNumber {
+			doc.Add(Line{Text: fmt.Sprintf("MESA %d", ticket.TableNumber), Bold: true, Size: SizeDoubleHeight})
+		}
+	}
+	if ticket.WaiterName != "" {
+		doc.Text("Mesero: %s", ticket.WaiterName)
+	}
+	doc.Text("Fecha:  %s", formatTime(ticket.CreatedAt))
+	doc.Separator()
+
+	// --- Items ---
+	for i, item := range ticket.Items {
+		if i > 0 {
+			doc.Blank()
+		}
+		text := fmt.Sprintf("%dx %s", item.Quantity, item.MenuItemName)
+		if item.Change != "" {
+			text = item.Change + " " + text
+		}
+		doc.Add(Line{Text: text, Bold: true, Size: SizeDoubleHeight})
+		if item.IsTakeout && ticket.OrderType == "mesa" {
+			doc.Indented(3, "[PARA LLEVAR]")
+		}
+		if item.Customizations != nil {
+			for _, ing := range item.Customizations.ActiveIngredients {
+				doc.Indented(3, "+ "+ing.Name)
+			}
+			if len(item.Customizations.SelectedAccompaniments) > 0 {
+				names := make([]string, 0, len(item.Customizations.SelectedAccompaniments))
+				for _, acc := range item.Customizations.SelectedAccompaniments {
+					names = append(names, acc.Name)
+				}
+				doc.Indented(3, "Acomp: "+strings.Join(names, ", "))
+			}
+		}
+		if item.Notes != "" {
+			doc.Indented(3, "Nota: "+item.Notes)
+		}
+	}
+
+	if ticket.SpecialNotes != "" {
+		doc.Separator()
+		doc.Add(Line{Text: "NOTAS: " + ticket.SpecialNotes, Bold: true})
+	}
+	doc.Separator()
+
+	return doc
+}
+
+// TestPageDocument arma una página de prueba con los datos de la impresora,
+// una muestra de caracteres especiales y de los formatos de texto
+func TestPageDocument(printer domain.Printer, columns int) *Document {
+	doc := NewDocument("Prueba - "+printer.Name, columns)
+
+	doc.Add(Line{Text: "PAGINA DE PRUEBA", Align: AlignCenter, Bold: true, Size: SizeDouble})
+	doc.Separator()
+	doc.Text("Impresora: %s", printer.Name)
+	if printer.StationName != "" {
+		doc.Text("Estación:  %s", printer.StationName)
+	}
+	if printer.PrinterType != domain.PrinterTypePDF {
+		doc.Text("Dirección: %s:%d", printer.IPAddress, printer.Port)
+	}
+	doc.Text("Tipo:      %s", printer.PrinterType)
+	doc.Text("Fecha:     %s", formatTime(time.Now()))
+	doc.Separator()
+
+	doc.Add(Line{Text: "Izquierda"})
+	doc.Add(Line{Text: "Centro", Align: AlignCenter})
+	doc.Add(Line{Text: "Derecha", Align: AlignRight})
+	doc.Add(Line{Text: "Negrita", Bold: true})
+	doc.Add(Line{Text: "Doble alto", Size: SizeDoubleHeight})
+	doc.Add(Line{Text: "Doble", Size: SizeDouble})
+	doc.Text("Tildes: áéíóú ÁÉÍÓÚ ñÑ ü ¿? ¡!")
+	doc.Text("%s", strings.Repeat("1234567890", columns/10+1)[:columns])
+	doc.Separator()
+
+	return doc
+}
+
+// formatTime formatea la hora de la orden en la zona horaria local del servidor
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		t = time.Now()
+	}
+	return t.Local().Format("02/01/2006 15:04")
+}
+
+// wrap parte un texto en líneas de como máximo width caracteres, respetando palabras
+func wrap(text string, width int) []string {
+	if width <= 0 || len([]rune(text)) <= width {
+		return []string{text}
+	}
+
+	var lines []string
+	var current []rune
+	for _, word := range strings.Fields(text) {
+		w := []rune(word)
+		// Palabras más largas que el ancho se cortan en trozos
+		for len(w) > width {
+			if len(current) > 0 {
+				lines = append(lines, string(current))
+				current = nil
+			}
+			lines = append(lines, string(w[:width]))
+			w = w[width:]
+		}
+		switch {
+		case len(current) == 0:
+			current = w
+		case len(current)+1+len(w) <= width:
+			current = append(append(current, ' '), w...)
+		default:
+			lines = append(lines, string(current))
+			current = w
+		}
+	}
+	if len(current) > 0 {
+		lines = append(lines, string(current))
+	}
+	return lines
+}
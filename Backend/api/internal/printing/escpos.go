@@ -0,0 +1,141 @@
+// =================================================================
+// ESC/POS Encoder
+// Convierte un Document en los bytes que entiende una impresora térmica
+// =================================================================
+package printing
+
+import (
+	"bytes"
+	"strings"
+)
+
+// Comandos ESC/POS utilizados
+var (
+	cmdInit          = []byte{0x1B, 0x40}             // ESC @  - reinicia la impresora
+	cmdCodePagePC850 = []byte{0x1B, 0x74, 0x02}       // ESC t 2 - tabla de caracteres PC850 (Multilingual)
+	cmdCut           = []byte{0x1D, 0x56, 0x42, 0x00} // GS V 66 0 - avanza y corta parcialmente
+)
+
+func cmdAlign(a Align) []byte { return []byte{0x1B, 0x61, byte(a)} } // ESC a n
+
+func cmdBold(on bool) []byte { // ESC E n
+	if on {
+		return []byte{0x1B, 0x45, 0x01}
+	}
+	return []byte{0x1B, 0x45, 0x00}
+}
+
+func cmdSize(s TextSize) []byte { // GS ! n
+	switch s {
+	case SizeDoubleHeight:
+		return []byte{0x1D, 0x21, 0x01}
+	case SizeDouble:
+		return []byte{0x1D, 0x21, 0x11}
+	default:
+		return []byte{0x1D, 0x21, 0x00}
+	}
+}
+
+func cmdFeed(lines byte) []byte { return []byte{0x1B, 0x64, lines} } // ESC d n
+
+// cmdQR genera el código QR en la impresora (GS ( k): modelo 2, módulo de 6 puntos,
+// corrección de errores M, y lo imprime
+func cmdQR(content string) []byte {
+	var buf bytes.Buffer
+	buf.Write([]byte{0x1D, 0x28, 0x6B, 0x04, 0x00, 0x31, 0x41, 0x32, 0x00}) // Modelo 2
+	buf.Write([]byte{0x1D, 0x28, 0x6B, 0x03, 0x00, 0x31, 0x43, 0x06})       // Tamaño del módulo
+	buf.Write([]byte{0x1D, 0x28, 0x6B, 0x03, 0x00, 0x31, 0x45, 0x31})       // Corrección M
+	length := len(content) + 3
+	buf.Write([]byte{0x1D, 0x28, 0x6B, byte(length % 256), byte(length / 256), 0x31, 0x50, 0x30})
+	buf.WriteString(content)
+	buf.Write([]byte{0x1D, 0x28, 0x6B, 0x03, 0x00, 0x31, 0x51, 0x30}) // Imprimir
+	return buf.Bytes()
+}
+
+// EncodeESCPOS genera los comandos ESC/POS para imprimir el documento y cortar el papel
+func EncodeESCPOS(doc *Document) []byte {
+	var buf bytes.Buffer
+	buf.Write(cmdInit)
+	buf.Write(cmdCodePagePC850)
+
+	for _, line := range doc.Lines {
+		buf.Write(cmdAlign(line.Align))
+		if line.QR != "" {
+			buf.Write(cmdQR(line.QR))
+			buf.WriteByte('\n')
+			continue
+		}
+		buf.Write(cmdBold(line.Bold))
+		buf.Write(cmdSize(line.Size))
+		buf.Write(toPC850(line.Text))
+		buf.WriteByte('\n')
+	}
+
+	// Volver al formato normal antes de cortar
+	buf.Write(cmdAlign(AlignLeft))
+	buf.Write(cmdBold(false))
+	buf.Write(cmdSize(SizeNormal))
+	buf.Write(cmdFeed(4))
+	buf.Write(cmdCut)
+	return buf.Bytes()
+}
+
+// EncodePlainText genera el documento como texto plano (PC850) sin comandos de formato.
+// Se usa para impresoras "raw" que no interpretan ESC/POS; los códigos QR se imprimen
+// como su contenido en texto.
+func EncodePlainText(doc *Document) []byte {
+	var buf bytes.Buffer
+	for _, line := range doc.Lines {
+		if line.QR != "" {
+			for _, part := range wrap(line.QR, doc.Columns) {
+				buf.Write(toPC850(part))
+				buf.WriteByte('\n')
+			}
+			continue
+		}
+		text := line.Text
+		width := doc.Columns
+		if line.Size == SizeDouble {
+			width = doc.Columns / 2
+		}
+		if pad := width - len([]rune(text)); pad > 0 {
+			switch line.Align {
+			case AlignCenter:
+				text = strings.Repeat(" ", pad/2) + text
+			case AlignRight:
+				text = strings.Repeat(" ", pad) + text
+			}
+		}
+		buf.Write(toPC850(text))
+		buf.WriteByte('\n')
+	}
+	buf.WriteString("\n\n\n\f")
+	return buf.Bytes()
+}
+
+// pc850 mapea los caracteres no ASCII más comunes en español a la tabla PC850
+var pc850 = map[rune]byte{
+	'á': 0xA0, 'é': 0x82, 'í': 0xA1, 'ó': 0xA2, 'ú': 0xA3,
+	'Á': 0xB5, 'É': 0x90, 'Í': 0xD6, 'Ó': 0xE0, 'Ú': 0xE9,
+	'ñ': 0xA4, 'Ñ': 0xA5, 'ü': 0x81, 'Ü': 0x9A,
+	'¿': 0xA8, '¡': 0xAD, '°': 0xF8, 'º': 0xA7, 'ª': 0xA6,
+	'€': 0xD5, '·': 0xFA,
+}
+
+// toPC850 convierte texto UTF-8 a la tabla PC850; los caracteres desconocidos se reemplazan por '?'
+func toPC850(s string) []byte {
+	out := make([]byte, 0, len(s))
+	for _, r := range s {
+		switch {
+		case r < 0x80:
+			out = append(out, byte(r))
+		default:
+			if b, ok := pc850[r]; ok {
+				out = append(out, b)
+			} else {
+				out = append(out, '?')
+			}
+		}
+	}
+	return out
+}
@@ -0,0 +1,117 @@
+package printing
+
+import (
+	"bytes"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/Hoxanfox/TurnyChain/Backend/api/internal/domain"
+	"github.com/google/uuid"
+)
+
+// fakePrinter escucha en un puerto local como una impresora de red y devuelve por el canal
+// los bytes que recibió en la primera conexión
+func fakePrinter(t *testing.T) (string, int, <-chan []byte) {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("net.Listen: %v", err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	received := make(chan []byte, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			close(received)
+			return
+		}
+		defer conn.Close()
+		_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+		data, _ := io.ReadAll(conn)
+		received <- data
+	}()
+
+	addr := listener.Addr().(*net.TCPAddr)
+	return addr.IP.String(), addr.Port, received
+}
+
+func TestSendKitchenTicketOverTCP(t *testing.T) {
+	host, port, received := fakePrinter(t)
+
+	ticket := domain.KitchenTicket{
+		OrderID:     uuid.New(),
+		OrderNumber: "ORD-007",
+		TableNumber: 5,
+		WaiterName:  "mesero1",
+		StationName: "Cocina",
+		OrderType:   "mesa",
+		CreatedAt:   time.Date(2026, 10, 18, 20, 15, 0, 0, time.UTC),
+		Items: []domain.KitchenTicketItem{
+			{MenuItemName: "Hamburguesa", Quantity: 2, Notes: "sin cebolla"},
+			{MenuItemName: "Jugo de piña", Quantity: 1},
+		},
+	}
+	doc := KitchenTicketDocument(ticket, DefaultColumns)
+
+	if err := NewTCPTransport().Send(host, port, EncodeESCPOS(doc)); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+
+	var data []byte
+	select {
+	case data = <-received:
+	case <-time.After(5 * time.Second):
+		t.Fatal("la impresora no recibió datos")
+	}
+
+	prefix := append(append([]byte{}, cmdInit...), cmdCodePagePC850...)
+	if !bytes.HasPrefix(data, prefix) {
+		t.Errorf("los datos no empiezan con ESC @ y la tabla PC850: % x", data[:min(len(data), 8)])
+	}
+
+	// Encabezado: estación centrada, en negrita y doble tamaño, seguida del número de orden
+	header := bytes.Join([][]byte{
+		cmdAlign(AlignCenter), cmdBold(true), cmdSize(SizeDouble), []byte("COCINA\n"),
+		cmdAlign(AlignCenter), cmdBold(true), cmdSize(SizeDoubleHeight), []byte("ORD-007\n"),
+	}, nil)
+	if !bytes.Contains(data, header) {
+		t.Error("no se encontró el encabezado con la estación y el número de orden")
+	}
+
+	for _, text := range [][]byte{
+		[]byte("MESA 5\n"),
+		[]byte("Mesero: mesero1\n"),
+		[]byte("2x Hamburguesa\n"),
+		[]byte("Nota: sin cebolla\n"),
+		toPC850("1x Jugo de piña\n"),
+	} {
+		if !bytes.Contains(data, text) {
+			t.Errorf("falta %q en el ticket", text)
+		}
+	}
+	if bytes.Contains(data, []byte("piña")) {
+		t.Error("la ñ se envió en UTF-8 en vez de PC850")
+	}
+
+	if !bytes.HasSuffix(data, append(cmdFeed(4), cmdCut...)) {
+		t.Errorf("el ticket no termina con el avance y el corte: % x", data[max(0, len(data)-8):])
+	}
+}
+
+func TestSendUnreachablePrinter(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("net.Listen: %v", err)
+	}
+	port := listener.Addr().(*net.TCPAddr).Port
+	listener.Close()
+
+	transport := NewTCPTransport()
+	transport.DialTimeout = time.Second
+	if err := transport.Send("127.0.0.1", port, []byte("x")); err == nil {
+		t.Error("se esperaba un error al enviar a una impresora que no escucha")
+	}
+}
@@ -0,0 +1,86 @@
+// =================================================================
+// HTML Encoder
+// Renderiza un Document como página HTML imprimible desde el navegador
+// con el mismo layout del rollo (fuente monoespaciada, QR en SVG)
+// =================================================================
+package printing
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+)
+
+const htmlStyle = `body{margin:0;background:#f4f4f4}
+.doc{width:%dch;margin:16px auto;padding:12px 16px;background:#fff;font:14px/1.3 "Courier New",Courier,monospace;color:#000}
+.l{white-space:pre;overflow:hidden}
+.c{text-align:center}.r{text-align:right}.b{font-weight:bold}
+.h2{transform:scaleY(2);transform-origin:top;margin-bottom:1.3em}.w2{font-size:200%%;line-height:1.3}
+.qr{display:block;margin:8px auto;width:60%%;max-width:220px;shape-rendering:crispEdges}
+@media print{body{background:#fff}.doc{margin:0;padding:0}}`
+
+// EncodeHTML genera una página HTML completa con el documento
+func EncodeHTML(doc *Document) []byte {
+	var buf bytes.Buffer
+	buf.WriteString("<!DOCTYPE html>\n<html lang=\"es\">\n<head>\n<meta charset=\"utf-8\">\n")
+	buf.WriteString("<meta name=\"viewport\" content=\"width=device-width, initial-scale=1\">\n")
+	fmt.Fprintf(&buf, "<title>%s</title>\n", html.EscapeString(doc.Title))
+	fmt.Fprintf(&buf, "<style>\n"+htmlStyle+"\n</style>\n</head>\n<body>\n", doc.Columns+2)
+	buf.WriteString("<div class=\"doc\">\n")
+
+	for _, line := range doc.Lines {
+		if line.QR != "" {
+			writeQRSVG(&buf, line.QR, doc.Columns)
+			continue
+		}
+
+		class := "l"
+		switch line.Align {
+		case AlignCenter:
+			class += " c"
+		case AlignRight:
+			class += " r"
+		}
+		if line.Bold {
+			class += " b"
+		}
+		switch line.Size {
+		case SizeDoubleHeight:
+			class += " h2"
+		case SizeDouble:
+			class += " w2"
+		}
+
+		text := html.EscapeString(line.Text)
+		if text == "" {
+			text = "&nbsp;"
+		}
+		fmt.Fprintf(&buf, "<div class=\"%s\">%s</div>\n", class, text)
+	}
+
+	buf.WriteString("</div>\n</body>\n</html>\n")
+	return buf.Bytes()
+}
+
+// writeQRSVG dibuja el código QR como SVG en línea; si no se puede codificar, escribe el texto
+func writeQRSVG(buf *bytes.Buffer, content string, columns int) {
+	matrix, err := qrMatrix(content)
+	if err != nil {
+		for _, part := range wrap(content, columns) {
+			fmt.Fprintf(buf, "<div class=\"l c\">%s</div>\n", html.EscapeString(part))
+		}
+		return
+	}
+
+	size := len(matrix)
+	fmt.Fprintf(buf, "<svg class=\"qr\" xmlns=\"http://www.w3.org/2000/svg\" viewBox=\"0 0 %d %d\" role=\"img\" aria-label=\"%s\">", size, size, html.EscapeString(content))
+	fmt.Fprintf(buf, "<rect width=\"%d\" height=\"%d\" fill=\"#fff\"/><path fill=\"#000\" d=\"", size, size)
+	for y, row := range matrix {
+		for x, dark := range row {
+			if dark {
+				fmt.Fprintf(buf, "M%d %dh1v1h-1z", x, y)
+			}
+		}
+	}
+	buf.WriteString("\"/></svg>\n")
+}
@@ -0,0 +1,163 @@
+// =================================================================
+// PDF Encoder
+// Genera un PDF de una sola página con el ancho de un rollo de 80mm
+// =================================================================
+package printing
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"strings"
+)
+
+// Medidas en puntos PDF (1mm = 2.8346pt)
+const (
+	pdfPageWidth  = 226.77 // 80mm
+	pdfMargin     = 10.0
+	pdfCharWidth  = 0.6 // Ancho de un carácter Courier en unidades de tamaño de fuente
+	pdfLineFactor = 1.25
+	pdfQRModule   = 3.0 // Tamaño máximo de un módulo del código QR
+)
+
+// EncodePDF renderiza el documento como un recibo angosto en PDF.
+// Usa las fuentes estándar Courier/Courier-Bold, por lo que no necesita incrustar fuentes.
+func EncodePDF(doc *Document) []byte {
+	fontSize := (pdfPageWidth - 2*pdfMargin) / (float64(doc.Columns) * pdfCharWidth)
+	leading := fontSize * pdfLineFactor
+
+	// Los códigos QR se dibujan como cuadros; si el contenido no se puede codificar
+	// se imprime como texto
+	qrCodes := make(map[int][][]bool)
+	lines := make([]Line, 0, len(doc.Lines))
+	for _, line := range doc.Lines {
+		if line.QR == "" {
+			lines = append(lines, line)
+			continue
+		}
+		if matrix, err := qrMatrix(line.QR); err == nil {
+			qrCodes[len(lines)] = matrix
+			lines = append(lines, line)
+			continue
+		}
+		for _, part := range wrap(line.QR, doc.Columns) {
+			lines = append(lines, Line{Text: part, Align: line.Align})
+		}
+	}
+
+	// Calcular el alto de la página según las líneas (las de doble alto ocupan dos)
+	height := 2 * pdfMargin
+	for i, line := range lines {
+		if matrix, ok := qrCodes[i]; ok {
+			height += float64(len(matrix)) * qrModuleSize(matrix)
+			continue
+		}
+		height += leading * lineScale(line.Size)
+	}
+
+	// --- Contenido de la página ---
+	var content bytes.Buffer
+	y := height - pdfMargin
+	for i, line := range lines {
+		if matrix, ok := qrCodes[i]; ok {
+			module := qrModuleSize(matrix)
+			y -= float64(len(matrix)) * module
+			x := (pdfPageWidth - float64(len(matrix))*module) / 2
+			content.WriteString("0 g\n")
+			for row, modules := range matrix {
+				for col, dark := range modules {
+					if dark {
+						fmt.Fprintf(&content, "%.2f %.2f %.2f %.2f re\n",
+							x+float64(col)*module, y+float64(len(matrix)-1-row)*module, module, module)
+					}
+				}
+			}
+			content.WriteString("f\n")
+			continue
+		}
+		scaleY := lineScale(line.Size)
+		scaleX := 1.0
+		if line.Size == SizeDouble {
+			scaleX = 2
+		}
+		y -= leading * scaleY
+		if line.Text == "" {
+			continue
+		}
+
+		textWidth := float64(len([]rune(line.Text))) * pdfCharWidth * fontSize * scaleX
+		x := pdfMargin
+		switch line.Align {
+		case AlignCenter:
+			x = (pdfPageWidth - textWidth) / 2
+		case AlignRight:
+			x = pdfPageWidth - pdfMargin - textWidth
+		}
+
+		font := "F1"
+		if line.Bold {
+			font = "F2"
+		}
+		fmt.Fprintf(&content, "BT /%s %.2f Tf %.2f 0 0 %.2f %.2f %.2f Tm (%s) Tj ET\n",
+			font, fontSize, scaleX, scaleY, x, y+leading*0.25*scaleY, pdfEscape(line.Text))
+	}
+
+	// --- Objetos del documento ---
+	objects := []string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %.2f %.2f] /Resources << /Font << /F1 5 0 R /F2 6 0 R >> >> /Contents 4 0 R >>", pdfPageWidth, height),
+		fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", content.Len(), content.String()),
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Courier /Encoding /WinAnsiEncoding >>",
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Courier-Bold /Encoding /WinAnsiEncoding >>",
+		fmt.Sprintf("<< /Title (%s) /Producer (TurnyChain) >>", pdfEscape(doc.Title)),
+	}
+
+	var out bytes.Buffer
+	out.WriteString("%PDF-1.4\n%\xE2\xE3\xCF\xD3\n")
+	offsets := make([]int, len(objects))
+	for i, obj := range objects {
+		offsets[i] = out.Len()
+		fmt.Fprintf(&out, "%d 0 obj\n%s\nendobj\n", i+1, obj)
+	}
+
+	xref := out.Len()
+	fmt.Fprintf(&out, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, off := range offsets {
+		fmt.Fprintf(&out, "%010d 00000 n \n", off)
+	}
+	fmt.Fprintf(&out, "trailer\n<< /Size %d /Root 1 0 R /Info %d 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, len(objects), xref)
+	return out.Bytes()
+}
+
+// qrModuleSize ajusta el tamaño del módulo para que el QR quepa en el ancho del rollo
+func qrModuleSize(matrix [][]bool) float64 {
+	return math.Min(pdfQRModule, (pdfPageWidth-2*pdfMargin)/float64(len(matrix)))
+}
+
+func lineScale(size TextSize) float64 {
+	if size == SizeNormal {
+		return 1
+	}
+	return 2
+}
+
+// pdfEscape convierte el texto a WinAnsi (Latin-1 para los caracteres del español)
+// y escapa los caracteres especiales de los strings PDF
+func pdfEscape(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case r == '(' || r == ')' || r == '\\':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r < 0x80:
+			b.WriteRune(r)
+		case r <= 0xFF:
+			b.WriteByte(byte(r))
+		default:
+			b.WriteByte('?')
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,20 @@
+// =================================================================
+// QR Codes
+// Matriz de módulos de un código QR para los encoders que lo dibujan
+// (PDF, HTML). Las impresoras ESC/POS generan el QR por sí mismas
+// =================================================================
+package printing
+
+import (
+	qrcode "github.com/skip2/go-qrcode"
+)
+
+// qrMatrix devuelve los módulos del código QR (matrix[y][x] = negro), incluida la
+// zona de silencio. Usa corrección de errores media (~15%), suficiente para papel térmico.
+func qrMatrix(content string) ([][]bool, error) {
+	code, err := qrcode.New(content, qrcode.Medium)
+	if err != nil {
+		return nil, err
+	}
+	return code.Bitmap(), nil
+}
@@ -0,0 +1,170 @@
+// =================================================================
+// Receipt Document
+// Layout del recibo del cliente: items con sus acompañamientos,
+// totales e impuestos, pagos y verificación de la factura en blockchain
+// =================================================================
+package printing
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Hoxanfox/TurnyChain/Backend/api/internal/domain"
+)
+
+// ReceiptDocument arma el recibo de una orden. Si la orden aún no está pagada el
+// documento es una pre-cuenta (sin número de factura).
+func ReceiptDocument(receipt domain.Receipt, columns int) *Document {
+	order := receipt.Order
+	title := fmt.Sprintf("Recibo ORD-%03d", order.OrderNumber)
+	if order.InvoiceNumber != nil {
+		title = fmt.Sprintf("Factura %06d", *order.InvoiceNumber)
+	}
+	doc := NewDocument(title, columns)
+
+	// --- Encabezado ---
+	doc.Add(Line{Text: receipt.RestaurantName, Align: AlignCenter, Bold: true, Size: SizeDouble})
+	switch {
+	case order.InvoiceNumber != nil:
+		doc.Add(Line{Text: fmt.Sprintf("FACTURA N° %06d", *order.InvoiceNumber), Align: AlignCenter, Bold: true, Size: SizeDoubleHeight})
+	default:
+		doc.Add(Line{Text: "PRE-CUENTA", Align: AlignCenter, Bold: true, Size: SizeDoubleHeight})
+	}
+	if order.Status == "cancelado" {
+		doc.Add(Line{Text: "*** ORDEN CANCELADA ***", Align: AlignCenter, Bold: true})
+	}
+	doc.Separator()
+
+	doc.Text("Orden:  ORD-%03d", order.OrderNumber)
+	switch order.OrderType {
+	case "llevar":
+		doc.Text("Tipo:   Para llevar")
+	case "domicilio":
+		doc.Text("Tipo:   Domicilio")
+		if order.DeliveryAddress != nil {
+			doc.Indented(8, *order.DeliveryAddress)
+		}
+	default:
+		if order.TableNumber != takeoutTableNumber && order.TableNumber != deliveryTableNumber {
+			doc.Text("Mesa:   %d", order.TableNumber)
+		}
+	}
+	if order.WaiterName != "" {
+		doc.Text("Mesero: %s", order.WaiterName)
+	}
+	doc.Text("Fecha:  %s", formatTime(receipt.GeneratedAt))
+	doc.Separator()
+
+	// --- Items ---
+	for _, item := range order.Items {
+		name := fmt.Sprintf("%dx %s", item.Quantity, item.MenuItemName)
+		doc.Row(name, item.PriceAtOrder.Mul(item.Quantity).String(), false)
+		if item.Quantity > 1 {
+			doc.Indented(3, "c/u "+item.PriceAtOrder.String())
+		}
+		for _, acc := range item.Customizations.SelectedAccompaniments {
+			price := "incluido"
+			if acc.Price > 0 {
+				price = "+" + acc.Price.String()
+			}
+			doc.Row("   + "+acc.Name, price, false)
+		}
+		if item.Notes != nil && *item.Notes != "" {
+			doc.Indented(3, "Nota: "+*item.Notes)
+		}
+	}
+	doc.Separator()
+
+	// --- Totales ---
+	doc.Row("Subtotal", order.Subtotal.String(), false)
+	for _, discount := range receipt.Discounts {
+		doc.Row("Descuento "+discount.PromotionName, "-"+discount.Amount.String(), false)
+	}
+	if order.ServiceCharge > 0 {
+		doc.Row(fmt.Sprintf("Servicio (%s%%)", order.ServiceChargeRate), order.ServiceCharge.String(), false)
+	}
+	if !receipt.PricesIncludeTax {
+		for _, line := range order.TaxBreakdown {
+			if line.Tax > 0 {
+				doc.Row(fmt.Sprintf("Impuesto %s%%", line.Rate), line.Tax.String(), false)
+			}
+		}
+	}
+	doc.Add(Line{Text: totalRow("TOTAL", order.Total.String(), columns/2), Bold: true, Size: SizeDouble})
+	if order.Tip > 0 {
+		doc.Row("Propina", order.Tip.String(), false)
+		doc.Row("Total con propina", order.Total.Add(order.Tip).String(), true)
+	}
+
+	// Desglose de impuestos (base gravable por tarifa)
+	if order.Tax > 0 {
+		doc.Blank()
+		if receipt.PricesIncludeTax {
+			doc.Text("Impuestos incluidos:")
+		} else {
+			doc.Text("Detalle de impuestos:")
+		}
+		for _, line := range order.TaxBreakdown {
+			if line.Tax > 0 {
+				doc.Row(fmt.Sprintf("  %s%% base %s", line.Rate, line.Base), line.Tax.String(), false)
+			}
+		}
+	}
+
+	// --- Pagos ---
+	if len(receipt.Payments) > 0 {
+		doc.Separator()
+		doc.Text("Pagos:")
+		var paid domain.Money
+		for _, payment := range receipt.Payments {
+			label := fmt.Sprintf("  %s %s", paymentMethodLabel(payment.Method), payment.CreatedAt.Local().Format("15:04"))
+			doc.Row(label, payment.Amount.String(), false)
+			if payment.Tip > 0 {
+				doc.Row("    propina", payment.Tip.String(), false)
+			}
+			paid = paid.Add(payment.Amount)
+		}
+		if due := order.Total.Sub(paid); due > 0 {
+			doc.Row("Saldo pendiente", due.String(), true)
+		}
+	}
+
+	// --- Verificación en blockchain ---
+	doc.Separator()
+	doc.Add(Line{Text: "Verificación de la factura", Align: AlignCenter, Bold: true})
+	doc.Text("Hash factura:")
+	doc.Indented(2, receipt.InvoiceHash)
+	if order.BlockchainTxHash != nil {
+		doc.Text("Transacción:")
+		doc.Indented(2, *order.BlockchainTxHash)
+	} else {
+		doc.Text("Transacción: pendiente de notarizar")
+	}
+	doc.QR(receipt.VerificationData)
+	doc.Separator()
+	doc.Add(Line{Text: "¡Gracias por su visita!", Align: AlignCenter})
+
+	return doc
+}
+
+// totalRow arma una fila etiqueta/monto para texto de doble ancho (la mitad de columnas)
+func totalRow(label, amount string, width int) string {
+	pad := width - len([]rune(label)) - len([]rune(amount))
+	if pad < 1 {
+		pad = 1
+	}
+	return label + strings.Repeat(" ", pad) + amount
+}
+
+// paymentMethodLabel devuelve el nombre del método de pago para mostrar
+func paymentMethodLabel(method string) string {
+	switch method {
+	case domain.PaymentMethodCash:
+		return "Efectivo"
+	case domain.PaymentMethodTransfer:
+		return "Transferencia"
+	case domain.PaymentMethodCard:
+		return "Tarjeta"
+	}
+	return method
+}
@@ -0,0 +1,66 @@
+// =================================================================
+// Printer Transport
+// Envío de bytes a impresoras de red (puerto 9100 / JetDirect)
+// =================================================================
+package printing
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"time"
+)
+
+// Transport envía un bloque de bytes a una impresora en host:port
+type Transport interface {
+	Send(host string, port int, data []byte) error
+}
+
+// TCPTransport envía los datos por un socket TCP con timeouts de conexión y escritura
+type TCPTransport struct {
+	DialTimeout  time.Duration
+	WriteTimeout time.Duration
+}
+
+// NewTCPTransport crea un transporte TCP con timeouts razonables para una LAN
+func NewTCPTransport() *TCPTransport {
+	return &TCPTransport{
+		DialTimeout:  3 * time.Second,
+		WriteTimeout: 5 * time.Second,
+	}
+}
+
+// Send abre la conexión, escribe todos los bytes y la cierra
+func (t *TCPTransport) Send(host string, port int, data []byte) error {
+	address := net.JoinHostPort(host, strconv.Itoa(port))
+
+	conn, err := net.DialTimeout("tcp", address, t.DialTimeout)
+	if err != nil {
+		return fmt.Errorf("no se pudo conectar a la impresora %s: %w", address, err)
+	}
+	defer conn.Close()
+
+	if err := conn.SetWriteDeadline(time.Now().Add(t.WriteTimeout)); err != nil {
+		return fmt.Errorf("error configurando timeout de escritura: %w", err)
+	}
+
+	if n, err := conn.Write(data); err != nil {
+		return fmt.Errorf("error enviando datos a la impresora %s (%d/%d bytes): %w", address, n, len(data), err)
+	}
+
+	return nil
+}
+
+// Probe verifica que la impresora acepte conexiones TCP y devuelve la latencia de conexión
+func Probe(host string, port int, timeout time.Duration) (time.Duration, error) {
+	address := net.JoinHostPort(host, strconv.Itoa(port))
+
+	start := time.Now()
+	conn, err := net.DialTimeout("tcp", address, timeout)
+	if err != nil {
+		return 0, err
+	}
+	latency := time.Since(start)
+	conn.Close()
+	return latency, nil
+}
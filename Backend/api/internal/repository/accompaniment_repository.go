@@ -11,10 +11,10 @@ import (
 )
 
 type AccompanimentRepository interface {
-	Create(name string, price float64) (*domain.Accompaniment, error)
+	Create(name string, price domain.Money) (*domain.Accompaniment, error)
 	GetAll() ([]domain.Accompaniment, error)
 	GetByIDs(ids []uuid.UUID) ([]domain.Accompaniment, error)
-	Update(id uuid.UUID, name string, price float64) (*domain.Accompaniment, error)
+	Update(id uuid.UUID, name string, price domain.Money) (*domain.Accompaniment, error)
 	Delete(id uuid.UUID) error
 }
 
@@ -24,7 +24,7 @@ func NewAccompanimentRepository(db *sql.DB) AccompanimentRepository {
 	return &accompanimentRepository{db: db}
 }
 
-func (r *accompanimentRepository) Create(name string, price float64) (*domain.Accompaniment, error) {
+func (r *accompanimentRepository) Create(name string, price domain.Money) (*domain.Accompaniment, error) {
 	acc := &domain.Accompaniment{ID: uuid.New(), Name: name, Price: price}
 	query := "INSERT INTO accompaniments (id, name, price) VALUES ($1, $2, $3) RETURNING id"
 	err := r.db.QueryRow(query, acc.ID, acc.Name, acc.Price).Scan(&acc.ID)
@@ -74,7 +74,7 @@ func (r *accompanimentRepository) GetByIDs(ids []uuid.UUID) ([]domain.Accompanim
 	return accompaniments, nil
 }
 
-func (r *accompanimentRepository) Update(id uuid.UUID, name string, price float64) (*domain.Accompaniment, error) {
+func (r *accompanimentRepository) Update(id uuid.UUID, name string, price domain.Money) (*domain.Accompaniment, error) {
 	acc := &domain.Accompaniment{ID: id, Name: name, Price: price}
 	query := "UPDATE accompaniments SET name = $1, price = $2 WHERE id = $3 RETURNING id, name, price"
 	err := r.db.QueryRow(query, acc.Name, acc.Price, acc.ID).Scan(&acc.ID, &acc.Name, &acc.Price)
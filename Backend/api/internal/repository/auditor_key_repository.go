@@ -0,0 +1,59 @@
+// =================================================================
+// Auditor Key Repository
+// Llaves públicas de los auditores para las exportaciones cifradas
+// =================================================================
+package repository
+
+import (
+	"database/sql"
+
+	"github.com/Hoxanfox/TurnyChain/Backend/api/internal/domain"
+	"github.com/google/uuid"
+)
+
+type AuditorKeyRepository interface {
+	Upsert(key *domain.AuditorKey) (*domain.AuditorKey, error)
+	GetByUserID(userID uuid.UUID) (*domain.AuditorKey, error)
+	Delete(userID uuid.UUID) error
+}
+
+type auditorKeyRepository struct{ db *sql.DB }
+
+func NewAuditorKeyRepository(db *sql.DB) AuditorKeyRepository {
+	return &auditorKeyRepository{db: db}
+}
+
+const auditorKeyColumns = `user_id, public_key, fingerprint, granted_by, created_at, updated_at`
+
+func scanAuditorKey(row interface{ Scan(...interface{}) error }) (*domain.AuditorKey, error) {
+	var k domain.AuditorKey
+	if err := row.Scan(&k.UserID, &k.PublicKey, &k.Fingerprint, &k.GrantedBy, &k.CreatedAt, &k.UpdatedAt); err != nil {
+		return nil, err
+	}
+	return &k, nil
+}
+
+// Upsert registra (o reemplaza) la llave del auditor. Devuelve sql.ErrNoRows si el usuario
+// no existe, está inactivo o no tiene el rol auditor.
+func (r *auditorKeyRepository) Upsert(key *domain.AuditorKey) (*domain.AuditorKey, error) {
+	query := `INSERT INTO auditor_keys (user_id, public_key, fingerprint, granted_by)
+	          SELECT id, $2, $3, $4 FROM users WHERE id = $1 AND role = 'auditor' AND is_active = true
+	          ON CONFLICT (user_id) DO UPDATE
+	          SET public_key = EXCLUDED.public_key, fingerprint = EXCLUDED.fingerprint, granted_by = EXCLUDED.granted_by
+	          RETURNING ` + auditorKeyColumns
+	return scanAuditorKey(r.db.QueryRow(query, key.UserID, key.PublicKey, key.Fingerprint, key.GrantedBy))
+}
+
+// GetByUserID devuelve la llave de un auditor activo (sql.ErrNoRows si no tiene)
+func (r *auditorKeyRepository) GetByUserID(userID uuid.UUID) (*domain.AuditorKey, error) {
+	query := `SELECT k.user_id, k.public_key, k.fingerprint, k.granted_by, k.created_at, k.updated_at
+	          FROM auditor_keys k
+	          JOIN users u ON u.id = k.user_id
+	          WHERE k.user_id = $1 AND u.is_active = true`
+	return scanAuditorKey(r.db.QueryRow(query, userID))
+}
+
+// Delete revoca la llave del auditor (sql.ErrNoRows si no tenía)
+func (r *auditorKeyRepository) Delete(userID uuid.UUID) error {
+	return execOne(r.db, `DELETE FROM auditor_keys WHERE user_id = $1`, userID)
+}
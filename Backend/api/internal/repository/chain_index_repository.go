@@ -0,0 +1,223 @@
+// =================================================================
+// Chain Index Repository
+// Eventos InvoiceSecured indexados y cursor del indexador por fuente
+// =================================================================
+package repository
+
+import (
+	"database/sql"
+
+	"github.com/Hoxanfox/TurnyChain/Backend/api/internal/domain"
+)
+
+type ChainIndexRepository interface {
+	GetCursor(source string) (*domain.ChainIndexCursor, error)
+	SaveRange(source string, events []domain.ChainInvoiceEvent, lastBlock int64, lastBlockHash string) error
+	Rewind(source string, lastBlock int64, lastBlockHash string) (int64, error)
+	LastEventAtOrBefore(source string, block int64) (*domain.ChainInvoiceEvent, error)
+	CountEvents(source string) (int, error)
+	ListEvents(source string, limit, offset int) ([]domain.ChainInvoiceEvent, error)
+	CountPaidWithoutEvent(source string) (int, error)
+	ListPaidWithoutEvent(source string, limit int) ([]domain.ReconciliationOrder, error)
+	CountEventsWithoutPaidOrder(source string) (int, error)
+	ListEventsWithoutPaidOrder(source string, limit int) ([]domain.ChainInvoiceEvent, error)
+}
+
+type chainIndexRepository struct{ db *sql.DB }
+
+func NewChainIndexRepository(db *sql.DB) ChainIndexRepository {
+	return &chainIndexRepository{db: db}
+}
+
+const chainEventColumns = `e.id, e.source, e.order_id, e.order_id_hash, e.tx_hash, e.log_index, e.block_number, e.block_hash, e.block_timestamp, e.created_at, o.status`
+
+func scanChainEvents(rows *sql.Rows) ([]domain.ChainInvoiceEvent, error) {
+	defer rows.Close()
+
+	events := make([]domain.ChainInvoiceEvent, 0)
+	for rows.Next() {
+		var e domain.ChainInvoiceEvent
+		if err := rows.Scan(&e.ID, &e.Source, &e.OrderID, &e.OrderIDHash, &e.TxHash, &e.LogIndex, &e.BlockNumber, &e.BlockHash, &e.BlockTimestamp, &e.CreatedAt, &e.OrderStatus); err != nil {
+			return nil, err
+		}
+		events = append(events, e)
+	}
+	return events, rows.Err()
+}
+
+// GetCursor devuelve el último bloque indexado de la fuente (sql.ErrNoRows si nunca indexó)
+func (r *chainIndexRepository) GetCursor(source string) (*domain.ChainIndexCursor, error) {
+	var c domain.ChainIndexCursor
+	query := `SELECT source, last_block, last_block_hash, updated_at FROM chain_indexer_state WHERE source = $1`
+	if err := r.db.QueryRow(query, source).Scan(&c.Source, &c.LastBlock, &c.LastBlockHash, &c.UpdatedAt); err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// SaveRange guarda los eventos de un rango de bloques y avanza el cursor en la misma
+// transacción. Un evento ya indexado (misma transacción y posición) no se duplica.
+func (r *chainIndexRepository) SaveRange(source string, events []domain.ChainInvoiceEvent, lastBlock int64, lastBlockHash string) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	eventQuery := `INSERT INTO chain_invoice_events (source, order_id, order_id_hash, tx_hash, log_index, block_number, block_hash, block_timestamp)
+	               VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	               ON CONFLICT (source, tx_hash, log_index) DO NOTHING`
+	for _, e := range events {
+		if _, err := tx.Exec(eventQuery, source, e.OrderID, e.OrderIDHash, e.TxHash, e.LogIndex, e.BlockNumber, e.BlockHash, e.BlockTimestamp); err != nil {
+			return err
+		}
+	}
+
+	if err := saveCursor(tx, source, lastBlock, lastBlockHash); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// Rewind borra los eventos posteriores a lastBlock (quedaron fuera de la cadena tras una
+// reorganización) y mueve el cursor ahí. Devuelve cuántos eventos borró.
+func (r *chainIndexRepository) Rewind(source string, lastBlock int64, lastBlockHash string) (int64, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(`DELETE FROM chain_invoice_events WHERE source = $1 AND block_number > $2`, source, lastBlock)
+	if err != nil {
+		return 0, err
+	}
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	if err := saveCursor(tx, source, lastBlock, lastBlockHash); err != nil {
+		return 0, err
+	}
+	return deleted, tx.Commit()
+}
+
+func saveCursor(tx *sql.Tx, source string, lastBlock int64, lastBlockHash string) error {
+	query := `INSERT INTO chain_indexer_state (source, last_block, last_block_hash) VALUES ($1, $2, $3)
+	          ON CONFLICT (source) DO UPDATE SET last_block = EXCLUDED.last_block, last_block_hash = EXCLUDED.last_block_hash`
+	_, err := tx.Exec(query, source, lastBlock, lastBlockHash)
+	return err
+}
+
+// LastEventAtOrBefore devuelve el último evento indexado hasta el bloque dado (sql.ErrNoRows
+// si no hay ninguno)
+func (r *chainIndexRepository) LastEventAtOrBefore(source string, block int64) (*domain.ChainInvoiceEvent, error) {
+	query := `SELECT ` + chainEventColumns + `
+	          FROM chain_invoice_events e
+	          LEFT JOIN orders o ON o.id = e.order_id
+	          WHERE e.source = $1 AND e.block_number <= $2
+	          ORDER BY e.block_number DESC, e.log_index DESC
+	          LIMIT 1`
+	rows, err := r.db.Query(query, source, block)
+	if err != nil {
+		return nil, err
+	}
+	events, err := scanChainEvents(rows)
+	if err != nil {
+		return nil, err
+	}
+	if len(events) == 0 {
+		return nil, sql.ErrNoRows
+	}
+	return &events[0], nil
+}
+
+// CountEvents cuenta los eventos indexados de la fuente
+func (r *chainIndexRepository) CountEvents(source string) (int, error) {
+	var count int
+	err := r.db.QueryRow(`SELECT COUNT(*) FROM chain_invoice_events WHERE source = $1`, source).Scan(&count)
+	return count, err
+}
+
+// ListEvents devuelve los eventos indexados de la fuente con el estado actual de su orden,
+// del más reciente al más antiguo
+func (r *chainIndexRepository) ListEvents(source string, limit, offset int) ([]domain.ChainInvoiceEvent, error) {
+	query := `SELECT ` + chainEventColumns + `
+	          FROM chain_invoice_events e
+	          LEFT JOIN orders o ON o.id = e.order_id
+	          WHERE e.source = $1
+	          ORDER BY e.block_number DESC, e.log_index DESC
+	          LIMIT $2 OFFSET $3`
+	rows, err := r.db.Query(query, source, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	return scanChainEvents(rows)
+}
+
+// paidWithoutEventWhere filtra las órdenes pagadas sin evento indexado en la fuente. Las
+// notarizadas en un lote confirmado no emiten InvoiceSecured y no se cuentan.
+const paidWithoutEventWhere = `
+	WHERE o.status = 'pagado'
+	  AND NOT EXISTS (SELECT 1 FROM chain_invoice_events e WHERE e.source = $1 AND e.order_id = o.id)
+	  AND NOT (n.batch_id IS NOT NULL AND n.status = 'confirmed')`
+
+// CountPaidWithoutEvent cuenta las órdenes pagadas sin evento indexado
+func (r *chainIndexRepository) CountPaidWithoutEvent(source string) (int, error) {
+	var count int
+	query := `SELECT COUNT(*) FROM orders o LEFT JOIN order_notarizations n ON n.order_id = o.id` + paidWithoutEventWhere
+	err := r.db.QueryRow(query, source).Scan(&count)
+	return count, err
+}
+
+// ListPaidWithoutEvent devuelve las órdenes pagadas sin evento indexado con su
+// notarización, de la más antigua a la más reciente
+func (r *chainIndexRepository) ListPaidWithoutEvent(source string, limit int) ([]domain.ReconciliationOrder, error) {
+	query := `SELECT o.id, o.invoice_number, o.table_number, o.total, o.updated_at, n.status, n.backend, n.tx_hash, n.block_number, n.confirmed_at
+	          FROM orders o
+	          LEFT JOIN order_notarizations n ON n.order_id = o.id` + paidWithoutEventWhere + `
+	          ORDER BY o.updated_at
+	          LIMIT $2`
+	rows, err := r.db.Query(query, source, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	orders := make([]domain.ReconciliationOrder, 0)
+	for rows.Next() {
+		var o domain.ReconciliationOrder
+		if err := rows.Scan(&o.OrderID, &o.InvoiceNumber, &o.TableNumber, &o.Total, &o.UpdatedAt, &o.NotarizationStatus, &o.Backend, &o.TxHash, &o.BlockNumber, &o.ConfirmedAt); err != nil {
+			return nil, err
+		}
+		orders = append(orders, o)
+	}
+	return orders, rows.Err()
+}
+
+const eventsWithoutPaidOrderWhere = `
+	WHERE e.source = $1 AND (o.id IS NULL OR o.status <> 'pagado')`
+
+// CountEventsWithoutPaidOrder cuenta los eventos indexados cuya orden no existe o no está pagada
+func (r *chainIndexRepository) CountEventsWithoutPaidOrder(source string) (int, error) {
+	var count int
+	query := `SELECT COUNT(*) FROM chain_invoice_events e LEFT JOIN orders o ON o.id = e.order_id` + eventsWithoutPaidOrderWhere
+	err := r.db.QueryRow(query, source).Scan(&count)
+	return count, err
+}
+
+// ListEventsWithoutPaidOrder devuelve los eventos indexados cuya orden no existe o no está
+// pagada, del más antiguo al más reciente
+func (r *chainIndexRepository) ListEventsWithoutPaidOrder(source string, limit int) ([]domain.ChainInvoiceEvent, error) {
+	query := `SELECT ` + chainEventColumns + `
+	          FROM chain_invoice_events e
+	          LEFT JOIN orders o ON o.id = e.order_id` + eventsWithoutPaidOrderWhere + `
+	          ORDER BY e.block_number, e.log_index
+	          LIMIT $2`
+	rows, err := r.db.Query(query, source, limit)
+	if err != nil {
+		return nil, err
+	}
+	return scanChainEvents(rows)
+}
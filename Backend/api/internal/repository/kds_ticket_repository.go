@@ -0,0 +1,166 @@
+// =================================================================
+// KDS Ticket Repository
+// =================================================================
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/Hoxanfox/TurnyChain/Backend/api/internal/domain"
+	"github.com/google/uuid"
+)
+
+type KDSTicketRepository struct {
+	db *sql.DB
+}
+
+func NewKDSTicketRepository(db *sql.DB) *KDSTicketRepository {
+	return &KDSTicketRepository{db: db}
+}
+
+const kdsTicketColumns = `
+		k.id, k.order_id, k.station_id, s.name as station_name, k.status, k.ticket,
+		k.created_at, k.started_at, k.ready_at, k.updated_at`
+
+const kdsTicketJoins = `
+		FROM kds_tickets k
+		INNER JOIN stations s ON s.id = k.station_id`
+
+func scanKDSTicket(row interface{ Scan(...interface{}) error }) (*domain.KDSTicket, error) {
+	var ticket domain.KDSTicket
+	var startedAt, readyAt sql.NullTime
+	err := row.Scan(&ticket.ID, &ticket.OrderID, &ticket.StationID, &ticket.StationName, &ticket.Status, &ticket.Ticket,
+		&ticket.CreatedAt, &startedAt, &readyAt, &ticket.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	if startedAt.Valid {
+		t := startedAt.Time
+		ticket.StartedAt = &t
+	}
+	if readyAt.Valid {
+		t := readyAt.Time
+		ticket.ReadyAt = &t
+	}
+	return &ticket, nil
+}
+
+func (r *KDSTicketRepository) queryTickets(query string, args ...interface{}) ([]domain.KDSTicket, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tickets := make([]domain.KDSTicket, 0)
+	for rows.Next() {
+		ticket, err := scanKDSTicket(rows)
+		if err != nil {
+			return nil, err
+		}
+		tickets = append(tickets, *ticket)
+	}
+	return tickets, rows.Err()
+}
+
+// Create registra un ticket en la pantalla de su estación
+func (r *KDSTicketRepository) Create(ticket domain.KitchenTicket) (*domain.KDSTicket, error) {
+	var id uuid.UUID
+	query := `
+		INSERT INTO kds_tickets (order_id, station_id, ticket)
+		VALUES ($1, $2, $3)
+		RETURNING id
+	`
+	if err := r.db.QueryRow(query, ticket.OrderID, ticket.StationID, ticket).Scan(&id); err != nil {
+		return nil, err
+	}
+	return r.GetByID(id)
+}
+
+// GetByID obtiene un ticket por ID
+func (r *KDSTicketRepository) GetByID(id uuid.UUID) (*domain.KDSTicket, error) {
+	query := `SELECT` + kdsTicketColumns + kdsTicketJoins + ` WHERE k.id = $1`
+	ticket, err := scanKDSTicket(r.db.QueryRow(query, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return ticket, err
+}
+
+// GetQueueByStation obtiene los tickets pendientes (new, in_progress) de una estación, los más antiguos primero
+func (r *KDSTicketRepository) GetQueueByStation(stationID uuid.UUID) ([]domain.KDSTicket, error) {
+	query := `SELECT` + kdsTicketColumns + kdsTicketJoins + `
+		WHERE k.station_id = $1 AND k.status IN ('new', 'in_progress')
+		ORDER BY k.created_at`
+	return r.queryTickets(query, stationID)
+}
+
+// GetByOrderID obtiene todos los tickets de una orden en todas las estaciones
+func (r *KDSTicketRepository) GetByOrderID(orderID uuid.UUID) ([]domain.KDSTicket, error) {
+	query := `SELECT` + kdsTicketColumns + kdsTicketJoins + ` WHERE k.order_id = $1 ORDER BY k.created_at`
+	return r.queryTickets(query, orderID)
+}
+
+// UpdateStatus cambia el estado del ticket y registra cuándo empezó y terminó su preparación
+func (r *KDSTicketRepository) UpdateStatus(id uuid.UUID, status domain.KDSTicketStatus) error {
+	query := `
+		UPDATE kds_tickets
+		SET status = $1,
+		    started_at = CASE WHEN $1 IN ('in_progress', 'ready') THEN COALESCE(started_at, now()) ELSE started_at END,
+		    ready_at = CASE WHEN $1 = 'ready' THEN now() ELSE NULL END
+		WHERE id = $2
+	`
+	result, err := r.db.Exec(query, status, id)
+	if err != nil {
+		return err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return fmt.Errorf("kds ticket not found")
+	}
+	return nil
+}
+
+// VoidOpenByOrderID marca como anulados los tickets de la orden que las estaciones aún no
+// terminaron (new, in_progress) y los devuelve
+func (r *KDSTicketRepository) VoidOpenByOrderID(orderID uuid.UUID) ([]domain.KDSTicket, error) {
+	query := `
+		UPDATE kds_tickets
+		SET status = 'voided'
+		WHERE order_id = $1 AND status IN ('new', 'in_progress')
+		RETURNING id
+	`
+	rows, err := r.db.Query(query, orderID)
+	if err != nil {
+		return nil, err
+	}
+	var ids []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	tickets := make([]domain.KDSTicket, 0, len(ids))
+	for _, id := range ids {
+		ticket, err := r.GetByID(id)
+		if err != nil {
+			return nil, err
+		}
+		if ticket != nil {
+			tickets = append(tickets, *ticket)
+		}
+	}
+	return tickets, nil
+}
@@ -13,10 +13,12 @@ import (
 type MenuRepository interface {
 	CreateMenuItem(item *domain.MenuItem, ingredientIDs, accompanimentIDs []uuid.UUID) (*domain.MenuItem, error)
 	GetMenuItems() ([]domain.MenuItem, error)
+	GetMenuItemByID(itemID uuid.UUID) (*domain.MenuItem, error)
 	GetMenuItemDetails(menuItemID uuid.UUID) ([]domain.Ingredient, []domain.Accompaniment, error)
 	UpdateMenuItem(item *domain.MenuItem, ingredientIDs, accompanimentIDs []uuid.UUID) (*domain.MenuItem, error)
 	DeleteMenuItem(itemID uuid.UUID) error
 	IncrementOrderCount(itemID uuid.UUID) error
+	DecrementOrderCount(itemID uuid.UUID, quantity int) error
 }
 
 type menuRepository struct{ db *sql.DB }
@@ -122,6 +124,22 @@ func (r *menuRepository) GetMenuItems() ([]domain.MenuItem, error) {
 	return finalItems, nil
 }
 
+// GetMenuItemByID obtiene un ítem del menú (disponible o no) con su precio y su impuesto vigentes
+func (r *menuRepository) GetMenuItemByID(itemID uuid.UUID) (*domain.MenuItem, error) {
+	var item domain.MenuItem
+	query := `SELECT m.id, m.name, m.description, m.price, m.category_id, c.name as category_name,
+	          m.is_available, m.order_count, c.tax_rate
+	          FROM menu_items m
+	          JOIN categories c ON m.category_id = c.id
+	          WHERE m.id = $1`
+	err := r.db.QueryRow(query, itemID).Scan(&item.ID, &item.Name, &item.Description, &item.Price, &item.CategoryID,
+		&item.CategoryName, &item.IsAvailable, &item.OrderCount, &item.TaxRate)
+	if err != nil {
+		return nil, err
+	}
+	return &item, nil
+}
+
 // GetMenuItemDetails obtiene todos los ingredientes y acompañantes de un menu item específico
 func (r *menuRepository) GetMenuItemDetails(menuItemID uuid.UUID) ([]domain.Ingredient, []domain.Accompaniment, error) {
 	// Obtener ingredientes
@@ -226,3 +244,10 @@ func (r *menuRepository) IncrementOrderCount(itemID uuid.UUID) error {
 	_, err := r.db.Exec(query, itemID)
 	return err
 }
+
+// DecrementOrderCount descuenta pedidos del contador de un item del menú (sin bajar de cero)
+func (r *menuRepository) DecrementOrderCount(itemID uuid.UUID, quantity int) error {
+	query := "UPDATE menu_items SET order_count = GREATEST(order_count - $2, 0) WHERE id = $1"
+	_, err := r.db.Exec(query, itemID, quantity)
+	return err
+}
@@ -0,0 +1,236 @@
+// =================================================================
+// Notarization Batch Repository
+// Lotes de facturas anclados con una sola transacción (raíz Merkle).
+// Los cambios de estado del lote se copian a las notarizaciones de
+// sus órdenes en la misma transacción de BD
+// =================================================================
+package repository
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/Hoxanfox/TurnyChain/Backend/api/internal/domain"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+type NotarizationBatchRepository interface {
+	PendingStats() (count int, oldest *time.Time, err error)
+	ClaimUnbatched(limit int, lease time.Duration) ([]domain.OrderNotarization, error)
+	Create(merkleRoot string, members []domain.NotarizationBatchMember) (*domain.NotarizationBatch, error)
+	ClaimDue(limit int, lease time.Duration) ([]domain.NotarizationBatch, error)
+	MarkSent(batchID uuid.UUID, txHash, backend string, nonce uint64, attempts int, checkAt time.Time) error
+	MarkPending(batchID uuid.UUID, checkAt time.Time) error
+	MarkConfirmed(batchID uuid.UUID, blockNumber int64) error
+	MarkRetry(batchID uuid.UUID, attempts int, lastError string, nextAttemptAt time.Time) error
+	MarkFailed(batchID uuid.UUID, attempts int, lastError string) error
+	GetByID(batchID uuid.UUID) (*domain.NotarizationBatch, error)
+}
+
+type notarizationBatchRepository struct{ db *sql.DB }
+
+func NewNotarizationBatchRepository(db *sql.DB) NotarizationBatchRepository {
+	return &notarizationBatchRepository{db: db}
+}
+
+const batchColumns = `id, merkle_root, size, status, tx_hash, nonce, backend, block_number, attempts, last_error, next_attempt_at, sent_at, confirmed_at, created_at, updated_at`
+
+func scanBatch(row interface{ Scan(...interface{}) error }) (*domain.NotarizationBatch, error) {
+	var b domain.NotarizationBatch
+	err := row.Scan(&b.ID, &b.MerkleRoot, &b.Size, &b.Status, &b.TxHash, &b.Nonce, &b.Backend, &b.BlockNumber, &b.Attempts, &b.LastError, &b.NextAttemptAt, &b.SentAt, &b.ConfirmedAt, &b.CreatedAt, &b.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &b, nil
+}
+
+// PendingStats cuenta las facturas pendientes que aún no están en un lote y devuelve la
+// fecha de la más antigua (nil si no hay)
+func (r *notarizationBatchRepository) PendingStats() (int, *time.Time, error) {
+	var count int
+	var oldest *time.Time
+	query := `SELECT COUNT(*), MIN(created_at) FROM order_notarizations WHERE status = 'pending' AND batch_id IS NULL`
+	if err := r.db.QueryRow(query).Scan(&count, &oldest); err != nil {
+		return 0, nil, err
+	}
+	return count, oldest, nil
+}
+
+// ClaimUnbatched reserva hasta limit facturas pendientes sin lote, de la más antigua a la
+// más reciente, con el mismo lease que las notarizaciones individuales
+func (r *notarizationBatchRepository) ClaimUnbatched(limit int, lease time.Duration) ([]domain.OrderNotarization, error) {
+	query := `
+		WITH due AS (
+			SELECT id FROM order_notarizations
+			WHERE status = 'pending' AND batch_id IS NULL AND next_attempt_at <= now()
+			ORDER BY created_at
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		UPDATE order_notarizations SET next_attempt_at = now() + make_interval(secs => $2)
+		WHERE id IN (SELECT id FROM due)
+		RETURNING ` + notarizationColumns
+	rows, err := r.db.Query(query, limit, lease.Seconds())
+	if err != nil {
+		return nil, err
+	}
+	return scanNotarizations(rows)
+}
+
+// Create guarda el lote y asigna a cada orden su lote, su prueba y la factura cifrada
+func (r *notarizationBatchRepository) Create(merkleRoot string, members []domain.NotarizationBatchMember) (*domain.NotarizationBatch, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	query := `INSERT INTO notarization_batches (merkle_root, size) VALUES ($1, $2) RETURNING ` + batchColumns
+	batch, err := scanBatch(tx.QueryRow(query, merkleRoot, len(members)))
+	if err != nil {
+		return nil, err
+	}
+
+	memberQuery := `UPDATE order_notarizations
+	                SET batch_id = $1, merkle_root = $2, merkle_proof = $3, invoice_hash = $4, encrypted_invoice = $5,
+	                    last_error = NULL, next_attempt_at = NOW()
+	                WHERE order_id = $6 AND status = 'pending' AND batch_id IS NULL`
+	for _, member := range members {
+		result, err := tx.Exec(memberQuery, batch.ID, merkleRoot, pq.Array(member.MerkleProof), member.InvoiceHash, member.EncryptedInvoice, member.OrderID)
+		if err != nil {
+			return nil, err
+		}
+		if affected, err := result.RowsAffected(); err != nil || affected == 0 {
+			// Otra instancia la tomó o cambió de estado: el lote ya no corresponde
+			if err == nil {
+				err = sql.ErrNoRows
+			}
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return batch, nil
+}
+
+// ClaimDue reserva hasta limit lotes vencidos: pendientes de envío o enviados cuyo recibo
+// toca consultar
+func (r *notarizationBatchRepository) ClaimDue(limit int, lease time.Duration) ([]domain.NotarizationBatch, error) {
+	query := `
+		WITH due AS (
+			SELECT id FROM notarization_batches
+			WHERE status IN ('pending', 'sent') AND next_attempt_at <= now()
+			ORDER BY next_attempt_at
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		UPDATE notarization_batches SET next_attempt_at = now() + make_interval(secs => $2)
+		WHERE id IN (SELECT id FROM due)
+		RETURNING ` + batchColumns
+	rows, err := r.db.Query(query, limit, lease.Seconds())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	batches := make([]domain.NotarizationBatch, 0)
+	for rows.Next() {
+		batch, err := scanBatch(rows)
+		if err != nil {
+			return nil, err
+		}
+		batches = append(batches, *batch)
+	}
+	return batches, rows.Err()
+}
+
+// MarkSent guarda la transacción que ancla el lote
+func (r *notarizationBatchRepository) MarkSent(batchID uuid.UUID, txHash, backend string, nonce uint64, attempts int, checkAt time.Time) error {
+	return r.update(
+		`UPDATE notarization_batches
+		 SET status = 'sent', tx_hash = $1, nonce = $2, attempts = $3, block_number = NULL,
+		     last_error = NULL, next_attempt_at = $4, sent_at = NOW(), confirmed_at = NULL, backend = $5
+		 WHERE id = $6`,
+		[]interface{}{txHash, int64(nonce), attempts, checkAt, backend, batchID},
+		`UPDATE order_notarizations
+		 SET status = 'sent', tx_hash = $1, nonce = $2, attempts = $3, block_number = NULL,
+		     last_error = NULL, sent_at = NOW(), confirmed_at = NULL, backend = $4
+		 WHERE batch_id = $5`,
+		[]interface{}{txHash, int64(nonce), attempts, backend, batchID})
+}
+
+// MarkPending programa la siguiente consulta del recibo del lote
+func (r *notarizationBatchRepository) MarkPending(batchID uuid.UUID, checkAt time.Time) error {
+	query := `UPDATE notarization_batches SET next_attempt_at = $1 WHERE id = $2 AND status = 'sent'`
+	return execOne(r.db, query, checkAt, batchID)
+}
+
+// MarkConfirmed guarda el bloque en el que se minó el lote
+func (r *notarizationBatchRepository) MarkConfirmed(batchID uuid.UUID, blockNumber int64) error {
+	return r.update(
+		`UPDATE notarization_batches
+		 SET status = 'confirmed', block_number = $1, confirmed_at = NOW(), last_error = NULL
+		 WHERE id = $2`,
+		[]interface{}{blockNumber, batchID},
+		`UPDATE order_notarizations
+		 SET status = 'confirmed', block_number = $1, confirmed_at = NOW(), last_error = NULL
+		 WHERE batch_id = $2`,
+		[]interface{}{blockNumber, batchID})
+}
+
+// MarkRetry registra un intento fallido del lote y programa el siguiente envío
+func (r *notarizationBatchRepository) MarkRetry(batchID uuid.UUID, attempts int, lastError string, nextAttemptAt time.Time) error {
+	return r.update(
+		`UPDATE notarization_batches
+		 SET status = 'pending', attempts = $1, last_error = $2, next_attempt_at = $3
+		 WHERE id = $4`,
+		[]interface{}{attempts, lastError, nextAttemptAt, batchID},
+		`UPDATE order_notarizations
+		 SET status = 'pending', attempts = $1, last_error = $2
+		 WHERE batch_id = $3`,
+		[]interface{}{attempts, lastError, batchID})
+}
+
+// MarkFailed marca el lote y sus notarizaciones como fallidos definitivamente
+func (r *notarizationBatchRepository) MarkFailed(batchID uuid.UUID, attempts int, lastError string) error {
+	return r.update(
+		`UPDATE notarization_batches SET status = 'failed', attempts = $1, last_error = $2 WHERE id = $3`,
+		[]interface{}{attempts, lastError, batchID},
+		`UPDATE order_notarizations SET status = 'failed', attempts = $1, last_error = $2 WHERE batch_id = $3`,
+		[]interface{}{attempts, lastError, batchID})
+}
+
+// GetByID devuelve el lote (sql.ErrNoRows si no existe)
+func (r *notarizationBatchRepository) GetByID(batchID uuid.UUID) (*domain.NotarizationBatch, error) {
+	query := `SELECT ` + batchColumns + ` FROM notarization_batches WHERE id = $1`
+	return scanBatch(r.db.QueryRow(query, batchID))
+}
+
+// update actualiza el lote y copia el cambio a sus notarizaciones en una transacción.
+// Devuelve sql.ErrNoRows si el lote no existe.
+func (r *notarizationBatchRepository) update(batchQuery string, batchArgs []interface{}, memberQuery string, memberArgs []interface{}) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(batchQuery, batchArgs...)
+	if err != nil {
+		return err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return sql.ErrNoRows
+	}
+	if _, err := tx.Exec(memberQuery, memberArgs...); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
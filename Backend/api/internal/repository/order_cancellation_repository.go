@@ -0,0 +1,78 @@
+// =================================================================
+// Order Cancellation Repository
+// Motivo, tipo y autor de las órdenes canceladas o anuladas
+// =================================================================
+package repository
+
+import (
+	"database/sql"
+
+	"github.com/Hoxanfox/TurnyChain/Backend/api/internal/domain"
+	"github.com/google/uuid"
+)
+
+type OrderCancellationRepository interface {
+	Create(cancellation *domain.OrderCancellation, events []domain.OrderEvent) error
+	GetByOrderID(orderID uuid.UUID) (*domain.OrderCancellation, error)
+}
+
+type orderCancellationRepository struct{ db *sql.DB }
+
+func NewOrderCancellationRepository(db *sql.DB) OrderCancellationRepository {
+	return &orderCancellationRepository{db: db}
+}
+
+// Create pasa la orden a 'cancelado' y registra el motivo y los eventos del historial en la
+// misma transacción. Si la orden ya no está en previous_status o tiene pagos registrados
+// devuelve domain.ErrOrderChanged.
+func (r *orderCancellationRepository) Create(c *domain.OrderCancellation, events []domain.OrderEvent) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	updateQuery := `UPDATE orders SET status = 'cancelado'
+	                WHERE id = $1 AND status = $2
+	                  AND NOT EXISTS (SELECT 1 FROM order_payments WHERE order_id = $1)`
+	result, err := tx.Exec(updateQuery, c.OrderID, c.PreviousStatus)
+	if err != nil {
+		return err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return domain.ErrOrderChanged
+	}
+
+	insertQuery := `INSERT INTO order_cancellations (order_id, kind, reason_code, notes, previous_status, total, cancelled_by)
+	                VALUES ($1, $2, $3, $4, $5, $6, $7)
+	                RETURNING created_at`
+	if err := tx.QueryRow(insertQuery, c.OrderID, c.Kind, c.ReasonCode, c.Notes, c.PreviousStatus, c.Total, c.CancelledBy).Scan(&c.CreatedAt); err != nil {
+		return err
+	}
+	if err := insertOrderEvents(tx, events); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// GetByOrderID obtiene el registro de cancelación de una orden (sql.ErrNoRows si no fue cancelada con motivo)
+func (r *orderCancellationRepository) GetByOrderID(orderID uuid.UUID) (*domain.OrderCancellation, error) {
+	query := `SELECT c.order_id, c.kind, c.reason_code, c.notes, c.previous_status, c.total, c.cancelled_by,
+	                 COALESCE(cu.username, ''), c.created_at, o.order_number, o.table_number, o.waiter_id, COALESCE(w.username, '')
+	          FROM order_cancellations c
+	          INNER JOIN orders o ON o.id = c.order_id
+	          LEFT JOIN users cu ON cu.id = c.cancelled_by
+	          LEFT JOIN users w ON w.id = o.waiter_id
+	          WHERE c.order_id = $1`
+	var c domain.OrderCancellation
+	err := r.db.QueryRow(query, orderID).Scan(&c.OrderID, &c.Kind, &c.ReasonCode, &c.Notes, &c.PreviousStatus, &c.Total,
+		&c.CancelledBy, &c.CancelledByName, &c.CreatedAt, &c.OrderNumber, &c.TableNumber, &c.WaiterID, &c.WaiterName)
+	if err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
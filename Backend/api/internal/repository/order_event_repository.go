@@ -0,0 +1,84 @@
+// =================================================================
+// Order Event Repository
+// =================================================================
+package repository
+
+import (
+	"database/sql"
+
+	"github.com/Hoxanfox/TurnyChain/Backend/api/internal/domain"
+	"github.com/google/uuid"
+)
+
+// OrderEventRepository lee el historial de las órdenes. Los eventos se escriben en la
+// transacción del cambio que registran (ver insertOrderEvents).
+type OrderEventRepository interface {
+	GetByOrderID(orderID uuid.UUID) ([]domain.OrderEvent, error)
+}
+
+type orderEventRepository struct{ db *sql.DB }
+
+func NewOrderEventRepository(db *sql.DB) OrderEventRepository {
+	return &orderEventRepository{db: db}
+}
+
+// insertOrderEvents guarda los eventos del historial dentro de la transacción del cambio que
+// registran: el cambio y su evento se guardan juntos o no se guarda ninguno
+func insertOrderEvents(tx *sql.Tx, events []domain.OrderEvent) error {
+	query := `INSERT INTO order_events (order_id, actor_id, event_type, old_value, new_value)
+	          VALUES ($1, $2, $3, $4, $5)
+	          RETURNING id, created_at`
+	for i := range events {
+		event := &events[i]
+		err := tx.QueryRow(query, event.OrderID, event.ActorID, event.EventType, nullableJSON(event.OldValue), nullableJSON(event.NewValue)).
+			Scan(&event.ID, &event.CreatedAt)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetByOrderID obtiene el historial de una orden en orden cronológico
+func (r *orderEventRepository) GetByOrderID(orderID uuid.UUID) ([]domain.OrderEvent, error) {
+	query := `
+		SELECT e.id, e.order_id, e.actor_id, u.username, u.role, e.event_type, e.old_value, e.new_value, e.created_at
+		FROM order_events e
+		LEFT JOIN users u ON u.id = e.actor_id
+		WHERE e.order_id = $1
+		ORDER BY e.created_at, e.id`
+
+	rows, err := r.db.Query(query, orderID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	events := make([]domain.OrderEvent, 0)
+	for rows.Next() {
+		var event domain.OrderEvent
+		var actorName, actorRole sql.NullString
+		var oldValue, newValue []byte
+		if err := rows.Scan(&event.ID, &event.OrderID, &event.ActorID, &actorName, &actorRole, &event.EventType, &oldValue, &newValue, &event.CreatedAt); err != nil {
+			return nil, err
+		}
+		if actorName.Valid {
+			event.ActorName = actorName.String
+		}
+		if actorRole.Valid {
+			event.ActorRole = actorRole.String
+		}
+		event.OldValue = oldValue
+		event.NewValue = newValue
+		events = append(events, event)
+	}
+	return events, rows.Err()
+}
+
+// nullableJSON convierte un JSON vacío en NULL para la columna jsonb
+func nullableJSON(value []byte) interface{} {
+	if len(value) == 0 {
+		return nil
+	}
+	return string(value)
+}
@@ -0,0 +1,215 @@
+// =================================================================
+// Order Notarization Repository
+// Outbox de notarizaciones: cada fila es una factura por enviar,
+// enviada o confirmada en blockchain
+// =================================================================
+package repository
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/Hoxanfox/TurnyChain/Backend/api/internal/domain"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+type OrderNotarizationRepository interface {
+	Enqueue(orderID uuid.UUID, invoiceHash string) (*domain.OrderNotarization, error)
+	ClaimDue(statuses []domain.NotarizationStatus, limit int, lease time.Duration) ([]domain.OrderNotarization, error)
+	MarkSent(orderID uuid.UUID, invoiceHash, txHash, backend string, nonce uint64, attempts int, checkAt time.Time) error
+	MarkPending(orderID uuid.UUID, checkAt time.Time) error
+	MarkConfirmed(orderID uuid.UUID, blockNumber int64) error
+	MarkRetry(orderID uuid.UUID, attempts int, lastError string, nextAttemptAt time.Time) error
+	MarkFailed(orderID uuid.UUID, attempts int, lastError string) error
+	GetByOrderID(orderID uuid.UUID) (*domain.OrderNotarization, error)
+	GetPaidOrdersWithoutNotarization() ([]uuid.UUID, error)
+	CountConfirmed(from, to time.Time) (int, error)
+	ListConfirmed(from, to time.Time, limit, offset int) ([]domain.OrderNotarization, error)
+}
+
+type orderNotarizationRepository struct{ db *sql.DB }
+
+func NewOrderNotarizationRepository(db *sql.DB) OrderNotarizationRepository {
+	return &orderNotarizationRepository{db: db}
+}
+
+const notarizationColumns = `id, order_id, status, invoice_hash, tx_hash, nonce, backend, block_number, attempts, last_error, next_attempt_at, sent_at, confirmed_at, batch_id, merkle_root, merkle_proof, encrypted_invoice, created_at, updated_at`
+
+func scanNotarization(row interface{ Scan(...interface{}) error }) (*domain.OrderNotarization, error) {
+	var n domain.OrderNotarization
+	err := row.Scan(&n.ID, &n.OrderID, &n.Status, &n.InvoiceHash, &n.TxHash, &n.Nonce, &n.Backend, &n.BlockNumber, &n.Attempts, &n.LastError, &n.NextAttemptAt, &n.SentAt, &n.ConfirmedAt, &n.BatchID, &n.MerkleRoot, pq.Array(&n.MerkleProof), &n.EncryptedInvoice, &n.CreatedAt, &n.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &n, nil
+}
+
+// Enqueue agrega la factura de la orden al outbox. Si la orden ya tiene notarización la
+// deja como está, salvo que haya fallado: en ese caso la vuelve a poner en cola con los
+// intentos en cero (reintento manual) y fuera del lote en el que falló.
+func (r *orderNotarizationRepository) Enqueue(orderID uuid.UUID, invoiceHash string) (*domain.OrderNotarization, error) {
+	query := `INSERT INTO order_notarizations (order_id, status, invoice_hash)
+	          VALUES ($1, 'pending', $2)
+	          ON CONFLICT (order_id) DO UPDATE
+	          SET status = 'pending', invoice_hash = EXCLUDED.invoice_hash, attempts = 0,
+	              last_error = NULL, next_attempt_at = NOW(),
+	              batch_id = NULL, merkle_root = NULL, merkle_proof = NULL, encrypted_invoice = NULL
+	          WHERE order_notarizations.status = 'failed'
+	          RETURNING ` + notarizationColumns
+	notarization, err := scanNotarization(r.db.QueryRow(query, orderID, invoiceHash))
+	if err == sql.ErrNoRows {
+		// Ya estaba en cola, enviada o confirmada
+		return r.GetByOrderID(orderID)
+	}
+	return notarization, err
+}
+
+// ClaimDue reserva hasta limit notarizaciones individuales vencidas con alguno de los
+// estados dados: pendientes de envío o enviadas cuyo recibo toca consultar. Las que
+// pertenecen a un lote las procesa el lote. La reserva mueve next_attempt_at hacia
+// adelante (lease) para que otra instancia no las procese al mismo tiempo.
+func (r *orderNotarizationRepository) ClaimDue(statuses []domain.NotarizationStatus, limit int, lease time.Duration) ([]domain.OrderNotarization, error) {
+	values := make([]string, len(statuses))
+	for i, status := range statuses {
+		values[i] = string(status)
+	}
+	query := `
+		WITH due AS (
+			SELECT id FROM order_notarizations
+			WHERE status = ANY($1) AND batch_id IS NULL AND next_attempt_at <= now()
+			ORDER BY next_attempt_at
+			LIMIT $2
+			FOR UPDATE SKIP LOCKED
+		)
+		UPDATE order_notarizations SET next_attempt_at = now() + make_interval(secs => $3)
+		WHERE id IN (SELECT id FROM due)
+		RETURNING ` + notarizationColumns
+	rows, err := r.db.Query(query, pq.Array(values), limit, lease.Seconds())
+	if err != nil {
+		return nil, err
+	}
+	return scanNotarizations(rows)
+}
+
+// scanNotarizations lee todas las filas de la consulta y la cierra
+func scanNotarizations(rows *sql.Rows) ([]domain.OrderNotarization, error) {
+	defer rows.Close()
+
+	notarizations := make([]domain.OrderNotarization, 0)
+	for rows.Next() {
+		notarization, err := scanNotarization(rows)
+		if err != nil {
+			return nil, err
+		}
+		notarizations = append(notarizations, *notarization)
+	}
+	return notarizations, rows.Err()
+}
+
+// MarkSent guarda la transacción enviada y cuándo consultar su recibo. La confirmación
+// anterior (si la hubo) deja de valer.
+func (r *orderNotarizationRepository) MarkSent(orderID uuid.UUID, invoiceHash, txHash, backend string, nonce uint64, attempts int, checkAt time.Time) error {
+	query := `UPDATE order_notarizations
+	          SET status = 'sent', invoice_hash = $1, tx_hash = $2, nonce = $3, attempts = $4, block_number = NULL,
+	              last_error = NULL, next_attempt_at = $5, sent_at = NOW(), confirmed_at = NULL, backend = $6
+	          WHERE order_id = $7`
+	return execOne(r.db, query, invoiceHash, txHash, int64(nonce), attempts, checkAt, backend, orderID)
+}
+
+// MarkPending programa la siguiente consulta del recibo de una transacción aún sin minar
+func (r *orderNotarizationRepository) MarkPending(orderID uuid.UUID, checkAt time.Time) error {
+	query := `UPDATE order_notarizations SET next_attempt_at = $1 WHERE order_id = $2 AND status = 'sent'`
+	return execOne(r.db, query, checkAt, orderID)
+}
+
+// MarkConfirmed guarda el bloque en el que se minó la transacción
+func (r *orderNotarizationRepository) MarkConfirmed(orderID uuid.UUID, blockNumber int64) error {
+	query := `UPDATE order_notarizations
+	          SET status = 'confirmed', block_number = $1, confirmed_at = NOW(), last_error = NULL
+	          WHERE order_id = $2`
+	return execOne(r.db, query, blockNumber, orderID)
+}
+
+// MarkRetry registra un intento fallido y programa el siguiente envío
+func (r *orderNotarizationRepository) MarkRetry(orderID uuid.UUID, attempts int, lastError string, nextAttemptAt time.Time) error {
+	query := `UPDATE order_notarizations
+	          SET status = 'pending', attempts = $1, last_error = $2, next_attempt_at = $3
+	          WHERE order_id = $4`
+	return execOne(r.db, query, attempts, lastError, nextAttemptAt, orderID)
+}
+
+// MarkFailed marca la notarización como fallida definitivamente
+func (r *orderNotarizationRepository) MarkFailed(orderID uuid.UUID, attempts int, lastError string) error {
+	query := `UPDATE order_notarizations SET status = 'failed', attempts = $1, last_error = $2 WHERE order_id = $3`
+	return execOne(r.db, query, attempts, lastError, orderID)
+}
+
+// GetByOrderID devuelve la notarización de la orden (sql.ErrNoRows si nunca se notarizó)
+func (r *orderNotarizationRepository) GetByOrderID(orderID uuid.UUID) (*domain.OrderNotarization, error) {
+	query := `SELECT ` + notarizationColumns + ` FROM order_notarizations WHERE order_id = $1`
+	return scanNotarization(r.db.QueryRow(query, orderID))
+}
+
+// GetPaidOrdersWithoutNotarization devuelve las órdenes pagadas que nunca entraron al
+// outbox (p. ej. el servidor se reinició antes de registrarlas), de la más antigua a la más reciente
+func (r *orderNotarizationRepository) GetPaidOrdersWithoutNotarization() ([]uuid.UUID, error) {
+	query := `SELECT o.id FROM orders o
+	          WHERE o.status = 'pagado'
+	            AND NOT EXISTS (SELECT 1 FROM order_notarizations n WHERE n.order_id = o.id)
+	          ORDER BY o.updated_at`
+	rows, err := r.db.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ids := make([]uuid.UUID, 0)
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// CountConfirmed cuenta las notarizaciones confirmadas en [from, to)
+func (r *orderNotarizationRepository) CountConfirmed(from, to time.Time) (int, error) {
+	var count int
+	query := `SELECT COUNT(*) FROM order_notarizations
+	          WHERE status = 'confirmed' AND confirmed_at >= $1 AND confirmed_at < $2`
+	err := r.db.QueryRow(query, from, to).Scan(&count)
+	return count, err
+}
+
+// ListConfirmed devuelve las notarizaciones confirmadas en [from, to), de la más antigua a
+// la más reciente
+func (r *orderNotarizationRepository) ListConfirmed(from, to time.Time, limit, offset int) ([]domain.OrderNotarization, error) {
+	query := `SELECT ` + notarizationColumns + ` FROM order_notarizations
+	          WHERE status = 'confirmed' AND confirmed_at >= $1 AND confirmed_at < $2
+	          ORDER BY confirmed_at, order_id
+	          LIMIT $3 OFFSET $4`
+	rows, err := r.db.Query(query, from, to, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	return scanNotarizations(rows)
+}
+
+// execOne ejecuta un UPDATE y devuelve sql.ErrNoRows si no afectó ninguna fila
+func execOne(db *sql.DB, query string, args ...interface{}) error {
+	result, err := db.Exec(query, args...)
+	if err != nil {
+		return err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
@@ -0,0 +1,116 @@
+// =================================================================
+// Order Payment Repository
+// =================================================================
+package repository
+
+import (
+	"database/sql"
+
+	"github.com/Hoxanfox/TurnyChain/Backend/api/internal/domain"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+type OrderPaymentRepository interface {
+	Create(payment *domain.OrderPayment, events []domain.OrderEvent) error
+	GetByOrderID(orderID uuid.UUID) ([]domain.OrderPayment, error)
+}
+
+type orderPaymentRepository struct{ db *sql.DB }
+
+func NewOrderPaymentRepository(db *sql.DB) OrderPaymentRepository {
+	return &orderPaymentRepository{db: db}
+}
+
+// Create registra un pago. Bloquea la orden mientras valida que el pago no supere el
+// saldo pendiente ni repita items ya pagados, para que dos pagos simultáneos no se pisen.
+// La propina se acumula en orders.tip y los eventos del historial se guardan en la misma
+// transacción. El ID del pago viene asignado.
+func (r *orderPaymentRepository) Create(payment *domain.OrderPayment, events []domain.OrderEvent) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var total domain.Money
+	if err := tx.QueryRow("SELECT total FROM orders WHERE id = $1 FOR UPDATE", payment.OrderID).Scan(&total); err != nil {
+		return err
+	}
+
+	var paid domain.Money
+	if err := tx.QueryRow("SELECT COALESCE(SUM(amount), 0) FROM order_payments WHERE order_id = $1", payment.OrderID).Scan(&paid); err != nil {
+		return err
+	}
+	if paid.Add(payment.Amount) > total {
+		return domain.ErrPaymentExceedsBalance
+	}
+
+	if payment.ItemIDs == nil {
+		payment.ItemIDs = []uuid.UUID{}
+	}
+	if len(payment.ItemIDs) > 0 {
+		var overlap bool
+		query := "SELECT EXISTS (SELECT 1 FROM order_payments WHERE order_id = $1 AND item_ids && $2::uuid[])"
+		if err := tx.QueryRow(query, payment.OrderID, pq.Array(payment.ItemIDs)).Scan(&overlap); err != nil {
+			return err
+		}
+		if overlap {
+			return domain.ErrItemsAlreadyPaid
+		}
+	}
+
+	query := `INSERT INTO order_payments (id, order_id, amount, tip, tip_waiter_id, method, proof_path, item_ids, received_by)
+	          VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	          RETURNING created_at`
+	err = tx.QueryRow(query, payment.ID, payment.OrderID, payment.Amount, payment.Tip, payment.TipWaiterID, payment.Method, payment.ProofPath, pq.Array(payment.ItemIDs), payment.ReceivedBy).
+		Scan(&payment.CreatedAt)
+	if err != nil {
+		return err
+	}
+
+	if payment.Tip > 0 {
+		if _, err := tx.Exec("UPDATE orders SET tip = tip + $1 WHERE id = $2", payment.Tip, payment.OrderID); err != nil {
+			return err
+		}
+	}
+
+	if err := insertOrderEvents(tx, events); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// GetByOrderID obtiene los pagos de una orden en orden cronológico
+func (r *orderPaymentRepository) GetByOrderID(orderID uuid.UUID) ([]domain.OrderPayment, error) {
+	query := `
+		SELECT p.id, p.order_id, p.amount, p.tip, p.tip_waiter_id, p.method, p.proof_path, p.item_ids, p.received_by, u.username, p.created_at
+		FROM order_payments p
+		LEFT JOIN users u ON u.id = p.received_by
+		WHERE p.order_id = $1
+		ORDER BY p.created_at, p.id`
+
+	rows, err := r.db.Query(query, orderID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	payments := make([]domain.OrderPayment, 0)
+	for rows.Next() {
+		var payment domain.OrderPayment
+		var proofPath, receivedByName sql.NullString
+		if err := rows.Scan(&payment.ID, &payment.OrderID, &payment.Amount, &payment.Tip, &payment.TipWaiterID, &payment.Method, &proofPath, pq.Array(&payment.ItemIDs), &payment.ReceivedBy, &receivedByName, &payment.CreatedAt); err != nil {
+			return nil, err
+		}
+		if proofPath.Valid {
+			path := proofPath.String
+			payment.ProofPath = &path
+		}
+		if receivedByName.Valid {
+			payment.ReceivedByName = receivedByName.String
+		}
+		payments = append(payments, payment)
+	}
+	return payments, rows.Err()
+}
@@ -13,54 +13,74 @@ import (
 )
 
 type OrderRepository interface {
-	CreateOrder(order *domain.Order) (*domain.Order, error)
+	CreateOrder(order *domain.Order, discounts []domain.OrderDiscount, events []domain.OrderEvent) (*domain.Order, error)
 	GetOrders(filters map[string]interface{}) ([]domain.Order, error)
 	GetOrderByID(orderID uuid.UUID) (*domain.Order, error)
-	UpdateOrderStatus(orderID, userID uuid.UUID, status string) (*domain.Order, error)
-	ManageOrder(orderID uuid.UUID, updates map[string]interface{}) (*domain.Order, error)
-	UpdateOrderItems(orderID uuid.UUID, items []domain.OrderItem, newTotal float64) error
-	AddPaymentProof(orderID uuid.UUID, method string, proofPath string) (*domain.Order, error)
+	UpdateOrderStatus(orderID, userID uuid.UUID, fromStatus, status string, events []domain.OrderEvent) (*domain.Order, error)
+	ManageOrder(orderID uuid.UUID, fromStatus string, updates map[string]interface{}, events []domain.OrderEvent) (*domain.Order, error)
+	UpdateOrderItems(orderID uuid.UUID, items []domain.OrderItem, amounts domain.OrderAmounts, discounts domain.OrderDiscountChanges, events []domain.OrderEvent) error
+	VoidOrderItems(orderID uuid.UUID, itemIDs []uuid.UUID, amounts domain.OrderAmounts, discounts domain.OrderDiscountChanges, events []domain.OrderEvent) error
+	UpdateOrderItemsStatus(orderID uuid.UUID, itemIDs []uuid.UUID, status string, events []domain.OrderEvent) error
+	AddPaymentProof(orderID uuid.UUID, fromStatus, method, proofPath string, events []domain.OrderEvent) (*domain.Order, error)
+	CountOpenOrdersByTable(tableNumber int) (int, error)
 }
 
 type orderRepository struct{ db *sql.DB }
 
+// waiterNameQuery devuelve el nombre del mesero guardado con la orden (el del usuario si la
+// orden es anterior a que se guardara)
+const waiterNameQuery = `SELECT COALESCE(o.waiter_name, u.username) FROM orders o JOIN users u ON u.id = o.waiter_id WHERE o.id = $1`
+
 func NewOrderRepository(db *sql.DB) OrderRepository {
 	return &orderRepository{db: db}
 }
 
-func (r *orderRepository) CreateOrder(order *domain.Order) (*domain.Order, error) {
+// CreateOrder guarda la orden, sus items, sus promociones automáticas y los eventos del
+// historial en una sola transacción. Los IDs de la orden y de sus items vienen asignados.
+func (r *orderRepository) CreateOrder(order *domain.Order, discounts []domain.OrderDiscount, events []domain.OrderEvent) (*domain.Order, error) {
 	tx, err := r.db.Begin()
 	if err != nil {
 		return nil, err
 	}
 
-	order.ID = uuid.New()
-	orderQuery := `INSERT INTO orders (id, waiter_id, table_id, table_number, status, total, order_type, delivery_address, delivery_phone, delivery_notes) 
-                   VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10) 
-                   RETURNING id, created_at`
-	err = tx.QueryRow(orderQuery, order.ID, order.WaiterID, order.TableID, order.TableNumber, order.Status, order.Total, order.OrderType, order.DeliveryAddress, order.DeliveryPhone, order.DeliveryNotes).Scan(&order.ID, &order.CreatedAt)
+	// El nombre del mesero se guarda con la orden para que la factura no cambie si se renombra el usuario
+	orderQuery := `INSERT INTO orders (id, waiter_id, waiter_name, table_id, table_number, status, subtotal, discount, service_charge_rate, service_charge, tax, tax_breakdown, total, order_type, delivery_address, delivery_phone, delivery_notes) 
+                   VALUES ($1, $2, (SELECT username FROM users WHERE id = $2), $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16) 
+                   RETURNING id, order_number, created_at`
+	err = tx.QueryRow(orderQuery, order.ID, order.WaiterID, order.TableID, order.TableNumber, order.Status, order.Subtotal, order.Discount, order.ServiceChargeRate, order.ServiceCharge, order.Tax, order.TaxBreakdown, order.Total, order.OrderType, order.DeliveryAddress, order.DeliveryPhone, order.DeliveryNotes).Scan(&order.ID, &order.OrderNumber, &order.CreatedAt)
 	if err != nil {
 		tx.Rollback()
 		return nil, err
 	}
 
-	itemQuery := `INSERT INTO order_items (order_id, menu_item_id, quantity, price_at_order, notes, customizations, is_takeout) 
-                  VALUES ($1, $2, $3, $4, $5, $6, $7)`
-	for _, item := range order.Items {
-		_, err := tx.Exec(itemQuery, order.ID, item.MenuItemID, item.Quantity, item.PriceAtOrder, item.Notes, item.Customizations, item.IsTakeout)
+	itemQuery := `INSERT INTO order_items (id, order_id, menu_item_id, menu_item_name, quantity, price_at_order, tax_rate, notes, customizations, is_takeout) 
+                  VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+                  RETURNING status`
+	for i := range order.Items {
+		item := &order.Items[i]
+		err := tx.QueryRow(itemQuery, item.ID, order.ID, item.MenuItemID, item.MenuItemName, item.Quantity, item.PriceAtOrder, item.TaxRate, item.Notes, item.Customizations, item.IsTakeout).Scan(&item.Status)
 		if err != nil {
 			tx.Rollback()
 			return nil, err
 		}
 	}
 
+	if err := saveOrderDiscounts(tx, order.ID, domain.OrderDiscountChanges{Automatic: discounts}); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err := insertOrderEvents(tx, events); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
 	if err := tx.Commit(); err != nil {
 		return nil, err
 	}
 
 	// Obtener el nombre del mesero
-	waiterQuery := `SELECT username FROM users WHERE id = $1`
-	if err := r.db.QueryRow(waiterQuery, order.WaiterID).Scan(&order.WaiterName); err != nil {
+	if err := r.db.QueryRow(waiterNameQuery, order.ID).Scan(&order.WaiterName); err != nil {
 		// Si no se puede obtener el nombre, no es un error crítico
 		order.WaiterName = ""
 	}
@@ -69,9 +89,10 @@ func (r *orderRepository) CreateOrder(order *domain.Order) (*domain.Order, error
 }
 
 func (r *orderRepository) GetOrders(filters map[string]interface{}) ([]domain.Order, error) {
-	query := `SELECT o.id, o.waiter_id, u.username as waiter_name, o.cashier_id, o.table_number, o.status, o.total, o.order_type, o.delivery_address, o.delivery_phone, o.delivery_notes, o.payment_method, o.payment_proof_path, o.created_at, o.updated_at 
+	query := `SELECT o.id, o.waiter_id, COALESCE(o.waiter_name, u.username) as waiter_name, o.cashier_id, o.table_number, o.status, o.subtotal, o.discount, o.service_charge_rate, o.service_charge, o.tip, o.tax, o.tax_breakdown, o.total, o.order_number, o.invoice_number, n.tx_hash, o.order_type, o.delivery_address, o.delivery_phone, o.delivery_notes, o.payment_method, o.payment_proof_path, o.created_at, o.updated_at 
               FROM orders o
               LEFT JOIN users u ON o.waiter_id = u.id
+              LEFT JOIN order_notarizations n ON n.order_id = o.id
               WHERE 1=1`
 	args := []interface{}{}
 	argId := 1
@@ -105,7 +126,7 @@ func (r *orderRepository) GetOrders(filters map[string]interface{}) ([]domain.Or
 		var deliveryAddress sql.NullString
 		var deliveryPhone sql.NullString
 		var deliveryNotes sql.NullString
-		if err := rows.Scan(&order.ID, &order.WaiterID, &waiterName, &cashierID, &order.TableNumber, &order.Status, &order.Total, &order.OrderType, &deliveryAddress, &deliveryPhone, &deliveryNotes, &paymentMethod, &paymentProof, &order.CreatedAt, &order.UpdatedAt); err != nil {
+		if err := rows.Scan(&order.ID, &order.WaiterID, &waiterName, &cashierID, &order.TableNumber, &order.Status, &order.Subtotal, &order.Discount, &order.ServiceChargeRate, &order.ServiceCharge, &order.Tip, &order.Tax, &order.TaxBreakdown, &order.Total, &order.OrderNumber, &order.InvoiceNumber, &order.BlockchainTxHash, &order.OrderType, &deliveryAddress, &deliveryPhone, &deliveryNotes, &paymentMethod, &paymentProof, &order.CreatedAt, &order.UpdatedAt); err != nil {
 			return nil, err
 		}
 		if cashierID.Valid {
@@ -144,13 +165,14 @@ func (r *orderRepository) GetOrders(filters map[string]interface{}) ([]domain.Or
 	}
 
 	itemsQuery := `
-		SELECT oi.order_id, oi.menu_item_id, mi.name, oi.quantity, oi.price_at_order, oi.notes, oi.customizations, oi.is_takeout,
+		SELECT oi.order_id, oi.id, oi.menu_item_id, COALESCE(oi.menu_item_name, mi.name), oi.quantity, oi.price_at_order, oi.tax_rate, oi.notes, oi.customizations, oi.is_takeout, oi.status,
 		       mi.category_id, c.station_id as category_station_id, s.name as category_station_name
 		FROM order_items oi
 		JOIN menu_items mi ON oi.menu_item_id = mi.id
 		LEFT JOIN categories c ON mi.category_id = c.id
 		LEFT JOIN stations s ON c.station_id = s.id
-		WHERE oi.order_id = ANY($1)`
+		WHERE oi.order_id = ANY($1)
+		ORDER BY oi.order_id, oi.line_number`
 
 	// 3. CORRECCIÓN: Usamos pq.Array para pasar la lista de IDs a la consulta.
 	itemRows, err := r.db.Query(itemsQuery, pq.Array(orderIDs))
@@ -166,7 +188,7 @@ func (r *orderRepository) GetOrders(filters map[string]interface{}) ([]domain.Or
 		var categoryStationID sql.NullString
 		var categoryStationName sql.NullString
 
-		if err := itemRows.Scan(&orderID, &item.MenuItemID, &item.MenuItemName, &item.Quantity, &item.PriceAtOrder, &item.Notes, &item.Customizations, &item.IsTakeout, &categoryID, &categoryStationID, &categoryStationName); err != nil {
+		if err := itemRows.Scan(&orderID, &item.ID, &item.MenuItemID, &item.MenuItemName, &item.Quantity, &item.PriceAtOrder, &item.TaxRate, &item.Notes, &item.Customizations, &item.IsTakeout, &item.Status, &categoryID, &categoryStationID, &categoryStationName); err != nil {
 			return nil, err
 		}
 
@@ -197,16 +219,19 @@ func (r *orderRepository) GetOrders(filters map[string]interface{}) ([]domain.Or
 }
 
 // loadOrderItems es un método auxiliar privado que carga los items de una orden
+// Los items salen en el orden en que se agregaron y con el nombre que tenían al pedirse,
+// así la factura que se notariza no cambia si se renombra un producto
 // IMPORTANTE: Este método asegura que SIEMPRE se carguen los items antes de enviar por WebSocket
 func (r *orderRepository) loadOrderItems(orderID uuid.UUID) ([]domain.OrderItem, error) {
 	itemsQuery := `
-		SELECT oi.menu_item_id, mi.name, oi.quantity, oi.price_at_order, oi.notes, oi.customizations, oi.is_takeout,
+		SELECT oi.id, oi.menu_item_id, COALESCE(oi.menu_item_name, mi.name), oi.quantity, oi.price_at_order, oi.tax_rate, oi.notes, oi.customizations, oi.is_takeout, oi.status,
 		       mi.category_id, c.station_id as category_station_id, s.name as category_station_name
 		FROM order_items oi
 		JOIN menu_items mi ON oi.menu_item_id = mi.id
 		LEFT JOIN categories c ON mi.category_id = c.id
 		LEFT JOIN stations s ON c.station_id = s.id
-		WHERE oi.order_id = $1`
+		WHERE oi.order_id = $1
+		ORDER BY oi.line_number`
 
 	rows, err := r.db.Query(itemsQuery, orderID)
 	if err != nil {
@@ -221,7 +246,7 @@ func (r *orderRepository) loadOrderItems(orderID uuid.UUID) ([]domain.OrderItem,
 		var categoryStationID sql.NullString
 		var categoryStationName sql.NullString
 
-		if err := rows.Scan(&item.MenuItemID, &item.MenuItemName, &item.Quantity, &item.PriceAtOrder, &item.Notes, &item.Customizations, &item.IsTakeout, &categoryID, &categoryStationID, &categoryStationName); err != nil {
+		if err := rows.Scan(&item.ID, &item.MenuItemID, &item.MenuItemName, &item.Quantity, &item.PriceAtOrder, &item.TaxRate, &item.Notes, &item.Customizations, &item.IsTakeout, &item.Status, &categoryID, &categoryStationID, &categoryStationName); err != nil {
 			return nil, err
 		}
 
@@ -246,9 +271,10 @@ func (r *orderRepository) loadOrderItems(orderID uuid.UUID) ([]domain.OrderItem,
 
 func (r *orderRepository) GetOrderByID(orderID uuid.UUID) (*domain.Order, error) {
 	order := &domain.Order{}
-	orderQuery := `SELECT o.id, o.waiter_id, u.username as waiter_name, o.cashier_id, o.table_number, o.status, o.total, o.order_type, o.delivery_address, o.delivery_phone, o.delivery_notes, o.payment_method, o.payment_proof_path, o.created_at, o.updated_at 
+	orderQuery := `SELECT o.id, o.waiter_id, COALESCE(o.waiter_name, u.username) as waiter_name, o.cashier_id, o.table_number, o.status, o.subtotal, o.discount, o.service_charge_rate, o.service_charge, o.tip, o.tax, o.tax_breakdown, o.total, o.order_number, o.invoice_number, n.tx_hash, o.order_type, o.delivery_address, o.delivery_phone, o.delivery_notes, o.payment_method, o.payment_proof_path, o.created_at, o.updated_at 
 	               FROM orders o
 	               LEFT JOIN users u ON o.waiter_id = u.id
+	               LEFT JOIN order_notarizations n ON n.order_id = o.id
 	               WHERE o.id = $1`
 	var waiterName sql.NullString
 	var paymentMethod sql.NullString
@@ -256,7 +282,7 @@ func (r *orderRepository) GetOrderByID(orderID uuid.UUID) (*domain.Order, error)
 	var deliveryAddress sql.NullString
 	var deliveryPhone sql.NullString
 	var deliveryNotes sql.NullString
-	err := r.db.QueryRow(orderQuery, orderID).Scan(&order.ID, &order.WaiterID, &waiterName, &order.CashierID, &order.TableNumber, &order.Status, &order.Total, &order.OrderType, &deliveryAddress, &deliveryPhone, &deliveryNotes, &paymentMethod, &paymentProof, &order.CreatedAt, &order.UpdatedAt)
+	err := r.db.QueryRow(orderQuery, orderID).Scan(&order.ID, &order.WaiterID, &waiterName, &order.CashierID, &order.TableNumber, &order.Status, &order.Subtotal, &order.Discount, &order.ServiceChargeRate, &order.ServiceCharge, &order.Tip, &order.Tax, &order.TaxBreakdown, &order.Total, &order.OrderNumber, &order.InvoiceNumber, &order.BlockchainTxHash, &order.OrderType, &deliveryAddress, &deliveryPhone, &deliveryNotes, &paymentMethod, &paymentProof, &order.CreatedAt, &order.UpdatedAt)
 	if err != nil {
 		return nil, err
 	}
@@ -294,10 +320,19 @@ func (r *orderRepository) GetOrderByID(orderID uuid.UUID) (*domain.Order, error)
 	return order, nil
 }
 
-func (r *orderRepository) UpdateOrderStatus(orderID, userID uuid.UUID, status string) (*domain.Order, error) {
+// UpdateOrderStatus cambia el estado solo si la orden sigue en fromStatus (el estado que se
+// validó); si otra petición la cambió antes devuelve domain.ErrOrderChanged. Los eventos del
+// historial se guardan en la misma transacción.
+func (r *orderRepository) UpdateOrderStatus(orderID, userID uuid.UUID, fromStatus, status string, events []domain.OrderEvent) (*domain.Order, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
 	order := &domain.Order{}
-	query := `UPDATE orders SET status = $1, cashier_id = $2 WHERE id = $3 
-	          RETURNING id, waiter_id, cashier_id, table_number, status, total, order_type, delivery_address, delivery_phone, delivery_notes, payment_method, payment_proof_path, created_at, updated_at`
+	query := `UPDATE orders SET status = $1, cashier_id = $2 WHERE id = $3 AND status = $4 
+	          RETURNING id, waiter_id, cashier_id, table_number, status, subtotal, discount, service_charge_rate, service_charge, tip, tax, tax_breakdown, total, order_number, invoice_number, (SELECT tx_hash FROM order_notarizations WHERE order_id = orders.id), order_type, delivery_address, delivery_phone, delivery_notes, payment_method, payment_proof_path, created_at, updated_at`
 
 	var deliveryAddress sql.NullString
 	var deliveryPhone sql.NullString
@@ -305,13 +340,23 @@ func (r *orderRepository) UpdateOrderStatus(orderID, userID uuid.UUID, status st
 	var paymentMethod sql.NullString
 	var paymentProof sql.NullString
 
-	err := r.db.QueryRow(query, status, userID, orderID).Scan(
-		&order.ID, &order.WaiterID, &order.CashierID, &order.TableNumber, &order.Status, &order.Total, &order.OrderType, &deliveryAddress, &deliveryPhone, &deliveryNotes, &paymentMethod, &paymentProof, &order.CreatedAt, &order.UpdatedAt,
+	err = tx.QueryRow(query, status, userID, orderID, fromStatus).Scan(
+		&order.ID, &order.WaiterID, &order.CashierID, &order.TableNumber, &order.Status, &order.Subtotal, &order.Discount, &order.ServiceChargeRate, &order.ServiceCharge, &order.Tip, &order.Tax, &order.TaxBreakdown, &order.Total, &order.OrderNumber, &order.InvoiceNumber, &order.BlockchainTxHash, &order.OrderType, &deliveryAddress, &deliveryPhone, &deliveryNotes, &paymentMethod, &paymentProof, &order.CreatedAt, &order.UpdatedAt,
 	)
+	if err == sql.ErrNoRows {
+		return nil, r.changedOrMissing(orderID)
+	}
 	if err != nil {
 		return nil, err
 	}
 
+	if err := insertOrderEvents(tx, events); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
 	if deliveryAddress.Valid {
 		addr := deliveryAddress.String
 		order.DeliveryAddress = &addr
@@ -334,8 +379,7 @@ func (r *orderRepository) UpdateOrderStatus(orderID, userID uuid.UUID, status st
 	}
 
 	// Obtener el nombre del mesero
-	waiterQuery := `SELECT username FROM users WHERE id = $1`
-	if err := r.db.QueryRow(waiterQuery, order.WaiterID).Scan(&order.WaiterName); err != nil {
+	if err := r.db.QueryRow(waiterNameQuery, order.ID).Scan(&order.WaiterName); err != nil {
 		order.WaiterName = ""
 	}
 
@@ -352,13 +396,22 @@ func (r *orderRepository) UpdateOrderStatus(orderID, userID uuid.UUID, status st
 	return order, nil
 }
 
-func (r *orderRepository) ManageOrder(orderID uuid.UUID, updates map[string]interface{}) (*domain.Order, error) {
+// ManageOrder aplica los cambios del admin (o del rollup) y guarda los eventos del historial
+// en una sola transacción. El estado solo cambia si la orden sigue en fromStatus; si otra
+// petición la cambió antes devuelve domain.ErrOrderChanged.
+func (r *orderRepository) ManageOrder(orderID uuid.UUID, fromStatus string, updates map[string]interface{}, events []domain.OrderEvent) (*domain.Order, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
 	order := &domain.Order{}
 	status, hasStatus := updates["status"]
 	waiterID, hasWaiter := updates["waiter_id"]
 
 	if hasStatus {
-		query := `UPDATE orders SET status = $1 WHERE id = $2 RETURNING id, waiter_id, cashier_id, table_number, status, total, order_type, delivery_address, delivery_phone, delivery_notes, payment_method, payment_proof_path, created_at, updated_at`
+		query := `UPDATE orders SET status = $1 WHERE id = $2 AND status = $3 RETURNING id, waiter_id, cashier_id, table_number, status, subtotal, discount, service_charge_rate, service_charge, tip, tax, tax_breakdown, total, order_number, invoice_number, (SELECT tx_hash FROM order_notarizations WHERE order_id = orders.id), order_type, delivery_address, delivery_phone, delivery_notes, payment_method, payment_proof_path, created_at, updated_at`
 
 		var deliveryAddress sql.NullString
 		var deliveryPhone sql.NullString
@@ -366,7 +419,10 @@ func (r *orderRepository) ManageOrder(orderID uuid.UUID, updates map[string]inte
 		var paymentMethod sql.NullString
 		var paymentProof sql.NullString
 
-		err := r.db.QueryRow(query, status, orderID).Scan(&order.ID, &order.WaiterID, &order.CashierID, &order.TableNumber, &order.Status, &order.Total, &order.OrderType, &deliveryAddress, &deliveryPhone, &deliveryNotes, &paymentMethod, &paymentProof, &order.CreatedAt, &order.UpdatedAt)
+		err := tx.QueryRow(query, status, orderID, fromStatus).Scan(&order.ID, &order.WaiterID, &order.CashierID, &order.TableNumber, &order.Status, &order.Subtotal, &order.Discount, &order.ServiceChargeRate, &order.ServiceCharge, &order.Tip, &order.Tax, &order.TaxBreakdown, &order.Total, &order.OrderNumber, &order.InvoiceNumber, &order.BlockchainTxHash, &order.OrderType, &deliveryAddress, &deliveryPhone, &deliveryNotes, &paymentMethod, &paymentProof, &order.CreatedAt, &order.UpdatedAt)
+		if err == sql.ErrNoRows {
+			return nil, r.changedOrMissing(orderID)
+		}
 		if err != nil {
 			return nil, err
 		}
@@ -393,7 +449,7 @@ func (r *orderRepository) ManageOrder(orderID uuid.UUID, updates map[string]inte
 		}
 	}
 	if hasWaiter {
-		query := `UPDATE orders SET waiter_id = $1 WHERE id = $2 RETURNING id, waiter_id, cashier_id, table_number, status, total, order_type, delivery_address, delivery_phone, delivery_notes, payment_method, payment_proof_path, created_at, updated_at`
+		query := `UPDATE orders SET waiter_id = $1, waiter_name = (SELECT username FROM users WHERE id = $1) WHERE id = $2 RETURNING id, waiter_id, cashier_id, table_number, status, subtotal, discount, service_charge_rate, service_charge, tip, tax, tax_breakdown, total, order_number, invoice_number, (SELECT tx_hash FROM order_notarizations WHERE order_id = orders.id), order_type, delivery_address, delivery_phone, delivery_notes, payment_method, payment_proof_path, created_at, updated_at`
 
 		var deliveryAddress sql.NullString
 		var deliveryPhone sql.NullString
@@ -401,7 +457,7 @@ func (r *orderRepository) ManageOrder(orderID uuid.UUID, updates map[string]inte
 		var paymentMethod sql.NullString
 		var paymentProof sql.NullString
 
-		err := r.db.QueryRow(query, waiterID, orderID).Scan(&order.ID, &order.WaiterID, &order.CashierID, &order.TableNumber, &order.Status, &order.Total, &order.OrderType, &deliveryAddress, &deliveryPhone, &deliveryNotes, &paymentMethod, &paymentProof, &order.CreatedAt, &order.UpdatedAt)
+		err := tx.QueryRow(query, waiterID, orderID).Scan(&order.ID, &order.WaiterID, &order.CashierID, &order.TableNumber, &order.Status, &order.Subtotal, &order.Discount, &order.ServiceChargeRate, &order.ServiceCharge, &order.Tip, &order.Tax, &order.TaxBreakdown, &order.Total, &order.OrderNumber, &order.InvoiceNumber, &order.BlockchainTxHash, &order.OrderType, &deliveryAddress, &deliveryPhone, &deliveryNotes, &paymentMethod, &paymentProof, &order.CreatedAt, &order.UpdatedAt)
 		if err != nil {
 			return nil, err
 		}
@@ -432,9 +488,15 @@ func (r *orderRepository) ManageOrder(orderID uuid.UUID, updates map[string]inte
 		return nil, sql.ErrNoRows
 	}
 
+	if err := insertOrderEvents(tx, events); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
 	// Obtener el nombre del mesero
-	waiterQuery := `SELECT username FROM users WHERE id = $1`
-	if err := r.db.QueryRow(waiterQuery, order.WaiterID).Scan(&order.WaiterName); err != nil {
+	if err := r.db.QueryRow(waiterNameQuery, order.ID).Scan(&order.WaiterName); err != nil {
 		order.WaiterName = ""
 	}
 
@@ -449,37 +511,166 @@ func (r *orderRepository) ManageOrder(orderID uuid.UUID, updates map[string]inte
 	return order, nil
 }
 
-func (r *orderRepository) UpdateOrderItems(orderID uuid.UUID, items []domain.OrderItem, newTotal float64) error {
+// UpdateOrderItems reemplaza los items y guarda los montos y descuentos recalculados y los
+// eventos del historial en una sola transacción. Devuelve domain.ErrOrderChanged si la orden
+// se cerró o recibió un pago después de validarla.
+func (r *orderRepository) UpdateOrderItems(orderID uuid.UUID, items []domain.OrderItem, amounts domain.OrderAmounts, discounts domain.OrderDiscountChanges, events []domain.OrderEvent) error {
 	tx, err := r.db.Begin()
 	if err != nil {
 		return err
 	}
 
+	if err := lockOrderWithoutPayments(tx, orderID); err != nil {
+		tx.Rollback()
+		return err
+	}
+
 	_, err = tx.Exec("DELETE FROM order_items WHERE order_id = $1", orderID)
 	if err != nil {
 		tx.Rollback()
 		return err
 	}
 
-	itemQuery := `INSERT INTO order_items (order_id, menu_item_id, quantity, price_at_order, notes, customizations, is_takeout) VALUES ($1, $2, $3, $4, $5, $6, $7)`
+	// Los items existentes conservan su ID y su estado de preparación
+	itemQuery := `INSERT INTO order_items (id, order_id, menu_item_id, menu_item_name, quantity, price_at_order, tax_rate, notes, customizations, is_takeout, status) VALUES (COALESCE($1, gen_random_uuid()), $2, $3, $4, $5, $6, $7, $8, $9, $10, COALESCE(NULLIF($11, ''), 'pending'))`
 	for _, item := range items {
-		_, err := tx.Exec(itemQuery, orderID, item.MenuItemID, item.Quantity, item.PriceAtOrder, item.Notes, item.Customizations, item.IsTakeout)
+		var itemID *uuid.UUID
+		if item.ID != uuid.Nil {
+			itemID = &item.ID
+		}
+		_, err := tx.Exec(itemQuery, itemID, orderID, item.MenuItemID, item.MenuItemName, item.Quantity, item.PriceAtOrder, item.TaxRate, item.Notes, item.Customizations, item.IsTakeout, item.Status)
 		if err != nil {
 			tx.Rollback()
 			return err
 		}
 	}
 
-	_, err = tx.Exec("UPDATE orders SET total = $1 WHERE id = $2", newTotal, orderID)
+	_, err = tx.Exec(updateAmountsQuery, amounts.Subtotal, amounts.Discount, amounts.ServiceCharge, amounts.Tax, amounts.TaxBreakdown, amounts.Total, orderID)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := saveOrderDiscounts(tx, orderID, discounts); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := insertOrderEvents(tx, events); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+const updateAmountsQuery = "UPDATE orders SET subtotal = $1, discount = $2, service_charge = $3, tax = $4, tax_breakdown = $5, total = $6 WHERE id = $7"
+
+// lockOrderWithoutPayments bloquea la orden dentro de la transacción (el mismo bloqueo que
+// toma el registro de un pago) y verifica que siga abierta y sin pagos, para que su total no
+// cambie después de cobrar una parte. Si otra petición la cerró o le registró un pago
+// devuelve domain.ErrOrderChanged.
+func lockOrderWithoutPayments(tx *sql.Tx, orderID uuid.UUID) error {
+	query := `SELECT status NOT IN ('pagado', 'cancelado')
+	                 AND NOT EXISTS (SELECT 1 FROM order_payments WHERE order_id = $1)
+	          FROM orders WHERE id = $1 FOR UPDATE`
+	var open bool
+	if err := tx.QueryRow(query, orderID).Scan(&open); err != nil {
+		return err
+	}
+	if !open {
+		return domain.ErrOrderChanged
+	}
+	return nil
+}
+
+// UpdateOrderItemsStatus cambia el estado de preparación de los items indicados de una orden
+// y guarda los eventos del historial en la misma transacción
+func (r *orderRepository) UpdateOrderItemsStatus(orderID uuid.UUID, itemIDs []uuid.UUID, status string, events []domain.OrderEvent) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `UPDATE order_items SET status = $1 WHERE order_id = $2 AND id = ANY($3)`
+	result, err := tx.Exec(query, status, orderID, pq.Array(itemIDs))
+	if err != nil {
+		return err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return sql.ErrNoRows
+	}
+
+	if err := insertOrderEvents(tx, events); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// VoidOrderItems anula los items indicados y guarda los montos y descuentos recalculados sin
+// ellos y los eventos del historial, en una sola transacción. Devuelve domain.ErrOrderChanged
+// si la orden se cerró o recibió un pago después de validarla.
+func (r *orderRepository) VoidOrderItems(orderID uuid.UUID, itemIDs []uuid.UUID, amounts domain.OrderAmounts, discounts domain.OrderDiscountChanges, events []domain.OrderEvent) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+
+	if err := lockOrderWithoutPayments(tx, orderID); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	result, err := tx.Exec(`UPDATE order_items SET status = 'voided' WHERE order_id = $1 AND id = ANY($2) AND status <> 'voided'`, orderID, pq.Array(itemIDs))
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	if rows != int64(len(itemIDs)) {
+		// Otra petición anuló o borró alguno de los items
+		tx.Rollback()
+		return domain.ErrOrderChanged
+	}
+
+	_, err = tx.Exec(updateAmountsQuery, amounts.Subtotal, amounts.Discount, amounts.ServiceCharge, amounts.Tax, amounts.TaxBreakdown, amounts.Total, orderID)
 	if err != nil {
 		tx.Rollback()
 		return err
 	}
 
+	if err := saveOrderDiscounts(tx, orderID, discounts); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := insertOrderEvents(tx, events); err != nil {
+		tx.Rollback()
+		return err
+	}
+
 	return tx.Commit()
 }
 
-func (r *orderRepository) AddPaymentProof(orderID uuid.UUID, method string, proofPath string) (*domain.Order, error) {
+// AddPaymentProof guarda el comprobante y pasa la orden a 'por_verificar' solo si sigue en
+// fromStatus; si otra petición la cambió antes devuelve domain.ErrOrderChanged. Los eventos
+// del historial se guardan en la misma transacción.
+func (r *orderRepository) AddPaymentProof(orderID uuid.UUID, fromStatus, method, proofPath string, events []domain.OrderEvent) (*domain.Order, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
 	order := &domain.Order{}
 
 	// Determinar el nuevo estado según el método de pago
@@ -494,7 +685,6 @@ func (r *orderRepository) AddPaymentProof(orderID uuid.UUID, method string, proo
 	}
 
 	var query string
-	var err error
 	var deliveryAddress sql.NullString
 	var deliveryPhone sql.NullString
 	var deliveryNotes sql.NullString
@@ -503,20 +693,30 @@ func (r *orderRepository) AddPaymentProof(orderID uuid.UUID, method string, proo
 
 	if proofPath != "" {
 		// Con comprobante
-		query = `UPDATE orders SET payment_method = $1, payment_proof_path = $2, status = $3 WHERE id = $4 
-		          RETURNING id, waiter_id, cashier_id, table_number, status, total, order_type, delivery_address, delivery_phone, delivery_notes, payment_method, payment_proof_path, created_at, updated_at`
-		err = r.db.QueryRow(query, method, proofPath, newStatus, orderID).Scan(&order.ID, &order.WaiterID, &order.CashierID, &order.TableNumber, &order.Status, &order.Total, &order.OrderType, &deliveryAddress, &deliveryPhone, &deliveryNotes, &paymentMethod, &paymentProof, &order.CreatedAt, &order.UpdatedAt)
+		query = `UPDATE orders SET payment_method = $1, payment_proof_path = $2, status = $3 WHERE id = $4 AND status = $5 
+		          RETURNING id, waiter_id, cashier_id, table_number, status, subtotal, discount, service_charge_rate, service_charge, tip, tax, tax_breakdown, total, order_number, invoice_number, (SELECT tx_hash FROM order_notarizations WHERE order_id = orders.id), order_type, delivery_address, delivery_phone, delivery_notes, payment_method, payment_proof_path, created_at, updated_at`
+		err = tx.QueryRow(query, method, proofPath, newStatus, orderID, fromStatus).Scan(&order.ID, &order.WaiterID, &order.CashierID, &order.TableNumber, &order.Status, &order.Subtotal, &order.Discount, &order.ServiceChargeRate, &order.ServiceCharge, &order.Tip, &order.Tax, &order.TaxBreakdown, &order.Total, &order.OrderNumber, &order.InvoiceNumber, &order.BlockchainTxHash, &order.OrderType, &deliveryAddress, &deliveryPhone, &deliveryNotes, &paymentMethod, &paymentProof, &order.CreatedAt, &order.UpdatedAt)
 	} else {
 		// Sin comprobante (efectivo)
-		query = `UPDATE orders SET payment_method = $1, status = $2 WHERE id = $3 
-		          RETURNING id, waiter_id, cashier_id, table_number, status, total, order_type, delivery_address, delivery_phone, delivery_notes, payment_method, payment_proof_path, created_at, updated_at`
-		err = r.db.QueryRow(query, method, newStatus, orderID).Scan(&order.ID, &order.WaiterID, &order.CashierID, &order.TableNumber, &order.Status, &order.Total, &order.OrderType, &deliveryAddress, &deliveryPhone, &deliveryNotes, &paymentMethod, &paymentProof, &order.CreatedAt, &order.UpdatedAt)
+		query = `UPDATE orders SET payment_method = $1, status = $2 WHERE id = $3 AND status = $4 
+		          RETURNING id, waiter_id, cashier_id, table_number, status, subtotal, discount, service_charge_rate, service_charge, tip, tax, tax_breakdown, total, order_number, invoice_number, (SELECT tx_hash FROM order_notarizations WHERE order_id = orders.id), order_type, delivery_address, delivery_phone, delivery_notes, payment_method, payment_proof_path, created_at, updated_at`
+		err = tx.QueryRow(query, method, newStatus, orderID, fromStatus).Scan(&order.ID, &order.WaiterID, &order.CashierID, &order.TableNumber, &order.Status, &order.Subtotal, &order.Discount, &order.ServiceChargeRate, &order.ServiceCharge, &order.Tip, &order.Tax, &order.TaxBreakdown, &order.Total, &order.OrderNumber, &order.InvoiceNumber, &order.BlockchainTxHash, &order.OrderType, &deliveryAddress, &deliveryPhone, &deliveryNotes, &paymentMethod, &paymentProof, &order.CreatedAt, &order.UpdatedAt)
 	}
 
+	if err == sql.ErrNoRows {
+		return nil, r.changedOrMissing(orderID)
+	}
 	if err != nil {
 		return nil, err
 	}
 
+	if err := insertOrderEvents(tx, events); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
 	if deliveryAddress.Valid {
 		addr := deliveryAddress.String
 		order.DeliveryAddress = &addr
@@ -539,8 +739,7 @@ func (r *orderRepository) AddPaymentProof(orderID uuid.UUID, method string, proo
 	}
 
 	// Obtener el nombre del mesero
-	waiterQuery := `SELECT username FROM users WHERE id = $1`
-	if err := r.db.QueryRow(waiterQuery, order.WaiterID).Scan(&order.WaiterName); err != nil {
+	if err := r.db.QueryRow(waiterNameQuery, order.ID).Scan(&order.WaiterName); err != nil {
 		order.WaiterName = ""
 	}
 
@@ -555,3 +754,24 @@ func (r *orderRepository) AddPaymentProof(orderID uuid.UUID, method string, proo
 
 	return order, nil
 }
+
+// CountOpenOrdersByTable cuenta las órdenes de la mesa que no están pagadas ni canceladas
+func (r *orderRepository) CountOpenOrdersByTable(tableNumber int) (int, error) {
+	var count int
+	query := "SELECT COUNT(*) FROM orders WHERE table_number = $1 AND status NOT IN ('pagado', 'cancelado')"
+	err := r.db.QueryRow(query, tableNumber).Scan(&count)
+	return count, err
+}
+
+// changedOrMissing distingue, cuando un UPDATE con guarda de estado no afectó ninguna fila,
+// si la orden no existe (sql.ErrNoRows) o si otra petición le cambió el estado (domain.ErrOrderChanged)
+func (r *orderRepository) changedOrMissing(orderID uuid.UUID) error {
+	var exists bool
+	if err := r.db.QueryRow("SELECT EXISTS (SELECT 1 FROM orders WHERE id = $1)", orderID).Scan(&exists); err != nil {
+		return err
+	}
+	if exists {
+		return domain.ErrOrderChanged
+	}
+	return sql.ErrNoRows
+}
@@ -0,0 +1,210 @@
+// =================================================================
+// Print Job Repository
+// =================================================================
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/Hoxanfox/TurnyChain/Backend/api/internal/domain"
+	"github.com/google/uuid"
+)
+
+type PrintJobRepository struct {
+	db *sql.DB
+}
+
+func NewPrintJobRepository(db *sql.DB) *PrintJobRepository {
+	return &PrintJobRepository{db: db}
+}
+
+const printJobColumns = `
+		j.id, j.order_id, j.station_id, s.name as station_name, j.printer_id, p.name as printer_name,
+		j.ticket, j.status, j.attempts, j.max_attempts, j.last_error, j.next_attempt_at, j.sent_at,
+		j.created_at, j.updated_at`
+
+const printJobJoins = `
+		FROM print_jobs j
+		INNER JOIN stations s ON s.id = j.station_id
+		LEFT JOIN printers p ON p.id = j.printer_id`
+
+func scanPrintJob(row interface{ Scan(...interface{}) error }) (*domain.PrintJob, error) {
+	var job domain.PrintJob
+	var printerName sql.NullString
+	var lastError sql.NullString
+	var sentAt sql.NullTime
+	err := row.Scan(&job.ID, &job.OrderID, &job.StationID, &job.StationName, &job.PrinterID, &printerName,
+		&job.Ticket, &job.Status, &job.Attempts, &job.MaxAttempts, &lastError, &job.NextAttemptAt, &sentAt,
+		&job.CreatedAt, &job.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	if printerName.Valid {
+		job.PrinterName = printerName.String
+	}
+	if lastError.Valid {
+		e := lastError.String
+		job.LastError = &e
+	}
+	if sentAt.Valid {
+		t := sentAt.Time
+		job.SentAt = &t
+	}
+	return &job, nil
+}
+
+func (r *PrintJobRepository) queryJobs(query string, args ...interface{}) ([]domain.PrintJob, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	jobs := make([]domain.PrintJob, 0)
+	for rows.Next() {
+		job, err := scanPrintJob(rows)
+		if err != nil {
+			return nil, err
+		}
+		jobs = append(jobs, *job)
+	}
+	return jobs, rows.Err()
+}
+
+// Create inserta un trabajo en la cola. nextAttemptAt permite reservarlo para un primer
+// intento síncrono sin que el worker lo tome al mismo tiempo.
+func (r *PrintJobRepository) Create(ticket domain.KitchenTicket, maxAttempts int, nextAttemptAt time.Time) (*domain.PrintJob, error) {
+	var id uuid.UUID
+	query := `
+		INSERT INTO print_jobs (order_id, station_id, ticket, max_attempts, next_attempt_at)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id
+	`
+	if err := r.db.QueryRow(query, ticket.OrderID, ticket.StationID, ticket, maxAttempts, nextAttemptAt).Scan(&id); err != nil {
+		return nil, err
+	}
+	return r.GetByID(id)
+}
+
+// GetByID obtiene un trabajo por ID
+func (r *PrintJobRepository) GetByID(id uuid.UUID) (*domain.PrintJob, error) {
+	query := `SELECT` + printJobColumns + printJobJoins + ` WHERE j.id = $1`
+	job, err := scanPrintJob(r.db.QueryRow(query, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return job, err
+}
+
+// GetByOrderID obtiene los trabajos de impresión de una orden
+func (r *PrintJobRepository) GetByOrderID(orderID uuid.UUID) ([]domain.PrintJob, error) {
+	query := `SELECT` + printJobColumns + printJobJoins + ` WHERE j.order_id = $1 ORDER BY j.created_at DESC`
+	return r.queryJobs(query, orderID)
+}
+
+// GetAll obtiene los últimos trabajos, opcionalmente filtrados por estado
+func (r *PrintJobRepository) GetAll(status string, limit int) ([]domain.PrintJob, error) {
+	query := `SELECT` + printJobColumns + printJobJoins + ` WHERE ($1 = '' OR j.status = $1) ORDER BY j.created_at DESC LIMIT $2`
+	return r.queryJobs(query, status, limit)
+}
+
+// ClaimDue reserva hasta limit trabajos pendientes cuyo reintento ya venció.
+// La reserva consiste en mover next_attempt_at hacia adelante (lease), así otro
+// worker no los toma mientras se procesan.
+func (r *PrintJobRepository) ClaimDue(limit int, lease time.Duration) ([]domain.PrintJob, error) {
+	query := `
+		WITH due AS (
+			SELECT id FROM print_jobs
+			WHERE status = 'queued' AND next_attempt_at <= now()
+			ORDER BY next_attempt_at
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		UPDATE print_jobs SET next_attempt_at = now() + make_interval(secs => $2)
+		WHERE id IN (SELECT id FROM due)
+		RETURNING id
+	`
+	rows, err := r.db.Query(query, limit, lease.Seconds())
+	if err != nil {
+		return nil, err
+	}
+	var ids []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+
+	jobs := make([]domain.PrintJob, 0, len(ids))
+	for _, id := range ids {
+		job, err := r.GetByID(id)
+		if err != nil {
+			return nil, err
+		}
+		if job != nil {
+			jobs = append(jobs, *job)
+		}
+	}
+	return jobs, nil
+}
+
+// MarkSent marca el trabajo como entregado a la impresora indicada
+func (r *PrintJobRepository) MarkSent(id, printerID uuid.UUID, attempts int) error {
+	query := `
+		UPDATE print_jobs
+		SET status = 'sent', printer_id = $1, attempts = $2, last_error = NULL, sent_at = now()
+		WHERE id = $3
+	`
+	return r.execOne(query, printerID, attempts, id)
+}
+
+// MarkRetry registra un intento fallido y programa el siguiente
+func (r *PrintJobRepository) MarkRetry(id uuid.UUID, printerID *uuid.UUID, attempts int, lastError string, nextAttemptAt time.Time) error {
+	query := `
+		UPDATE print_jobs
+		SET status = 'queued', printer_id = COALESCE($1, printer_id), attempts = $2, last_error = $3, next_attempt_at = $4
+		WHERE id = $5
+	`
+	return r.execOne(query, printerID, attempts, lastError, nextAttemptAt, id)
+}
+
+// MarkFailed marca el trabajo como fallido definitivamente
+func (r *PrintJobRepository) MarkFailed(id uuid.UUID, printerID *uuid.UUID, attempts int, lastError string) error {
+	query := `
+		UPDATE print_jobs
+		SET status = 'failed', printer_id = COALESCE($1, printer_id), attempts = $2, last_error = $3
+		WHERE id = $4
+	`
+	return r.execOne(query, printerID, attempts, lastError, id)
+}
+
+// Requeue vuelve a poner en cola un trabajo (reintento manual), reiniciando los intentos
+func (r *PrintJobRepository) Requeue(id uuid.UUID) error {
+	query := `
+		UPDATE print_jobs
+		SET status = 'queued', attempts = 0, next_attempt_at = now()
+		WHERE id = $1
+	`
+	return r.execOne(query, id)
+}
+
+func (r *PrintJobRepository) execOne(query string, args ...interface{}) error {
+	result, err := r.db.Exec(query, args...)
+	if err != nil {
+		return err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return fmt.Errorf("print job not found")
+	}
+	return nil
+}
@@ -6,6 +6,7 @@ package repository
 import (
 	"database/sql"
 	"fmt"
+	"time"
 
 	"github.com/Hoxanfox/TurnyChain/Backend/api/internal/domain"
 	"github.com/google/uuid"
@@ -19,11 +20,42 @@ func NewPrinterRepository(db *sql.DB) *PrinterRepository {
 	return &PrinterRepository{db: db}
 }
 
+// printerColumns son las columnas que lee scanPrinter (incluye el estado de conectividad)
+const printerColumns = `p.id, p.name, p.ip_address, p.port, p.printer_type,
+		       p.station_id, s.name as station_name, p.is_active, p.created_at,
+		       p.is_online, p.last_seen_at, p.last_checked_at, p.latency_ms`
+
+func scanPrinter(row interface{ Scan(...interface{}) error }) (*domain.Printer, error) {
+	var printer domain.Printer
+	var stationName sql.NullString
+	var lastSeenAt, lastCheckedAt sql.NullTime
+	var latencyMs sql.NullInt64
+	if err := row.Scan(&printer.ID, &printer.Name, &printer.IPAddress, &printer.Port, &printer.PrinterType, &printer.StationID, &stationName, &printer.IsActive, &printer.CreatedAt,
+		&printer.IsOnline, &lastSeenAt, &lastCheckedAt, &latencyMs); err != nil {
+		return nil, err
+	}
+	if stationName.Valid {
+		printer.StationName = stationName.String
+	}
+	if lastSeenAt.Valid {
+		t := lastSeenAt.Time
+		printer.LastSeenAt = &t
+	}
+	if lastCheckedAt.Valid {
+		t := lastCheckedAt.Time
+		printer.LastCheckedAt = &t
+	}
+	if latencyMs.Valid {
+		ms := int(latencyMs.Int64)
+		printer.LatencyMs = &ms
+	}
+	return &printer, nil
+}
+
 // GetAll obtiene todas las impresoras con info de su estación
 func (r *PrinterRepository) GetAll() ([]domain.Printer, error) {
 	query := `
-		SELECT p.id, p.name, p.ip_address, p.port, p.printer_type, 
-		       p.station_id, s.name as station_name, p.is_active, p.created_at
+		SELECT ` + printerColumns + `
 		FROM printers p
 		INNER JOIN stations s ON s.id = p.station_id
 		ORDER BY s.name, p.name
@@ -36,15 +68,11 @@ func (r *PrinterRepository) GetAll() ([]domain.Printer, error) {
 
 	printers := make([]domain.Printer, 0)
 	for rows.Next() {
-		var printer domain.Printer
-		var stationName sql.NullString
-		if err := rows.Scan(&printer.ID, &printer.Name, &printer.IPAddress, &printer.Port, &printer.PrinterType, &printer.StationID, &stationName, &printer.IsActive, &printer.CreatedAt); err != nil {
+		printer, err := scanPrinter(rows)
+		if err != nil {
 			return nil, err
 		}
-		if stationName.Valid {
-			printer.StationName = stationName.String
-		}
-		printers = append(printers, printer)
+		printers = append(printers, *printer)
 	}
 	return printers, nil
 }
@@ -52,8 +80,7 @@ func (r *PrinterRepository) GetAll() ([]domain.Printer, error) {
 // GetAllActive obtiene solo las impresoras activas
 func (r *PrinterRepository) GetAllActive() ([]domain.Printer, error) {
 	query := `
-		SELECT p.id, p.name, p.ip_address, p.port, p.printer_type, 
-		       p.station_id, s.name as station_name, p.is_active, p.created_at
+		SELECT ` + printerColumns + `
 		FROM printers p
 		INNER JOIN stations s ON s.id = p.station_id
 		WHERE p.is_active = true AND s.is_active = true
@@ -67,48 +94,37 @@ func (r *PrinterRepository) GetAllActive() ([]domain.Printer, error) {
 
 	printers := make([]domain.Printer, 0)
 	for rows.Next() {
-		var printer domain.Printer
-		var stationName sql.NullString
-		if err := rows.Scan(&printer.ID, &printer.Name, &printer.IPAddress, &printer.Port, &printer.PrinterType, &printer.StationID, &stationName, &printer.IsActive, &printer.CreatedAt); err != nil {
+		printer, err := scanPrinter(rows)
+		if err != nil {
 			return nil, err
 		}
-		if stationName.Valid {
-			printer.StationName = stationName.String
-		}
-		printers = append(printers, printer)
+		printers = append(printers, *printer)
 	}
 	return printers, nil
 }
 
 // GetByID obtiene una impresora por ID
 func (r *PrinterRepository) GetByID(id uuid.UUID) (*domain.Printer, error) {
-	var printer domain.Printer
-	var stationName sql.NullString
 	query := `
-		SELECT p.id, p.name, p.ip_address, p.port, p.printer_type, 
-		       p.station_id, s.name as station_name, p.is_active, p.created_at
+		SELECT ` + printerColumns + `
 		FROM printers p
 		INNER JOIN stations s ON s.id = p.station_id
 		WHERE p.id = $1
 	`
-	err := r.db.QueryRow(query, id).Scan(&printer.ID, &printer.Name, &printer.IPAddress, &printer.Port, &printer.PrinterType, &printer.StationID, &stationName, &printer.IsActive, &printer.CreatedAt)
+	printer, err := scanPrinter(r.db.QueryRow(query, id))
 	if err == sql.ErrNoRows {
 		return nil, nil
 	}
 	if err != nil {
 		return nil, err
 	}
-	if stationName.Valid {
-		printer.StationName = stationName.String
-	}
-	return &printer, nil
+	return printer, nil
 }
 
 // GetByStationID obtiene todas las impresoras de una estación específica
 func (r *PrinterRepository) GetByStationID(stationID uuid.UUID) ([]domain.Printer, error) {
 	query := `
-		SELECT p.id, p.name, p.ip_address, p.port, p.printer_type, 
-		       p.station_id, s.name as station_name, p.is_active, p.created_at
+		SELECT ` + printerColumns + `
 		FROM printers p
 		INNER JOIN stations s ON s.id = p.station_id
 		WHERE p.station_id = $1 AND p.is_active = true
@@ -122,15 +138,38 @@ func (r *PrinterRepository) GetByStationID(stationID uuid.UUID) ([]domain.Printe
 
 	printers := make([]domain.Printer, 0)
 	for rows.Next() {
-		var printer domain.Printer
-		var stationName sql.NullString
-		if err := rows.Scan(&printer.ID, &printer.Name, &printer.IPAddress, &printer.Port, &printer.PrinterType, &printer.StationID, &stationName, &printer.IsActive, &printer.CreatedAt); err != nil {
+		printer, err := scanPrinter(rows)
+		if err != nil {
 			return nil, err
 		}
-		if stationName.Valid {
-			printer.StationName = stationName.String
+		printers = append(printers, *printer)
+	}
+	return printers, nil
+}
+
+// GetCashierPrinters obtiene las impresoras activas de las estaciones de caja,
+// primero las que están en línea
+func (r *PrinterRepository) GetCashierPrinters() ([]domain.Printer, error) {
+	query := `
+		SELECT ` + printerColumns + `
+		FROM printers p
+		INNER JOIN stations s ON s.id = p.station_id
+		WHERE s.is_cashier = true AND s.is_active = true AND p.is_active = true
+		ORDER BY p.is_online DESC, p.name
+	`
+	rows, err := r.db.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	printers := make([]domain.Printer, 0)
+	for rows.Next() {
+		printer, err := scanPrinter(rows)
+		if err != nil {
+			return nil, err
 		}
-		printers = append(printers, printer)
+		printers = append(printers, *printer)
 	}
 	return printers, nil
 }
@@ -143,8 +182,7 @@ func (r *PrinterRepository) GetByStationIDs(stationIDs []uuid.UUID) ([]domain.Pr
 
 	// Construir query con placeholders dinámicos
 	query := `
-		SELECT p.id, p.name, p.ip_address, p.port, p.printer_type, 
-		       p.station_id, s.name as station_name, p.is_active, p.created_at
+		SELECT ` + printerColumns + `
 		FROM printers p
 		INNER JOIN stations s ON s.id = p.station_id
 		WHERE p.is_active = true AND s.is_active = true AND p.station_id IN (`
@@ -167,15 +205,11 @@ func (r *PrinterRepository) GetByStationIDs(stationIDs []uuid.UUID) ([]domain.Pr
 
 	printers := make([]domain.Printer, 0)
 	for rows.Next() {
-		var printer domain.Printer
-		var stationName sql.NullString
-		if err := rows.Scan(&printer.ID, &printer.Name, &printer.IPAddress, &printer.Port, &printer.PrinterType, &printer.StationID, &stationName, &printer.IsActive, &printer.CreatedAt); err != nil {
+		printer, err := scanPrinter(rows)
+		if err != nil {
 			return nil, err
 		}
-		if stationName.Valid {
-			printer.StationName = stationName.String
-		}
-		printers = append(printers, printer)
+		printers = append(printers, *printer)
 	}
 	return printers, nil
 }
@@ -230,6 +264,21 @@ func (r *PrinterRepository) Update(id uuid.UUID, req domain.UpdatePrinterRequest
 	return nil
 }
 
+// UpdateStatus registra el resultado de un chequeo de conectividad.
+// last_seen_at y latency_ms solo se actualizan cuando la impresora respondió.
+func (r *PrinterRepository) UpdateStatus(id uuid.UUID, online bool, latencyMs int, checkedAt time.Time) error {
+	query := `
+		UPDATE printers
+		SET is_online = $1,
+		    last_checked_at = $2,
+		    last_seen_at = CASE WHEN $1 THEN $2 ELSE last_seen_at END,
+		    latency_ms = CASE WHEN $1 THEN $3 ELSE NULL END
+		WHERE id = $4
+	`
+	_, err := r.db.Exec(query, online, checkedAt, latencyMs, id)
+	return err
+}
+
 // Delete elimina una impresora (soft delete)
 func (r *PrinterRepository) Delete(id uuid.UUID) error {
 	query := `UPDATE printers SET is_active = false WHERE id = $1`
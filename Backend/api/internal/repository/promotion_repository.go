@@ -0,0 +1,346 @@
+// =================================================================
+// Promotion Repository
+// Promociones y descuentos aplicados a las órdenes
+// =================================================================
+package repository
+
+import (
+	"database/sql"
+
+	"github.com/Hoxanfox/TurnyChain/Backend/api/internal/domain"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+type PromotionRepository struct {
+	db *sql.DB
+}
+
+func NewPromotionRepository(db *sql.DB) *PromotionRepository {
+	return &PromotionRepository{db: db}
+}
+
+// Los usos cuentan los descuentos no rechazados de la promoción
+const promotionColumns = `
+	p.id, p.name, p.description, p.type, p.scope, p.percentage, p.amount, p.target_ids, p.coupon_code,
+	p.days_of_week, p.start_time, p.end_time, p.starts_at, p.ends_at, p.max_uses,
+	(SELECT COUNT(*) FROM order_discounts d INNER JOIN orders o ON o.id = d.order_id
+	 WHERE d.promotion_id = p.id AND d.status <> 'rejected' AND o.status <> 'cancelado') AS uses,
+	p.is_active, p.created_by, p.created_at, p.updated_at`
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanPromotion(row rowScanner) (*domain.Promotion, error) {
+	var promotion domain.Promotion
+	var days pq.Int64Array
+	var startTime, endTime sql.NullString
+	var maxUses sql.NullInt64
+	err := row.Scan(&promotion.ID, &promotion.Name, &promotion.Description, &promotion.Type, &promotion.Scope,
+		&promotion.Percentage, &promotion.Amount, pq.Array(&promotion.TargetIDs), &promotion.CouponCode,
+		&days, &startTime, &endTime, &promotion.StartsAt, &promotion.EndsAt, &maxUses,
+		&promotion.Uses, &promotion.IsActive, &promotion.CreatedBy, &promotion.CreatedAt, &promotion.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	promotion.DaysOfWeek = make([]int, len(days))
+	for i, day := range days {
+		promotion.DaysOfWeek[i] = int(day)
+	}
+	// Las columnas time llegan como "HH:MM:SS"
+	if startTime.Valid && len(startTime.String) >= 5 {
+		start := startTime.String[:5]
+		promotion.StartTime = &start
+	}
+	if endTime.Valid && len(endTime.String) >= 5 {
+		end := endTime.String[:5]
+		promotion.EndTime = &end
+	}
+	if maxUses.Valid {
+		uses := int(maxUses.Int64)
+		promotion.MaxUses = &uses
+	}
+	if promotion.TargetIDs == nil {
+		promotion.TargetIDs = []uuid.UUID{}
+	}
+	return &promotion, nil
+}
+
+func (r *PromotionRepository) queryPromotions(where string, args ...interface{}) ([]domain.Promotion, error) {
+	rows, err := r.db.Query(`SELECT `+promotionColumns+` FROM promotions p `+where+` ORDER BY p.name`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	promotions := make([]domain.Promotion, 0)
+	for rows.Next() {
+		promotion, err := scanPromotion(rows)
+		if err != nil {
+			return nil, err
+		}
+		promotions = append(promotions, *promotion)
+	}
+	return promotions, rows.Err()
+}
+
+// GetAll obtiene todas las promociones, activas o no
+func (r *PromotionRepository) GetAll() ([]domain.Promotion, error) {
+	return r.queryPromotions("")
+}
+
+// GetAutomatic obtiene las promociones activas sin cupón (se aplican solas)
+func (r *PromotionRepository) GetAutomatic() ([]domain.Promotion, error) {
+	return r.queryPromotions("WHERE p.is_active = true AND p.coupon_code IS NULL")
+}
+
+func (r *PromotionRepository) GetByID(id uuid.UUID) (*domain.Promotion, error) {
+	return scanPromotion(r.db.QueryRow(`SELECT `+promotionColumns+` FROM promotions p WHERE p.id = $1`, id))
+}
+
+// GetByCouponCode busca una promoción por su cupón (sin distinguir mayúsculas)
+func (r *PromotionRepository) GetByCouponCode(code string) (*domain.Promotion, error) {
+	return scanPromotion(r.db.QueryRow(`SELECT `+promotionColumns+` FROM promotions p WHERE UPPER(p.coupon_code) = UPPER($1)`, code))
+}
+
+func (r *PromotionRepository) Create(promotion *domain.Promotion) (*domain.Promotion, error) {
+	query := `
+		INSERT INTO promotions (name, description, type, scope, percentage, amount, target_ids, coupon_code,
+		                        days_of_week, start_time, end_time, starts_at, ends_at, max_uses, is_active, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16)
+		RETURNING id`
+	var id uuid.UUID
+	err := r.db.QueryRow(query, promotion.Name, promotion.Description, promotion.Type, promotion.Scope,
+		promotion.Percentage, promotion.Amount, pq.Array(promotion.TargetIDs), promotion.CouponCode,
+		pq.Array(promotion.DaysOfWeek), promotion.StartTime, promotion.EndTime, promotion.StartsAt,
+		promotion.EndsAt, promotion.MaxUses, promotion.IsActive, promotion.CreatedBy).Scan(&id)
+	if err != nil {
+		return nil, err
+	}
+	return r.GetByID(id)
+}
+
+func (r *PromotionRepository) Update(promotion *domain.Promotion) (*domain.Promotion, error) {
+	query := `
+		UPDATE promotions SET name = $1, description = $2, type = $3, scope = $4, percentage = $5, amount = $6,
+		       target_ids = $7, coupon_code = $8, days_of_week = $9, start_time = $10, end_time = $11,
+		       starts_at = $12, ends_at = $13, max_uses = $14, is_active = $15
+		WHERE id = $16`
+	result, err := r.db.Exec(query, promotion.Name, promotion.Description, promotion.Type, promotion.Scope,
+		promotion.Percentage, promotion.Amount, pq.Array(promotion.TargetIDs), promotion.CouponCode,
+		pq.Array(promotion.DaysOfWeek), promotion.StartTime, promotion.EndTime, promotion.StartsAt,
+		promotion.EndsAt, promotion.MaxUses, promotion.IsActive, promotion.ID)
+	if err != nil {
+		return nil, err
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return nil, sql.ErrNoRows
+	}
+	return r.GetByID(promotion.ID)
+}
+
+// Delete elimina la promoción. Los descuentos ya aplicados conservan su nombre y monto.
+func (r *PromotionRepository) Delete(id uuid.UUID) error {
+	result, err := r.db.Exec("DELETE FROM promotions WHERE id = $1", id)
+	if err != nil {
+		return err
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// --- Descuentos de las órdenes ---
+
+const orderDiscountColumns = `
+	d.id, d.order_id, d.promotion_id, d.promotion_name, d.coupon_code, d.amount, d.status,
+	d.applied_by, ua.username, d.approved_by, ub.username, d.created_at, d.updated_at`
+
+func scanOrderDiscount(row rowScanner) (*domain.OrderDiscount, error) {
+	var discount domain.OrderDiscount
+	var appliedByName, approvedByName sql.NullString
+	err := row.Scan(&discount.ID, &discount.OrderID, &discount.PromotionID, &discount.PromotionName,
+		&discount.CouponCode, &discount.Amount, &discount.Status, &discount.AppliedBy, &appliedByName,
+		&discount.ApprovedBy, &approvedByName, &discount.CreatedAt, &discount.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	discount.AppliedByName = appliedByName.String
+	discount.ApprovedByName = approvedByName.String
+	return &discount, nil
+}
+
+// GetOrderDiscounts obtiene los descuentos de una orden (incluye pendientes y rechazados)
+func (r *PromotionRepository) GetOrderDiscounts(orderID uuid.UUID) ([]domain.OrderDiscount, error) {
+	query := `SELECT ` + orderDiscountColumns + `
+		FROM order_discounts d
+		LEFT JOIN users ua ON ua.id = d.applied_by
+		LEFT JOIN users ub ON ub.id = d.approved_by
+		WHERE d.order_id = $1
+		ORDER BY d.created_at, d.id`
+	rows, err := r.db.Query(query, orderID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	discounts := make([]domain.OrderDiscount, 0)
+	for rows.Next() {
+		discount, err := scanOrderDiscount(rows)
+		if err != nil {
+			return nil, err
+		}
+		discounts = append(discounts, *discount)
+	}
+	return discounts, rows.Err()
+}
+
+// CreateOrderDiscount registra un descuento de cupón (con el ID asignado), los montos
+// recalculados de la orden (nil si el descuento no cambia el total) y sus eventos del
+// historial en una sola transacción. Falla si el cupón ya está en la orden, devuelve
+// domain.ErrOrderChanged si la orden se cerró o recibió un pago y domain.ErrCouponExhausted
+// si el cupón ya no tiene usos disponibles.
+func (r *PromotionRepository) CreateOrderDiscount(discount *domain.OrderDiscount, amounts *domain.OrderAmounts, events []domain.OrderEvent) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := lockOrderWithoutPayments(tx, discount.OrderID); err != nil {
+		return err
+	}
+	if discount.PromotionID != nil {
+		if err := checkCouponUses(tx, *discount.PromotionID); err != nil {
+			return err
+		}
+	}
+
+	query := `
+		INSERT INTO order_discounts (id, order_id, promotion_id, promotion_name, coupon_code, amount, status, applied_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING created_at, updated_at`
+	err = tx.QueryRow(query, discount.ID, discount.OrderID, discount.PromotionID, discount.PromotionName, discount.CouponCode,
+		discount.Amount, discount.Status, discount.AppliedBy).Scan(&discount.CreatedAt, &discount.UpdatedAt)
+	if err != nil {
+		return err
+	}
+
+	if err := saveDiscountedAmounts(tx, discount.OrderID, amounts); err != nil {
+		return err
+	}
+	if err := insertOrderEvents(tx, events); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// saveOrderDiscounts guarda los descuentos recalculados de la orden dentro de la transacción
+// que guarda sus items y montos. Las promociones automáticas quedan exactamente como las
+// indicadas: se actualiza el monto de las que siguen, se agregan las nuevas y se borran las
+// que ya no aplican. Los cupones solo cambian de monto y estado; al volver a pendiente
+// pierden la aprobación anterior.
+func saveOrderDiscounts(tx *sql.Tx, orderID uuid.UUID, changes domain.OrderDiscountChanges) error {
+	promotionIDs := make([]uuid.UUID, 0, len(changes.Automatic))
+	for _, discount := range changes.Automatic {
+		promotionIDs = append(promotionIDs, *discount.PromotionID)
+	}
+	// Las automáticas cuya promoción se borró quedan con promotion_id NULL y también se borran
+	_, err := tx.Exec(`DELETE FROM order_discounts WHERE order_id = $1 AND coupon_code IS NULL
+		AND (promotion_id IS NULL OR NOT (promotion_id = ANY($2)))`,
+		orderID, pq.Array(promotionIDs))
+	if err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO order_discounts (order_id, promotion_id, promotion_name, amount, status)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (order_id, promotion_id) DO UPDATE SET amount = EXCLUDED.amount`
+	for _, discount := range changes.Automatic {
+		if _, err := tx.Exec(query, orderID, discount.PromotionID, discount.PromotionName, discount.Amount, discount.Status); err != nil {
+			return err
+		}
+	}
+
+	couponQuery := `
+		UPDATE order_discounts
+		SET amount = $1, status = $2,
+		    approved_by = CASE WHEN $2 = 'pending_approval' THEN NULL ELSE approved_by END
+		WHERE id = $3 AND order_id = $4`
+	for _, coupon := range changes.Coupons {
+		if _, err := tx.Exec(couponQuery, coupon.Amount, coupon.Status, coupon.ID, orderID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ReviewOrderDiscount aprueba o rechaza un descuento pendiente y guarda los montos
+// recalculados de la orden (nil si no cambian) y sus eventos del historial en la misma
+// transacción. Devuelve sql.ErrNoRows si el descuento no existe en la orden o ya no está
+// pendiente, y domain.ErrOrderChanged si la orden se cerró o recibió un pago.
+func (r *PromotionRepository) ReviewOrderDiscount(orderID, id uuid.UUID, status string, reviewerID uuid.UUID, amounts *domain.OrderAmounts, events []domain.OrderEvent) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := lockOrderWithoutPayments(tx, orderID); err != nil {
+		return err
+	}
+
+	result, err := tx.Exec(`UPDATE order_discounts SET status = $1, approved_by = $2
+		WHERE id = $3 AND order_id = $4 AND status = 'pending_approval'`, status, reviewerID, id, orderID)
+	if err != nil {
+		return err
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return sql.ErrNoRows
+	}
+
+	if err := saveDiscountedAmounts(tx, orderID, amounts); err != nil {
+		return err
+	}
+	if err := insertOrderEvents(tx, events); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// checkCouponUses bloquea la promoción dentro de la transacción y vuelve a contar sus usos:
+// dos órdenes que aplican a la vez el último uso de un cupón no pueden quedarse ambas con él
+func checkCouponUses(tx *sql.Tx, promotionID uuid.UUID) error {
+	var maxUses sql.NullInt64
+	if err := tx.QueryRow(`SELECT max_uses FROM promotions WHERE id = $1 FOR UPDATE`, promotionID).Scan(&maxUses); err != nil {
+		return err
+	}
+	if !maxUses.Valid {
+		return nil
+	}
+
+	var uses int64
+	query := `SELECT COUNT(*) FROM order_discounts d INNER JOIN orders o ON o.id = d.order_id
+	          WHERE d.promotion_id = $1 AND d.status <> 'rejected' AND o.status <> 'cancelado'`
+	if err := tx.QueryRow(query, promotionID).Scan(&uses); err != nil {
+		return err
+	}
+	if uses >= maxUses.Int64 {
+		return domain.ErrCouponExhausted
+	}
+	return nil
+}
+
+// saveDiscountedAmounts guarda los montos de la orden recalculados con sus descuentos dentro
+// de la transacción que aplica o aprueba el descuento (nil: el total no cambia)
+func saveDiscountedAmounts(tx *sql.Tx, orderID uuid.UUID, amounts *domain.OrderAmounts) error {
+	if amounts == nil {
+		return nil
+	}
+	_, err := tx.Exec(updateAmountsQuery, amounts.Subtotal, amounts.Discount, amounts.ServiceCharge, amounts.Tax, amounts.TaxBreakdown, amounts.Total, orderID)
+	return err
+}
@@ -0,0 +1,98 @@
+// =================================================================
+// Report Repository
+// =================================================================
+package repository
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/Hoxanfox/TurnyChain/Backend/api/internal/domain"
+	"github.com/google/uuid"
+)
+
+type ReportRepository struct {
+	db *sql.DB
+}
+
+func NewReportRepository(db *sql.DB) *ReportRepository {
+	return &ReportRepository{db: db}
+}
+
+// GetWaiterReport suma por mesero las órdenes pagadas creadas en [from, to) y las propinas
+// registradas en ese rango (sin las de órdenes canceladas). Las propinas se atribuyen con tip_waiter_id, así que cuentan
+// para el mesero que atendía la orden cuando se pagó. Con waiterID filtra un solo mesero.
+func (r *ReportRepository) GetWaiterReport(from, to time.Time, waiterID *uuid.UUID) ([]domain.WaiterReport, error) {
+	query := `
+		WITH sales AS (
+			SELECT waiter_id, COUNT(*) AS orders,
+			       SUM(subtotal) AS subtotal, SUM(service_charge) AS service_charge, SUM(total) AS total
+			FROM orders
+			WHERE status = 'pagado' AND created_at >= $1 AND created_at < $2
+			GROUP BY waiter_id
+		), tips AS (
+			SELECT p.tip_waiter_id AS waiter_id, SUM(p.tip) AS tips
+			FROM order_payments p
+			INNER JOIN orders o ON o.id = p.order_id
+			WHERE p.tip > 0 AND p.created_at >= $1 AND p.created_at < $2 AND o.status <> 'cancelado'
+			GROUP BY p.tip_waiter_id
+		)
+		SELECT u.id, u.username,
+		       COALESCE(s.orders, 0), COALESCE(s.subtotal, 0), COALESCE(s.service_charge, 0),
+		       COALESCE(s.total, 0), COALESCE(t.tips, 0)
+		FROM users u
+		LEFT JOIN sales s ON s.waiter_id = u.id
+		LEFT JOIN tips t ON t.waiter_id = u.id
+		WHERE (s.waiter_id IS NOT NULL OR t.waiter_id IS NOT NULL)
+		  AND ($3::uuid IS NULL OR u.id = $3)
+		ORDER BY u.username`
+
+	rows, err := r.db.Query(query, from, to, waiterID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	reports := make([]domain.WaiterReport, 0)
+	for rows.Next() {
+		var report domain.WaiterReport
+		if err := rows.Scan(&report.WaiterID, &report.WaiterName, &report.Orders, &report.Subtotal,
+			&report.ServiceCharge, &report.Sales, &report.Tips); err != nil {
+			return nil, err
+		}
+		reports = append(reports, report)
+	}
+	return reports, rows.Err()
+}
+
+// GetCancellations lista las órdenes canceladas o anuladas en [from, to), las más recientes
+// primero. Con waiterID solo las órdenes de ese mesero.
+func (r *ReportRepository) GetCancellations(from, to time.Time, waiterID *uuid.UUID) ([]domain.OrderCancellation, error) {
+	query := `
+		SELECT c.order_id, c.kind, c.reason_code, c.notes, c.previous_status, c.total, c.cancelled_by,
+		       COALESCE(cu.username, ''), c.created_at, o.order_number, o.table_number, o.waiter_id, COALESCE(w.username, '')
+		FROM order_cancellations c
+		INNER JOIN orders o ON o.id = c.order_id
+		LEFT JOIN users cu ON cu.id = c.cancelled_by
+		LEFT JOIN users w ON w.id = o.waiter_id
+		WHERE c.created_at >= $1 AND c.created_at < $2
+		  AND ($3::uuid IS NULL OR o.waiter_id = $3)
+		ORDER BY c.created_at DESC`
+
+	rows, err := r.db.Query(query, from, to, waiterID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	cancellations := make([]domain.OrderCancellation, 0)
+	for rows.Next() {
+		var c domain.OrderCancellation
+		if err := rows.Scan(&c.OrderID, &c.Kind, &c.ReasonCode, &c.Notes, &c.PreviousStatus, &c.Total, &c.CancelledBy,
+			&c.CancelledByName, &c.CreatedAt, &c.OrderNumber, &c.TableNumber, &c.WaiterID, &c.WaiterName); err != nil {
+			return nil, err
+		}
+		cancellations = append(cancellations, c)
+	}
+	return cancellations, rows.Err()
+}
@@ -0,0 +1,63 @@
+// =================================================================
+// Service Charge Repository
+// =================================================================
+package repository
+
+import (
+	"database/sql"
+
+	"github.com/Hoxanfox/TurnyChain/Backend/api/internal/domain"
+)
+
+type ServiceChargeRepository struct {
+	db *sql.DB
+}
+
+func NewServiceChargeRepository(db *sql.DB) *ServiceChargeRepository {
+	return &ServiceChargeRepository{db: db}
+}
+
+// GetAll obtiene el cargo por servicio de todos los tipos de orden
+func (r *ServiceChargeRepository) GetAll() ([]domain.ServiceChargeSetting, error) {
+	rows, err := r.db.Query(`SELECT order_type, percentage, updated_at FROM service_charge_settings ORDER BY order_type`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	settings := make([]domain.ServiceChargeSetting, 0)
+	for rows.Next() {
+		var setting domain.ServiceChargeSetting
+		if err := rows.Scan(&setting.OrderType, &setting.Percentage, &setting.UpdatedAt); err != nil {
+			return nil, err
+		}
+		settings = append(settings, setting)
+	}
+	return settings, rows.Err()
+}
+
+// GetPercentage obtiene el porcentaje de un tipo de orden; sin configuración es 0
+func (r *ServiceChargeRepository) GetPercentage(orderType string) (domain.Percent, error) {
+	var percentage domain.Percent
+	err := r.db.QueryRow(`SELECT percentage FROM service_charge_settings WHERE order_type = $1`, orderType).Scan(&percentage)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+	return percentage, err
+}
+
+// Upsert guarda el porcentaje de un tipo de orden
+func (r *ServiceChargeRepository) Upsert(orderType string, percentage domain.Percent) (*domain.ServiceChargeSetting, error) {
+	setting := domain.ServiceChargeSetting{}
+	query := `
+		INSERT INTO service_charge_settings (order_type, percentage)
+		VALUES ($1, $2)
+		ON CONFLICT (order_type) DO UPDATE SET percentage = EXCLUDED.percentage
+		RETURNING order_type, percentage, updated_at
+	`
+	err := r.db.QueryRow(query, orderType, percentage).Scan(&setting.OrderType, &setting.Percentage, &setting.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &setting, nil
+}
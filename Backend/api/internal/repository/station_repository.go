@@ -21,7 +21,7 @@ func NewStationRepository(db *sql.DB) *StationRepository {
 
 // GetAll obtiene todas las estaciones
 func (r *StationRepository) GetAll() ([]domain.Station, error) {
-	query := `SELECT id, name, description, is_active, created_at FROM stations ORDER BY name`
+	query := `SELECT id, name, description, is_active, auto_print, is_cashier, created_at FROM stations ORDER BY name`
 	rows, err := r.db.Query(query)
 	if err != nil {
 		return nil, err
@@ -32,7 +32,7 @@ func (r *StationRepository) GetAll() ([]domain.Station, error) {
 	for rows.Next() {
 		var station domain.Station
 		var description sql.NullString
-		if err := rows.Scan(&station.ID, &station.Name, &description, &station.IsActive, &station.CreatedAt); err != nil {
+		if err := rows.Scan(&station.ID, &station.Name, &description, &station.IsActive, &station.AutoPrint, &station.IsCashier, &station.CreatedAt); err != nil {
 			return nil, err
 		}
 		if description.Valid {
@@ -45,7 +45,7 @@ func (r *StationRepository) GetAll() ([]domain.Station, error) {
 
 // GetAllActive obtiene solo las estaciones activas
 func (r *StationRepository) GetAllActive() ([]domain.Station, error) {
-	query := `SELECT id, name, description, is_active, created_at FROM stations WHERE is_active = true ORDER BY name`
+	query := `SELECT id, name, description, is_active, auto_print, is_cashier, created_at FROM stations WHERE is_active = true ORDER BY name`
 	rows, err := r.db.Query(query)
 	if err != nil {
 		return nil, err
@@ -56,7 +56,7 @@ func (r *StationRepository) GetAllActive() ([]domain.Station, error) {
 	for rows.Next() {
 		var station domain.Station
 		var description sql.NullString
-		if err := rows.Scan(&station.ID, &station.Name, &description, &station.IsActive, &station.CreatedAt); err != nil {
+		if err := rows.Scan(&station.ID, &station.Name, &description, &station.IsActive, &station.AutoPrint, &station.IsCashier, &station.CreatedAt); err != nil {
 			return nil, err
 		}
 		if description.Valid {
@@ -71,8 +71,8 @@ func (r *StationRepository) GetAllActive() ([]domain.Station, error) {
 func (r *StationRepository) GetByID(id uuid.UUID) (*domain.Station, error) {
 	var station domain.Station
 	var description sql.NullString
-	query := `SELECT id, name, description, is_active, created_at FROM stations WHERE id = $1`
-	err := r.db.QueryRow(query, id).Scan(&station.ID, &station.Name, &description, &station.IsActive, &station.CreatedAt)
+	query := `SELECT id, name, description, is_active, auto_print, is_cashier, created_at FROM stations WHERE id = $1`
+	err := r.db.QueryRow(query, id).Scan(&station.ID, &station.Name, &description, &station.IsActive, &station.AutoPrint, &station.IsCashier, &station.CreatedAt)
 	if err == sql.ErrNoRows {
 		return nil, nil
 	}
@@ -89,12 +89,12 @@ func (r *StationRepository) GetByID(id uuid.UUID) (*domain.Station, error) {
 func (r *StationRepository) Create(req domain.CreateStationRequest) (*domain.Station, error) {
 	var station domain.Station
 	query := `
-		INSERT INTO stations (name, description)
-		VALUES ($1, $2)
-		RETURNING id, name, description, is_active, created_at
+		INSERT INTO stations (name, description, auto_print, is_cashier)
+		VALUES ($1, $2, COALESCE($3, true), COALESCE($4, false))
+		RETURNING id, name, description, is_active, auto_print, is_cashier, created_at
 	`
 	var description sql.NullString
-	err := r.db.QueryRow(query, req.Name, req.Description).Scan(&station.ID, &station.Name, &description, &station.IsActive, &station.CreatedAt)
+	err := r.db.QueryRow(query, req.Name, req.Description, req.AutoPrint, req.IsCashier).Scan(&station.ID, &station.Name, &description, &station.IsActive, &station.AutoPrint, &station.IsCashier, &station.CreatedAt)
 	if err != nil {
 		return nil, err
 	}
@@ -110,10 +110,12 @@ func (r *StationRepository) Update(id uuid.UUID, req domain.UpdateStationRequest
 		UPDATE stations
 		SET name = COALESCE(NULLIF($1, ''), name),
 		    description = COALESCE(NULLIF($2, ''), description),
-		    is_active = COALESCE($3, is_active)
-		WHERE id = $4
+		    is_active = COALESCE($3, is_active),
+		    auto_print = COALESCE($4, auto_print),
+		    is_cashier = COALESCE($5, is_cashier)
+		WHERE id = $6
 	`
-	result, err := r.db.Exec(query, req.Name, req.Description, req.IsActive, id)
+	result, err := r.db.Exec(query, req.Name, req.Description, req.IsActive, req.AutoPrint, req.IsCashier, id)
 	if err != nil {
 		return err
 	}
@@ -156,7 +158,7 @@ func (r *StationRepository) GetStationsByCategoryIDs(categoryIDs []uuid.UUID) ([
 
 	// Construir query con placeholders dinámicos
 	query := `
-		SELECT DISTINCT s.id, s.name, s.description, s.is_active, s.created_at
+		SELECT DISTINCT s.id, s.name, s.description, s.is_active, s.auto_print, s.is_cashier, s.created_at
 		FROM stations s
 		INNER JOIN categories c ON c.station_id = s.id
 		WHERE s.is_active = true AND c.id IN (`
@@ -181,7 +183,7 @@ func (r *StationRepository) GetStationsByCategoryIDs(categoryIDs []uuid.UUID) ([
 	for rows.Next() {
 		var station domain.Station
 		var description sql.NullString
-		if err := rows.Scan(&station.ID, &station.Name, &description, &station.IsActive, &station.CreatedAt); err != nil {
+		if err := rows.Scan(&station.ID, &station.Name, &description, &station.IsActive, &station.AutoPrint, &station.IsCashier, &station.CreatedAt); err != nil {
 			return nil, err
 		}
 		if description.Valid {
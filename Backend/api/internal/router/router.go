@@ -6,11 +6,12 @@ package router
 import (
 	"github.com/Hoxanfox/TurnyChain/Backend/api/internal/handler"
 	"github.com/Hoxanfox/TurnyChain/Backend/api/internal/middleware"
+	"github.com/Hoxanfox/TurnyChain/Backend/api/internal/service"
 	"github.com/gofiber/contrib/websocket"
 	"github.com/gofiber/fiber/v2"
 )
 
-func SetupRoutes(app *fiber.App, authHandler *handler.AuthHandler, userHandler *handler.UserHandler, menuHandler *handler.MenuHandler, orderHandler *handler.OrderHandler, tableHandler *handler.TableHandler, categoryHandler *handler.CategoryHandler, ingredientHandler *handler.IngredientHandler, accompanimentHandler *handler.AccompanimentHandler, wsHandler *handler.WebSocketHandler, stationHandler *handler.StationHandler, printerHandler *handler.PrinterHandler, kitchenTicketHandler *handler.KitchenTicketHandler) {
+func SetupRoutes(app *fiber.App, authHandler *handler.AuthHandler, userHandler *handler.UserHandler, menuHandler *handler.MenuHandler, orderHandler *handler.OrderHandler, tableHandler *handler.TableHandler, categoryHandler *handler.CategoryHandler, ingredientHandler *handler.IngredientHandler, accompanimentHandler *handler.AccompanimentHandler, wsHandler *handler.WebSocketHandler, stationHandler *handler.StationHandler, printerHandler *handler.PrinterHandler, kitchenTicketHandler *handler.KitchenTicketHandler, printJobHandler *handler.PrintJobHandler, kdsHandler *handler.KDSHandler, serviceChargeHandler *handler.ServiceChargeHandler, reportHandler *handler.ReportHandler, promotionHandler *handler.PromotionHandler, receiptHandler *handler.ReceiptHandler, auditHandler *handler.AuditHandler, chainIndexHandler *handler.ChainIndexHandler) {
 	// Ruta pública para WebSockets
 	app.Get("/ws", websocket.New(wsHandler.HandleConnection))
 
@@ -24,6 +25,8 @@ func SetupRoutes(app *fiber.App, authHandler *handler.AuthHandler, userHandler *
 	// A partir de aquí, todas las rutas requieren un token JWT válido.
 	protected := api.Group("/")
 	protected.Use(middleware.Protected())
+	// El auditor puede consultar todo pero no modificar nada
+	protected.Use(middleware.ReadOnlyRole(service.RoleAuditor))
 
 	// Rutas de Usuarios
 	users := protected.Group("/users")
@@ -46,10 +49,25 @@ func SetupRoutes(app *fiber.App, authHandler *handler.AuthHandler, userHandler *
 	orders.Post("/with-payment", orderHandler.CreateOrderWithPayment) // Nueva ruta para orden con pago
 	orders.Get("/", orderHandler.GetOrders)
 	orders.Get("/:id", orderHandler.GetOrderByID)
+	orders.Get("/:id/history", orderHandler.GetOrderHistory)
 	orders.Put("/:id/status", orderHandler.UpdateOrderStatus)
 	orders.Put("/:id/manage", orderHandler.ManageOrder)
+	orders.Post("/:id/cancel", orderHandler.CancelOrder)
+	orders.Post("/:id/void", middleware.RequireRole(service.RoleAdmin), orderHandler.VoidOrder)
+	orders.Get("/:id/cancellation", orderHandler.GetOrderCancellation)
 	orders.Put("/:id/items", orderHandler.UpdateOrderItems)
+	orders.Put("/:id/items/:itemId/status", orderHandler.UpdateOrderItemStatus)
 	orders.Post("/:id/proof", orderHandler.UploadPaymentProof) // Nueva ruta para subir comprobante de pago
+	orders.Get("/:id/payments", orderHandler.GetOrderPayments)
+	orders.Get("/:id/payments/split", orderHandler.SplitOrderEvenly)
+	orders.Post("/:id/payments", orderHandler.AddOrderPayment)
+	orders.Post("/:id/coupon", orderHandler.ApplyCoupon)
+	orders.Get("/:id/discounts", orderHandler.GetOrderDiscounts)
+	orders.Put("/:id/discounts/:discountId/approve", middleware.RequireRole(service.RoleAdmin), orderHandler.ApproveDiscount)
+	orders.Put("/:id/discounts/:discountId/reject", middleware.RequireRole(service.RoleAdmin), orderHandler.RejectDiscount)
+	orders.Get("/:id/notarization", orderHandler.GetOrderNotarization)
+	orders.Post("/:id/notarization/retry", orderHandler.RetryOrderNotarization)
+	orders.Get("/:id/verify", orderHandler.VerifyOrderInvoice)
 
 	// Rutas de Mesas
 	tables := protected.Group("/tables")
@@ -87,6 +105,10 @@ func SetupRoutes(app *fiber.App, authHandler *handler.AuthHandler, userHandler *
 	stations.Delete("/:id", stationHandler.Delete)
 	// Impresoras de una estación
 	stations.Get("/:stationId/printers", printerHandler.GetByStationID)
+	// Pantalla de cocina (KDS) de una estación
+	stations.Get("/:id/queue", kdsHandler.GetQueue)
+	stations.Post("/:id/tickets/:ticketId/bump", kdsHandler.Bump)
+	stations.Put("/:id/orders/:orderId/items/status", orderHandler.UpdateStationItemsStatus)
 
 	// Rutas de Impresoras
 	printers := protected.Group("/printers")
@@ -96,8 +118,55 @@ func SetupRoutes(app *fiber.App, authHandler *handler.AuthHandler, userHandler *
 	printers.Post("/", printerHandler.Create)
 	printers.Put("/:id", printerHandler.Update)
 	printers.Delete("/:id", printerHandler.Delete)
+	printers.Post("/:id/test", printerHandler.TestPrint)
 
 	// Rutas de Tickets de Cocina (anidadas bajo orders)
 	orders.Get("/:orderId/kitchen-tickets/preview", kitchenTicketHandler.GetTicketsPreview)
 	orders.Post("/:orderId/kitchen-tickets/print", kitchenTicketHandler.PrintKitchenTickets)
+	orders.Get("/:orderId/kitchen-tickets/pdf", kitchenTicketHandler.ListTicketPDFs)
+	orders.Get("/:orderId/kitchen-tickets/pdf/:fileName", kitchenTicketHandler.DownloadTicketPDF)
+	orders.Get("/:orderId/print-jobs", printJobHandler.GetByOrderID)
+
+	// Rutas de Recibos (anidadas bajo orders)
+	orders.Get("/:id/receipt", receiptHandler.GetReceipt)
+	orders.Post("/:id/receipt/print", receiptHandler.PrintReceipt)
+
+	// Rutas de la Cola de Impresión
+	printJobs := protected.Group("/print-jobs")
+	printJobs.Get("/", printJobHandler.GetAll)
+	printJobs.Post("/:id/retry", printJobHandler.Retry)
+
+	// Rutas de Cargo por Servicio
+	serviceCharges := protected.Group("/service-charges")
+	serviceCharges.Get("/", serviceChargeHandler.GetAll)
+	serviceCharges.Put("/:orderType", middleware.RequireRole(service.RoleAdmin), serviceChargeHandler.Update)
+
+	// Rutas de Reportes
+	reports := protected.Group("/reports")
+	reports.Get("/waiters", reportHandler.GetWaiterReport)
+	reports.Get("/cancellations", reportHandler.GetCancellationReport)
+
+	// Rutas de Promociones
+	promotions := protected.Group("/promotions")
+	promotions.Get("/", promotionHandler.GetAll)
+	promotions.Get("/:id", promotionHandler.GetByID)
+	promotions.Post("/", middleware.RequireRole(service.RoleAdmin), promotionHandler.Create)
+	promotions.Put("/:id", middleware.RequireRole(service.RoleAdmin), promotionHandler.Update)
+	promotions.Delete("/:id", middleware.RequireRole(service.RoleAdmin), promotionHandler.Delete)
+
+	// Rutas de Auditoría (facturas notarizadas descifradas)
+	audit := protected.Group("/audit")
+	audit.Use(middleware.RequireRole(service.RoleAdmin, service.RoleAuditor))
+	audit.Get("/invoices", auditHandler.ListInvoices)
+	audit.Get("/invoices/export", auditHandler.ExportInvoices)
+	audit.Get("/auditors/:id/key", auditHandler.GetAuditorKey)
+	audit.Put("/auditors/:id/key", auditHandler.SetAuditorKey)
+	audit.Delete("/auditors/:id/key", auditHandler.RevokeAuditorKey)
+
+	// Rutas del índice de eventos de la cadena (facturas vistas desde la cadena)
+	chainIndex := protected.Group("/chain-index")
+	chainIndex.Use(middleware.RequireRole(service.RoleAdmin, service.RoleAuditor))
+	chainIndex.Get("/status", chainIndexHandler.GetStatus)
+	chainIndex.Get("/invoices", chainIndexHandler.ListInvoices)
+	chainIndex.Get("/reconciliation", chainIndexHandler.GetReconciliation)
 }
@@ -10,16 +10,16 @@ import (
 )
 
 type AccompanimentService interface {
-	Create(name string, price float64) (*domain.Accompaniment, error)
+	Create(name string, price domain.Money) (*domain.Accompaniment, error)
 	GetAll() ([]domain.Accompaniment, error)
-	Update(id uuid.UUID, name string, price float64) (*domain.Accompaniment, error)
+	Update(id uuid.UUID, name string, price domain.Money) (*domain.Accompaniment, error)
 	Delete(id uuid.UUID) error
 }
 
 type accompanimentService struct { repo repository.AccompanimentRepository }
 func NewAccompanimentService(repo repository.AccompanimentRepository) AccompanimentService { return &accompanimentService{repo: repo} }
 
-func (s *accompanimentService) Create(name string, price float64) (*domain.Accompaniment, error) { return s.repo.Create(name, price) }
+func (s *accompanimentService) Create(name string, price domain.Money) (*domain.Accompaniment, error) { return s.repo.Create(name, price) }
 func (s *accompanimentService) GetAll() ([]domain.Accompaniment, error) { return s.repo.GetAll() }
-func (s *accompanimentService) Update(id uuid.UUID, name string, price float64) (*domain.Accompaniment, error) { return s.repo.Update(id, name, price) }
+func (s *accompanimentService) Update(id uuid.UUID, name string, price domain.Money) (*domain.Accompaniment, error) { return s.repo.Update(id, name, price) }
 func (s *accompanimentService) Delete(id uuid.UUID) error { return s.repo.Delete(id) }
\ No newline at end of file
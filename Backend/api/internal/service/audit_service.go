@@ -0,0 +1,409 @@
+// =================================================================
+// Audit Service
+// Acceso de auditores a las facturas notarizadas: las lee de la
+// cadena (o de su lote), las descifra y las entrega en claro o
+// cifradas solo para la llave pública del auditor
+// =================================================================
+package service
+
+import (
+	"bytes"
+	"crypto/rsa"
+	"database/sql"
+	"encoding/base64"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Hoxanfox/TurnyChain/Backend/api/internal/domain"
+	"github.com/Hoxanfox/TurnyChain/Backend/api/internal/repository"
+	"github.com/Hoxanfox/TurnyChain/Backend/api/internal/utils"
+	"github.com/google/uuid"
+
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+const (
+	auditDefaultLimit = 100
+	auditMaxLimit     = 500
+	// auditExportMaxRows limita una exportación; rangos más grandes se exportan por partes
+	auditExportMaxRows = 10000
+)
+
+var (
+	// ErrInvalidAuditFormat indica un formato de exportación no soportado
+	ErrInvalidAuditFormat = errors.New("formato inválido (json o csv)")
+	// ErrAuditExportTooLarge indica que el rango tiene más facturas de las que se exportan de una vez
+	ErrAuditExportTooLarge = fmt.Errorf("el rango tiene más de %d facturas: exporta un rango menor", auditExportMaxRows)
+	// ErrAuditorKeyNotFound indica que el auditor no tiene llave pública registrada
+	ErrAuditorKeyNotFound = errors.New("el auditor no tiene llave pública registrada")
+	// ErrAuditorNotFound indica que el usuario no existe, está inactivo o no es auditor
+	ErrAuditorNotFound = errors.New("el usuario no es un auditor activo")
+	// ErrInvalidAuditorKey indica una llave pública que no se pudo leer o es muy débil
+	ErrInvalidAuditorKey = errors.New("llave pública inválida")
+)
+
+type AuditService struct {
+	repo    repository.OrderNotarizationRepository
+	keyRepo repository.AuditorKeyRepository
+	notary  *NotarizationService
+}
+
+func NewAuditService(repo repository.OrderNotarizationRepository, keyRepo repository.AuditorKeyRepository, notary *NotarizationService) *AuditService {
+	return &AuditService{repo: repo, keyRepo: keyRepo, notary: notary}
+}
+
+// auditRecipient es la llave a la que se cifran los envelopes
+type auditRecipient struct {
+	key         *rsa.PublicKey
+	fingerprint string
+}
+
+// ListInvoices devuelve una página de las facturas confirmadas en [from, to). Con
+// envelopeFor cada factura va cifrada para la llave de ese auditor en vez de en claro.
+func (s *AuditService) ListInvoices(from, to time.Time, limit, offset int, envelopeFor *uuid.UUID) (*domain.AuditInvoiceList, error) {
+	if !from.Before(to) {
+		return nil, ErrInvalidReportRange
+	}
+	if limit <= 0 {
+		limit = auditDefaultLimit
+	}
+	if limit > auditMaxLimit {
+		limit = auditMaxLimit
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	recipient, err := s.recipient(envelopeFor)
+	if err != nil {
+		return nil, err
+	}
+	total, err := s.repo.CountConfirmed(from, to)
+	if err != nil {
+		return nil, err
+	}
+	notarizations, err := s.repo.ListConfirmed(from, to, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	invoices, err := s.auditInvoices(notarizations, recipient)
+	if err != nil {
+		return nil, err
+	}
+
+	list := &domain.AuditInvoiceList{From: from, To: to, Total: total, Limit: limit, Offset: offset, Invoices: invoices}
+	if recipient != nil {
+		list.KeyFingerprint = recipient.fingerprint
+	}
+	return list, nil
+}
+
+// ExportInvoices exporta todas las facturas confirmadas en [from, to) en JSON (el mismo
+// listado de ListInvoices) o CSV. Devuelve el contenido, su Content-Type y cuántas facturas lleva.
+func (s *AuditService) ExportInvoices(from, to time.Time, format string, envelopeFor *uuid.UUID) ([]byte, string, int, error) {
+	if format != domain.AuditFormatJSON && format != domain.AuditFormatCSV {
+		return nil, "", 0, ErrInvalidAuditFormat
+	}
+	if !from.Before(to) {
+		return nil, "", 0, ErrInvalidReportRange
+	}
+
+	recipient, err := s.recipient(envelopeFor)
+	if err != nil {
+		return nil, "", 0, err
+	}
+	total, err := s.repo.CountConfirmed(from, to)
+	if err != nil {
+		return nil, "", 0, err
+	}
+	if total > auditExportMaxRows {
+		return nil, "", 0, ErrAuditExportTooLarge
+	}
+	notarizations, err := s.repo.ListConfirmed(from, to, auditExportMaxRows, 0)
+	if err != nil {
+		return nil, "", 0, err
+	}
+	invoices, err := s.auditInvoices(notarizations, recipient)
+	if err != nil {
+		return nil, "", 0, err
+	}
+
+	if format == domain.AuditFormatCSV {
+		content, err := auditInvoicesCSV(invoices)
+		return content, "text/csv; charset=utf-8", len(invoices), err
+	}
+
+	list := &domain.AuditInvoiceList{From: from, To: to, Total: len(invoices), Limit: len(invoices), Invoices: invoices}
+	if recipient != nil {
+		list.KeyFingerprint = recipient.fingerprint
+	}
+	content, err := json.MarshalIndent(list, "", "  ")
+	return content, "application/json", len(invoices), err
+}
+
+// recipient carga la llave del auditor para los envelopes (nil si no se pidieron)
+func (s *AuditService) recipient(auditorID *uuid.UUID) (*auditRecipient, error) {
+	if auditorID == nil {
+		return nil, nil
+	}
+	stored, err := s.keyRepo.GetByUserID(*auditorID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrAuditorKeyNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	key, fingerprint, err := utils.ParsePublicKey(stored.PublicKey)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidAuditorKey, err)
+	}
+	return &auditRecipient{key: key, fingerprint: fingerprint}, nil
+}
+
+func (s *AuditService) auditInvoices(notarizations []domain.OrderNotarization, recipient *auditRecipient) ([]domain.AuditInvoice, error) {
+	events, err := s.chainEvents(notarizations)
+	if err != nil {
+		return nil, err
+	}
+
+	invoices := make([]domain.AuditInvoice, 0, len(notarizations))
+	for i := range notarizations {
+		invoice, err := s.auditInvoice(&notarizations[i], recipient, events)
+		if err != nil {
+			return nil, err
+		}
+		invoices = append(invoices, *invoice)
+	}
+	return invoices, nil
+}
+
+// chainEvents lee de una vez los eventos InvoiceSecured de los bloques en los que se
+// confirmaron las facturas individuales del backend actual, indexados por transacción. Así
+// la página o la exportación no hace una consulta al backend por factura.
+func (s *AuditService) chainEvents(notarizations []domain.OrderNotarization) (map[string]InvoiceEvent, error) {
+	if s.notary == nil || s.notary.blockchain == nil {
+		return nil, nil
+	}
+	backend := s.notary.blockchain.Name()
+
+	blocks := make([]int64, 0, len(notarizations))
+	for _, n := range notarizations {
+		if n.BatchID == nil && n.BlockNumber != nil && n.TxHash != nil && (n.Backend == nil || *n.Backend == backend) {
+			blocks = append(blocks, *n.BlockNumber)
+		}
+	}
+	if len(blocks) == 0 {
+		return nil, nil
+	}
+
+	found, err := s.notary.blockchain.FindInvoicesInBlocks(blocks)
+	if err != nil {
+		return nil, err
+	}
+	events := make(map[string]InvoiceEvent, len(found))
+	for _, event := range found {
+		events[strings.ToLower(event.TxHash)] = event
+	}
+	return events, nil
+}
+
+// auditInvoice lee la factura notarizada: del evento InvoiceSecured de su transacción (de
+// events si ya se leyó) o, si se notarizó en un lote, la guardada con su prueba Merkle. Las
+// facturas que no se pueden leer llevan el motivo en Error; un error consultando el backend
+// corta la consulta.
+func (s *AuditService) auditInvoice(n *domain.OrderNotarization, recipient *auditRecipient, events map[string]InvoiceEvent) (*domain.AuditInvoice, error) {
+	item := &domain.AuditInvoice{
+		OrderID:     n.OrderID,
+		Mode:        "single",
+		InvoiceHash: n.InvoiceHash,
+		ConfirmedAt: n.ConfirmedAt,
+		BatchID:     n.BatchID,
+		MerkleProof: n.MerkleProof,
+	}
+	if n.Backend != nil {
+		item.Backend = *n.Backend
+	}
+	if n.TxHash != nil {
+		item.TxHash = *n.TxHash
+	}
+	if n.BlockNumber != nil {
+		item.BlockNumber = *n.BlockNumber
+	}
+
+	var encryptedData string
+	switch {
+	case n.BatchID != nil:
+		item.Mode = "batch"
+		if n.MerkleRoot != nil {
+			item.MerkleRoot = *n.MerkleRoot
+		}
+		if n.EncryptedInvoice == nil {
+			item.Error = "la orden no tiene la factura del lote"
+			return item, nil
+		}
+		encryptedData = *n.EncryptedInvoice
+	case s.notary == nil || s.notary.blockchain == nil:
+		return nil, ErrBlockchainUnavailable
+	case n.Backend != nil && *n.Backend != s.notary.blockchain.Name():
+		item.Error = fmt.Sprintf("se notarizó con el backend %s (el actual es %s)", *n.Backend, s.notary.blockchain.Name())
+		return item, nil
+	default:
+		event, err := s.invoiceEvent(n, events)
+		if err != nil {
+			return nil, err
+		}
+		if event == nil {
+			item.Error = "no se encontró el evento InvoiceSecured de la orden"
+			return item, nil
+		}
+		item.TxHash = event.TxHash
+		item.BlockNumber = event.BlockNumber
+		encryptedData = event.EncryptedData
+	}
+
+	item.KeyID = utils.CiphertextKeyID(encryptedData)
+	invoice, err := decryptInvoice(encryptedData)
+	if err != nil {
+		item.Error = err.Error()
+		return item, nil
+	}
+	item.PayloadValid = invoice.VerifyHash() && invoice.Hash == n.InvoiceHash
+	if item.Mode == "batch" {
+		proofValid := merkleProofValid(invoice.Hash, n.MerkleProof, item.MerkleRoot)
+		item.ProofValid = &proofValid
+	}
+
+	if recipient == nil {
+		item.Invoice = invoice
+		return item, nil
+	}
+	invoiceJSON, _ := json.Marshal(invoice)
+	encryptedKey, nonce, ciphertext, err := utils.SealEnvelope(recipient.key, invoiceJSON)
+	if err != nil {
+		return nil, fmt.Errorf("no se pudo cifrar la factura para el auditor: %v", err)
+	}
+	item.Envelope = &domain.InvoiceEnvelope{
+		Algorithm:      utils.EnvelopeAlgorithm,
+		KeyFingerprint: recipient.fingerprint,
+		EncryptedKey:   base64.StdEncoding.EncodeToString(encryptedKey),
+		Nonce:          base64.StdEncoding.EncodeToString(nonce),
+		Ciphertext:     base64.StdEncoding.EncodeToString(ciphertext),
+	}
+	return item, nil
+}
+
+// invoiceEvent busca el evento de la transacción guardada entre los ya leídos; si no está
+// (p. ej. el bloque quedó fuera de la cadena por una reorganización) se busca la orden en
+// la cadena
+func (s *AuditService) invoiceEvent(n *domain.OrderNotarization, events map[string]InvoiceEvent) (*InvoiceEvent, error) {
+	if n.TxHash != nil {
+		event, ok := events[strings.ToLower(*n.TxHash)]
+		if ok && strings.EqualFold(event.OrderIDHash, crypto.Keccak256Hash([]byte(n.OrderID.String())).Hex()) {
+			return &event, nil
+		}
+	}
+	_, event, err := s.notary.findInvoiceEvent(n.OrderID, n)
+	return event, err
+}
+
+// auditCSVHeader son las columnas del CSV. Los datos de la factura van vacíos cuando se
+// exporta con envelope.
+var auditCSVHeader = []string{
+	"order_id", "invoice_number", "invoice_timestamp", "table_number", "waiter_name",
+	"subtotal", "discount", "service_charge", "tax", "total", "tip", "items",
+	"invoice_hash", "payload_valid", "mode", "backend", "tx_hash", "block_number", "confirmed_at",
+	"merkle_root", "merkle_proof", "proof_valid", "key_id", "error",
+	"invoice_json", "envelope_key_fingerprint", "envelope_encrypted_key", "envelope_nonce", "envelope_ciphertext",
+}
+
+func auditInvoicesCSV(invoices []domain.AuditInvoice) ([]byte, error) {
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+	if err := writer.Write(auditCSVHeader); err != nil {
+		return nil, err
+	}
+
+	for _, item := range invoices {
+		row := make([]string, 0, len(auditCSVHeader))
+		if inv := item.Invoice; inv != nil {
+			invoiceNumber := ""
+			if inv.InvoiceNumber != nil {
+				invoiceNumber = strconv.FormatInt(*inv.InvoiceNumber, 10)
+			}
+			row = append(row, item.OrderID.String(), invoiceNumber, inv.Timestamp.Format(time.RFC3339),
+				strconv.Itoa(inv.TableNumber), inv.WaiterName, inv.Subtotal.String(), inv.Discount.String(),
+				inv.ServiceCharge.String(), inv.Tax.String(), inv.Total.String(), inv.Tip.String(), strconv.Itoa(len(inv.Items)))
+		} else {
+			row = append(row, item.OrderID.String(), "", "", "", "", "", "", "", "", "", "", "")
+		}
+
+		confirmedAt, proofValid := "", ""
+		if item.ConfirmedAt != nil {
+			confirmedAt = item.ConfirmedAt.Format(time.RFC3339)
+		}
+		if item.ProofValid != nil {
+			proofValid = strconv.FormatBool(*item.ProofValid)
+		}
+		row = append(row, item.InvoiceHash, strconv.FormatBool(item.PayloadValid), item.Mode, item.Backend, item.TxHash,
+			strconv.FormatInt(item.BlockNumber, 10), confirmedAt, item.MerkleRoot, strings.Join(item.MerkleProof, ";"),
+			proofValid, item.KeyID, item.Error)
+
+		invoiceJSON := ""
+		if item.Invoice != nil {
+			data, _ := json.Marshal(item.Invoice)
+			invoiceJSON = string(data)
+		}
+		if env := item.Envelope; env != nil {
+			row = append(row, invoiceJSON, env.KeyFingerprint, env.EncryptedKey, env.Nonce, env.Ciphertext)
+		} else {
+			row = append(row, invoiceJSON, "", "", "", "")
+		}
+
+		if err := writer.Write(row); err != nil {
+			return nil, err
+		}
+	}
+	writer.Flush()
+	return buf.Bytes(), writer.Error()
+}
+
+// SetAuditorKey registra (o reemplaza) la llave pública de un auditor
+func (s *AuditService) SetAuditorKey(auditorID, grantedBy uuid.UUID, publicKey string) (*domain.AuditorKey, error) {
+	_, fingerprint, err := utils.ParsePublicKey(strings.TrimSpace(publicKey))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidAuditorKey, err)
+	}
+	key, err := s.keyRepo.Upsert(&domain.AuditorKey{
+		UserID:      auditorID,
+		PublicKey:   strings.TrimSpace(publicKey),
+		Fingerprint: fingerprint,
+		GrantedBy:   &grantedBy,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrAuditorNotFound
+	}
+	return key, err
+}
+
+// GetAuditorKey devuelve la llave pública registrada del auditor
+func (s *AuditService) GetAuditorKey(auditorID uuid.UUID) (*domain.AuditorKey, error) {
+	key, err := s.keyRepo.GetByUserID(auditorID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrAuditorKeyNotFound
+	}
+	return key, err
+}
+
+// RevokeAuditorKey borra la llave del auditor: deja de recibir envelopes
+func (s *AuditService) RevokeAuditorKey(auditorID uuid.UUID) error {
+	err := s.keyRepo.Delete(auditorID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrAuditorKeyNotFound
+	}
+	return err
+}
@@ -4,14 +4,18 @@ import (
 	"context"
 	"crypto/ecdsa"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"math/big"
+	"sort"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/Hoxanfox/TurnyChain/Backend/api/internal/domain"
 	"github.com/Hoxanfox/TurnyChain/Backend/api/internal/utils"
+	"github.com/google/uuid"
 
 	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/accounts/abi"
@@ -21,26 +25,94 @@ import (
 	"github.com/ethereum/go-ethereum/ethclient"
 )
 
-// ABI mínimo del contrato para la función 'notarize'
-const contractABIJSON = `[{"inputs":[{"internalType":"string","name":"_orderId","type":"string"},{"internalType":"string","name":"_encryptedData","type":"string"}],"name":"notarize","outputs":[],"stateMutability":"nonpayable","type":"function"}]`
+// ABI mínimo del contrato: las funciones 'notarize' y 'anchorBatch' y sus eventos
+// 'InvoiceSecured' y 'BatchAnchored'
+const contractABIJSON = `[{"inputs":[{"internalType":"bytes32","name":"_merkleRoot","type":"bytes32"},{"internalType":"uint256","name":"_batchSize","type":"uint256"}],"name":"anchorBatch","outputs":[],"stateMutability":"nonpayable","type":"function"},{"anonymous":false,"inputs":[{"indexed":true,"internalType":"bytes32","name":"merkleRoot","type":"bytes32"},{"indexed":false,"internalType":"uint256","name":"batchSize","type":"uint256"},{"indexed":false,"internalType":"uint256","name":"timestamp","type":"uint256"}],"name":"BatchAnchored","type":"event"},{"inputs":[{"internalType":"string","name":"_orderId","type":"string"},{"internalType":"string","name":"_encryptedData","type":"string"}],"name":"notarize","outputs":[],"stateMutability":"nonpayable","type":"function"},{"anonymous":false,"inputs":[{"indexed":true,"internalType":"string","name":"orderId","type":"string"},{"indexed":false,"internalType":"uint256","name":"timestamp","type":"uint256"},{"indexed":false,"internalType":"string","name":"encryptedData","type":"string"}],"name":"InvoiceSecured","type":"event"}]`
 
+// BlockchainService es el backend donde se notarizan las facturas (ver notary_backend.go):
+// Ethereum (contrato InvoiceNotary), un log local encadenado por hashes o una cadena en
+// memoria. En los backends locales "transacción" y "bloque" son la entrada del log.
+// replace es la transacción anterior que no se minó: el reenvío la reemplaza con su mismo
+// nonce (nil: transacción nueva).
 type BlockchainService interface {
-	NotarizeOrder(order *domain.Order) (string, error)
+	Name() string
+	NotarizeOrder(order *domain.Order, replace *NotarizationTx) (*NotarizationTx, error)
+	TransactionReceipt(txHash string) (*TxReceipt, error)
+	FindInvoices(orderID uuid.UUID, blockNumber *int64) ([]InvoiceEvent, error)
+	FindInvoicesInBlocks(blockNumbers []int64) ([]InvoiceEvent, error)
+	AnchorBatch(merkleRoot string, size int, replace *NotarizationTx) (*NotarizationTx, error)
+	FindBatchAnchors(merkleRoot string, blockNumber *int64) ([]BatchAnchorEvent, error)
+}
+
+// EthClient son las operaciones del nodo que usa el servicio. La cumplen tanto
+// *ethclient.Client como el backend simulado de go-ethereum (ethclient/simulated),
+// con el que se puede probar la notarización y la verificación sin un nodo real.
+type EthClient interface {
+	ethereum.ChainReader
+	ethereum.ChainIDReader
+	ethereum.ChainStateReader
+	ethereum.PendingStateReader
+	ethereum.GasPricer1559
+	ethereum.GasEstimator
+	ethereum.TransactionSender
+	ethereum.TransactionReader
+	ethereum.LogFilterer
+}
+
+// NotarizationTx es la transacción enviada para notarizar una factura
+type NotarizationTx struct {
+	Hash  string
+	Nonce uint64
+}
+
+// TxReceipt es el resultado de una transacción minada
+type TxReceipt struct {
+	BlockNumber int64
+	Success     bool // false: la transacción se revirtió
+}
+
+// InvoiceEvent es un evento InvoiceSecured emitido por el contrato para una orden
+type InvoiceEvent struct {
+	TxHash        string
+	BlockNumber   int64
+	Timestamp     time.Time // block.timestamp registrado por el contrato
+	EncryptedData string
+	OrderIDHash   string // keccak256 del ID de la orden (topic indexado)
+}
+
+// BatchAnchorEvent es un evento BatchAnchored emitido por el contrato para una raíz Merkle
+type BatchAnchorEvent struct {
+	TxHash      string
+	BlockNumber int64
+	Timestamp   time.Time // block.timestamp registrado por el contrato
+	BatchSize   int64
 }
 
 type blockchainService struct {
-	client          *ethclient.Client
+	client          EthClient
 	privateKey      *ecdsa.PrivateKey
 	contractAddress common.Address
 	parsedABI       abi.ABI
 	chainID         *big.Int
+
+	// Nonces de la wallet: las transacciones se firman y envían de a una para que dos
+	// notarizaciones simultáneas no usen el mismo nonce
+	nonceMu   sync.Mutex
+	nextNonce *uint64 // nil: se consulta a la red en el siguiente envío
 }
 
-func NewBlockchainService(rpcURL, privateKeyHex, contractAddr string) BlockchainService {
+// NewBlockchainService conecta el backend Ethereum con el RPC, la wallet y el contrato dados
+func NewBlockchainService(rpcURL, privateKeyHex, contractAddr string) (BlockchainService, error) {
+	if rpcURL == "" {
+		return nil, fmt.Errorf("falta BLOCKCHAIN_RPC_URL")
+	}
+	if !common.IsHexAddress(contractAddr) {
+		return nil, fmt.Errorf("CONTRACT_ADDRESS inválida: %q", contractAddr)
+	}
+
 	client, err := ethclient.Dial(rpcURL)
 	if err != nil {
-		log.Printf("⚠️ No se pudo conectar a Blockchain (RPC: %s): %v", rpcURL, err)
-		return nil
+		return nil, fmt.Errorf("no se pudo conectar a Blockchain (RPC: %s): %v", rpcURL, err)
 	}
 
 	if strings.HasPrefix(privateKeyHex, "0x") {
@@ -49,36 +121,52 @@ func NewBlockchainService(rpcURL, privateKeyHex, contractAddr string) Blockchain
 
 	privateKey, err := crypto.HexToECDSA(privateKeyHex)
 	if err != nil {
-		log.Printf("⚠️ Error cargando llave privada: %v", err)
-		return nil
+		return nil, fmt.Errorf("error cargando llave privada: %v", err)
 	}
 
-	parsedABI, _ := abi.JSON(strings.NewReader(contractABIJSON))
+	service, err := NewBlockchainServiceWithClient(client, privateKey, common.HexToAddress(contractAddr))
+	if err != nil {
+		return nil, err
+	}
+
+	log.Println("✅ Servicio Blockchain conectado exitosamente")
+	return service, nil
+}
+
+// NewBlockchainServiceWithClient crea el servicio sobre un cliente ya conectado, p. ej. el
+// backend simulado de go-ethereum con el contrato desplegado en contractAddress
+func NewBlockchainServiceWithClient(client EthClient, privateKey *ecdsa.PrivateKey, contractAddress common.Address) (BlockchainService, error) {
+	parsedABI, err := abi.JSON(strings.NewReader(contractABIJSON))
+	if err != nil {
+		return nil, fmt.Errorf("ABI del contrato inválido: %v", err)
+	}
 
 	// Obtenemos el ChainID (Anvil suele ser 31337)
-	chainID, err := client.NetworkID(context.Background())
+	chainID, err := client.ChainID(context.Background())
 	if err != nil {
-		log.Printf("⚠️ Error obteniendo NetworkID: %v", err)
-		return nil
+		return nil, fmt.Errorf("error obteniendo ChainID: %v", err)
 	}
 
-	log.Println("✅ Servicio Blockchain conectado exitosamente")
 	return &blockchainService{
 		client:          client,
 		privateKey:      privateKey,
-		contractAddress: common.HexToAddress(contractAddr),
+		contractAddress: contractAddress,
 		parsedABI:       parsedABI,
 		chainID:         chainID,
-	}
+	}, nil
 }
 
-func (s *blockchainService) NotarizeOrder(order *domain.Order) (string, error) {
+func (s *blockchainService) Name() string {
+	return NotaryBackendEthereum
+}
+
+// NotarizeOrder envía la factura de la orden al contrato y devuelve la transacción sin
+// esperar a que se mine
+func (s *blockchainService) NotarizeOrder(order *domain.Order, replace *NotarizationTx) (*NotarizationTx, error) {
 	if s == nil || s.client == nil {
-		return "", fmt.Errorf("servicio blockchain no disponible")
+		return nil, fmt.Errorf("servicio blockchain no disponible")
 	}
 
-	ctx := context.Background()
-
 	// 1. Crear factura optimizada para blockchain (solo datos esenciales)
 	invoice := domain.CreateBlockchainInvoice(order)
 
@@ -86,7 +174,7 @@ func (s *blockchainService) NotarizeOrder(order *domain.Order) (string, error) {
 	invoiceJSON, _ := json.Marshal(invoice)
 	encryptedData, err := utils.Encrypt(string(invoiceJSON))
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
 	// Log del tamaño de la factura
@@ -96,25 +184,62 @@ func (s *blockchainService) NotarizeOrder(order *domain.Order) (string, error) {
 	// 3. Empaquetar datos para el contrato
 	data, err := s.parsedABI.Pack("notarize", order.ID.String(), encryptedData)
 	if err != nil {
-		return "", err
+		return nil, err
+	}
+
+	tx, err := s.sendContractTx(data, replace)
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("✅ Factura Mesa %d enviada a blockchain. Hash: %s (nonce %d)", order.TableNumber, tx.Hash, tx.Nonce)
+	return tx, nil
+}
+
+// AnchorBatch envía la raíz Merkle de un lote de facturas al contrato y devuelve la
+// transacción sin esperar a que se mine
+func (s *blockchainService) AnchorBatch(merkleRoot string, size int, replace *NotarizationTx) (*NotarizationTx, error) {
+	if s == nil || s.client == nil {
+		return nil, fmt.Errorf("servicio blockchain no disponible")
+	}
+
+	data, err := s.parsedABI.Pack("anchorBatch", common.HexToHash(merkleRoot), big.NewInt(int64(size)))
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := s.sendContractTx(data, replace)
+	if err != nil {
+		return nil, err
 	}
+	log.Printf("✅ Lote de %d facturas anclado en blockchain. Raíz: %s | Hash: %s (nonce %d)", size, merkleRoot, tx.Hash, tx.Nonce)
+	return tx, nil
+}
+
+// sendContractTx firma y envía una llamada al contrato con gas dinámico (EIP-1559). Con
+// replace reutiliza el nonce de esa transacción si aún no se usó, con comisiones más altas
+// para que la red acepte el reemplazo.
+func (s *blockchainService) sendContractTx(data []byte, replace *NotarizationTx) (*NotarizationTx, error) {
+	ctx := context.Background()
 
 	// 4. Obtener dirección del remitente
 	publicKey := s.privateKey.Public()
 	publicKeyECDSA, _ := publicKey.(*ecdsa.PublicKey)
 	fromAddress := crypto.PubkeyToAddress(*publicKeyECDSA)
 
-	// 5. Obtener nonce
-	nonce, err := s.client.PendingNonceAt(ctx, fromAddress)
+	// 5. Reservar el nonce (se libera al terminar el envío)
+	s.nonceMu.Lock()
+	defer s.nonceMu.Unlock()
+
+	nonce, replacing, err := s.replacementNonce(ctx, fromAddress, replace)
 	if err != nil {
-		return "", fmt.Errorf("error obteniendo nonce: %v", err)
+		return nil, fmt.Errorf("error obteniendo nonce: %v", err)
 	}
 
 	// 6. CALCULAR PRECIO DEL GAS DINÁMICAMENTE (EIP-1559)
 	// Obtener BaseFee del último bloque
 	head, err := s.client.HeaderByNumber(ctx, nil)
 	if err != nil {
-		return "", fmt.Errorf("error obteniendo header del bloque: %v", err)
+		return nil, fmt.Errorf("error obteniendo header del bloque: %v", err)
 	}
 
 	baseFee := head.BaseFee
@@ -126,7 +251,7 @@ func (s *blockchainService) NotarizeOrder(order *domain.Order) (string, error) {
 	// Obtener tip sugerido (propina para el minero)
 	tipCap, err := s.client.SuggestGasTipCap(ctx)
 	if err != nil {
-		return "", fmt.Errorf("error obteniendo gas tip: %v", err)
+		return nil, fmt.Errorf("error obteniendo gas tip: %v", err)
 	}
 
 	// Calcular MaxFeePerGas = (BaseFee * 2) + Tip
@@ -136,6 +261,11 @@ func (s *blockchainService) NotarizeOrder(order *domain.Order) (string, error) {
 		tipCap,
 	)
 
+	if replacing {
+		tipCap, maxFeePerGas = s.replacementFees(ctx, replace.Hash, tipCap, maxFeePerGas)
+		log.Printf("🔁 Reemplazando la transacción %s (nonce %d)", replace.Hash, nonce)
+	}
+
 	log.Printf("⛽ BaseFee: %s | Tip: %s | MaxFee: %s",
 		baseFee.String(), tipCap.String(), maxFeePerGas.String())
 
@@ -151,7 +281,7 @@ func (s *blockchainService) NotarizeOrder(order *domain.Order) (string, error) {
 
 	estimatedGas, err := s.client.EstimateGas(ctx, msg)
 	if err != nil {
-		return "", fmt.Errorf("falló la estimación de gas (posiblemente la factura es muy grande o hay error en contrato): %v", err)
+		return nil, fmt.Errorf("falló la estimación de gas (posiblemente la factura es muy grande o hay error en contrato): %v", err)
 	}
 
 	// Agregar 20% de margen de seguridad al gas estimado
@@ -175,7 +305,7 @@ func (s *blockchainService) NotarizeOrder(order *domain.Order) (string, error) {
 	// 9. Firmar transacción
 	signedTx, err := types.SignTx(tx, types.LatestSignerForChainID(s.chainID), s.privateKey)
 	if err != nil {
-		return "", fmt.Errorf("error firmando transacción: %v", err)
+		return nil, fmt.Errorf("error firmando transacción: %v", err)
 	}
 
 	// 10. Enviar transacción con timeout
@@ -184,10 +314,411 @@ func (s *blockchainService) NotarizeOrder(order *domain.Order) (string, error) {
 
 	err = s.client.SendTransaction(sendCtx, signedTx)
 	if err != nil {
-		return "", fmt.Errorf("❌ Error Blockchain: %v", err)
+		// El nonce local puede haber quedado desfasado (p. ej. "nonce too low"): se vuelve a consultar
+		s.nextNonce = nil
+		return nil, fmt.Errorf("❌ Error Blockchain: %v", err)
+	}
+	if !replacing {
+		next := nonce + 1
+		s.nextNonce = &next
+	}
+
+	return &NotarizationTx{Hash: signedTx.Hash().Hex(), Nonce: nonce}, nil
+}
+
+// replacementFeeBump es cuánto (en %) suben las comisiones al reemplazar una transacción;
+// los nodos exigen al menos un 10% más que la transacción pendiente
+const replacementFeeBump = 15
+
+// replacementNonce decide el nonce del envío. Si la transacción a reemplazar sigue sin
+// minarse (la red aún no usó su nonce) se reutiliza su nonce; si ya se minó o se reemplazó
+// se toma uno nuevo. Se llama con nonceMu tomado.
+func (s *blockchainService) replacementNonce(ctx context.Context, from common.Address, replace *NotarizationTx) (uint64, bool, error) {
+	if replace != nil {
+		mined, err := s.client.NonceAt(ctx, from, nil)
+		if err != nil {
+			return 0, false, err
+		}
+		if replace.Nonce >= mined {
+			return replace.Nonce, true, nil
+		}
+		log.Printf("🔁 El nonce %d de la transacción %s ya se usó: se envía con un nonce nuevo", replace.Nonce, replace.Hash)
+	}
+	nonce, err := s.takeNonce(ctx, from)
+	return nonce, false, err
+}
+
+// replacementFees sube las comisiones sugeridas para que superen en replacementFeeBump% a
+// las de la transacción pendiente. Si el nodo ya no la tiene se usan las sugeridas.
+func (s *blockchainService) replacementFees(ctx context.Context, txHash string, tipCap, maxFeePerGas *big.Int) (*big.Int, *big.Int) {
+	pending, _, err := s.client.TransactionByHash(ctx, common.HexToHash(txHash))
+	if err != nil {
+		return tipCap, maxFeePerGas
+	}
+	bump := func(fee *big.Int) *big.Int {
+		bumped := new(big.Int).Mul(fee, big.NewInt(100+replacementFeeBump))
+		return bumped.Div(bumped, big.NewInt(100))
+	}
+	if minTip := bump(pending.GasTipCap()); tipCap.Cmp(minTip) < 0 {
+		tipCap = minTip
+	}
+	if minFee := bump(pending.GasFeeCap()); maxFeePerGas.Cmp(minFee) < 0 {
+		maxFeePerGas = minFee
+	}
+	if maxFeePerGas.Cmp(tipCap) < 0 {
+		maxFeePerGas = new(big.Int).Set(tipCap)
+	}
+	return tipCap, maxFeePerGas
+}
+
+// takeNonce devuelve el nonce de la siguiente transacción. Usa el contador local y lo
+// sincroniza con la red cuando no lo conoce o la red va por delante (transacciones
+// enviadas desde fuera del backend). Se llama con nonceMu tomado.
+func (s *blockchainService) takeNonce(ctx context.Context, from common.Address) (uint64, error) {
+	pending, err := s.client.PendingNonceAt(ctx, from)
+	if err != nil {
+		return 0, err
+	}
+	if s.nextNonce == nil || pending > *s.nextNonce {
+		return pending, nil
+	}
+	return *s.nextNonce, nil
+}
+
+// TransactionReceipt consulta el resultado de una transacción. Devuelve nil (sin error)
+// si todavía no se ha minado.
+func (s *blockchainService) TransactionReceipt(txHash string) (*TxReceipt, error) {
+	if s == nil || s.client == nil {
+		return nil, fmt.Errorf("servicio blockchain no disponible")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	receipt, err := s.client.TransactionReceipt(ctx, common.HexToHash(txHash))
+	if errors.Is(err, ethereum.NotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &TxReceipt{
+		BlockNumber: receipt.BlockNumber.Int64(),
+		Success:     receipt.Status == types.ReceiptStatusSuccessful,
+	}, nil
+}
+
+// FindInvoices busca en los logs del contrato los eventos InvoiceSecured de la orden, del
+// más antiguo al más reciente. Con blockNumber solo se revisa ese bloque; sin él, toda la
+// cadena. Una orden puede tener más de un evento si una transacción reenviada se minó
+// junto a la anterior.
+func (s *blockchainService) FindInvoices(orderID uuid.UUID, blockNumber *int64) ([]InvoiceEvent, error) {
+	if s == nil || s.client == nil {
+		return nil, fmt.Errorf("servicio blockchain no disponible")
 	}
 
-	txHash := signedTx.Hash().Hex()
-	log.Printf("✅ Factura Mesa %d notarizada. Hash: %s", order.TableNumber, txHash)
-	return txHash, nil
+	// Los string indexados se guardan en el topic como su keccak256
+	logs, err := s.filterEvents("InvoiceSecured", crypto.Keccak256Hash([]byte(orderID.String())), blockNumber)
+	if err != nil {
+		return nil, err
+	}
+
+	events := make([]InvoiceEvent, 0, len(logs))
+	for _, vLog := range logs {
+		var data struct {
+			Timestamp     *big.Int
+			EncryptedData string
+		}
+		if err := s.parsedABI.UnpackIntoInterface(&data, "InvoiceSecured", vLog.Data); err != nil {
+			return nil, fmt.Errorf("evento InvoiceSecured inválido en la transacción %s: %v", vLog.TxHash.Hex(), err)
+		}
+		events = append(events, InvoiceEvent{
+			TxHash:        vLog.TxHash.Hex(),
+			BlockNumber:   int64(vLog.BlockNumber),
+			Timestamp:     time.Unix(data.Timestamp.Int64(), 0).UTC(),
+			EncryptedData: data.EncryptedData,
+			OrderIDHash:   vLog.Topics[1].Hex(),
+		})
+	}
+	return events, nil
+}
+
+// maxLogBlockRange es el rango máximo de bloques de una consulta de logs (los proveedores
+// RPC suelen limitarlo)
+const maxLogBlockRange = 2000
+
+// FindInvoicesInBlocks busca los eventos InvoiceSecured de todas las órdenes en los bloques
+// indicados. Los bloques cercanos se consultan juntos, con una consulta por tramo, en vez de
+// una por orden.
+func (s *blockchainService) FindInvoicesInBlocks(blockNumbers []int64) ([]InvoiceEvent, error) {
+	if s == nil || s.client == nil {
+		return nil, fmt.Errorf("servicio blockchain no disponible")
+	}
+
+	events := make([]InvoiceEvent, 0, len(blockNumbers))
+	for _, r := range blockRanges(blockNumbers, maxLogBlockRange) {
+		logs, err := s.filterLogs(ethereum.FilterQuery{
+			Addresses: []common.Address{s.contractAddress},
+			Topics:    [][]common.Hash{{s.parsedABI.Events["InvoiceSecured"].ID}},
+			FromBlock: big.NewInt(r[0]),
+			ToBlock:   big.NewInt(r[1]),
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, vLog := range logs {
+			if len(vLog.Topics) < 2 {
+				continue
+			}
+			var data struct {
+				Timestamp     *big.Int
+				EncryptedData string
+			}
+			if err := s.parsedABI.UnpackIntoInterface(&data, "InvoiceSecured", vLog.Data); err != nil {
+				return nil, fmt.Errorf("evento InvoiceSecured inválido en la transacción %s: %v", vLog.TxHash.Hex(), err)
+			}
+			events = append(events, InvoiceEvent{
+				TxHash:        vLog.TxHash.Hex(),
+				BlockNumber:   int64(vLog.BlockNumber),
+				Timestamp:     time.Unix(data.Timestamp.Int64(), 0).UTC(),
+				EncryptedData: data.EncryptedData,
+				OrderIDHash:   vLog.Topics[1].Hex(),
+			})
+		}
+	}
+	return events, nil
+}
+
+// blockRanges agrupa los bloques en tramos [desde, hasta] de a lo sumo maxRange bloques
+func blockRanges(blockNumbers []int64, maxRange int64) [][2]int64 {
+	sorted := append([]int64(nil), blockNumbers...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	ranges := make([][2]int64, 0)
+	for _, block := range sorted {
+		if n := len(ranges); n > 0 && block-ranges[n-1][0] < maxRange {
+			if block > ranges[n-1][1] {
+				ranges[n-1][1] = block
+			}
+			continue
+		}
+		ranges = append(ranges, [2]int64{block, block})
+	}
+	return ranges
+}
+
+// FindBatchAnchors busca los eventos BatchAnchored de una raíz Merkle, del más antiguo al
+// más reciente. Con blockNumber solo se revisa ese bloque.
+func (s *blockchainService) FindBatchAnchors(merkleRoot string, blockNumber *int64) ([]BatchAnchorEvent, error) {
+	if s == nil || s.client == nil {
+		return nil, fmt.Errorf("servicio blockchain no disponible")
+	}
+
+	logs, err := s.filterEvents("BatchAnchored", common.HexToHash(merkleRoot), blockNumber)
+	if err != nil {
+		return nil, err
+	}
+
+	events := make([]BatchAnchorEvent, 0, len(logs))
+	for _, vLog := range logs {
+		var data struct {
+			BatchSize *big.Int
+			Timestamp *big.Int
+		}
+		if err := s.parsedABI.UnpackIntoInterface(&data, "BatchAnchored", vLog.Data); err != nil {
+			return nil, fmt.Errorf("evento BatchAnchored inválido en la transacción %s: %v", vLog.TxHash.Hex(), err)
+		}
+		events = append(events, BatchAnchorEvent{
+			TxHash:      vLog.TxHash.Hex(),
+			BlockNumber: int64(vLog.BlockNumber),
+			Timestamp:   time.Unix(data.Timestamp.Int64(), 0).UTC(),
+			BatchSize:   data.BatchSize.Int64(),
+		})
+	}
+	return events, nil
+}
+
+// SourceID identifica la red y el contrato indexados: otro contrato es otro índice
+func (s *blockchainService) SourceID() string {
+	return NotaryBackendEthereum + ":" + strings.ToLower(s.contractAddress.Hex())
+}
+
+// LatestBlock devuelve el número del último bloque de la cadena
+func (s *blockchainService) LatestBlock() (int64, error) {
+	if s == nil || s.client == nil {
+		return 0, fmt.Errorf("servicio blockchain no disponible")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	header, err := s.client.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("error consultando el último bloque: %v", err)
+	}
+	return header.Number.Int64(), nil
+}
+
+// BlockHash devuelve el hash del bloque, o "" si la cadena no llega a ese bloque
+func (s *blockchainService) BlockHash(number int64) (string, error) {
+	if s == nil || s.client == nil {
+		return "", fmt.Errorf("servicio blockchain no disponible")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	header, err := s.client.HeaderByNumber(ctx, big.NewInt(number))
+	if errors.Is(err, ethereum.NotFound) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("error consultando el bloque %d: %v", number, err)
+	}
+	return header.Hash().Hex(), nil
+}
+
+// InvoiceEventsInRange devuelve todos los eventos InvoiceSecured entre los bloques from y
+// to (inclusivos). El topic solo guarda el keccak256 del ID de la orden, así que la orden se
+// obtiene descifrando la factura o, si no hay clave, de los argumentos de la transacción;
+// en ambos casos se comprueba contra el topic.
+func (s *blockchainService) InvoiceEventsInRange(from, to int64) ([]domain.ChainInvoiceEvent, error) {
+	if s == nil || s.client == nil {
+		return nil, fmt.Errorf("servicio blockchain no disponible")
+	}
+
+	logs, err := s.filterLogs(ethereum.FilterQuery{
+		Addresses: []common.Address{s.contractAddress},
+		Topics:    [][]common.Hash{{s.parsedABI.Events["InvoiceSecured"].ID}},
+		FromBlock: big.NewInt(from),
+		ToBlock:   big.NewInt(to),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	events := make([]domain.ChainInvoiceEvent, 0, len(logs))
+	for _, vLog := range logs {
+		if len(vLog.Topics) < 2 {
+			continue
+		}
+		var data struct {
+			Timestamp     *big.Int
+			EncryptedData string
+		}
+		if err := s.parsedABI.UnpackIntoInterface(&data, "InvoiceSecured", vLog.Data); err != nil {
+			return nil, fmt.Errorf("evento InvoiceSecured inválido en la transacción %s: %v", vLog.TxHash.Hex(), err)
+		}
+
+		orderIDHash := vLog.Topics[1]
+		orderID := invoiceOrderID(data.EncryptedData, orderIDHash)
+		if orderID == nil {
+			if orderID, err = s.notarizedOrderID(vLog.TxHash, orderIDHash); err != nil {
+				return nil, err
+			}
+		}
+
+		events = append(events, domain.ChainInvoiceEvent{
+			OrderID:        orderID,
+			OrderIDHash:    orderIDHash.Hex(),
+			TxHash:         vLog.TxHash.Hex(),
+			LogIndex:       int(vLog.Index),
+			BlockNumber:    int64(vLog.BlockNumber),
+			BlockHash:      vLog.BlockHash.Hex(),
+			BlockTimestamp: time.Unix(data.Timestamp.Int64(), 0).UTC(),
+		})
+	}
+	return events, nil
+}
+
+// notarizedOrderID lee el ID de la orden de los argumentos de la llamada a notarize que
+// emitió el evento. Devuelve nil si la transacción no es una llamada directa a notarize
+// (p. ej. pasó por otro contrato) o el ID no corresponde al topic.
+func (s *blockchainService) notarizedOrderID(txHash common.Hash, orderIDHash common.Hash) (*uuid.UUID, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	tx, _, err := s.client.TransactionByHash(ctx, txHash)
+	if errors.Is(err, ethereum.NotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error consultando la transacción %s: %v", txHash.Hex(), err)
+	}
+
+	input := tx.Data()
+	if len(input) < 4 {
+		return nil, nil
+	}
+	method, err := s.parsedABI.MethodById(input[:4])
+	if err != nil || method.Name != "notarize" {
+		return nil, nil
+	}
+	args, err := method.Inputs.Unpack(input[4:])
+	if err != nil || len(args) == 0 {
+		return nil, nil
+	}
+	value, _ := args[0].(string)
+	return matchOrderID(value, orderIDHash), nil
+}
+
+// invoiceOrderID descifra la factura del evento y devuelve su orden si corresponde al topic.
+// Devuelve nil si no se puede descifrar (p. ej. la clave ya no está configurada).
+func invoiceOrderID(encryptedData string, orderIDHash common.Hash) *uuid.UUID {
+	decrypted, err := utils.Decrypt(encryptedData)
+	if err != nil {
+		return nil
+	}
+	var invoice domain.BlockchainInvoice
+	if err := json.Unmarshal([]byte(decrypted), &invoice); err != nil {
+		return nil
+	}
+	return matchOrderID(invoice.OrderID.String(), orderIDHash)
+}
+
+// matchOrderID devuelve el ID si es un UUID y su keccak256 es el topic del evento
+func matchOrderID(value string, orderIDHash common.Hash) *uuid.UUID {
+	if crypto.Keccak256Hash([]byte(value)) != orderIDHash {
+		return nil
+	}
+	orderID, err := uuid.Parse(value)
+	if err != nil {
+		return nil
+	}
+	return &orderID
+}
+
+// filterEvents devuelve los logs del contrato con el evento dado y el primer topic indexado.
+// Con blockNumber solo se revisa ese bloque; sin él, toda la cadena.
+func (s *blockchainService) filterEvents(eventName string, topic common.Hash, blockNumber *int64) ([]types.Log, error) {
+	query := ethereum.FilterQuery{
+		Addresses: []common.Address{s.contractAddress},
+		Topics:    [][]common.Hash{{s.parsedABI.Events[eventName].ID}, {topic}},
+	}
+	if blockNumber != nil {
+		query.FromBlock = big.NewInt(*blockNumber)
+		query.ToBlock = big.NewInt(*blockNumber)
+	} else {
+		query.FromBlock = big.NewInt(0)
+	}
+	return s.filterLogs(query)
+}
+
+// filterLogs ejecuta la consulta de logs y omite los revertidos por una reorganización
+func (s *blockchainService) filterLogs(query ethereum.FilterQuery) ([]types.Log, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	logs, err := s.client.FilterLogs(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("error consultando los eventos del contrato: %v", err)
+	}
+
+	valid := make([]types.Log, 0, len(logs))
+	for _, vLog := range logs {
+		if !vLog.Removed {
+			valid = append(valid, vLog)
+		}
+	}
+	return valid, nil
 }
@@ -11,16 +11,16 @@ import (
 )
 
 type CategoryService interface {
-	Create(name string, stationID *uuid.UUID) (*domain.Category, error)
+	Create(name string, stationID *uuid.UUID, taxRate domain.Percent) (*domain.Category, error)
 	GetAll() ([]domain.Category, error)
-	Update(id uuid.UUID, name string, stationID *uuid.UUID) (*domain.Category, error)
+	Update(id uuid.UUID, name string, stationID *uuid.UUID, taxRate *domain.Percent) (*domain.Category, error)
 	Delete(id uuid.UUID) error
 }
 
 type categoryService struct { repo repository.CategoryRepository }
 func NewCategoryService(repo repository.CategoryRepository) CategoryService { return &categoryService{repo: repo} }
 
-func (s *categoryService) Create(name string, stationID *uuid.UUID) (*domain.Category, error) { return s.repo.Create(name, stationID) }
+func (s *categoryService) Create(name string, stationID *uuid.UUID, taxRate domain.Percent) (*domain.Category, error) { return s.repo.Create(name, stationID, taxRate) }
 func (s *categoryService) GetAll() ([]domain.Category, error) { return s.repo.GetAll() }
-func (s *categoryService) Update(id uuid.UUID, name string, stationID *uuid.UUID) (*domain.Category, error) { return s.repo.Update(id, name, stationID) }
+func (s *categoryService) Update(id uuid.UUID, name string, stationID *uuid.UUID, taxRate *domain.Percent) (*domain.Category, error) { return s.repo.Update(id, name, stationID, taxRate) }
 func (s *categoryService) Delete(id uuid.UUID) error { return s.repo.Delete(id) }
\ No newline at end of file
@@ -0,0 +1,397 @@
+// =================================================================
+// Chain Indexer Service
+// Sigue los eventos InvoiceSecured del backend de notarización desde
+// un bloque inicial y los guarda en chain_invoice_events. Tolera
+// reorganizaciones: si el último bloque indexado ya no está en la
+// cadena descarta los eventos recientes y los vuelve a indexar. Con
+// el índice compara las órdenes pagadas con lo registrado en la cadena
+// =================================================================
+package service
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/Hoxanfox/TurnyChain/Backend/api/internal/domain"
+	"github.com/Hoxanfox/TurnyChain/Backend/api/internal/repository"
+)
+
+const (
+	chainIndexerPollPeriod   = 15 * time.Second
+	chainIndexerReorgDepth   = 64 // Bloques que se vuelven a indexar al detectar una reorganización
+	DefaultChainIndexerRange = 2000
+
+	chainIndexDefaultLimit = 100
+	chainIndexMaxLimit     = 500
+)
+
+// ErrChainIndexerDisabled indica que el indexador de eventos no está activo
+var ErrChainIndexerDisabled = errors.New("el indexador de eventos de la cadena está desactivado")
+
+// InvoiceEventSource es la parte de un backend de notarización que permite indexar sus
+// eventos InvoiceSecured por rangos de bloques. La cumplen el backend Ethereum y el log
+// encadenado (donde cada entrada es un bloque).
+type InvoiceEventSource interface {
+	SourceID() string
+	LatestBlock() (int64, error)
+	BlockHash(number int64) (string, error) // "" si la cadena no llega a ese bloque
+	InvoiceEventsInRange(from, to int64) ([]domain.ChainInvoiceEvent, error)
+}
+
+type ChainIndexerService struct {
+	repo       repository.ChainIndexRepository
+	source     InvoiceEventSource
+	backend    string
+	startBlock int64
+	blockRange int64
+
+	// Avance en memoria para /status
+	mu        sync.Mutex
+	headBlock *int64
+	reorgs    int
+	lastError string
+	lastRunAt *time.Time
+}
+
+// NewChainIndexerService crea el indexador del backend dado. Indexa desde startBlock en
+// rangos de hasta blockRange bloques. Devuelve error si el backend no expone sus eventos.
+func NewChainIndexerService(repo repository.ChainIndexRepository, blockchain BlockchainService, startBlock, blockRange int64) (*ChainIndexerService, error) {
+	source, ok := blockchain.(InvoiceEventSource)
+	if !ok {
+		return nil, fmt.Errorf("el backend de notarización %s no permite indexar eventos", blockchain.Name())
+	}
+	if startBlock < 0 {
+		return nil, fmt.Errorf("bloque inicial inválido: %d", startBlock)
+	}
+	if blockRange <= 0 {
+		blockRange = DefaultChainIndexerRange
+	}
+	return &ChainIndexerService{
+		repo:       repo,
+		source:     source,
+		backend:    blockchain.Name(),
+		startBlock: startBlock,
+		blockRange: blockRange,
+	}, nil
+}
+
+// Run indexa la cadena en segundo plano. Se debe ejecutar en una goroutine. Mientras esté
+// atrasado indexa un rango tras otro sin esperar; al alcanzar la cabeza consulta cada
+// chainIndexerPollPeriod.
+func (s *ChainIndexerService) Run() {
+	log.Printf("🔭 [Indexer] Indexando eventos InvoiceSecured de %s desde el bloque %d", s.source.SourceID(), s.startBlock)
+
+	ticker := time.NewTicker(chainIndexerPollPeriod)
+	defer ticker.Stop()
+
+	for {
+		caughtUp, err := s.indexNext()
+		if s.recordRun(err) {
+			log.Printf("⚠️ [Indexer] Error indexando %s: %v", s.source.SourceID(), err)
+		}
+		if err == nil && !caughtUp {
+			continue
+		}
+		<-ticker.C
+	}
+}
+
+// recordRun guarda el resultado de la última pasada. Devuelve true si es un error nuevo,
+// para no repetir el mismo mensaje en cada consulta mientras el nodo no responda.
+func (s *ChainIndexerService) recordRun(err error) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	s.lastRunAt = &now
+	if err == nil {
+		s.lastError = ""
+		return false
+	}
+	isNew := s.lastError != err.Error()
+	s.lastError = err.Error()
+	return isNew
+}
+
+// indexNext indexa el siguiente rango de bloques. Devuelve true si ya alcanzó la cabeza de
+// la cadena.
+func (s *ChainIndexerService) indexNext() (bool, error) {
+	sourceID := s.source.SourceID()
+
+	head, err := s.source.LatestBlock()
+	if err != nil {
+		return false, err
+	}
+	s.mu.Lock()
+	s.headBlock = &head
+	s.mu.Unlock()
+
+	last := s.startBlock - 1
+	var lastHash string
+	cursor, err := s.repo.GetCursor(sourceID)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		// Primera pasada: se indexa desde el bloque inicial
+		if lastHash, err = s.blockHash(last); err != nil {
+			return false, err
+		}
+	case err != nil:
+		return false, err
+	default:
+		last, lastHash = cursor.LastBlock, cursor.LastBlockHash
+		if ok, err := s.onChain(last, lastHash); err != nil || !ok {
+			if err != nil {
+				return false, err
+			}
+			return false, s.rewind(last, head)
+		}
+	}
+
+	if last >= head {
+		return true, nil
+	}
+	from := last + 1
+	to := from + s.blockRange - 1
+	if to > head {
+		to = head
+	}
+
+	toHash, err := s.blockHash(to)
+	if err != nil {
+		return false, err
+	}
+	events, err := s.source.InvoiceEventsInRange(from, to)
+	if err != nil {
+		return false, err
+	}
+	// Si el rango cambió mientras se leían los eventos se repite en la siguiente pasada
+	if ok, err := s.onChain(to, toHash); err != nil || !ok {
+		return false, err
+	}
+	if ok, err := s.onChain(last, lastHash); err != nil || !ok {
+		return false, err
+	}
+
+	if err := s.repo.SaveRange(sourceID, events, to, toHash); err != nil {
+		return false, err
+	}
+	if len(events) > 0 {
+		log.Printf("🔭 [Indexer] %d eventos InvoiceSecured indexados en los bloques %d a %d", len(events), from, to)
+	}
+	return to >= head, nil
+}
+
+// rewind descarta los eventos de los últimos chainIndexerReorgDepth bloques indexados y
+// mueve el cursor antes de ellos. Si el último evento que se conserva tampoco está en la
+// cadena (reorganización más profunda o cadena reiniciada) se reindexa desde el bloque
+// inicial.
+func (s *ChainIndexerService) rewind(last, head int64) error {
+	sourceID := s.source.SourceID()
+
+	target := last - chainIndexerReorgDepth
+	if target > head {
+		target = head
+	}
+	if target < s.startBlock-1 {
+		target = s.startBlock - 1
+	}
+
+	kept, err := s.repo.LastEventAtOrBefore(sourceID, target)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+	case err != nil:
+		return err
+	default:
+		ok, err := s.onChain(kept.BlockNumber, kept.BlockHash)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			target = s.startBlock - 1
+		}
+	}
+
+	targetHash, err := s.blockHash(target)
+	if err != nil {
+		return err
+	}
+	deleted, err := s.repo.Rewind(sourceID, target, targetHash)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	s.reorgs++
+	s.mu.Unlock()
+	log.Printf("🔀 [Indexer] El bloque %d ya no está en la cadena %s: se reindexa desde el bloque %d (%d eventos descartados)", last, sourceID, target+1, deleted)
+	return nil
+}
+
+// blockHash devuelve el hash del bloque. Antes del primer bloque no hay hash.
+func (s *ChainIndexerService) blockHash(number int64) (string, error) {
+	if number < 0 {
+		return "", nil
+	}
+	return s.source.BlockHash(number)
+}
+
+// onChain indica si el bloque sigue en la cadena con el hash dado
+func (s *ChainIndexerService) onChain(number int64, hash string) (bool, error) {
+	current, err := s.blockHash(number)
+	if err != nil {
+		return false, err
+	}
+	return current == hash, nil
+}
+
+// Status devuelve el avance del indexador
+func (s *ChainIndexerService) Status() (*domain.ChainIndexerStatus, error) {
+	if s == nil {
+		return nil, ErrChainIndexerDisabled
+	}
+	sourceID := s.source.SourceID()
+	status := &domain.ChainIndexerStatus{Source: sourceID, StartBlock: s.startBlock}
+
+	cursor, err := s.repo.GetCursor(sourceID)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+	case err != nil:
+		return nil, err
+	default:
+		status.LastBlock = &cursor.LastBlock
+		status.LastBlockHash = cursor.LastBlockHash
+	}
+
+	if status.Events, err = s.repo.CountEvents(sourceID); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	status.HeadBlock = s.headBlock
+	status.Reorgs = s.reorgs
+	status.LastError = s.lastError
+	status.LastRunAt = s.lastRunAt
+
+	if s.headBlock != nil {
+		last := s.startBlock - 1
+		if status.LastBlock != nil {
+			last = *status.LastBlock
+		}
+		lag := *s.headBlock - last
+		if lag < 0 {
+			lag = 0
+		}
+		status.Lag = &lag
+	}
+	return status, nil
+}
+
+// ListEvents devuelve una página de los eventos indexados, del más reciente al más antiguo
+func (s *ChainIndexerService) ListEvents(limit, offset int) (*domain.ChainInvoiceEventList, error) {
+	if s == nil {
+		return nil, ErrChainIndexerDisabled
+	}
+	limit, offset = chainIndexPage(limit, offset)
+	sourceID := s.source.SourceID()
+
+	total, err := s.repo.CountEvents(sourceID)
+	if err != nil {
+		return nil, err
+	}
+	events, err := s.repo.ListEvents(sourceID, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	return &domain.ChainInvoiceEventList{Total: total, Limit: limit, Offset: offset, Events: events}, nil
+}
+
+// Reconciliation compara las órdenes pagadas con los eventos indexados: órdenes pagadas sin
+// evento y eventos cuya orden no existe o no está pagada, cada una con su motivo. Las
+// facturas ancladas en lotes no emiten InvoiceSecured y no se comparan.
+func (s *ChainIndexerService) Reconciliation(limit int) (*domain.ChainReconciliation, error) {
+	if s == nil {
+		return nil, ErrChainIndexerDisabled
+	}
+	limit, _ = chainIndexPage(limit, 0)
+	sourceID := s.source.SourceID()
+
+	result := &domain.ChainReconciliation{Source: sourceID, GeneratedAt: time.Now()}
+	cursor, err := s.repo.GetCursor(sourceID)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+	case err != nil:
+		return nil, err
+	default:
+		result.LastBlock = &cursor.LastBlock
+	}
+
+	if result.PaidWithoutRecordTotal, err = s.repo.CountPaidWithoutEvent(sourceID); err != nil {
+		return nil, err
+	}
+	orders, err := s.repo.ListPaidWithoutEvent(sourceID, limit)
+	if err != nil {
+		return nil, err
+	}
+	for i := range orders {
+		orders[i].Reason = s.paidWithoutRecordReason(&orders[i], result.LastBlock)
+	}
+	result.PaidWithoutRecord = orders
+
+	if result.RecordsWithoutPaidOrderTotal, err = s.repo.CountEventsWithoutPaidOrder(sourceID); err != nil {
+		return nil, err
+	}
+	events, err := s.repo.ListEventsWithoutPaidOrder(sourceID, limit)
+	if err != nil {
+		return nil, err
+	}
+	result.RecordsWithoutPaidOrder = make([]domain.ReconciliationEvent, 0, len(events))
+	for _, event := range events {
+		reason := domain.ReconcileOrderNotPaid
+		switch {
+		case event.OrderID == nil:
+			reason = domain.ReconcileUnknownOrder
+		case event.OrderStatus == nil:
+			reason = domain.ReconcileOrderNotFound
+		}
+		result.RecordsWithoutPaidOrder = append(result.RecordsWithoutPaidOrder, domain.ReconciliationEvent{ChainInvoiceEvent: event, Reason: reason})
+	}
+	return result, nil
+}
+
+// paidWithoutRecordReason explica por qué una orden pagada no tiene evento indexado
+func (s *ChainIndexerService) paidWithoutRecordReason(order *domain.ReconciliationOrder, lastBlock *int64) string {
+	var status domain.NotarizationStatus
+	if order.NotarizationStatus != nil {
+		status = *order.NotarizationStatus
+	}
+	switch {
+	case status == domain.NotarizationSent:
+		return domain.ReconcileNotIndexed
+	case status != domain.NotarizationConfirmed:
+		return domain.ReconcileNotNotarized
+	case order.Backend != nil && *order.Backend != s.backend:
+		return domain.ReconcileOtherBackend
+	case order.BlockNumber == nil || lastBlock == nil || *order.BlockNumber > *lastBlock:
+		return domain.ReconcileNotIndexed
+	}
+	return domain.ReconcileMissing
+}
+
+// chainIndexPage normaliza la paginación de las consultas del índice
+func chainIndexPage(limit, offset int) (int, int) {
+	if limit <= 0 {
+		limit = chainIndexDefaultLimit
+	}
+	if limit > chainIndexMaxLimit {
+		limit = chainIndexMaxLimit
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	return limit, offset
+}
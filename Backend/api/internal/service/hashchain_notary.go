@@ -0,0 +1,443 @@
+// =================================================================
+// Hash Chain Notary
+// Backend de notarización sin blockchain: un log append-only en el
+// que cada entrada guarda el hash de la anterior, así cualquier
+// modificación, borrado o reordenamiento rompe la cadena. Con ruta
+// se persiste en un archivo JSON Lines; sin ruta vive en memoria
+// (cadena simulada para pruebas)
+// =================================================================
+package service
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Hoxanfox/TurnyChain/Backend/api/internal/domain"
+	"github.com/Hoxanfox/TurnyChain/Backend/api/internal/utils"
+	"github.com/google/uuid"
+
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+const (
+	chainEntryInvoice = "invoice" // Equivale a InvoiceSecured
+	chainEntryBatch   = "batch"   // Equivale a BatchAnchored
+
+	chainGenesisHash = "0000000000000000000000000000000000000000000000000000000000000000"
+)
+
+// chainEntry es una entrada del log. Hash es el SHA-256 de la entrada con Hash vacío,
+// que incluye PrevHash.
+type chainEntry struct {
+	Index         int64     `json:"index"` // Empieza en 1; hace de número de bloque y de nonce
+	Kind          string    `json:"kind"`
+	OrderID       string    `json:"order_id,omitempty"`
+	EncryptedData string    `json:"encrypted_data,omitempty"`
+	MerkleRoot    string    `json:"merkle_root,omitempty"`
+	BatchSize     int64     `json:"batch_size,omitempty"`
+	Timestamp     time.Time `json:"timestamp"`
+	PrevHash      string    `json:"prev_hash"`
+	Hash          string    `json:"hash"`
+}
+
+func (e chainEntry) calculateHash() string {
+	e.Hash = ""
+	data, _ := json.Marshal(e)
+	hash := sha256.Sum256(data)
+	return hex.EncodeToString(hash[:])
+}
+
+// txHash es el identificador de la entrada con el formato de un hash de transacción
+func (e chainEntry) txHash() string {
+	return "0x" + e.Hash
+}
+
+// invoiceEvent es la entrada de factura con el formato de un evento InvoiceSecured
+func (e chainEntry) invoiceEvent() InvoiceEvent {
+	return InvoiceEvent{
+		TxHash:        e.txHash(),
+		BlockNumber:   e.Index,
+		Timestamp:     e.Timestamp,
+		EncryptedData: e.EncryptedData,
+		OrderIDHash:   crypto.Keccak256Hash([]byte(e.OrderID)).Hex(),
+	}
+}
+
+type hashChainNotary struct {
+	name    string
+	path    string // Vacía: cadena en memoria
+	mu      sync.Mutex
+	entries []chainEntry
+
+	// Estado del archivo la última vez que se verificó completo (o se escribió): mientras
+	// no cambie basta con comprobar la última entrada
+	size       int64
+	modTime    time.Time
+	headOffset int64 // Posición de la última entrada en el archivo
+}
+
+// NewFileNotary abre (o crea) el log encadenado en path y verifica la cadena completa. Si
+// alguna entrada fue alterada devuelve error: no se agregan facturas a una cadena rota.
+func NewFileNotary(path string) (BlockchainService, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("no se pudo crear el directorio del log de notarización: %v", err)
+	}
+	info, _ := os.Stat(path)
+	entries, headOffset, err := readChainFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	head := chainGenesisHash
+	if len(entries) > 0 {
+		head = entries[len(entries)-1].Hash
+	}
+	log.Printf("🔗 Log de notarización %s verificado: %d entradas, cabeza %s", path, len(entries), head)
+	s := &hashChainNotary{name: NotaryBackendFile, path: path, entries: entries, headOffset: headOffset}
+	s.rememberFile(info)
+	return s, nil
+}
+
+// NewMemoryNotary crea una cadena simulada en memoria: notariza y verifica igual que los
+// demás backends, pero se pierde al reiniciar. Pensada para pruebas y desarrollo.
+func NewMemoryNotary() BlockchainService {
+	return &hashChainNotary{name: NotaryBackendMemory}
+}
+
+func (s *hashChainNotary) Name() string {
+	return s.name
+}
+
+// NotarizeOrder agrega la factura cifrada de la orden a la cadena. Las entradas se confirman
+// al escribirse, así que no hay transacciones pendientes que reemplazar (replace se ignora).
+func (s *hashChainNotary) NotarizeOrder(order *domain.Order, replace *NotarizationTx) (*NotarizationTx, error) {
+	invoice := domain.CreateBlockchainInvoice(order)
+	invoiceJSON, _ := json.Marshal(invoice)
+	encryptedData, err := utils.Encrypt(string(invoiceJSON))
+	if err != nil {
+		return nil, err
+	}
+
+	entry, err := s.append(chainEntry{Kind: chainEntryInvoice, OrderID: order.ID.String(), EncryptedData: encryptedData})
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("✅ Factura Mesa %d registrada en el log %s. Hash: %s (entrada %d)", order.TableNumber, s.name, entry.txHash(), entry.Index)
+	return &NotarizationTx{Hash: entry.txHash(), Nonce: uint64(entry.Index)}, nil
+}
+
+// AnchorBatch agrega la raíz Merkle de un lote a la cadena
+func (s *hashChainNotary) AnchorBatch(merkleRoot string, size int, replace *NotarizationTx) (*NotarizationTx, error) {
+	entry, err := s.append(chainEntry{Kind: chainEntryBatch, MerkleRoot: strings.ToLower(merkleRoot), BatchSize: int64(size)})
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("✅ Lote de %d facturas registrado en el log %s. Raíz: %s | Hash: %s", size, s.name, merkleRoot, entry.txHash())
+	return &NotarizationTx{Hash: entry.txHash(), Nonce: uint64(entry.Index)}, nil
+}
+
+// TransactionReceipt devuelve la entrada como un recibo exitoso: se confirma al escribirse.
+// Devuelve nil si la entrada no existe (p. ej. se envió con otro backend).
+func (s *hashChainNotary) TransactionReceipt(txHash string) (*TxReceipt, error) {
+	entries, err := s.verifiedEntries()
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range entries {
+		if strings.EqualFold(entry.txHash(), txHash) {
+			return &TxReceipt{BlockNumber: entry.Index, Success: true}, nil
+		}
+	}
+	return nil, nil
+}
+
+// FindInvoices busca las entradas de la orden tras verificar la cadena
+func (s *hashChainNotary) FindInvoices(orderID uuid.UUID, blockNumber *int64) ([]InvoiceEvent, error) {
+	entries, err := s.verifiedEntries()
+	if err != nil {
+		return nil, err
+	}
+	events := make([]InvoiceEvent, 0)
+	for _, entry := range entries {
+		if entry.Kind != chainEntryInvoice || entry.OrderID != orderID.String() {
+			continue
+		}
+		if blockNumber != nil && entry.Index != *blockNumber {
+			continue
+		}
+		events = append(events, entry.invoiceEvent())
+	}
+	return events, nil
+}
+
+// FindInvoicesInBlocks devuelve las facturas de las entradas indicadas con una sola
+// verificación de la cadena
+func (s *hashChainNotary) FindInvoicesInBlocks(blockNumbers []int64) ([]InvoiceEvent, error) {
+	entries, err := s.verifiedEntries()
+	if err != nil {
+		return nil, err
+	}
+	events := make([]InvoiceEvent, 0, len(blockNumbers))
+	seen := make(map[int64]bool, len(blockNumbers))
+	for _, number := range blockNumbers {
+		if number < 1 || number > int64(len(entries)) || seen[number] {
+			continue
+		}
+		seen[number] = true
+		if entry := entries[number-1]; entry.Kind == chainEntryInvoice {
+			events = append(events, entry.invoiceEvent())
+		}
+	}
+	return events, nil
+}
+
+// FindBatchAnchors busca las entradas de la raíz Merkle tras verificar la cadena
+func (s *hashChainNotary) FindBatchAnchors(merkleRoot string, blockNumber *int64) ([]BatchAnchorEvent, error) {
+	entries, err := s.verifiedEntries()
+	if err != nil {
+		return nil, err
+	}
+	events := make([]BatchAnchorEvent, 0)
+	for _, entry := range entries {
+		if entry.Kind != chainEntryBatch || !strings.EqualFold(entry.MerkleRoot, merkleRoot) {
+			continue
+		}
+		if blockNumber != nil && entry.Index != *blockNumber {
+			continue
+		}
+		events = append(events, BatchAnchorEvent{
+			TxHash:      entry.txHash(),
+			BlockNumber: entry.Index,
+			Timestamp:   entry.Timestamp,
+			BatchSize:   entry.BatchSize,
+		})
+	}
+	return events, nil
+}
+
+// SourceID identifica el log indexado: el nombre del backend
+func (s *hashChainNotary) SourceID() string {
+	return s.name
+}
+
+// LatestBlock devuelve el índice de la última entrada
+func (s *hashChainNotary) LatestBlock() (int64, error) {
+	entries, err := s.verifiedEntries()
+	if err != nil {
+		return 0, err
+	}
+	return int64(len(entries)), nil
+}
+
+// BlockHash devuelve el hash de la entrada con ese índice, o "" si no existe
+func (s *hashChainNotary) BlockHash(number int64) (string, error) {
+	entries, err := s.verifiedEntries()
+	if err != nil {
+		return "", err
+	}
+	if number < 1 || number > int64(len(entries)) {
+		return "", nil
+	}
+	return entries[number-1].txHash(), nil
+}
+
+// InvoiceEventsInRange devuelve las facturas registradas en las entradas from a to
+// (inclusivas) tras verificar la cadena. Cada entrada es su propio bloque y transacción.
+func (s *hashChainNotary) InvoiceEventsInRange(from, to int64) ([]domain.ChainInvoiceEvent, error) {
+	entries, err := s.verifiedEntries()
+	if err != nil {
+		return nil, err
+	}
+	events := make([]domain.ChainInvoiceEvent, 0)
+	for _, entry := range entries {
+		if entry.Kind != chainEntryInvoice || entry.Index < from || entry.Index > to {
+			continue
+		}
+		event := domain.ChainInvoiceEvent{
+			OrderIDHash:    crypto.Keccak256Hash([]byte(entry.OrderID)).Hex(),
+			TxHash:         entry.txHash(),
+			BlockNumber:    entry.Index,
+			BlockHash:      entry.txHash(),
+			BlockTimestamp: entry.Timestamp,
+		}
+		if orderID, err := uuid.Parse(entry.OrderID); err == nil {
+			event.OrderID = &orderID
+		}
+		events = append(events, event)
+	}
+	return events, nil
+}
+
+// append encadena la entrada a la cabeza actual y la escribe (con fsync) antes de darla
+// por registrada
+func (s *hashChainNotary) append(entry chainEntry) (*chainEntry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry.Index = int64(len(s.entries)) + 1
+	entry.PrevHash = chainGenesisHash
+	if len(s.entries) > 0 {
+		entry.PrevHash = s.entries[len(s.entries)-1].Hash
+	}
+	entry.Timestamp = time.Now().UTC()
+	entry.Hash = entry.calculateHash()
+
+	if s.path != "" {
+		// Si el archivo cambió desde la última verificación, el estado nuevo no se da por
+		// verificado: la siguiente lectura verifica la cadena completa
+		verified := s.fileUnchanged()
+		line, _ := json.Marshal(entry)
+		file, err := os.OpenFile(s.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+		if err != nil {
+			return nil, fmt.Errorf("no se pudo abrir el log de notarización: %v", err)
+		}
+		defer file.Close()
+		if _, err := file.Write(append(line, '\n')); err != nil {
+			return nil, fmt.Errorf("no se pudo escribir en el log de notarización: %v", err)
+		}
+		if err := file.Sync(); err != nil {
+			return nil, fmt.Errorf("no se pudo guardar el log de notarización: %v", err)
+		}
+		if info, err := file.Stat(); err == nil && verified {
+			s.headOffset = info.Size() - int64(len(line)) - 1
+			s.rememberFile(info)
+		} else {
+			s.size = -1
+		}
+	}
+
+	s.entries = append(s.entries, entry)
+	return &entry, nil
+}
+
+// verifiedEntries devuelve las entradas de la cadena. Con archivo comprueba que contiene
+// todas las entradas escritas desde el arranque: así un archivo editado, truncado o
+// reemplazado se detecta al verificar una factura. Si el tamaño, la fecha de modificación y
+// la última entrada siguen como en la última verificación se usan las entradas ya
+// verificadas; si no, se vuelve a leer y verificar la cadena completa.
+func (s *hashChainNotary) verifiedEntries() ([]chainEntry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.path == "" {
+		return append([]chainEntry(nil), s.entries...), nil
+	}
+
+	if s.fileUnchanged() && s.headUnchanged() {
+		return append([]chainEntry(nil), s.entries...), nil
+	}
+
+	info, _ := os.Stat(s.path)
+	entries, headOffset, err := readChainFile(s.path)
+	if err != nil {
+		return nil, err
+	}
+	if len(entries) < len(s.entries) {
+		return nil, fmt.Errorf("el log de notarización perdió entradas: tiene %d y se escribieron %d", len(entries), len(s.entries))
+	}
+	if n := len(s.entries); n > 0 && entries[n-1].Hash != s.entries[n-1].Hash {
+		return nil, fmt.Errorf("el log de notarización fue reemplazado: la entrada %d no coincide", n)
+	}
+
+	s.entries = entries
+	s.headOffset = headOffset
+	s.rememberFile(info)
+	return append([]chainEntry(nil), entries...), nil
+}
+
+// rememberFile guarda el tamaño y la fecha de modificación del archivo verificado
+func (s *hashChainNotary) rememberFile(info os.FileInfo) {
+	if info == nil {
+		s.size, s.modTime = 0, time.Time{}
+		return
+	}
+	s.size, s.modTime = info.Size(), info.ModTime()
+}
+
+// fileUnchanged indica si el tamaño y la fecha de modificación del archivo siguen como en
+// la última verificación
+func (s *hashChainNotary) fileUnchanged() bool {
+	info, err := os.Stat(s.path)
+	if os.IsNotExist(err) {
+		return s.size == 0
+	}
+	return err == nil && info.Size() == s.size && info.ModTime().Equal(s.modTime)
+}
+
+// headUnchanged lee solo la última entrada del archivo y comprueba que sigue siendo la
+// cabeza verificada
+func (s *hashChainNotary) headUnchanged() bool {
+	if len(s.entries) == 0 {
+		return s.size == 0
+	}
+	if s.headOffset >= s.size {
+		return false
+	}
+	file, err := os.Open(s.path)
+	if err != nil {
+		return false
+	}
+	defer file.Close()
+
+	line := make([]byte, s.size-s.headOffset)
+	if _, err := file.ReadAt(line, s.headOffset); err != nil {
+		return false
+	}
+	var entry chainEntry
+	if err := json.Unmarshal(line, &entry); err != nil {
+		return false
+	}
+	head := s.entries[len(s.entries)-1]
+	return entry.Hash == head.Hash && entry.calculateHash() == head.Hash
+}
+
+// readChainFile lee el log y verifica cada eslabón: índice consecutivo, hash de la entrada
+// anterior y hash de la propia entrada. Devuelve también la posición de la última entrada.
+// Un archivo inexistente es una cadena vacía.
+func readChainFile(path string) ([]chainEntry, int64, error) {
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return []chainEntry{}, 0, nil
+	}
+	if err != nil {
+		return nil, 0, fmt.Errorf("no se pudo abrir el log de notarización: %v", err)
+	}
+	defer file.Close()
+
+	entries := make([]chainEntry, 0)
+	prevHash := chainGenesisHash
+	var offset, headOffset int64
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		var entry chainEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return nil, 0, fmt.Errorf("log de notarización alterado: línea %d ilegible: %v", line, err)
+		}
+		switch {
+		case entry.Index != int64(len(entries))+1:
+			return nil, 0, fmt.Errorf("log de notarización alterado: la línea %d tiene el índice %d", line, entry.Index)
+		case entry.PrevHash != prevHash:
+			return nil, 0, fmt.Errorf("log de notarización alterado: la entrada %d no apunta a la anterior", entry.Index)
+		case entry.Hash != entry.calculateHash():
+			return nil, 0, fmt.Errorf("log de notarización alterado: el hash de la entrada %d no corresponde a su contenido", entry.Index)
+		}
+		entries = append(entries, entry)
+		prevHash = entry.Hash
+		headOffset = offset
+		offset += int64(len(scanner.Bytes())) + 1
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, 0, fmt.Errorf("no se pudo leer el log de notarización: %v", err)
+	}
+	return entries, headOffset, nil
+}
@@ -0,0 +1,97 @@
+package service
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestFileNotaryDetectsEdits(t *testing.T) {
+	setTestKeyRing(t)
+	path := filepath.Join(t.TempDir(), "notary-chain.jsonl")
+
+	notary, err := NewFileNotary(path)
+	if err != nil {
+		t.Fatalf("NewFileNotary: %v", err)
+	}
+	first, second := paidTestOrder(), paidTestOrder()
+	if _, err := notary.NotarizeOrder(first, nil); err != nil {
+		t.Fatalf("NotarizeOrder: %v", err)
+	}
+	if _, err := notary.NotarizeOrder(second, nil); err != nil {
+		t.Fatalf("NotarizeOrder: %v", err)
+	}
+
+	// Consultas repetidas sin cambios en el archivo usan la cadena ya verificada
+	for i := 0; i < 2; i++ {
+		events, err := notary.FindInvoices(first.ID, nil)
+		if err != nil {
+			t.Fatalf("FindInvoices: %v", err)
+		}
+		if len(events) != 1 || events[0].BlockNumber != 1 {
+			t.Fatalf("eventos = %+v, se esperaba la entrada 1", events)
+		}
+	}
+
+	// Reabrir el archivo verifica la cadena completa
+	if _, err := NewFileNotary(path); err != nil {
+		t.Fatalf("NewFileNotary al reabrir: %v", err)
+	}
+
+	// Se altera la primera entrada sin cambiar el tamaño del archivo
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	target := []byte(first.ID.String())
+	tampered := bytes.Replace(data, target, append([]byte{flipHex(target[0])}, target[1:]...), 1)
+	if bytes.Equal(tampered, data) || len(tampered) != len(data) {
+		t.Fatal("no se pudo alterar el log para la prueba")
+	}
+	if err := os.WriteFile(path, tampered, 0644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+	// Asegurar que la fecha de modificación cambie aunque el sistema de archivos tenga poca resolución
+	later := time.Now().Add(time.Second)
+	if err := os.Chtimes(path, later, later); err != nil {
+		t.Fatalf("Chtimes: %v", err)
+	}
+
+	if _, err := notary.FindInvoices(second.ID, nil); err == nil {
+		t.Error("se esperaba un error al leer un log alterado")
+	}
+	if _, err := NewFileNotary(path); err == nil {
+		t.Error("se esperaba un error al abrir un log alterado")
+	}
+}
+
+func TestFileNotaryDetectsTruncation(t *testing.T) {
+	setTestKeyRing(t)
+	path := filepath.Join(t.TempDir(), "notary-chain.jsonl")
+
+	notary, err := NewFileNotary(path)
+	if err != nil {
+		t.Fatalf("NewFileNotary: %v", err)
+	}
+	order := paidTestOrder()
+	if _, err := notary.NotarizeOrder(order, nil); err != nil {
+		t.Fatalf("NotarizeOrder: %v", err)
+	}
+	if err := os.WriteFile(path, nil, 0644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	if _, err := notary.FindInvoices(order.ID, nil); err == nil {
+		t.Error("se esperaba un error al leer un log que perdió entradas")
+	}
+}
+
+// flipHex cambia un dígito hexadecimal por otro distinto
+func flipHex(c byte) byte {
+	if c == 'a' {
+		return 'b'
+	}
+	return 'a'
+}
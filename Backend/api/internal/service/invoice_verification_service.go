@@ -0,0 +1,241 @@
+// =================================================================
+// Invoice Verification
+// Lee de los logs del contrato InvoiceNotary la factura notarizada
+// de una orden (o la raíz Merkle de su lote) y la compara con la
+// orden guardada hoy en la BD
+// =================================================================
+package service
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/Hoxanfox/TurnyChain/Backend/api/internal/domain"
+	"github.com/Hoxanfox/TurnyChain/Backend/api/internal/utils"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrBlockchainUnavailable indica que no hay backend de notarización para leer la factura
+	ErrBlockchainUnavailable = errors.New("servicio blockchain no disponible")
+	// ErrNotaryBackendMismatch indica que la orden se notarizó con otro backend que el configurado
+	ErrNotaryBackendMismatch = errors.New("la orden se notarizó con otro backend")
+)
+
+// Verify busca el evento InvoiceSecured de la orden, descifra la factura notarizada y la
+// compara con la que se obtiene hoy de la orden con CreateBlockchainInvoice. Si la orden
+// tiene varios eventos se verifica el de su transacción confirmada o, en su defecto, el
+// más reciente. Si la orden se notarizó en un lote se verifica con su prueba Merkle.
+func (s *NotarizationService) Verify(orderID uuid.UUID) (*domain.InvoiceVerification, error) {
+	order, err := s.orderRepo.GetOrderByID(orderID)
+	if err != nil {
+		return nil, err
+	}
+	if s.blockchain == nil {
+		return nil, ErrBlockchainUnavailable
+	}
+
+	current := domain.CreateBlockchainInvoice(order)
+	result := &domain.InvoiceVerification{
+		OrderID:     orderID,
+		Status:      domain.VerificationNotNotarized,
+		Backend:     s.blockchain.Name(),
+		Mode:        "single",
+		CurrentHash: current.Hash,
+		VerifiedAt:  time.Now(),
+	}
+
+	// El bloque de la notarización confirmada acota la búsqueda en los logs
+	notarization, err := s.repo.GetByOrderID(orderID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, err
+	}
+	if notarization != nil && notarization.Backend != nil && *notarization.Backend != s.blockchain.Name() {
+		return nil, fmt.Errorf("%w: %s (el actual es %s)", ErrNotaryBackendMismatch, *notarization.Backend, s.blockchain.Name())
+	}
+	if notarization != nil && notarization.BatchID != nil {
+		return s.verifyBatched(notarization, current, result)
+	}
+
+	events, event, err := s.findInvoiceEvent(orderID, notarization)
+	if err != nil {
+		return nil, err
+	}
+	result.Events = len(events)
+	if event == nil {
+		return result, nil
+	}
+	result.TxHash = event.TxHash
+	result.BlockNumber = event.BlockNumber
+	result.NotarizedAt = &event.Timestamp
+
+	result.KeyID = utils.CiphertextKeyID(event.EncryptedData)
+	notarized, err := decryptInvoice(event.EncryptedData)
+	if err != nil {
+		result.Status = domain.VerificationInvalid
+		result.Error = err.Error()
+		log.Printf("⚠️ [Verify] No se pudo leer la factura notarizada de la orden %s (tx %s): %v", orderID, event.TxHash, err)
+		return result, nil
+	}
+	compareInvoices(result, notarized, current)
+	return result, nil
+}
+
+// findInvoiceEvent busca los eventos InvoiceSecured de la orden y elige el que certifica su
+// notarización: el de su transacción o, en su defecto, el más reciente (nil si no hay). Si
+// la notarización está confirmada se busca solo en su bloque y, si ahí no aparece, en toda
+// la cadena.
+func (s *NotarizationService) findInvoiceEvent(orderID uuid.UUID, n *domain.OrderNotarization) ([]InvoiceEvent, *InvoiceEvent, error) {
+	var blockNumber *int64
+	if n != nil && n.Status == domain.NotarizationConfirmed {
+		blockNumber = n.BlockNumber
+	}
+
+	events, err := s.blockchain.FindInvoices(orderID, blockNumber)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(events) == 0 && blockNumber != nil {
+		// El bloque guardado pudo quedar fuera de la cadena (reorganización): se busca en toda la cadena
+		if events, err = s.blockchain.FindInvoices(orderID, nil); err != nil {
+			return nil, nil, err
+		}
+	}
+	if len(events) == 0 {
+		return events, nil, nil
+	}
+
+	event := events[len(events)-1]
+	if n != nil && n.TxHash != nil {
+		for _, e := range events {
+			if e.TxHash == *n.TxHash {
+				event = e
+				break
+			}
+		}
+	}
+	return events, &event, nil
+}
+
+// verifyBatched verifica una orden notarizada en un lote: la factura cifrada guardada en
+// la BD vale si su hash, con la prueba Merkle de la orden, lleva a la raíz anclada en el
+// contrato. Luego se compara con la orden actual igual que en el modo individual.
+func (s *NotarizationService) verifyBatched(n *domain.OrderNotarization, current *domain.BlockchainInvoice, result *domain.InvoiceVerification) (*domain.InvoiceVerification, error) {
+	result.Mode = "batch"
+
+	batch, err := s.batchRepo.GetByID(*n.BatchID)
+	if err != nil {
+		return nil, err
+	}
+	result.MerkleRoot = batch.MerkleRoot
+
+	var blockNumber *int64
+	if batch.Status == domain.NotarizationConfirmed {
+		blockNumber = batch.BlockNumber
+	}
+	anchors, err := s.blockchain.FindBatchAnchors(batch.MerkleRoot, blockNumber)
+	if err != nil {
+		return nil, err
+	}
+	if len(anchors) == 0 && blockNumber != nil {
+		if anchors, err = s.blockchain.FindBatchAnchors(batch.MerkleRoot, nil); err != nil {
+			return nil, err
+		}
+	}
+	result.Events = len(anchors)
+	if len(anchors) == 0 {
+		return result, nil
+	}
+
+	anchor := anchors[len(anchors)-1]
+	if batch.TxHash != nil {
+		for _, a := range anchors {
+			if a.TxHash == *batch.TxHash {
+				anchor = a
+				break
+			}
+		}
+	}
+	result.TxHash = anchor.TxHash
+	result.BlockNumber = anchor.BlockNumber
+	result.NotarizedAt = &anchor.Timestamp
+
+	if n.EncryptedInvoice == nil {
+		result.Status = domain.VerificationInvalid
+		result.Error = "la orden no tiene la factura del lote"
+		return result, nil
+	}
+	result.KeyID = utils.CiphertextKeyID(*n.EncryptedInvoice)
+	notarized, err := decryptInvoice(*n.EncryptedInvoice)
+	if err != nil {
+		result.Status = domain.VerificationInvalid
+		result.Error = err.Error()
+		return result, nil
+	}
+
+	proofValid := merkleProofValid(notarized.Hash, n.MerkleProof, batch.MerkleRoot)
+	result.ProofValid = &proofValid
+	if !proofValid {
+		result.Status = domain.VerificationInvalid
+		result.Error = "la prueba Merkle de la factura no lleva a la raíz anclada"
+		result.NotarizedHash = notarized.Hash
+		return result, nil
+	}
+
+	compareInvoices(result, notarized, current)
+	return result, nil
+}
+
+// merkleProofValid comprueba que la prueba lleva de la hoja del hash de la factura a la raíz
+func merkleProofValid(invoiceHash string, merkleProof []string, merkleRoot string) bool {
+	leaf, err := utils.MerkleLeaf(invoiceHash)
+	if err != nil {
+		return false
+	}
+	proof := make([]common.Hash, len(merkleProof))
+	for i, node := range merkleProof {
+		proof[i] = common.HexToHash(node)
+	}
+	return utils.VerifyMerkleProof(leaf, proof, common.HexToHash(merkleRoot))
+}
+
+// compareInvoices completa el resultado comparando la factura notarizada con la actual
+func compareInvoices(result *domain.InvoiceVerification, notarized, current *domain.BlockchainInvoice) {
+	result.NotarizedInvoice = notarized
+	result.NotarizedHash = notarized.Hash
+	result.PayloadValid = notarized.VerifyHash()
+
+	switch {
+	case notarized.OrderID != result.OrderID:
+		result.Status = domain.VerificationInvalid
+		result.Error = fmt.Sprintf("la factura notarizada es de la orden %s", notarized.OrderID)
+	case !result.PayloadValid:
+		result.Status = domain.VerificationInvalid
+		result.Error = "el hash de la factura notarizada no corresponde a su contenido"
+	case notarized.Hash != current.Hash:
+		result.Status = domain.VerificationAltered
+		result.Altered = true
+		result.ChangedFields = notarized.ChangedFields(current)
+		log.Printf("🚨 [Verify] La orden %s cambió después de notarizarse: %v", result.OrderID, result.ChangedFields)
+	default:
+		result.Status = domain.VerificationValid
+	}
+}
+
+// decryptInvoice descifra una factura notarizada (payload de InvoiceSecured o factura del lote)
+func decryptInvoice(encryptedData string) (*domain.BlockchainInvoice, error) {
+	plaintext, err := utils.Decrypt(encryptedData)
+	if err != nil {
+		return nil, fmt.Errorf("no se pudo descifrar la factura: %v", err)
+	}
+	var invoice domain.BlockchainInvoice
+	if err := json.Unmarshal([]byte(plaintext), &invoice); err != nil {
+		return nil, fmt.Errorf("la factura descifrada no es válida: %v", err)
+	}
+	return &invoice, nil
+}
@@ -0,0 +1,123 @@
+package service
+
+import (
+	"bytes"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/Hoxanfox/TurnyChain/Backend/api/internal/domain"
+	"github.com/Hoxanfox/TurnyChain/Backend/api/internal/repository"
+	"github.com/Hoxanfox/TurnyChain/Backend/api/internal/utils"
+	"github.com/google/uuid"
+)
+
+// fakeOrderRepo devuelve siempre la misma orden; el resto de métodos no se usa en estas pruebas
+type fakeOrderRepo struct {
+	repository.OrderRepository
+	order *domain.Order
+}
+
+func (r *fakeOrderRepo) GetOrderByID(orderID uuid.UUID) (*domain.Order, error) {
+	if r.order == nil || r.order.ID != orderID {
+		return nil, sql.ErrNoRows
+	}
+	copied := *r.order
+	copied.Items = append([]domain.OrderItem(nil), r.order.Items...)
+	return &copied, nil
+}
+
+// fakeNotarizationRepo no tiene filas: Verify busca los eventos en toda la cadena
+type fakeNotarizationRepo struct {
+	repository.OrderNotarizationRepository
+}
+
+func (fakeNotarizationRepo) GetByOrderID(uuid.UUID) (*domain.OrderNotarization, error) {
+	return nil, sql.ErrNoRows
+}
+
+func setTestKeyRing(t *testing.T) {
+	t.Helper()
+	ring, err := utils.NewKeyRing("test", map[string][]byte{"test": bytes.Repeat([]byte{7}, 32)})
+	if err != nil {
+		t.Fatalf("NewKeyRing: %v", err)
+	}
+	utils.SetKeyRing(ring)
+}
+
+func paidTestOrder() *domain.Order {
+	invoiceNumber := int64(42)
+	return &domain.Order{
+		ID:          uuid.New(),
+		WaiterID:    uuid.New(),
+		WaiterName:  "mesero1",
+		TableNumber: 5,
+		Status:      StatusPaid,
+		Subtotal:    domain.Money(30000 * domain.MoneyScale),
+		Total:       domain.Money(30000 * domain.MoneyScale),
+		Items: []domain.OrderItem{
+			{ID: uuid.New(), MenuItemID: uuid.New(), MenuItemName: "Hamburguesa", Quantity: 1, PriceAtOrder: domain.Money(20000 * domain.MoneyScale), Status: domain.ItemStatusServed},
+			{ID: uuid.New(), MenuItemID: uuid.New(), MenuItemName: "Limonada", Quantity: 2, PriceAtOrder: domain.Money(5000 * domain.MoneyScale), Status: domain.ItemStatusServed},
+		},
+		InvoiceNumber: &invoiceNumber,
+		UpdatedAt:     time.Date(2026, 10, 18, 20, 15, 0, 0, time.UTC),
+	}
+}
+
+func TestVerifyMemoryNotary(t *testing.T) {
+	setTestKeyRing(t)
+
+	order := paidTestOrder()
+	notary := NewMemoryNotary()
+	if _, err := notary.NotarizeOrder(order, nil); err != nil {
+		t.Fatalf("NotarizeOrder: %v", err)
+	}
+	orders := &fakeOrderRepo{order: order}
+	service := NewNotarizationService(fakeNotarizationRepo{}, nil, orders, notary, nil)
+
+	result, err := service.Verify(order.ID)
+	if err != nil {
+		t.Fatalf("Verify: %v", err)
+	}
+	if result.Status != domain.VerificationValid {
+		t.Fatalf("status = %s (error %q), se esperaba %s", result.Status, result.Error, domain.VerificationValid)
+	}
+	if result.Backend != NotaryBackendMemory || result.Events != 1 || !result.PayloadValid {
+		t.Errorf("resultado inesperado: backend %s, eventos %d, payload válido %v", result.Backend, result.Events, result.PayloadValid)
+	}
+	if result.NotarizedHash != result.CurrentHash {
+		t.Errorf("hash notarizado %s distinto del actual %s", result.NotarizedHash, result.CurrentHash)
+	}
+
+	// Alguien cambia la cantidad y el total de la orden ya notarizada
+	order.Items[1].Quantity = 1
+	order.Total = domain.Money(25000 * domain.MoneyScale)
+
+	result, err = service.Verify(order.ID)
+	if err != nil {
+		t.Fatalf("Verify: %v", err)
+	}
+	if result.Status != domain.VerificationAltered || !result.Altered {
+		t.Fatalf("status = %s, se esperaba %s", result.Status, domain.VerificationAltered)
+	}
+	for _, field := range []string{"items", "total"} {
+		if !contains(result.ChangedFields, field) {
+			t.Errorf("changed_fields = %v, falta '%s'", result.ChangedFields, field)
+		}
+	}
+}
+
+func TestVerifyNotNotarized(t *testing.T) {
+	setTestKeyRing(t)
+
+	order := paidTestOrder()
+	service := NewNotarizationService(fakeNotarizationRepo{}, nil, &fakeOrderRepo{order: order}, NewMemoryNotary(), nil)
+
+	result, err := service.Verify(order.ID)
+	if err != nil {
+		t.Fatalf("Verify: %v", err)
+	}
+	if result.Status != domain.VerificationNotNotarized {
+		t.Errorf("status = %s, se esperaba %s", result.Status, domain.VerificationNotNotarized)
+	}
+}
@@ -0,0 +1,170 @@
+// =================================================================
+// KDS Service
+// Pantallas de cocina por estación: cola de tickets, estados de
+// preparación y aviso al mesero cuando la orden está completa
+// =================================================================
+package service
+
+import (
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/Hoxanfox/TurnyChain/Backend/api/internal/domain"
+	"github.com/Hoxanfox/TurnyChain/Backend/api/internal/repository"
+	wshub "github.com/Hoxanfox/TurnyChain/Backend/api/internal/websocket"
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrKDSTicketNotFound indica que el ticket no existe o no pertenece a la estación
+	ErrKDSTicketNotFound = errors.New("kds ticket not found")
+	// ErrInvalidKDSStatus indica un estado de ticket desconocido
+	ErrInvalidKDSStatus = errors.New("estado de ticket inválido")
+	// ErrKDSTicketClosed indica un ticket anulado o que ya está listo
+	ErrKDSTicketClosed = errors.New("el ticket ya no se puede avanzar")
+)
+
+// StationItemsUpdater cambia el estado de los items de una estación en la orden (lo
+// implementa el servicio de órdenes)
+type StationItemsUpdater interface {
+	UpdateStationItemsStatus(orderID, stationID, actorID uuid.UUID, userRole string, req domain.UpdateItemStatusRequest) (*domain.Order, error)
+}
+
+type KDSService struct {
+	kdsRepo *repository.KDSTicketRepository
+	wsHub   *wshub.Hub
+	items   StationItemsUpdater
+}
+
+func NewKDSService(kdsRepo *repository.KDSTicketRepository, wsHub *wshub.Hub) *KDSService {
+	return &KDSService{kdsRepo: kdsRepo, wsHub: wsHub}
+}
+
+// Publish registra los tickets en las pantallas de sus estaciones y los envía por WebSocket
+func (s *KDSService) Publish(tickets []domain.KitchenTicket) {
+	for _, ticket := range tickets {
+		kdsTicket, err := s.kdsRepo.Create(ticket)
+		if err != nil {
+			log.Printf("❌ [KDS] No se pudo registrar el ticket de %s (orden %s): %v", ticket.StationName, ticket.OrderNumber, err)
+			continue
+		}
+		s.wsHub.BroadcastToStation(kdsTicket.StationID.String(), "KDS_TICKET_NEW", kdsTicket)
+	}
+}
+
+// VoidOrder retira de las pantallas los tickets aún no terminados de una orden anulada y
+// envía a cada estación involucrada su ticket de anulación
+func (s *KDSService) VoidOrder(orderID uuid.UUID, voidTickets []domain.KitchenTicket) {
+	voided, err := s.kdsRepo.VoidOpenByOrderID(orderID)
+	if err != nil {
+		log.Printf("❌ [KDS] No se pudieron anular los tickets de la orden %s: %v", orderID, err)
+	}
+
+	stationTickets := make(map[uuid.UUID][]domain.KDSTicket)
+	for _, ticket := range voided {
+		stationTickets[ticket.StationID] = append(stationTickets[ticket.StationID], ticket)
+	}
+	for _, ticket := range voidTickets {
+		s.wsHub.BroadcastToStation(ticket.StationID.String(), "KDS_ORDER_VOIDED", map[string]interface{}{
+			"order_id":       orderID,
+			"ticket":         ticket,
+			"voided_tickets": stationTickets[ticket.StationID],
+		})
+		delete(stationTickets, ticket.StationID)
+	}
+	// Estaciones con tickets abiertos pero sin items por anular (ej: sus items ya estaban anulados)
+	for stationID, tickets := range stationTickets {
+		s.wsHub.BroadcastToStation(stationID.String(), "KDS_ORDER_VOIDED", map[string]interface{}{
+			"order_id":       orderID,
+			"voided_tickets": tickets,
+		})
+	}
+	log.Printf("🚫 [KDS] Orden %s anulada: %d ticket(s) retirados de las pantallas", orderID, len(voided))
+}
+
+// SetItemsUpdater conecta el servicio de órdenes, que se crea después del KDS (se llama al
+// arrancar)
+func (s *KDSService) SetItemsUpdater(items StationItemsUpdater) {
+	s.items = items
+}
+
+// GetQueue obtiene los tickets pendientes de una estación
+func (s *KDSService) GetQueue(stationID uuid.UUID) ([]domain.KDSTicket, error) {
+	return s.kdsRepo.GetQueueByStation(stationID)
+}
+
+// Bump avanza el ticket al siguiente estado (new → in_progress → ready) o al estado indicado.
+// Los items de la estación en la orden pasan al estado equivalente (pending, preparing o
+// ready) antes de mover el ticket, así la orden y la pantalla no se desincronizan. Cuando
+// todas las estaciones de la orden terminan, se notifica al mesero.
+func (s *KDSService) Bump(stationID, ticketID, actorID uuid.UUID, userRole string, status domain.KDSTicketStatus) (*domain.KDSTicket, error) {
+	ticket, err := s.kdsRepo.GetByID(ticketID)
+	if err != nil {
+		return nil, err
+	}
+	if ticket == nil || ticket.StationID != stationID {
+		return nil, ErrKDSTicketNotFound
+	}
+	if ticket.Status == domain.KDSTicketVoided {
+		return nil, fmt.Errorf("%w: el ticket fue anulado", ErrKDSTicketClosed)
+	}
+
+	if status == "" {
+		next, ok := ticket.Status.Next()
+		if !ok {
+			return nil, fmt.Errorf("%w: el ticket ya está listo", ErrKDSTicketClosed)
+		}
+		status = next
+	}
+	itemStatus := status.ItemStatus()
+	if itemStatus == "" {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidKDSStatus, status)
+	}
+
+	if s.items != nil {
+		_, err := s.items.UpdateStationItemsStatus(ticket.OrderID, stationID, actorID, userRole, domain.UpdateItemStatusRequest{Status: itemStatus})
+		// Sin items de la estación por actualizar (ya servidos o anulados) solo se mueve el ticket
+		if err != nil && !errors.Is(err, ErrOrderItemNotFound) {
+			return nil, err
+		}
+	}
+
+	if err := s.kdsRepo.UpdateStatus(ticketID, status); err != nil {
+		return nil, err
+	}
+	updated, err := s.kdsRepo.GetByID(ticketID)
+	if err != nil {
+		return nil, err
+	}
+
+	s.wsHub.BroadcastToStation(stationID.String(), "KDS_TICKET_UPDATED", updated)
+	log.Printf("🍳 [KDS] Ticket de %s (orden %s) → %s", updated.StationName, updated.Ticket.OrderNumber, updated.Status)
+
+	if updated.Status == domain.KDSTicketReady {
+		s.notifyIfOrderReady(updated)
+	}
+	return updated, nil
+}
+
+// notifyIfOrderReady avisa al mesero si todos los tickets de la orden están listos
+func (s *KDSService) notifyIfOrderReady(ticket *domain.KDSTicket) {
+	tickets, err := s.kdsRepo.GetByOrderID(ticket.OrderID)
+	if err != nil {
+		log.Printf("⚠️ [KDS] No se pudo verificar el estado de la orden %s: %v", ticket.OrderID, err)
+		return
+	}
+	for _, t := range tickets {
+		if t.Status != domain.KDSTicketReady {
+			return
+		}
+	}
+
+	s.wsHub.BroadcastToUser(ticket.Ticket.WaiterID.String(), "ORDER_STATIONS_READY", domain.OrderStationsReady{
+		OrderID:     ticket.OrderID,
+		OrderNumber: ticket.Ticket.OrderNumber,
+		TableNumber: ticket.Ticket.TableNumber,
+		Tickets:     tickets,
+	})
+	log.Printf("🔔 [KDS] Orden %s lista en todas las estaciones, mesero notificado", ticket.Ticket.OrderNumber)
+}
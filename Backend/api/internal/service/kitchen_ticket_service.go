@@ -5,9 +5,9 @@
 package service
 
 import (
+	"encoding/json"
 	"fmt"
 	"log"
-	"time"
 
 	"github.com/Hoxanfox/TurnyChain/Backend/api/internal/domain"
 	"github.com/Hoxanfox/TurnyChain/Backend/api/internal/repository"
@@ -16,22 +16,36 @@ import (
 
 type KitchenTicketService struct {
 	orderRepo   repository.OrderRepository
-	printerRepo *repository.PrinterRepository
 	stationRepo *repository.StationRepository
+	printQueue  *PrintQueueService
+	dispatcher  *PrinterDispatcher
+	kds         *KDSService
 }
 
 func NewKitchenTicketService(
 	orderRepo repository.OrderRepository,
-	printerRepo *repository.PrinterRepository,
 	stationRepo *repository.StationRepository,
+	printQueue *PrintQueueService,
+	dispatcher *PrinterDispatcher,
+	kds *KDSService,
 ) *KitchenTicketService {
 	return &KitchenTicketService{
 		orderRepo:   orderRepo,
-		printerRepo: printerRepo,
 		stationRepo: stationRepo,
+		printQueue:  printQueue,
+		dispatcher:  dispatcher,
+		kds:         kds,
 	}
 }
 
+// KitchenTicketPrinter es la parte del servicio de tickets que usa el servicio de órdenes
+// para imprimir automáticamente
+type KitchenTicketPrinter interface {
+	AutoPrintKitchenTickets(orderID uuid.UUID) (*domain.PrintResponse, error)
+	PrintItemChanges(before, after *domain.Order) (*domain.PrintResponse, error)
+	PrintVoidTickets(order *domain.Order, reason string) (*domain.PrintResponse, error)
+}
+
 // GenerateKitchenTickets genera los tickets cortados para una orden
 // Agrupa los items por estación según su categoría
 func (s *KitchenTicketService) GenerateKitchenTickets(orderID uuid.UUID) ([]domain.KitchenTicket, error) {
@@ -47,58 +61,52 @@ func (s *KitchenTicketService) GenerateKitchenTickets(orderID uuid.UUID) ([]doma
 
 	// 2. Agrupar items por estación
 	stationItems := make(map[uuid.UUID][]domain.KitchenTicketItem)
-	stationInfo := make(map[uuid.UUID]struct {
-		ID   uuid.UUID
-		Name string
-	})
+	stationNames := make(map[uuid.UUID]string)
 
 	for _, item := range order.Items {
 		// Si el item tiene category con station_id, usar esa estación
 		if item.CategoryStationID != nil {
 			stationID := *item.CategoryStationID
-
-			// Agregar info de la estación si no existe
-			if _, exists := stationInfo[stationID]; !exists {
-				stationInfo[stationID] = struct {
-					ID   uuid.UUID
-					Name string
-				}{
-					ID:   stationID,
-					Name: item.CategoryStationName,
-				}
-			}
-
-			// Agregar el item a la estación
-			kitchenItem := domain.KitchenTicketItem{
-				MenuItemName:   item.MenuItemName,
-				Quantity:       item.Quantity,
-				Customizations: &item.Customizations,
-				IsTakeout:      item.IsTakeout,
-			}
-
-			// Manejar Notes que puede ser nil
-			if item.Notes != nil {
-				kitchenItem.Notes = *item.Notes
-			}
-
-			stationItems[stationID] = append(stationItems[stationID], kitchenItem)
+			stationNames[stationID] = item.CategoryStationName
+			stationItems[stationID] = append(stationItems[stationID], kitchenTicketItem(item, item.Quantity))
 		}
 	}
 
 	// 3. Generar tickets por estación
+	return buildKitchenTickets(order, stationItems, stationNames), nil
+}
+
+// kitchenTicketItem convierte un item de la orden en un item de ticket con la cantidad indicada
+func kitchenTicketItem(item domain.OrderItem, quantity int) domain.KitchenTicketItem {
+	customizations := item.Customizations
+	kitchenItem := domain.KitchenTicketItem{
+		MenuItemName:   item.MenuItemName,
+		Quantity:       quantity,
+		Customizations: &customizations,
+		IsTakeout:      item.IsTakeout,
+	}
+
+	// Manejar Notes que puede ser nil
+	if item.Notes != nil {
+		kitchenItem.Notes = *item.Notes
+	}
+	return kitchenItem
+}
+
+// buildKitchenTickets arma un ticket por estación con los items agrupados
+func buildKitchenTickets(order *domain.Order, stationItems map[uuid.UUID][]domain.KitchenTicketItem, stationNames map[uuid.UUID]string) []domain.KitchenTicket {
 	var tickets []domain.KitchenTicket
-	orderNumber := fmt.Sprintf("ORD-%s", orderID.String()[:8])
+	orderNumber := fmt.Sprintf("ORD-%03d", order.OrderNumber)
 
 	for stationID, items := range stationItems {
-		info := stationInfo[stationID]
-
 		ticket := domain.KitchenTicket{
 			OrderID:      order.ID,
 			OrderNumber:  orderNumber,
 			TableNumber:  order.TableNumber,
+			WaiterID:     order.WaiterID,
 			WaiterName:   order.WaiterName,
 			StationID:    stationID,
-			StationName:  info.Name,
+			StationName:  stationNames[stationID],
 			Items:        items,
 			CreatedAt:    order.CreatedAt,
 			OrderType:    order.OrderType,
@@ -108,10 +116,12 @@ func (s *KitchenTicketService) GenerateKitchenTickets(orderID uuid.UUID) ([]doma
 		tickets = append(tickets, ticket)
 	}
 
-	return tickets, nil
+	return tickets
 }
 
-// PrintKitchenTickets genera los tickets y los envía a las impresoras correspondientes
+// PrintKitchenTickets genera los tickets y los encola para las impresoras correspondientes.
+// Cada ticket se intenta imprimir de inmediato; los que fallan quedan en la cola
+// de impresión y se reintentan en segundo plano (con failover a otras impresoras).
 func (s *KitchenTicketService) PrintKitchenTickets(orderID uuid.UUID, reprint bool) (*domain.PrintResponse, error) {
 	// 1. Generar los tickets
 	tickets, err := s.GenerateKitchenTickets(orderID)
@@ -119,113 +129,225 @@ func (s *KitchenTicketService) PrintKitchenTickets(orderID uuid.UUID, reprint bo
 		return nil, err
 	}
 
-	if len(tickets) == 0 {
-		return &domain.PrintResponse{
-			Success:     true,
-			Message:     "No hay items para imprimir (sin estaciones asignadas)",
-			TicketsSent: 0,
-			Tickets:     tickets,
-		}, nil
+	for i := range tickets {
+		tickets[i].IsReprint = reprint
 	}
 
-	// 2. Obtener las estaciones involucradas
-	stationIDs := make([]uuid.UUID, 0, len(tickets))
-	for _, ticket := range tickets {
-		stationIDs = append(stationIDs, ticket.StationID)
-	}
+	// 2. Encolar cada ticket (el primer intento es inmediato)
+	return s.enqueueTickets(tickets), nil
+}
 
-	// 3. Obtener las impresoras de esas estaciones
-	printers, err := s.printerRepo.GetByStationIDs(stationIDs)
+// AutoPrintKitchenTickets envía los tickets de una orden recién aprobada a las pantallas
+// de cocina (KDS) y los imprime solo en las estaciones con impresión automática
+func (s *KitchenTicketService) AutoPrintKitchenTickets(orderID uuid.UUID) (*domain.PrintResponse, error) {
+	tickets, err := s.GenerateKitchenTickets(orderID)
 	if err != nil {
-		return nil, fmt.Errorf("error al obtener impresoras: %w", err)
+		return nil, err
 	}
 
-	// Mapear impresoras por estación
-	printersByStation := make(map[uuid.UUID][]domain.Printer)
-	for _, printer := range printers {
-		printersByStation[printer.StationID] = append(printersByStation[printer.StationID], printer)
+	if s.kds != nil {
+		s.kds.Publish(tickets)
 	}
 
-	// 4. Enviar tickets a cada impresora
-	var failedPrints []domain.FailedPrintInfo
-	successCount := 0
+	return s.enqueueTickets(s.filterAutoPrint(tickets)), nil
+}
+
+// PrintItemChanges compara los items de la orden antes y después de una edición e
+// imprime tickets de modificación ("ADD 2x ..." / "CANCEL 1x ...") solo para las
+// estaciones cuyos items cambiaron y que tienen activada la impresión automática
+func (s *KitchenTicketService) PrintItemChanges(before, after *domain.Order) (*domain.PrintResponse, error) {
+	stationItems, stationNames := diffOrderItems(before.Items, after.Items)
+
+	tickets := buildKitchenTickets(after, stationItems, stationNames)
+	for i := range tickets {
+		tickets[i].IsUpdate = true
+	}
+
+	if s.kds != nil {
+		s.kds.Publish(tickets)
+	}
+
+	return s.enqueueTickets(s.filterAutoPrint(tickets)), nil
+}
+
+// PrintVoidTickets avisa a las estaciones que la orden se anuló: retira sus tickets de las
+// pantallas de cocina e imprime un ticket de anulación ("CANCEL 2x ...") con el motivo en las
+// estaciones con impresión automática. Los items servidos o ya anulados no se incluyen.
+func (s *KitchenTicketService) PrintVoidTickets(order *domain.Order, reason string) (*domain.PrintResponse, error) {
+	stationItems := make(map[uuid.UUID][]domain.KitchenTicketItem)
+	stationNames := make(map[uuid.UUID]string)
+	for _, item := range order.Items {
+		if item.CategoryStationID == nil || item.Status == domain.ItemStatusServed || item.Status == domain.ItemStatusVoided {
+			continue
+		}
+		kitchenItem := kitchenTicketItem(item, item.Quantity)
+		kitchenItem.Change = domain.TicketChangeCancel
+
+		stationID := *item.CategoryStationID
+		stationNames[stationID] = item.CategoryStationName
+		stationItems[stationID] = append(stationItems[stationID], kitchenItem)
+	}
+
+	tickets := buildKitchenTickets(order, stationItems, stationNames)
+	for i := range tickets {
+		tickets[i].IsVoid = true
+		tickets[i].SpecialNotes = "Motivo: " + reason
+	}
+
+	if s.kds != nil {
+		s.kds.VoidOrder(order.ID, tickets)
+	}
+
+	return s.enqueueTickets(s.filterAutoPrint(tickets)), nil
+}
+
+// diffOrderItems calcula, por estación, los items agregados y retirados entre dos
+// versiones de la orden. Dos items son el mismo si coinciden en plato, notas,
+// personalización y si son para llevar.
+func diffOrderItems(before, after []domain.OrderItem) (map[uuid.UUID][]domain.KitchenTicketItem, map[uuid.UUID]string) {
+	type entry struct {
+		item     domain.OrderItem
+		quantity int
+	}
+
+	var keys []string
+	deltas := make(map[string]*entry)
+	add := func(item domain.OrderItem, quantity int) {
+		key := orderItemKey(item)
+		e, ok := deltas[key]
+		if !ok {
+			e = &entry{item: item}
+			deltas[key] = e
+			keys = append(keys, key)
+		}
+		e.quantity += quantity
+	}
+	for _, item := range before {
+		add(item, -item.Quantity)
+	}
+	for _, item := range after {
+		add(item, item.Quantity)
+	}
+
+	stationItems := make(map[uuid.UUID][]domain.KitchenTicketItem)
+	stationNames := make(map[uuid.UUID]string)
+	for _, key := range keys {
+		e := deltas[key]
+		if e.quantity == 0 || e.item.CategoryStationID == nil {
+			continue
+		}
 
+		var kitchenItem domain.KitchenTicketItem
+		if e.quantity > 0 {
+			kitchenItem = kitchenTicketItem(e.item, e.quantity)
+			kitchenItem.Change = domain.TicketChangeAdd
+		} else {
+			kitchenItem = kitchenTicketItem(e.item, -e.quantity)
+			kitchenItem.Change = domain.TicketChangeCancel
+		}
+
+		stationID := *e.item.CategoryStationID
+		stationNames[stationID] = e.item.CategoryStationName
+		stationItems[stationID] = append(stationItems[stationID], kitchenItem)
+	}
+	return stationItems, stationNames
+}
+
+// orderItemKey identifica un item de la orden para comparar versiones
+func orderItemKey(item domain.OrderItem) string {
+	notes := ""
+	if item.Notes != nil {
+		notes = *item.Notes
+	}
+	customizations, _ := json.Marshal(item.Customizations)
+	return fmt.Sprintf("%s|%t|%s|%s", item.MenuItemID, item.IsTakeout, notes, customizations)
+}
+
+// filterAutoPrint descarta los tickets de estaciones con la impresión automática desactivada
+func (s *KitchenTicketService) filterAutoPrint(tickets []domain.KitchenTicket) []domain.KitchenTicket {
+	filtered := make([]domain.KitchenTicket, 0, len(tickets))
 	for _, ticket := range tickets {
-		printers, exists := printersByStation[ticket.StationID]
-		if !exists || len(printers) == 0 {
-			failedPrints = append(failedPrints, domain.FailedPrintInfo{
-				StationName: ticket.StationName,
-				PrinterName: "N/A",
-				Error:       "No hay impresoras configuradas para esta estación",
-			})
+		station, err := s.stationRepo.GetByID(ticket.StationID)
+		if err != nil {
+			log.Printf("⚠️ No se pudo consultar la estación %s: %v", ticket.StationName, err)
 			continue
 		}
+		if station == nil || !station.AutoPrint {
+			log.Printf("⏭️ Impresión automática desactivada para %s", ticket.StationName)
+			continue
+		}
+		filtered = append(filtered, ticket)
+	}
+	return filtered
+}
 
-		// Enviar a la primera impresora activa de la estación
-		// En el futuro se puede implementar load balancing o backup printers
-		printer := printers[0]
+// enqueueTickets encola los tickets y arma la respuesta con el resultado del primer intento
+func (s *KitchenTicketService) enqueueTickets(tickets []domain.KitchenTicket) *domain.PrintResponse {
+	if len(tickets) == 0 {
+		return &domain.PrintResponse{
+			Success:     true,
+			Message:     "No hay items para imprimir (sin estaciones asignadas)",
+			TicketsSent: 0,
+			Tickets:     tickets,
+		}
+	}
 
-		err := s.sendToPrinter(printer, ticket)
+	var failedPrints []domain.FailedPrintInfo
+	jobs := make([]domain.PrintJob, 0, len(tickets))
+	successCount := 0
+
+	for _, ticket := range tickets {
+		job, err := s.printQueue.Enqueue(ticket)
 		if err != nil {
 			failedPrints = append(failedPrints, domain.FailedPrintInfo{
 				StationName: ticket.StationName,
-				PrinterName: printer.Name,
+				PrinterName: "N/A",
 				Error:       err.Error(),
 			})
-			log.Printf("❌ Error al imprimir en %s (%s): %v", printer.Name, ticket.StationName, err)
-		} else {
+			log.Printf("❌ No se pudo encolar el ticket de %s: %v", ticket.StationName, err)
+			continue
+		}
+		jobs = append(jobs, *job)
+
+		if job.Status == domain.PrintJobSent {
 			successCount++
-			log.Printf("✅ Ticket enviado a %s (%s)", printer.Name, ticket.StationName)
+			continue
+		}
+
+		info := domain.FailedPrintInfo{
+			StationName: ticket.StationName,
+			PrinterName: "N/A",
+			Error:       "Error desconocido",
+			JobID:       &job.ID,
+		}
+		if job.PrinterName != "" {
+			info.PrinterName = job.PrinterName
 		}
+		if job.LastError != nil {
+			info.Error = *job.LastError
+		}
+		if job.Status == domain.PrintJobQueued {
+			info.Error += " (se reintentará automáticamente)"
+		}
+		failedPrints = append(failedPrints, info)
 	}
 
-	// 5. Preparar respuesta
+	// Preparar respuesta
 	response := &domain.PrintResponse{
 		Success:      len(failedPrints) == 0,
 		TicketsSent:  successCount,
 		FailedPrints: failedPrints,
 		Tickets:      tickets,
+		Jobs:         jobs,
 	}
 
 	if response.Success {
 		response.Message = fmt.Sprintf("Tickets impresos correctamente en %d estaciones", successCount)
 	} else {
-		response.Message = fmt.Sprintf("Impresión completada con errores: %d exitosos, %d fallidos", successCount, len(failedPrints))
+		response.Message = fmt.Sprintf("Impresión completada con errores: %d exitosos, %d pendientes o fallidos", successCount, len(failedPrints))
 	}
 
-	return response, nil
-}
-
-// sendToPrinter envía el ticket a una impresora específica
-// TODO: Implementar la lógica real de impresión ESC/POS
-func (s *KitchenTicketService) sendToPrinter(printer domain.Printer, ticket domain.KitchenTicket) error {
-	// Por ahora solo simulamos el envío
-	// En producción, aquí se implementaría:
-	// 1. Generar comandos ESC/POS
-	// 2. Conectar al socket de la impresora (printer.IPAddress:printer.Port)
-	// 3. Enviar los bytes del ticket
-
-	log.Printf("📄 Simulando impresión en %s (%s:%d)", printer.Name, printer.IPAddress, printer.Port)
-	log.Printf("   Orden: %s | Mesa: %d | Estación: %s", ticket.OrderNumber, ticket.TableNumber, ticket.StationName)
-	log.Printf("   Items: %d", len(ticket.Items))
-
-	// Simular delay de red/impresora
-	time.Sleep(100 * time.Millisecond)
-
-	// TODO: Implementar lógica real según printer.PrinterType
-	switch printer.PrinterType {
-	case domain.PrinterTypeESCPOS:
-		// Implementar comandos ESC/POS
-		return nil
-	case domain.PrinterTypePDF:
-		// Generar PDF
-		return nil
-	case domain.PrinterTypeRaw:
-		// Envío raw
-		return nil
-	default:
-		return fmt.Errorf("tipo de impresora no soportado: %s", printer.PrinterType)
-	}
+	return response
 }
 
 // GetTicketsPreview obtiene una vista previa de los tickets sin imprimirlos
@@ -240,3 +362,20 @@ func (s *KitchenTicketService) GetTicketsPreview(orderID uuid.UUID) (*domain.Sta
 		Tickets: tickets,
 	}, nil
 }
+
+// ListTicketPDFs lista los tickets PDF generados para una orden (más recientes primero)
+func (s *KitchenTicketService) ListTicketPDFs(orderID uuid.UUID) ([]domain.KitchenTicketFile, error) {
+	files, err := s.dispatcher.ListPDFs(orderID.String())
+	if err != nil {
+		return nil, err
+	}
+	for i := range files {
+		files[i].URL = fmt.Sprintf("/api/orders/%s/kitchen-tickets/pdf/%s", orderID, files[i].FileName)
+	}
+	return files, nil
+}
+
+// GetTicketPDFPath devuelve la ruta en disco de un ticket PDF de la orden
+func (s *KitchenTicketService) GetTicketPDFPath(orderID uuid.UUID, fileName string) (string, error) {
+	return s.dispatcher.PDFPath(orderID.String(), fileName)
+}
@@ -20,12 +20,12 @@ type MenuService interface {
 
 // Structs para los payloads que vienen del handler
 type CreateMenuItemPayload struct {
-	Name             string      `json:"name"`
-	Description      string      `json:"description"`
-	Price            float64     `json:"price"`
-	CategoryID       uuid.UUID   `json:"category_id"`
-	IngredientIDs    []uuid.UUID `json:"ingredient_ids"`
-	AccompanimentIDs []uuid.UUID `json:"accompaniment_ids"`
+	Name             string       `json:"name"`
+	Description      string       `json:"description"`
+	Price            domain.Money `json:"price"`
+	CategoryID       uuid.UUID    `json:"category_id"`
+	IngredientIDs    []uuid.UUID  `json:"ingredient_ids"`
+	AccompanimentIDs []uuid.UUID  `json:"accompaniment_ids"`
 }
 type UpdateMenuItemPayload CreateMenuItemPayload
 
@@ -0,0 +1,222 @@
+// =================================================================
+// Notarization Batches
+// Modo batch del outbox: agrupa los hashes de las facturas pendientes
+// en un árbol Merkle, ancla su raíz con una sola transacción y guarda
+// la prueba de cada orden
+// =================================================================
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/Hoxanfox/TurnyChain/Backend/api/internal/domain"
+	"github.com/Hoxanfox/TurnyChain/Backend/api/internal/utils"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// formBatch arma un lote con las facturas pendientes cuando la más antigua cumplió la
+// ventana de espera o ya se juntaron suficientes para llenarlo
+func (s *NotarizationService) formBatch() {
+	count, oldest, err := s.batchRepo.PendingStats()
+	if err != nil {
+		log.Printf("⚠️ [Notary] Error consultando facturas pendientes de lote: %v", err)
+		return
+	}
+	if count == 0 || (count < s.batchMaxSize && oldest != nil && time.Since(*oldest) < s.batchWindow) {
+		return
+	}
+
+	notarizations, err := s.batchRepo.ClaimUnbatched(s.batchMaxSize, notarizationLease)
+	if err != nil {
+		log.Printf("⚠️ [Notary] Error reservando facturas para el lote: %v", err)
+		return
+	}
+
+	members := make([]domain.NotarizationBatchMember, 0, len(notarizations))
+	leaves := make([]common.Hash, 0, len(notarizations))
+	for i := range notarizations {
+		n := &notarizations[i]
+		member, leaf, err := s.batchMember(n)
+		if err != nil {
+			s.scheduleRetry(n, n.Attempts+1, err.Error())
+			continue
+		}
+		members = append(members, *member)
+		leaves = append(leaves, leaf)
+	}
+	if len(members) == 0 {
+		return
+	}
+
+	root, proofs := utils.BuildMerkleTree(leaves)
+	for i := range members {
+		members[i].MerkleProof = make([]string, len(proofs[i]))
+		for j, node := range proofs[i] {
+			members[i].MerkleProof[j] = node.Hex()
+		}
+	}
+
+	batch, err := s.batchRepo.Create(root.Hex(), members)
+	if err != nil {
+		// Las facturas siguen pendientes y entran en el próximo lote al vencer el lease
+		log.Printf("⚠️ [Notary] No se pudo guardar el lote de %d facturas: %v", len(members), err)
+		return
+	}
+	log.Printf("🌳 [Notary] Lote %s con %d facturas. Raíz: %s", batch.ID, batch.Size, batch.MerkleRoot)
+	s.wsHub.BroadcastMessage("NOTARIZATION_BATCH_UPDATED", batch)
+}
+
+// batchMember calcula la factura actual de la orden, la cifra (queda en la BD para la
+// verificación) y devuelve su hoja del árbol
+func (s *NotarizationService) batchMember(n *domain.OrderNotarization) (*domain.NotarizationBatchMember, common.Hash, error) {
+	order, err := s.orderRepo.GetOrderByID(n.OrderID)
+	if err != nil {
+		return nil, common.Hash{}, fmt.Errorf("error al obtener la orden: %v", err)
+	}
+
+	invoice := domain.CreateBlockchainInvoice(order)
+	invoiceJSON, _ := json.Marshal(invoice)
+	encryptedData, err := utils.Encrypt(string(invoiceJSON))
+	if err != nil {
+		return nil, common.Hash{}, fmt.Errorf("error cifrando la factura: %v", err)
+	}
+
+	leaf, err := utils.MerkleLeaf(invoice.Hash)
+	if err != nil {
+		return nil, common.Hash{}, err
+	}
+	return &domain.NotarizationBatchMember{
+		OrderID:          n.OrderID,
+		InvoiceHash:      invoice.Hash,
+		EncryptedInvoice: encryptedData,
+	}, leaf, nil
+}
+
+// processDueBatches envía los lotes pendientes y consulta el recibo de los enviados
+func (s *NotarizationService) processDueBatches() {
+	batches, err := s.batchRepo.ClaimDue(notarizationBatchSize, notarizationLease)
+	if err != nil {
+		log.Printf("⚠️ [Notary] Error obteniendo lotes pendientes: %v", err)
+		return
+	}
+	for i := range batches {
+		switch batches[i].Status {
+		case domain.NotarizationPending:
+			s.sendBatch(&batches[i])
+		case domain.NotarizationSent:
+			s.checkBatchReceipt(&batches[i])
+		}
+	}
+}
+
+// sendBatch ancla la raíz del lote. Igual que send, reemplaza la transacción anterior que no
+// se minó o confirma el lote si se minó mientras tanto.
+func (s *NotarizationService) sendBatch(b *domain.NotarizationBatch) {
+	attempts := b.Attempts + 1
+
+	replace := s.previousTx(b.TxHash, b.Nonce, b.Backend)
+	if receipt := s.minedReceipt(replace); receipt != nil {
+		if err := s.batchRepo.MarkConfirmed(b.ID, receipt.BlockNumber); err != nil {
+			log.Printf("⚠️ [Notary] No se pudo guardar la confirmación del lote %s: %v", b.ID, err)
+			return
+		}
+		log.Printf("✅ [Notary] Lote %s anclado en el bloque %d (la transacción se minó antes del reenvío)", b.ID, receipt.BlockNumber)
+		s.refreshAndBroadcastBatch(b)
+		return
+	}
+
+	tx, err := s.blockchain.AnchorBatch(b.MerkleRoot, b.Size, replace)
+	if err != nil {
+		log.Printf("❌ [Notary] Error anclando el lote %s (intento %d): %v", b.ID, attempts, err)
+		s.scheduleBatchRetry(b, attempts, err.Error())
+		return
+	}
+
+	if err := s.batchRepo.MarkSent(b.ID, tx.Hash, s.blockchain.Name(), tx.Nonce, attempts, time.Now().Add(notarizationReceiptPoll)); err != nil {
+		log.Printf("⚠️ [Notary] Transacción %s enviada pero no se pudo guardar (lote %s): %v", tx.Hash, b.ID, err)
+	}
+	log.Printf("⛓️ [Notary] Lote %s enviado (intento %d, nonce %d). Tx: %s", b.ID, attempts, tx.Nonce, tx.Hash)
+	s.refreshAndBroadcastBatch(b)
+}
+
+// checkBatchReceipt confirma la transacción del lote, igual que checkReceipt
+func (s *NotarizationService) checkBatchReceipt(b *domain.NotarizationBatch) {
+	if b.TxHash == nil {
+		s.scheduleBatchRetry(b, b.Attempts, "lote enviado sin transacción")
+		return
+	}
+	if b.Backend != nil && *b.Backend != s.blockchain.Name() {
+		s.scheduleBatchRetry(b, b.Attempts, fmt.Sprintf("la transacción %s se envió con el backend %s", *b.TxHash, *b.Backend))
+		return
+	}
+
+	receipt, err := s.blockchain.TransactionReceipt(*b.TxHash)
+	if err != nil {
+		// Error de la red: no cuenta como intento
+		log.Printf("⚠️ [Notary] Error consultando el recibo de %s: %v", *b.TxHash, err)
+		s.recheckBatchLater(b)
+		return
+	}
+
+	if receipt == nil {
+		if b.SentAt != nil && time.Since(*b.SentAt) > notarizationReceiptTimeout {
+			s.scheduleBatchRetry(b, b.Attempts, fmt.Sprintf("la transacción %s no se minó en %s", *b.TxHash, notarizationReceiptTimeout))
+			return
+		}
+		s.recheckBatchLater(b)
+		return
+	}
+
+	if !receipt.Success {
+		s.scheduleBatchRetry(b, b.Attempts, fmt.Sprintf("la transacción %s se revirtió en el bloque %d", *b.TxHash, receipt.BlockNumber))
+		return
+	}
+
+	if err := s.batchRepo.MarkConfirmed(b.ID, receipt.BlockNumber); err != nil {
+		log.Printf("⚠️ [Notary] No se pudo guardar la confirmación del lote %s: %v", b.ID, err)
+		return
+	}
+	log.Printf("✅ [Notary] Lote %s (%d facturas) anclado en el bloque %d", b.ID, b.Size, receipt.BlockNumber)
+	s.refreshAndBroadcastBatch(b)
+}
+
+// recheckBatchLater programa la siguiente consulta del recibo del lote
+func (s *NotarizationService) recheckBatchLater(b *domain.NotarizationBatch) {
+	if err := s.batchRepo.MarkPending(b.ID, time.Now().Add(notarizationReceiptPoll)); err != nil {
+		log.Printf("⚠️ [Notary] No se pudo programar la consulta del recibo del lote %s: %v", b.ID, err)
+	}
+}
+
+// scheduleBatchRetry registra el fallo del lote y programa el siguiente envío, o lo marca
+// como fallido (junto con sus órdenes) si se agotaron los reintentos
+func (s *NotarizationService) scheduleBatchRetry(b *domain.NotarizationBatch, attempts int, lastError string) {
+	if attempts >= notarizationMaxAttempts {
+		if err := s.batchRepo.MarkFailed(b.ID, attempts, lastError); err != nil {
+			log.Printf("⚠️ [Notary] No se pudo marcar como fallido el lote %s: %v", b.ID, err)
+		}
+		log.Printf("🚨 [Notary] El lote %s falló tras %d intentos: %s", b.ID, attempts, lastError)
+		s.refreshAndBroadcastBatch(b)
+		return
+	}
+
+	next := time.Now().Add(notarizationBackoff(attempts))
+	if err := s.batchRepo.MarkRetry(b.ID, attempts, lastError, next); err != nil {
+		log.Printf("⚠️ [Notary] No se pudo programar el reintento del lote %s: %v", b.ID, err)
+	}
+	log.Printf("⏳ [Notary] Reintento %d/%d del lote %s programado para %s", attempts+1, notarizationMaxAttempts, b.ID, next.Format("15:04:05"))
+	s.refreshAndBroadcastBatch(b)
+}
+
+// refreshAndBroadcastBatch recarga el lote desde la BD y notifica su estado por WebSocket
+func (s *NotarizationService) refreshAndBroadcastBatch(b *domain.NotarizationBatch) {
+	updated, err := s.batchRepo.GetByID(b.ID)
+	if err != nil {
+		log.Printf("⚠️ [Notary] No se pudo recargar el lote %s: %v", b.ID, err)
+		return
+	}
+	*b = *updated
+	s.wsHub.BroadcastMessage("NOTARIZATION_BATCH_UPDATED", b)
+}
@@ -0,0 +1,342 @@
+// =================================================================
+// Notarization Service
+// Outbox persistente de notarizaciones: envía las facturas pagadas a
+// blockchain con reintentos (backoff exponencial), confirma cada
+// transacción con su recibo y recupera al arrancar las órdenes
+// pagadas que nunca se notarizaron. Opcionalmente agrupa las facturas
+// en lotes anclados con una raíz Merkle (notarization_batch_service.go)
+// =================================================================
+package service
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/Hoxanfox/TurnyChain/Backend/api/internal/domain"
+	"github.com/Hoxanfox/TurnyChain/Backend/api/internal/repository"
+	wshub "github.com/Hoxanfox/TurnyChain/Backend/api/internal/websocket"
+	"github.com/google/uuid"
+)
+
+const (
+	notarizationMaxAttempts    = 8
+	notarizationBaseBackoff    = 10 * time.Second
+	notarizationMaxBackoff     = 10 * time.Minute
+	notarizationLease          = 2 * time.Minute // Tiempo reservado para procesar una notarización
+	notarizationPollPeriod     = 5 * time.Second
+	notarizationBatchSize      = 20
+	notarizationReceiptPoll    = 5 * time.Second  // Espera entre consultas del recibo
+	notarizationReceiptTimeout = 10 * time.Minute // Sin minar tras este tiempo se reenvía
+)
+
+var (
+	// ErrNotarizationNotFound indica que la factura de la orden nunca se envió a notarizar
+	ErrNotarizationNotFound = errors.New("la orden no tiene notarización")
+	// ErrOrderNotPaid indica que la orden aún no está pagada y su factura no se notariza
+	ErrOrderNotPaid = errors.New("solo se notarizan órdenes pagadas")
+)
+
+// OrderNotarizer es la parte del servicio de notarización que usa el servicio de órdenes
+type OrderNotarizer interface {
+	Enqueue(orderID uuid.UUID) (*domain.OrderNotarization, error)
+	GetByOrderID(orderID uuid.UUID) (*domain.OrderNotarization, error)
+	Verify(orderID uuid.UUID) (*domain.InvoiceVerification, error)
+}
+
+type NotarizationService struct {
+	repo       repository.OrderNotarizationRepository
+	batchRepo  repository.NotarizationBatchRepository
+	orderRepo  repository.OrderRepository
+	blockchain BlockchainService
+	wsHub      *wshub.Hub
+	wake       chan struct{}
+
+	// Modo batch: las facturas se acumulan hasta batchWindow o batchMaxSize y se anclan
+	// en un solo lote. Con batchMaxSize en 0 cada factura va en su propia transacción.
+	batchWindow  time.Duration
+	batchMaxSize int
+}
+
+func NewNotarizationService(
+	repo repository.OrderNotarizationRepository,
+	batchRepo repository.NotarizationBatchRepository,
+	orderRepo repository.OrderRepository,
+	blockchain BlockchainService,
+	wsHub *wshub.Hub,
+) *NotarizationService {
+	return &NotarizationService{
+		repo:       repo,
+		batchRepo:  batchRepo,
+		orderRepo:  orderRepo,
+		blockchain: blockchain,
+		wsHub:      wsHub,
+		wake:       make(chan struct{}, 1),
+	}
+}
+
+// EnableBatching activa el modo batch: las facturas pendientes se anclan en un lote cuando
+// la más antigua lleva window esperando o se juntan maxSize. Se llama antes de Run.
+func (s *NotarizationService) EnableBatching(window time.Duration, maxSize int) {
+	s.batchWindow = window
+	s.batchMaxSize = maxSize
+}
+
+// batching indica si las facturas nuevas se agrupan en lotes
+func (s *NotarizationService) batching() bool {
+	return s.batchMaxSize > 0
+}
+
+// Run recupera las órdenes pendientes y procesa el outbox en segundo plano. Se debe
+// ejecutar en una goroutine. Sin servicio blockchain las notarizaciones quedan en cola
+// hasta que se configure.
+func (s *NotarizationService) Run() {
+	s.Reconcile()
+	if s.blockchain == nil {
+		log.Println("⚠️ [Notary] Servicio blockchain no disponible: las facturas quedan en cola sin enviar")
+		return
+	}
+
+	ticker := time.NewTicker(notarizationPollPeriod)
+	defer ticker.Stop()
+
+	if s.batching() {
+		log.Printf("🌳 [Notary] Modo batch: lotes de hasta %d facturas cada %s", s.batchMaxSize, s.batchWindow)
+	}
+
+	for {
+		s.processDue()
+		if s.batching() {
+			s.formBatch()
+		}
+		// Los lotes ya formados se terminan aunque el modo batch se haya desactivado
+		s.processDueBatches()
+		select {
+		case <-ticker.C:
+		case <-s.wake:
+		}
+	}
+}
+
+// Reconcile agrega al outbox las órdenes pagadas que nunca se registraron (el servidor
+// se detuvo entre el pago y el registro). Las ya registradas se retoman solas: las
+// pendientes se reenvían y las enviadas vuelven a consultar su recibo.
+func (s *NotarizationService) Reconcile() {
+	orderIDs, err := s.repo.GetPaidOrdersWithoutNotarization()
+	if err != nil {
+		log.Printf("⚠️ [Notary] Error buscando órdenes pagadas sin notarizar: %v", err)
+		return
+	}
+	for _, orderID := range orderIDs {
+		if _, err := s.Enqueue(orderID); err != nil {
+			log.Printf("⚠️ [Notary] No se pudo encolar la orden %s: %v", orderID, err)
+		}
+	}
+	if len(orderIDs) > 0 {
+		log.Printf("🔁 [Notary] %d órdenes pagadas sin notarizar agregadas al outbox", len(orderIDs))
+	}
+}
+
+// Enqueue agrega la factura de la orden al outbox y despierta al worker. Una notarización
+// fallida vuelve a la cola con los intentos en cero; una en curso o confirmada no cambia.
+func (s *NotarizationService) Enqueue(orderID uuid.UUID) (*domain.OrderNotarization, error) {
+	order, err := s.orderRepo.GetOrderByID(orderID)
+	if err != nil {
+		return nil, err
+	}
+	notarization, err := s.repo.Enqueue(orderID, domain.CreateBlockchainInvoice(order).Hash)
+	if err != nil {
+		return nil, fmt.Errorf("error al encolar la notarización: %w", err)
+	}
+
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+	return notarization, nil
+}
+
+// GetByOrderID devuelve la notarización de la orden
+func (s *NotarizationService) GetByOrderID(orderID uuid.UUID) (*domain.OrderNotarization, error) {
+	notarization, err := s.repo.GetByOrderID(orderID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotarizationNotFound
+	}
+	return notarization, err
+}
+
+// processDue toma las notarizaciones individuales vencidas: envía las pendientes y consulta
+// el recibo de las enviadas. En modo batch las pendientes esperan su lote.
+func (s *NotarizationService) processDue() {
+	statuses := []domain.NotarizationStatus{domain.NotarizationPending, domain.NotarizationSent}
+	if s.batching() {
+		statuses = []domain.NotarizationStatus{domain.NotarizationSent}
+	}
+	notarizations, err := s.repo.ClaimDue(statuses, notarizationBatchSize, notarizationLease)
+	if err != nil {
+		log.Printf("⚠️ [Notary] Error obteniendo notarizaciones pendientes: %v", err)
+		return
+	}
+	for i := range notarizations {
+		switch notarizations[i].Status {
+		case domain.NotarizationPending:
+			s.send(&notarizations[i])
+		case domain.NotarizationSent:
+			s.checkReceipt(&notarizations[i])
+		}
+	}
+}
+
+// send envía la factura. Si la transacción anterior no se minó a tiempo se reemplaza con su
+// mismo nonce; si se minó mientras tanto se confirma en vez de reenviarla.
+func (s *NotarizationService) send(n *domain.OrderNotarization) {
+	attempts := n.Attempts + 1
+
+	replace := s.previousTx(n.TxHash, n.Nonce, n.Backend)
+	if receipt := s.minedReceipt(replace); receipt != nil {
+		if err := s.repo.MarkConfirmed(n.OrderID, receipt.BlockNumber); err != nil {
+			log.Printf("⚠️ [Notary] No se pudo guardar la confirmación de la orden %s: %v", n.OrderID, err)
+			return
+		}
+		log.Printf("✅ [Notary] Orden %s notarizada en el bloque %d (la transacción se minó antes del reenvío)", n.OrderID, receipt.BlockNumber)
+		s.refreshAndBroadcast(n)
+		return
+	}
+
+	order, err := s.orderRepo.GetOrderByID(n.OrderID)
+	if err != nil {
+		s.scheduleRetry(n, attempts, fmt.Sprintf("error al obtener la orden: %v", err))
+		return
+	}
+
+	invoiceHash := domain.CreateBlockchainInvoice(order).Hash
+	tx, err := s.blockchain.NotarizeOrder(order, replace)
+	if err != nil {
+		log.Printf("❌ [Notary] Error enviando la orden %s (intento %d): %v", n.OrderID, attempts, err)
+		s.scheduleRetry(n, attempts, err.Error())
+		return
+	}
+
+	if err := s.repo.MarkSent(n.OrderID, invoiceHash, tx.Hash, s.blockchain.Name(), tx.Nonce, attempts, time.Now().Add(notarizationReceiptPoll)); err != nil {
+		log.Printf("⚠️ [Notary] Transacción %s enviada pero no se pudo guardar (orden %s): %v", tx.Hash, n.OrderID, err)
+	}
+	log.Printf("⛓️ [Notary] Orden %s enviada (intento %d, nonce %d). Tx: %s", n.OrderID, attempts, tx.Nonce, tx.Hash)
+	s.refreshAndBroadcast(n)
+}
+
+// checkReceipt confirma la transacción enviada. Si aún no se mina se vuelve a consultar más
+// tarde; si se revirtió o no se minó a tiempo (descartada de la mempool) se reenvía.
+func (s *NotarizationService) checkReceipt(n *domain.OrderNotarization) {
+	if n.TxHash == nil {
+		s.scheduleRetry(n, n.Attempts, "notarización enviada sin transacción")
+		return
+	}
+	if n.Backend != nil && *n.Backend != s.blockchain.Name() {
+		// Se envió con otro backend: se reenvía al actual
+		s.scheduleRetry(n, n.Attempts, fmt.Sprintf("la transacción %s se envió con el backend %s", *n.TxHash, *n.Backend))
+		return
+	}
+
+	receipt, err := s.blockchain.TransactionReceipt(*n.TxHash)
+	if err != nil {
+		// Error de la red: no cuenta como intento
+		log.Printf("⚠️ [Notary] Error consultando el recibo de %s: %v", *n.TxHash, err)
+		s.recheckLater(n)
+		return
+	}
+
+	if receipt == nil {
+		if n.SentAt != nil && time.Since(*n.SentAt) > notarizationReceiptTimeout {
+			s.scheduleRetry(n, n.Attempts, fmt.Sprintf("la transacción %s no se minó en %s", *n.TxHash, notarizationReceiptTimeout))
+			return
+		}
+		s.recheckLater(n)
+		return
+	}
+
+	if !receipt.Success {
+		s.scheduleRetry(n, n.Attempts, fmt.Sprintf("la transacción %s se revirtió en el bloque %d", *n.TxHash, receipt.BlockNumber))
+		return
+	}
+
+	if err := s.repo.MarkConfirmed(n.OrderID, receipt.BlockNumber); err != nil {
+		log.Printf("⚠️ [Notary] No se pudo guardar la confirmación de la orden %s: %v", n.OrderID, err)
+		return
+	}
+	log.Printf("✅ [Notary] Orden %s notarizada en el bloque %d", n.OrderID, receipt.BlockNumber)
+	s.refreshAndBroadcast(n)
+}
+
+// previousTx devuelve la transacción del envío anterior si se hizo con el backend actual,
+// para reemplazarla en el reenvío (nil si no hay)
+func (s *NotarizationService) previousTx(txHash *string, nonce *int64, backend *string) *NotarizationTx {
+	if txHash == nil || nonce == nil || backend == nil || *backend != s.blockchain.Name() {
+		return nil
+	}
+	return &NotarizationTx{Hash: *txHash, Nonce: uint64(*nonce)}
+}
+
+// minedReceipt devuelve el recibo de la transacción anterior si se minó con éxito después
+// de darla por atascada (nil si no hay transacción, no se minó o falló la consulta)
+func (s *NotarizationService) minedReceipt(tx *NotarizationTx) *TxReceipt {
+	if tx == nil {
+		return nil
+	}
+	receipt, err := s.blockchain.TransactionReceipt(tx.Hash)
+	if err != nil || receipt == nil || !receipt.Success {
+		return nil
+	}
+	return receipt
+}
+
+// recheckLater programa la siguiente consulta del recibo
+func (s *NotarizationService) recheckLater(n *domain.OrderNotarization) {
+	if err := s.repo.MarkPending(n.OrderID, time.Now().Add(notarizationReceiptPoll)); err != nil {
+		log.Printf("⚠️ [Notary] No se pudo programar la consulta del recibo de la orden %s: %v", n.OrderID, err)
+	}
+}
+
+// scheduleRetry registra el fallo y programa el siguiente envío, o marca la notarización
+// como fallida si se agotaron los reintentos
+func (s *NotarizationService) scheduleRetry(n *domain.OrderNotarization, attempts int, lastError string) {
+	if attempts >= notarizationMaxAttempts {
+		if err := s.repo.MarkFailed(n.OrderID, attempts, lastError); err != nil {
+			log.Printf("⚠️ [Notary] No se pudo marcar como fallida la notarización de la orden %s: %v", n.OrderID, err)
+		}
+		log.Printf("🚨 [Notary] La notarización de la orden %s falló tras %d intentos: %s", n.OrderID, attempts, lastError)
+		s.refreshAndBroadcast(n)
+		return
+	}
+
+	next := time.Now().Add(notarizationBackoff(attempts))
+	if err := s.repo.MarkRetry(n.OrderID, attempts, lastError, next); err != nil {
+		log.Printf("⚠️ [Notary] No se pudo programar el reintento de la orden %s: %v", n.OrderID, err)
+	}
+	log.Printf("⏳ [Notary] Reintento %d/%d de la orden %s programado para %s", attempts+1, notarizationMaxAttempts, n.OrderID, next.Format("15:04:05"))
+	s.refreshAndBroadcast(n)
+}
+
+// refreshAndBroadcast recarga la notarización desde la BD y notifica su estado por WebSocket
+func (s *NotarizationService) refreshAndBroadcast(n *domain.OrderNotarization) {
+	updated, err := s.repo.GetByOrderID(n.OrderID)
+	if err != nil {
+		log.Printf("⚠️ [Notary] No se pudo recargar la notarización de la orden %s: %v", n.OrderID, err)
+		return
+	}
+	*n = *updated
+	s.wsHub.BroadcastMessage("ORDER_NOTARIZATION_UPDATED", n)
+}
+
+// notarizationBackoff calcula la espera antes del siguiente envío: 10s, 20s, 40s... hasta 10 min
+func notarizationBackoff(attempts int) time.Duration {
+	backoff := notarizationBaseBackoff
+	for i := 1; i < attempts && backoff < notarizationMaxBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > notarizationMaxBackoff {
+		backoff = notarizationMaxBackoff
+	}
+	return backoff
+}
@@ -0,0 +1,47 @@
+// =================================================================
+// Notary Backends
+// Selección del backend de notarización por configuración
+// =================================================================
+package service
+
+import "fmt"
+
+const (
+	NotaryBackendEthereum = "ethereum" // Contrato InvoiceNotary en una red EVM
+	NotaryBackendFile     = "file"     // Log local encadenado por hashes
+	NotaryBackendMemory   = "memory"   // Cadena simulada en memoria (pruebas)
+)
+
+// NotaryConfig reúne la configuración de los backends de notarización
+type NotaryConfig struct {
+	Backend         string // Vacío: ethereum si hay RPCURL, si no file
+	RPCURL          string
+	PrivateKey      string
+	ContractAddress string
+	LogPath         string // Archivo del backend file
+}
+
+// NewNotaryBackend crea el backend configurado. Un backend mal configurado devuelve error
+// en vez de desactivar la notarización.
+func NewNotaryBackend(cfg NotaryConfig) (BlockchainService, error) {
+	backend := cfg.Backend
+	if backend == "" {
+		backend = NotaryBackendFile
+		if cfg.RPCURL != "" {
+			backend = NotaryBackendEthereum
+		}
+	}
+
+	switch backend {
+	case NotaryBackendEthereum:
+		return NewBlockchainService(cfg.RPCURL, cfg.PrivateKey, cfg.ContractAddress)
+	case NotaryBackendFile:
+		if cfg.LogPath == "" {
+			return nil, fmt.Errorf("falta la ruta del log de notarización")
+		}
+		return NewFileNotary(cfg.LogPath)
+	case NotaryBackendMemory:
+		return NewMemoryNotary(), nil
+	}
+	return nil, fmt.Errorf("backend de notarización desconocido: %q (ethereum, file o memory)", backend)
+}
@@ -0,0 +1,233 @@
+// =================================================================
+// Order Cancellations
+// Cancelación (antes de cocina) y anulación (después) de órdenes con
+// motivo obligatorio: tickets de anulación, contadores de popularidad
+// y liberación de la mesa
+// =================================================================
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/Hoxanfox/TurnyChain/Backend/api/internal/domain"
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrInvalidCancelReason indica un motivo desconocido o el motivo "other" sin notas
+	ErrInvalidCancelReason = errors.New("motivo de cancelación inválido")
+	// ErrCancelReasonRequired indica un intento de cancelar la orden cambiando su estado, sin motivo
+	ErrCancelReasonRequired = errors.New("para cancelar una orden usa POST /api/orders/:id/cancel o /void con un motivo")
+)
+
+// CancelOrder cancela una orden que aún no llegó a cocina (pendiente de aprobación o
+// recibida). El mesero solo puede rechazar sus propias órdenes pendientes de aprobación.
+func (s *orderService) CancelOrder(orderID, actorID uuid.UUID, userRole string, req domain.CancelOrderRequest) (*domain.OrderCancellation, *domain.Order, error) {
+	return s.cancelOrder(domain.CancellationKindCancel, orderID, actorID, userRole, req)
+}
+
+// VoidOrder anula una orden que la cocina ya recibió (solo el admin). Las estaciones reciben
+// un ticket de anulación y los contadores de popularidad sumados al aprobarla se descuentan.
+func (s *orderService) VoidOrder(orderID, actorID uuid.UUID, userRole string, req domain.CancelOrderRequest) (*domain.OrderCancellation, *domain.Order, error) {
+	return s.cancelOrder(domain.CancellationKindVoid, orderID, actorID, userRole, req)
+}
+
+// GetOrderCancellation devuelve el motivo y el autor de la cancelación de una orden
+func (s *orderService) GetOrderCancellation(orderID uuid.UUID) (*domain.OrderCancellation, error) {
+	return s.cancellations.GetByOrderID(orderID)
+}
+
+func (s *orderService) cancelOrder(kind string, orderID, actorID uuid.UUID, userRole string, req domain.CancelOrderRequest) (*domain.OrderCancellation, *domain.Order, error) {
+	notes := strings.TrimSpace(req.Notes)
+	if err := validateCancelReason(req.ReasonCode, notes); err != nil {
+		return nil, nil, err
+	}
+
+	order, err := s.orderRepo.GetOrderByID(orderID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if order.Status == StatusPaid || order.Status == StatusCancelled {
+		return nil, nil, fmt.Errorf("%w: estado '%s'", ErrOrderClosed, order.Status)
+	}
+
+	// Antes de que la cocina la reciba se cancela; después se anula
+	inKitchen := order.Status != StatusPendingApproval && order.Status != StatusReceived
+	if kind == domain.CancellationKindCancel && inKitchen {
+		return nil, nil, fmt.Errorf("%w: la orden ya está en cocina ('%s'), debe anularse", ErrInvalidTransition, order.Status)
+	}
+	if kind == domain.CancellationKindVoid && !inKitchen {
+		return nil, nil, fmt.Errorf("%w: la orden no ha llegado a cocina ('%s'), debe cancelarse", ErrInvalidTransition, order.Status)
+	}
+	if userRole == RoleWaiter && order.WaiterID != actorID {
+		return nil, nil, fmt.Errorf("%w: un mesero solo puede cancelar sus propias órdenes", ErrInvalidTransition)
+	}
+	if err := ValidateOrderTransition(order.Status, StatusCancelled, userRole); err != nil {
+		log.Printf("⛔ [Service] %v", err)
+		return nil, nil, err
+	}
+
+	payments, err := s.paymentRepo.GetByOrderID(orderID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(payments) > 0 {
+		return nil, nil, fmt.Errorf("%w (%d), no se puede cancelar", ErrOrderHasPayments, len(payments))
+	}
+
+	cancellation := &domain.OrderCancellation{
+		OrderID:        orderID,
+		Kind:           kind,
+		ReasonCode:     req.ReasonCode,
+		PreviousStatus: order.Status,
+		Total:          order.Total,
+		CancelledBy:    &actorID,
+	}
+	if notes != "" {
+		cancellation.Notes = &notes
+	}
+	eventType, message, action := domain.OrderEventCancelled, "ORDER_CANCELLED", "cancelada"
+	if kind == domain.CancellationKindVoid {
+		eventType, message, action = domain.OrderEventVoided, "ORDER_VOIDED", "anulada"
+	}
+	cancelled := orderEvent(orderID, &actorID, eventType,
+		map[string]interface{}{"status": order.Status},
+		map[string]interface{}{"status": StatusCancelled, "reason_code": req.ReasonCode, "notes": cancellation.Notes})
+	if err := s.cancellations.Create(cancellation, []domain.OrderEvent{cancelled}); err != nil {
+		return nil, nil, err
+	}
+
+	updatedOrder, err := s.orderRepo.GetOrderByID(orderID)
+	if err != nil {
+		return nil, nil, err
+	}
+	cancellation.OrderNumber = updatedOrder.OrderNumber
+	cancellation.TableNumber = updatedOrder.TableNumber
+	cancellation.WaiterID = updatedOrder.WaiterID
+	cancellation.WaiterName = updatedOrder.WaiterName
+
+	log.Printf("🚫 [Service] Orden %s %s desde '%s' (motivo: %s)", orderID, action, order.Status, req.ReasonCode)
+
+	if kind == domain.CancellationKindVoid {
+		// La popularidad se sumó al aprobar la orden: se descuentan los items de ese momento
+		if counted, ok := s.approvedItemCounts(orderID); ok {
+			go s.reverseOrderCount(orderID, counted)
+		}
+
+		if s.kitchenTickets != nil && kitchenHasOrder(order.Status) {
+			reason := domain.CancelReasonLabel(req.ReasonCode)
+			if notes != "" {
+				reason += " - " + notes
+			}
+			go func() {
+				response, err := s.kitchenTickets.PrintVoidTickets(updatedOrder, reason)
+				if err != nil {
+					log.Printf("❌ Error enviando la anulación de la orden %s a cocina: %v", orderID, err)
+					return
+				}
+				log.Printf("🖨️ Anulación de la orden %s enviada a cocina: %s", orderID, response.Message)
+			}()
+		}
+	}
+
+	s.wsHub.BroadcastMessage("ORDER_STATUS_UPDATED", updatedOrder)
+	s.wsHub.BroadcastMessage(message, map[string]interface{}{
+		"order":        updatedOrder,
+		"cancellation": cancellation,
+	})
+	s.releaseTableIfFree(updatedOrder)
+
+	return cancellation, updatedOrder, nil
+}
+
+// validateCancelReason valida el motivo de una cancelación o anulación (de la orden o de
+// sus items); el motivo "other" exige notas
+func validateCancelReason(code, notes string) error {
+	if !domain.IsValidCancelReason(code) {
+		return fmt.Errorf("%w: '%s'", ErrInvalidCancelReason, code)
+	}
+	if code == domain.CancelReasonOther && notes == "" {
+		return fmt.Errorf("%w: el motivo 'other' requiere notas", ErrInvalidCancelReason)
+	}
+	return nil
+}
+
+// countedItem es un item sumado a los contadores de popularidad al aprobar la orden
+type countedItem struct {
+	MenuItemID uuid.UUID `json:"menu_item_id"`
+	Quantity   int       `json:"quantity"`
+}
+
+// countedItems resume los items que se suman a los contadores al aprobar la orden
+func countedItems(items []domain.OrderItem) []countedItem {
+	counted := make([]countedItem, 0, len(items))
+	for _, item := range items {
+		counted = append(counted, countedItem{MenuItemID: item.MenuItemID, Quantity: item.Quantity})
+	}
+	return counted
+}
+
+// approvedItemCounts devuelve los items que se sumaron a los contadores de popularidad al
+// aprobar la orden, guardados en el evento de la aprobación (ok es false si no hay ninguno).
+// Los items que se agregaron o quitaron después de aprobarla no se sumaron y no se descuentan.
+func (s *orderService) approvedItemCounts(orderID uuid.UUID) ([]countedItem, bool) {
+	events, err := s.eventRepo.GetByOrderID(orderID)
+	if err != nil {
+		log.Printf("⚠️ No se pudo leer el historial de la orden %s: %v", orderID, err)
+		return nil, false
+	}
+	for _, event := range events {
+		if event.EventType != domain.OrderEventStatusChanged {
+			continue
+		}
+		var value struct {
+			Status       string         `json:"status"`
+			CountedItems *[]countedItem `json:"counted_items"`
+		}
+		if json.Unmarshal(event.NewValue, &value) != nil || value.Status != StatusApproved {
+			continue
+		}
+		if value.CountedItems == nil {
+			// Aprobada sin sumar contadores (p. ej. desde la gestión del admin)
+			continue
+		}
+		return *value.CountedItems, true
+	}
+	return nil, false
+}
+
+// reverseOrderCount descuenta de los contadores de popularidad los items que se sumaron al
+// aprobar una orden que luego se anuló
+func (s *orderService) reverseOrderCount(orderID uuid.UUID, counted []countedItem) {
+	for _, item := range counted {
+		if err := s.menuRepo.DecrementOrderCount(item.MenuItemID, item.Quantity); err != nil {
+			log.Printf("⚠️ Error descontando el contador del item %s: %v", item.MenuItemID, err)
+		}
+	}
+	log.Printf("✅ Contadores de popularidad revertidos para la orden %s", orderID)
+}
+
+// releaseTableIfFree avisa que la mesa quedó libre cuando la orden cancelada era la última
+// abierta en ella (las mesas virtuales de llevar y domicilio no se liberan)
+func (s *orderService) releaseTableIfFree(order *domain.Order) {
+	if order.OrderType != "mesa" {
+		return
+	}
+	open, err := s.orderRepo.CountOpenOrdersByTable(order.TableNumber)
+	if err != nil {
+		log.Printf("⚠️ No se pudo verificar las órdenes abiertas de la mesa %d: %v", order.TableNumber, err)
+		return
+	}
+	if open > 0 {
+		return
+	}
+	s.wsHub.BroadcastMessage("TABLE_RELEASED", map[string]interface{}{
+		"table_number": order.TableNumber,
+		"order_id":     order.ID.String(),
+	})
+	log.Printf("📡 [Service] Mesa %d liberada", order.TableNumber)
+}
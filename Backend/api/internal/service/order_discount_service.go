@@ -0,0 +1,417 @@
+// =================================================================
+// Order Discounts
+// Promociones automáticas (happy hour, combos, descuentos por
+// producto o categoría) y cupones, con aprobación del admin cuando
+// el descuento supera el umbral
+// =================================================================
+package service
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/Hoxanfox/TurnyChain/Backend/api/internal/domain"
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrCouponNotFound indica que no existe una promoción con ese cupón
+	ErrCouponNotFound = errors.New("cupón no encontrado")
+	// ErrCouponNotApplicable indica un cupón vencido, agotado, repetido o que no aplica a la orden
+	ErrCouponNotApplicable = errors.New("el cupón no aplica a esta orden")
+	// ErrOrderHasPayments indica que la orden ya tiene pagos: su total no puede bajar ni puede cancelarse
+	ErrOrderHasPayments = errors.New("la orden ya tiene pagos registrados")
+	// ErrDiscountNotPending indica que el descuento no existe en la orden o ya fue revisado
+	ErrDiscountNotPending = errors.New("el descuento no está pendiente de aprobación")
+)
+
+// discountApprovalThreshold es el porcentaje del subtotal a partir del cual un cupón
+// aplicado por alguien que no es admin queda pendiente de aprobación
+var discountApprovalThreshold = domain.Percent(20 * domain.MoneyScale)
+
+// SetDiscountApprovalThreshold configura el umbral de aprobación (se llama al arrancar)
+func SetDiscountApprovalThreshold(threshold domain.Percent) {
+	discountApprovalThreshold = threshold
+}
+
+// ApplyCoupon aplica un cupón a la orden. Si el descuento supera el umbral y quien lo
+// aplica no es admin, queda pendiente y no cambia el total hasta que un admin lo apruebe.
+func (s *orderService) ApplyCoupon(orderID, actorID uuid.UUID, userRole, code string) (*domain.OrderDiscount, *domain.Order, error) {
+	order, err := s.openOrderWithoutPayments(orderID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	promotion, err := s.promotions.GetByCouponCode(strings.TrimSpace(code))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil, fmt.Errorf("%w: %s", ErrCouponNotFound, code)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	if !promotion.IsActiveAt(time.Now()) {
+		return nil, nil, fmt.Errorf("%w: el cupón no está vigente", ErrCouponNotApplicable)
+	}
+	// El repositorio vuelve a contar los usos con la promoción bloqueada al guardar el descuento
+	if promotion.MaxUses != nil && promotion.Uses >= *promotion.MaxUses {
+		return nil, nil, fmt.Errorf("%w: el cupón ya no tiene usos disponibles", ErrCouponNotApplicable)
+	}
+
+	existing, err := s.promotions.GetOrderDiscounts(orderID)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, discount := range existing {
+		if discount.PromotionID != nil && *discount.PromotionID == promotion.ID {
+			return nil, nil, fmt.Errorf("%w: el cupón ya se usó en esta orden", ErrCouponNotApplicable)
+		}
+	}
+
+	amount := promotionDiscount(promotion, order.Items, order.Subtotal)
+	if amount <= 0 {
+		return nil, nil, fmt.Errorf("%w: ningún producto de la orden participa en la promoción", ErrCouponNotApplicable)
+	}
+
+	status := domain.DiscountStatusApplied
+	if userRole != RoleAdmin && amount > order.Subtotal.ApplyPercent(discountApprovalThreshold) {
+		status = domain.DiscountStatusPending
+	}
+
+	discount := &domain.OrderDiscount{
+		ID:            uuid.New(),
+		OrderID:       orderID,
+		PromotionID:   &promotion.ID,
+		PromotionName: promotion.Name,
+		CouponCode:    promotion.CouponCode,
+		Amount:        amount,
+		Status:        status,
+		AppliedBy:     &actorID,
+	}
+	eventType := domain.OrderEventDiscountApplied
+	if status == domain.DiscountStatusPending {
+		eventType = domain.OrderEventDiscountRequested
+	}
+	applied := orderEvent(orderID, &actorID, eventType, nil, discountSnapshot(discount))
+
+	// Un cupón aplicado baja el total: se guarda junto con los montos recalculados
+	var amounts *domain.OrderAmounts
+	if status == domain.DiscountStatusApplied {
+		recalculated := orderAmounts(order.Items, order.Subtotal, order.ServiceChargeRate, append(existing, *discount))
+		amounts = &recalculated
+	}
+	if err := s.promotions.CreateOrderDiscount(discount, amounts, []domain.OrderEvent{applied}); err != nil {
+		return nil, nil, err
+	}
+
+	if status == domain.DiscountStatusPending {
+		log.Printf("🏷️ [Service] Cupón %s (%s) en la orden %s pendiente de aprobación", *discount.CouponCode, amount, orderID)
+		s.wsHub.BroadcastToRole(RoleAdmin, "DISCOUNT_APPROVAL_PENDING", map[string]interface{}{
+			"order_id":     order.ID.String(),
+			"table_number": order.TableNumber,
+			"discount":     discount,
+		})
+		return discount, order, nil
+	}
+
+	log.Printf("🏷️ [Service] Cupón %s aplicado a la orden %s: -%s", *discount.CouponCode, orderID, amount)
+	updatedOrder, err := s.orderRepo.GetOrderByID(orderID)
+	if err != nil {
+		return nil, nil, err
+	}
+	s.wsHub.BroadcastMessage("ORDER_UPDATED", updatedOrder)
+	return discount, updatedOrder, nil
+}
+
+// ReviewDiscount aprueba o rechaza un descuento pendiente (solo admin, validado en el handler)
+func (s *orderService) ReviewDiscount(orderID, discountID, reviewerID uuid.UUID, approve bool) (*domain.Order, error) {
+	order, err := s.openOrderWithoutPayments(orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	status := domain.DiscountStatusRejected
+	eventType := domain.OrderEventDiscountRejected
+	if approve {
+		status = domain.DiscountStatusApplied
+		eventType = domain.OrderEventDiscountApproved
+	}
+
+	reviewed := orderEvent(orderID, &reviewerID, eventType,
+		map[string]interface{}{"discount_id": discountID, "status": domain.DiscountStatusPending},
+		map[string]interface{}{"discount_id": discountID, "status": status})
+
+	// Al aprobarlo el descuento baja el total: se guarda junto con los montos recalculados
+	var amounts *domain.OrderAmounts
+	if approve {
+		discounts, err := s.promotions.GetOrderDiscounts(orderID)
+		if err != nil {
+			return nil, err
+		}
+		for i := range discounts {
+			if discounts[i].ID == discountID && discounts[i].Status == domain.DiscountStatusPending {
+				discounts[i].Status = domain.DiscountStatusApplied
+			}
+		}
+		recalculated := orderAmounts(order.Items, order.Subtotal, order.ServiceChargeRate, discounts)
+		amounts = &recalculated
+	}
+	err = s.promotions.ReviewOrderDiscount(orderID, discountID, status, reviewerID, amounts, []domain.OrderEvent{reviewed})
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("%w: %s", ErrDiscountNotPending, discountID)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if !approve {
+		return order, nil
+	}
+	updatedOrder, err := s.orderRepo.GetOrderByID(orderID)
+	if err != nil {
+		return nil, err
+	}
+	s.wsHub.BroadcastMessage("ORDER_UPDATED", updatedOrder)
+	return updatedOrder, nil
+}
+
+// GetOrderDiscounts obtiene los descuentos de la orden con quién los aplicó y aprobó
+func (s *orderService) GetOrderDiscounts(orderID uuid.UUID) ([]domain.OrderDiscount, error) {
+	if _, err := s.orderRepo.GetOrderByID(orderID); err != nil {
+		return nil, err
+	}
+	return s.promotions.GetOrderDiscounts(orderID)
+}
+
+// openOrderWithoutPayments obtiene la orden y verifica que todavía se pueda bajar su total
+func (s *orderService) openOrderWithoutPayments(orderID uuid.UUID) (*domain.Order, error) {
+	order, err := s.orderRepo.GetOrderByID(orderID)
+	if err != nil {
+		return nil, err
+	}
+	if order.Status == StatusPaid || order.Status == StatusCancelled {
+		return nil, fmt.Errorf("%w: estado '%s'", ErrOrderClosed, order.Status)
+	}
+	payments, err := s.paymentRepo.GetByOrderID(orderID)
+	if err != nil {
+		return nil, err
+	}
+	if len(payments) > 0 {
+		return nil, ErrOrderHasPayments
+	}
+	return order, nil
+}
+
+// recalculateOrder evalúa las promociones automáticas (a la hora en que se creó la orden) y
+// los cupones sobre los items, y calcula los montos de la orden con esos descuentos. También
+// devuelve los cupones que volvieron a quedar pendientes de aprobación.
+func (s *orderService) recalculateOrder(order *domain.Order, items []domain.OrderItem, subtotal domain.Money) (domain.OrderAmounts, domain.OrderDiscountChanges, []domain.OrderDiscount, error) {
+	automatic, err := s.automaticDiscounts(items, subtotal, order.CreatedAt)
+	if err != nil {
+		return domain.OrderAmounts{}, domain.OrderDiscountChanges{}, nil, err
+	}
+	coupons, reopened, err := s.repriceCoupons(order.ID, items, subtotal)
+	if err != nil {
+		return domain.OrderAmounts{}, domain.OrderDiscountChanges{}, nil, err
+	}
+	discounts := append(append([]domain.OrderDiscount{}, automatic...), coupons...)
+	changes := domain.OrderDiscountChanges{Automatic: automatic, Coupons: coupons}
+	return orderAmounts(items, subtotal, order.ServiceChargeRate, discounts), changes, reopened, nil
+}
+
+// automaticDiscounts evalúa las promociones sin cupón vigentes en 'at' sobre los items
+func (s *orderService) automaticDiscounts(items []domain.OrderItem, subtotal domain.Money, at time.Time) ([]domain.OrderDiscount, error) {
+	promotions, err := s.promotions.GetAutomatic()
+	if err != nil {
+		return nil, err
+	}
+
+	discounts := make([]domain.OrderDiscount, 0)
+	for i := range promotions {
+		promotion := &promotions[i]
+		if !promotion.IsActiveAt(at) {
+			continue
+		}
+		amount := promotionDiscount(promotion, items, subtotal)
+		if amount <= 0 {
+			continue
+		}
+		discounts = append(discounts, domain.OrderDiscount{
+			PromotionID:   &promotion.ID,
+			PromotionName: promotion.Name,
+			Amount:        amount,
+			Status:        domain.DiscountStatusApplied,
+		})
+	}
+	return discounts, nil
+}
+
+// repriceCoupons recalcula los cupones de la orden con los items nuevos. Un cupón cuya
+// promoción ya no existe conserva su monto. Un cupón aplicado cuyo monto sube por encima del
+// umbral de aprobación vuelve a quedar pendiente (la aprobación cubría el monto anterior);
+// esos cupones se devuelven también en reopened.
+func (s *orderService) repriceCoupons(orderID uuid.UUID, items []domain.OrderItem, subtotal domain.Money) (coupons, reopened []domain.OrderDiscount, err error) {
+	existing, err := s.promotions.GetOrderDiscounts(orderID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	coupons = make([]domain.OrderDiscount, 0)
+	for _, discount := range existing {
+		if discount.CouponCode == nil {
+			continue
+		}
+		if discount.PromotionID != nil && discount.Status != domain.DiscountStatusRejected {
+			promotion, err := s.promotions.GetByID(*discount.PromotionID)
+			if err != nil && !errors.Is(err, sql.ErrNoRows) {
+				return nil, nil, err
+			}
+			if err == nil {
+				previous := discount.Amount
+				discount.Amount = promotionDiscount(promotion, items, subtotal)
+				if discount.Status == domain.DiscountStatusApplied && discount.Amount > previous &&
+					discount.Amount > subtotal.ApplyPercent(discountApprovalThreshold) {
+					discount.Status = domain.DiscountStatusPending
+					discount.ApprovedBy = nil
+					discount.ApprovedByName = ""
+					reopened = append(reopened, discount)
+				}
+			}
+		}
+		coupons = append(coupons, discount)
+	}
+	return coupons, reopened, nil
+}
+
+// couponApprovalEvents arma los eventos del historial de los cupones que vuelven a quedar
+// pendientes al recalcular la orden; se guardan junto con los descuentos recalculados
+func couponApprovalEvents(orderID, actorID uuid.UUID, reopened []domain.OrderDiscount) []domain.OrderEvent {
+	events := make([]domain.OrderEvent, 0, len(reopened))
+	for i := range reopened {
+		events = append(events, orderEvent(orderID, &actorID, domain.OrderEventDiscountRequested,
+			map[string]interface{}{"discount_id": reopened[i].ID, "status": domain.DiscountStatusApplied},
+			discountSnapshot(&reopened[i])))
+	}
+	return events
+}
+
+// requestCouponApprovals avisa a los admins de los cupones que volvieron a quedar pendientes
+// al recalcular la orden
+func (s *orderService) requestCouponApprovals(order *domain.Order, reopened []domain.OrderDiscount) {
+	for i := range reopened {
+		discount := &reopened[i]
+		log.Printf("🏷️ [Service] Cupón %s (%s) en la orden %s vuelve a quedar pendiente de aprobación", *discount.CouponCode, discount.Amount, order.ID)
+		s.wsHub.BroadcastToRole(RoleAdmin, "DISCOUNT_APPROVAL_PENDING", map[string]interface{}{
+			"order_id":     order.ID.String(),
+			"table_number": order.TableNumber,
+			"discount":     discount,
+		})
+	}
+}
+
+// promotionDiscount calcula cuánto descuenta la promoción sobre los items de la orden,
+// redondeado a la moneda. Los montos fijos por producto nunca superan el precio del item
+// y los items anulados no cuentan.
+func promotionDiscount(promotion *domain.Promotion, items []domain.OrderItem, subtotal domain.Money) domain.Money {
+	items = billableItems(items)
+	targets := make(map[uuid.UUID]bool, len(promotion.TargetIDs))
+	for _, id := range promotion.TargetIDs {
+		targets[id] = true
+	}
+	matches := func(item domain.OrderItem) bool {
+		switch promotion.Scope {
+		case domain.PromotionScopeMenuItem:
+			return targets[item.MenuItemID]
+		case domain.PromotionScopeCategory:
+			return item.CategoryID != nil && targets[*item.CategoryID]
+		}
+		return true
+	}
+
+	var discount domain.Money
+	switch promotion.Type {
+	case domain.PromotionTypePercentage:
+		if promotion.Scope == domain.PromotionScopeOrder {
+			discount = subtotal.ApplyPercent(promotion.Percentage)
+			break
+		}
+		for _, item := range items {
+			if matches(item) {
+				discount = discount.Add(item.PriceAtOrder.Mul(item.Quantity).ApplyPercent(promotion.Percentage))
+			}
+		}
+
+	case domain.PromotionTypeFixed:
+		if promotion.Scope == domain.PromotionScopeOrder {
+			discount = promotion.Amount
+			break
+		}
+		for _, item := range items {
+			if matches(item) {
+				unit := promotion.Amount
+				if unit > item.PriceAtOrder {
+					unit = item.PriceAtOrder
+				}
+				discount = discount.Add(unit.Mul(item.Quantity))
+			}
+		}
+
+	case domain.PromotionTypeCombo:
+		discount = comboDiscount(promotion, items)
+	}
+
+	if discount > subtotal {
+		discount = subtotal
+	}
+	return discount.Round(domain.DefaultCurrency())
+}
+
+// comboDiscount cuenta cuántos combos completos hay en la orden y descuenta la diferencia
+// entre el precio normal y el del combo. Un producto repetido en target_ids se pide varias
+// veces por combo; si aparece en varias líneas se toma su precio más bajo.
+func comboDiscount(promotion *domain.Promotion, items []domain.OrderItem) domain.Money {
+	if len(promotion.TargetIDs) == 0 {
+		return 0
+	}
+
+	quantities := make(map[uuid.UUID]int)
+	prices := make(map[uuid.UUID]domain.Money)
+	for _, item := range items {
+		quantities[item.MenuItemID] += item.Quantity
+		if price, ok := prices[item.MenuItemID]; !ok || item.PriceAtOrder < price {
+			prices[item.MenuItemID] = item.PriceAtOrder
+		}
+	}
+
+	required := make(map[uuid.UUID]int)
+	var regular domain.Money
+	for _, id := range promotion.TargetIDs {
+		required[id]++
+		regular = regular.Add(prices[id])
+	}
+
+	combos := -1
+	for id, count := range required {
+		if available := quantities[id] / count; combos == -1 || available < combos {
+			combos = available
+		}
+	}
+	if combos <= 0 || regular <= promotion.Amount {
+		return 0
+	}
+	return regular.Sub(promotion.Amount).Mul(combos)
+}
+
+func discountSnapshot(discount *domain.OrderDiscount) map[string]interface{} {
+	return map[string]interface{}{
+		"discount_id":    discount.ID,
+		"promotion_id":   discount.PromotionID,
+		"promotion_name": discount.PromotionName,
+		"coupon_code":    discount.CouponCode,
+		"amount":         discount.Amount,
+		"status":         discount.Status,
+	}
+}
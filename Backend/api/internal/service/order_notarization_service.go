@@ -0,0 +1,61 @@
+// =================================================================
+// Order Notarization
+// Registro de la factura en el outbox de notarización al pagar la
+// orden, consulta de su estado y verificación contra blockchain
+// =================================================================
+package service
+
+import (
+	"log"
+
+	"github.com/Hoxanfox/TurnyChain/Backend/api/internal/domain"
+	"github.com/google/uuid"
+)
+
+// GetOrderNotarization devuelve el registro de notarización de la orden
+func (s *orderService) GetOrderNotarization(orderID uuid.UUID) (*domain.OrderNotarization, error) {
+	if _, err := s.orderRepo.GetOrderByID(orderID); err != nil {
+		return nil, err
+	}
+	if s.notarizer == nil {
+		return nil, ErrNotarizationNotFound
+	}
+	return s.notarizer.GetByOrderID(orderID)
+}
+
+// RetryOrderNotarization vuelve a poner en cola la notarización fallida de una orden pagada
+func (s *orderService) RetryOrderNotarization(orderID uuid.UUID) (*domain.OrderNotarization, error) {
+	order, err := s.orderRepo.GetOrderByID(orderID)
+	if err != nil {
+		return nil, err
+	}
+	if order.Status != StatusPaid {
+		return nil, ErrOrderNotPaid
+	}
+	if s.notarizer == nil {
+		return nil, ErrNotarizationNotFound
+	}
+	return s.notarizer.Enqueue(orderID)
+}
+
+// VerifyOrderInvoice compara la orden con la factura notarizada en blockchain
+func (s *orderService) VerifyOrderInvoice(orderID uuid.UUID) (*domain.InvoiceVerification, error) {
+	if s.notarizer == nil {
+		if _, err := s.orderRepo.GetOrderByID(orderID); err != nil {
+			return nil, err
+		}
+		return nil, ErrBlockchainUnavailable
+	}
+	return s.notarizer.Verify(orderID)
+}
+
+// enqueueNotarization agrega la factura de la orden pagada al outbox. Si el registro falla
+// (o el servidor se detiene antes), el worker la recupera al arrancar.
+func (s *orderService) enqueueNotarization(orderID uuid.UUID) {
+	if s.notarizer == nil {
+		return
+	}
+	if _, err := s.notarizer.Enqueue(orderID); err != nil {
+		log.Printf("⚠️ No se pudo encolar la notarización de la orden %s: %v", orderID, err)
+	}
+}
@@ -0,0 +1,302 @@
+// =================================================================
+// Order Payments
+// Pagos parciales de una orden: abonos de métodos mixtos, división
+// por items o en partes iguales, y saldo pendiente
+// =================================================================
+package service
+
+import (
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/Hoxanfox/TurnyChain/Backend/api/internal/domain"
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrInvalidPayment indica un método de pago desconocido o un monto/propina inválidos
+	ErrInvalidPayment = errors.New("pago inválido")
+	// ErrOrderClosed indica que la orden ya está pagada o cancelada y no acepta pagos
+	ErrOrderClosed = errors.New("la orden ya está cerrada")
+	// ErrBalanceDue indica que la orden no puede pasar a 'pagado' porque tiene saldo pendiente
+	ErrBalanceDue = errors.New("la orden tiene saldo pendiente")
+)
+
+// GetOrderBalance devuelve los pagos registrados y el saldo pendiente de la orden
+func (s *orderService) GetOrderBalance(orderID uuid.UUID) (*domain.OrderBalance, error) {
+	order, err := s.orderRepo.GetOrderByID(orderID)
+	if err != nil {
+		return nil, err
+	}
+	return s.buildBalance(order)
+}
+
+// AddOrderPayment registra un pago parcial. Con item_ids el monto es la suma de esos
+// items más su cargo por servicio (e impuesto); sin ellos se usa el monto enviado. Si el saldo
+// llega a cero y el rol puede cerrar la orden, la orden pasa a 'pagado'.
+func (s *orderService) AddOrderPayment(orderID, actorID uuid.UUID, userRole string, req domain.CreateOrderPaymentRequest, proofPath *string) (*domain.OrderBalance, error) {
+	order, err := s.orderRepo.GetOrderByID(orderID)
+	if err != nil {
+		return nil, err
+	}
+	if order.Status == StatusPaid || order.Status == StatusCancelled {
+		return nil, fmt.Errorf("%w: estado '%s'", ErrOrderClosed, order.Status)
+	}
+	if !domain.IsValidPaymentMethod(req.Method) {
+		return nil, fmt.Errorf("%w: método '%s'", ErrInvalidPayment, req.Method)
+	}
+
+	payment := &domain.OrderPayment{
+		ID:         uuid.New(),
+		OrderID:    orderID,
+		Method:     req.Method,
+		ProofPath:  proofPath,
+		ReceivedBy: &actorID,
+	}
+	if len(req.ItemIDs) > 0 {
+		payments, err := s.paymentRepo.GetByOrderID(orderID)
+		if err != nil {
+			return nil, err
+		}
+		var itemsTotal domain.Money
+		payment.ItemIDs, itemsTotal, err = itemsAmount(order, req.ItemIDs, paidItemIDs(payments))
+		if err != nil {
+			return nil, err
+		}
+		payment.Amount = itemsPaymentAmount(order, payment.ItemIDs, itemsTotal, payments)
+	} else if req.Amount != nil {
+		payment.Amount = *req.Amount
+	}
+	if req.Tip != nil {
+		payment.Tip = *req.Tip
+	}
+	if payment.Amount < 0 || payment.Tip < 0 {
+		return nil, fmt.Errorf("%w: el monto y la propina no pueden ser negativos", ErrInvalidPayment)
+	}
+	if payment.Amount == 0 && payment.Tip == 0 {
+		return nil, fmt.Errorf("%w: el monto debe ser mayor a cero", ErrInvalidPayment)
+	}
+	// La propina se atribuye al mesero que atiende la orden
+	if payment.Tip > 0 {
+		waiterID := order.WaiterID
+		payment.TipWaiterID = &waiterID
+	}
+
+	if err := s.paymentRepo.Create(payment, []domain.OrderEvent{paymentEvent(payment, actorID)}); err != nil {
+		return nil, err
+	}
+	log.Printf("💵 [Service] Pago de %s + propina %s (%s) registrado en la orden %s", payment.Amount, payment.Tip, payment.Method, orderID)
+
+	balance, err := s.buildBalance(order)
+	if err != nil {
+		return nil, err
+	}
+	s.wsHub.BroadcastMessage("ORDER_PAYMENT_ADDED", balance)
+
+	// Saldo en cero: cerrar la orden si el estado y el rol lo permiten
+	if balance.BalanceDue == 0 && ValidateOrderTransition(order.Status, StatusPaid, userRole) == nil {
+		paidOrder, err := s.UpdateOrderStatus(orderID, actorID, userRole, StatusPaid)
+		if err != nil {
+			log.Printf("⚠️ [Service] Saldo en cero pero no se pudo cerrar la orden %s: %v", orderID, err)
+		} else {
+			balance.Status = paidOrder.Status
+		}
+	}
+
+	return balance, nil
+}
+
+// SplitOrderEvenly divide el saldo pendiente en partes iguales (solo calcula, no registra pagos)
+func (s *orderService) SplitOrderEvenly(orderID uuid.UUID, ways int) (*domain.SplitEvenlyResponse, error) {
+	if ways < 1 {
+		return nil, fmt.Errorf("%w: el número de partes debe ser mayor a cero", ErrInvalidPayment)
+	}
+	balance, err := s.GetOrderBalance(orderID)
+	if err != nil {
+		return nil, err
+	}
+	return &domain.SplitEvenlyResponse{
+		OrderID:    orderID,
+		BalanceDue: balance.BalanceDue,
+		Ways:       ways,
+		Shares:     balance.BalanceDue.Split(ways, domain.DefaultCurrency()),
+	}, nil
+}
+
+// settleBeforePaid se llama antes de pasar una orden a 'pagado'. Si la orden no tiene
+// pagos registrados (flujo de un solo pago) registra uno por el total con el método de
+// la orden; si tiene pagos parciales, exige que el saldo esté en cero.
+func (s *orderService) settleBeforePaid(order *domain.Order, actorID uuid.UUID) error {
+	payments, err := s.paymentRepo.GetByOrderID(order.ID)
+	if err != nil {
+		return err
+	}
+
+	if len(payments) == 0 {
+		if order.Total <= 0 {
+			return nil
+		}
+		method := domain.PaymentMethodCash
+		if order.PaymentMethod != nil && domain.IsValidPaymentMethod(*order.PaymentMethod) {
+			method = *order.PaymentMethod
+		}
+		payment := &domain.OrderPayment{
+			ID:         uuid.New(),
+			OrderID:    order.ID,
+			Amount:     order.Total,
+			Method:     method,
+			ProofPath:  order.PaymentProofPath,
+			ReceivedBy: &actorID,
+		}
+		return s.paymentRepo.Create(payment, []domain.OrderEvent{paymentEvent(payment, actorID)})
+	}
+
+	paid := paymentsTotal(payments)
+	if paid < order.Total {
+		return fmt.Errorf("%w: faltan %s", ErrBalanceDue, order.Total.Sub(paid))
+	}
+	return nil
+}
+
+// paymentEvent arma el evento del historial de un pago; se guarda junto con el pago
+func paymentEvent(payment *domain.OrderPayment, actorID uuid.UUID) domain.OrderEvent {
+	return orderEvent(payment.OrderID, &actorID, domain.OrderEventPaymentAdded, nil, map[string]interface{}{
+		"payment_id": payment.ID,
+		"amount":     payment.Amount,
+		"tip":        payment.Tip,
+		"method":     payment.Method,
+		"item_ids":   payment.ItemIDs,
+	})
+}
+
+// buildBalance arma el estado de cuenta de la orden a partir de sus pagos
+func (s *orderService) buildBalance(order *domain.Order) (*domain.OrderBalance, error) {
+	payments, err := s.paymentRepo.GetByOrderID(order.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	balance := &domain.OrderBalance{
+		OrderID:  order.ID,
+		Status:   order.Status,
+		Total:    order.Total,
+		Payments: payments,
+		Items:    make([]domain.OrderBalanceItem, 0, len(order.Items)),
+	}
+
+	paidItems := paidItemIDs(payments)
+	for _, payment := range payments {
+		balance.Paid = balance.Paid.Add(payment.Amount)
+		balance.Tips = balance.Tips.Add(payment.Tip)
+	}
+	balance.BalanceDue = order.Total.Sub(balance.Paid)
+	if balance.BalanceDue < 0 {
+		balance.BalanceDue = 0
+	}
+
+	// Los items anulados no se cobran
+	for _, item := range billableItems(order.Items) {
+		balance.Items = append(balance.Items, domain.OrderBalanceItem{
+			ItemID:       item.ID,
+			MenuItemName: item.MenuItemName,
+			Quantity:     item.Quantity,
+			Amount:       item.PriceAtOrder.Mul(item.Quantity),
+			Paid:         paidItems[item.ID],
+		})
+	}
+	return balance, nil
+}
+
+// paymentsTotal suma los montos pagados (sin propinas)
+func paymentsTotal(payments []domain.OrderPayment) domain.Money {
+	var paid domain.Money
+	for _, payment := range payments {
+		paid = paid.Add(payment.Amount)
+	}
+	return paid
+}
+
+// itemsPayment calcula lo que pagan los items seleccionados con el mismo cálculo de
+// orderAmounts: el descuento de la orden se reparte en proporción a su valor, el cargo por
+// servicio se aplica sobre el neto y, si los precios no incluyen el impuesto, se suma el de
+// esos items con su parte del descuento
+func itemsPayment(order *domain.Order, itemIDs []uuid.UUID, itemsTotal domain.Money) domain.Money {
+	discount := mulDiv(order.Discount, itemsTotal, order.Subtotal).Round(domain.DefaultCurrency())
+	if discount > itemsTotal {
+		discount = itemsTotal
+	}
+	_, amount := applyServiceCharge(itemsTotal.Sub(discount), order.ServiceChargeRate)
+	return amount.Add(itemsTax(order, itemIDs, discount))
+}
+
+// itemsPaymentAmount calcula el pago de los items seleccionados (itemsPayment) sin superar el
+// saldo pendiente. Cada pago redondea su parte por separado y las partes pueden no sumar el
+// total de la orden, así que el pago que salda los últimos items sin pagar cobra exactamente
+// el saldo pendiente.
+func itemsPaymentAmount(order *domain.Order, itemIDs []uuid.UUID, itemsTotal domain.Money, payments []domain.OrderPayment) domain.Money {
+	due := order.Total.Sub(paymentsTotal(payments))
+	amount := itemsPayment(order, itemIDs, itemsTotal)
+	if amount > due || settlesRemainingItems(order, itemIDs, paidItemIDs(payments)) {
+		amount = due
+	}
+	return amount
+}
+
+// settlesRemainingItems indica si, con los items seleccionados, quedan pagados todos los
+// items cobrables de la orden
+func settlesRemainingItems(order *domain.Order, itemIDs []uuid.UUID, paidItems map[uuid.UUID]bool) bool {
+	selected := make(map[uuid.UUID]bool, len(itemIDs))
+	for _, id := range itemIDs {
+		selected[id] = true
+	}
+	for _, item := range billableItems(order.Items) {
+		if !paidItems[item.ID] && !selected[item.ID] {
+			return false
+		}
+	}
+	return true
+}
+
+// paidItemIDs devuelve los items que ya se pagaron en pagos por items
+func paidItemIDs(payments []domain.OrderPayment) map[uuid.UUID]bool {
+	paid := make(map[uuid.UUID]bool)
+	for _, payment := range payments {
+		for _, id := range payment.ItemIDs {
+			paid[id] = true
+		}
+	}
+	return paid
+}
+
+// itemsAmount valida que los items pertenezcan a la orden, no estén anulados ni pagados en
+// un pago anterior (paidItems) y suma su valor (sin repetidos)
+func itemsAmount(order *domain.Order, itemIDs []uuid.UUID, paidItems map[uuid.UUID]bool) ([]uuid.UUID, domain.Money, error) {
+	items := make(map[uuid.UUID]domain.OrderItem, len(order.Items))
+	for _, item := range order.Items {
+		items[item.ID] = item
+	}
+
+	var amount domain.Money
+	seen := make(map[uuid.UUID]bool, len(itemIDs))
+	ids := make([]uuid.UUID, 0, len(itemIDs))
+	for _, id := range itemIDs {
+		if seen[id] {
+			continue
+		}
+		item, ok := items[id]
+		if !ok {
+			return nil, 0, fmt.Errorf("%w: %s", ErrOrderItemNotFound, id)
+		}
+		if item.Status == domain.ItemStatusVoided {
+			return nil, 0, fmt.Errorf("%w: el item %s está anulado", ErrInvalidPayment, item.MenuItemName)
+		}
+		if paidItems[id] {
+			return nil, 0, fmt.Errorf("%w: %s", domain.ErrItemsAlreadyPaid, item.MenuItemName)
+		}
+		seen[id] = true
+		ids = append(ids, id)
+		amount = amount.Add(item.PriceAtOrder.Mul(item.Quantity))
+	}
+	return ids, amount, nil
+}
@@ -0,0 +1,87 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/Hoxanfox/TurnyChain/Backend/api/internal/domain"
+	"github.com/google/uuid"
+)
+
+// itemsTestOrder arma una orden con los montos calculados por orderAmounts
+func itemsTestOrder(rate domain.Percent, discounts []domain.OrderDiscount, prices ...domain.Money) *domain.Order {
+	items := make([]domain.OrderItem, 0, len(prices))
+	for _, price := range prices {
+		items = append(items, domain.OrderItem{ID: uuid.New(), MenuItemID: uuid.New(), Quantity: 1, PriceAtOrder: price, Status: domain.ItemStatusServed})
+	}
+	amounts := orderAmounts(items, itemsSubtotal(items), rate, discounts)
+	return &domain.Order{
+		ID:                uuid.New(),
+		Status:            StatusDelivered,
+		Subtotal:          amounts.Subtotal,
+		Discount:          amounts.Discount,
+		ServiceChargeRate: rate,
+		ServiceCharge:     amounts.ServiceCharge,
+		Tax:               amounts.Tax,
+		Total:             amounts.Total,
+		Items:             items,
+	}
+}
+
+func TestItemsPaymentsAddUpToTotal(t *testing.T) {
+	cases := []struct {
+		name      string
+		rate      domain.Percent
+		discounts []domain.OrderDiscount
+		prices    []domain.Money
+		want      []domain.Money
+	}{
+		{
+			// 3 × 1.24 de cargo por servicio no suman el 3.71 de la orden: el último paga el saldo
+			name:   "redondeo del cargo por servicio",
+			rate:   1000,
+			prices: []domain.Money{1235, 1235, 1235},
+			want:   []domain.Money{1359, 1359, 1358},
+		},
+		{
+			name:      "descuento repartido entre los items",
+			rate:      1000,
+			discounts: []domain.OrderDiscount{{Amount: 3000 * domain.MoneyScale, Status: domain.DiscountStatusApplied}},
+			prices:    []domain.Money{20000 * domain.MoneyScale, 10000 * domain.MoneyScale},
+			want:      []domain.Money{19800 * domain.MoneyScale, 9900 * domain.MoneyScale},
+		},
+		{
+			name:      "cupón pendiente no descuenta",
+			rate:      1000,
+			discounts: []domain.OrderDiscount{{Amount: 3000 * domain.MoneyScale, Status: domain.DiscountStatusPending}},
+			prices:    []domain.Money{20000 * domain.MoneyScale, 10000 * domain.MoneyScale},
+			want:      []domain.Money{22000 * domain.MoneyScale, 11000 * domain.MoneyScale},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			order := itemsTestOrder(tc.rate, tc.discounts, tc.prices...)
+			var payments []domain.OrderPayment
+			for i, item := range order.Items {
+				ids := []uuid.UUID{item.ID}
+				amount := itemsPaymentAmount(order, ids, item.PriceAtOrder.Mul(item.Quantity), payments)
+				if amount != tc.want[i] {
+					t.Errorf("pago del item %d = %s, se esperaba %s", i+1, amount, tc.want[i])
+				}
+				payments = append(payments, domain.OrderPayment{Amount: amount, ItemIDs: ids})
+			}
+			if paid := paymentsTotal(payments); paid != order.Total {
+				t.Errorf("los pagos suman %s, el total de la orden es %s", paid, order.Total)
+			}
+		})
+	}
+}
+
+func TestItemsPaymentAfterPartialAmount(t *testing.T) {
+	order := itemsTestOrder(1000, nil, 1235, 1235)
+	// Un pago por monto ya cubrió parte de la cuenta: los últimos items pagan solo el saldo
+	payments := []domain.OrderPayment{{Amount: 2000}}
+	ids := []uuid.UUID{order.Items[0].ID, order.Items[1].ID}
+	if amount := itemsPaymentAmount(order, ids, 2470, payments); amount != order.Total-2000 {
+		t.Errorf("pago = %s, se esperaba el saldo %s", amount, order.Total-2000)
+	}
+}
@@ -0,0 +1,221 @@
+// =================================================================
+// Order Pricing
+// Cálculo de precios de la orden en el servidor: el precio de cada
+// línea sale del menú y de los acompañamientos, nunca del cliente
+// =================================================================
+package service
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/Hoxanfox/TurnyChain/Backend/api/internal/domain"
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrMenuItemNotFound indica que un item de la orden apunta a un producto inexistente
+	ErrMenuItemNotFound = errors.New("menu item not found")
+	// ErrMenuItemUnavailable indica que el producto está marcado como no disponible
+	ErrMenuItemUnavailable = errors.New("menu item not available")
+	// ErrInvalidQuantity indica una cantidad menor a 1
+	ErrInvalidQuantity = errors.New("invalid item quantity")
+)
+
+// priceOrderItems completa cada item con el nombre, las customizaciones, el precio y el
+// impuesto vigentes del menú y devuelve el subtotal de la orden.
+// previous son los items que ya estaban en la orden (edición): una línea existente
+// del mismo producto y con los mismos acompañamientos conserva su precio e impuesto
+// originales y no se rechaza si el producto dejó de estar disponible.
+// Los items anulados conservan su precio pero no suman al subtotal.
+// El subtotal se redondea a los decimales de la moneda configurada.
+func (s *orderService) priceOrderItems(items []domain.OrderItem, previous []domain.OrderItem) (domain.Money, error) {
+	previousByID := make(map[uuid.UUID]domain.OrderItem, len(previous))
+	for _, item := range previous {
+		previousByID[item.ID] = item
+	}
+
+	var total domain.Money
+	for i := range items {
+		item := &items[i]
+		if item.Quantity < 1 {
+			return 0, fmt.Errorf("%w: %d", ErrInvalidQuantity, item.Quantity)
+		}
+
+		menuItem, err := s.menuRepo.GetMenuItemByID(item.MenuItemID)
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, fmt.Errorf("%w: %s", ErrMenuItemNotFound, item.MenuItemID)
+		}
+		if err != nil {
+			return 0, err
+		}
+
+		prev, existing := previousByID[item.ID]
+		existing = existing && prev.MenuItemID == item.MenuItemID
+		if !menuItem.IsAvailable && !existing {
+			return 0, fmt.Errorf("%w: %s", ErrMenuItemUnavailable, menuItem.Name)
+		}
+
+		allIngredients, allAccompaniments, err := s.menuRepo.GetMenuItemDetails(item.MenuItemID)
+		if err != nil {
+			return 0, err
+		}
+		item.Customizations = resolveCustomizations(*item, allIngredients, allAccompaniments)
+		item.MenuItemName = menuItem.Name
+		if existing {
+			item.MenuItemName = prev.MenuItemName // Nombre con el que se pidió
+		}
+		categoryID := menuItem.CategoryID
+		item.CategoryID = &categoryID // Para las promociones por categoría
+
+		if existing && sameAccompaniments(prev.Customizations, item.Customizations) {
+			item.PriceAtOrder = prev.PriceAtOrder
+			item.TaxRate = prev.TaxRate
+		} else {
+			item.TaxRate = menuItem.TaxRate
+			item.PriceAtOrder = menuItem.Price
+			for _, acc := range item.Customizations.SelectedAccompaniments {
+				item.PriceAtOrder = item.PriceAtOrder.Add(acc.Price)
+			}
+		}
+
+		if item.Status != domain.ItemStatusVoided {
+			total = total.Add(item.PriceAtOrder.Mul(item.Quantity))
+		}
+	}
+	return total.Round(domain.DefaultCurrency()), nil
+}
+
+// itemsSubtotal suma los items que se cobran (sin los anulados) con sus precios ya fijados
+func itemsSubtotal(items []domain.OrderItem) domain.Money {
+	var total domain.Money
+	for _, item := range billableItems(items) {
+		total = total.Add(item.PriceAtOrder.Mul(item.Quantity))
+	}
+	return total.Round(domain.DefaultCurrency())
+}
+
+// billableItems devuelve los items que se cobran: los anulados no cuentan para el subtotal,
+// los descuentos ni los impuestos
+func billableItems(items []domain.OrderItem) []domain.OrderItem {
+	billable := make([]domain.OrderItem, 0, len(items))
+	for _, item := range items {
+		if item.Status != domain.ItemStatusVoided {
+			billable = append(billable, item)
+		}
+	}
+	return billable
+}
+
+// applyServiceCharge calcula el cargo por servicio sobre el subtotal y el total a cobrar
+// (sin propina, que se registra con los pagos)
+func applyServiceCharge(subtotal domain.Money, rate domain.Percent) (serviceCharge, total domain.Money) {
+	currency := domain.DefaultCurrency()
+	serviceCharge = subtotal.ApplyPercent(rate).Round(currency)
+	return serviceCharge, subtotal.Add(serviceCharge)
+}
+
+// orderAmounts suma los descuentos aplicados (sin superar el subtotal), calcula el cargo
+// por servicio sobre el subtotal con descuento y desglosa los impuestos de los items.
+// Si los precios no incluyen el impuesto, este se suma al total.
+func orderAmounts(items []domain.OrderItem, subtotal domain.Money, serviceChargeRate domain.Percent, discounts []domain.OrderDiscount) domain.OrderAmounts {
+	var discount domain.Money
+	for _, d := range discounts {
+		if d.Status == domain.DiscountStatusApplied {
+			discount = discount.Add(d.Amount)
+		}
+	}
+	if discount > subtotal {
+		discount = subtotal
+	}
+
+	serviceCharge, total := applyServiceCharge(subtotal.Sub(discount), serviceChargeRate)
+	taxes := orderTaxes(items, discount)
+	tax := taxes.Total()
+	if !pricesIncludeTax {
+		total = total.Add(tax)
+	}
+	return domain.OrderAmounts{
+		Subtotal:      subtotal,
+		Discount:      discount,
+		ServiceCharge: serviceCharge,
+		Tax:           tax,
+		TaxBreakdown:  taxes,
+		Total:         total,
+	}
+}
+
+// resolveCustomizations arma las customizaciones con los datos del menú (nombres y precios
+// reales). Con customizations_input se descuenta lo que el cliente NO quiere; si el item
+// ya trae customizaciones (edición) se conservan solo las que pertenecen al producto;
+// si no trae nada, lleva todo.
+func resolveCustomizations(item domain.OrderItem, allIngredients []domain.Ingredient, allAccompaniments []domain.Accompaniment) domain.Customizations {
+	if item.CustomizationsInput != nil {
+		removedIngredients := make(map[uuid.UUID]bool)
+		for _, id := range item.CustomizationsInput.RemovedIngredientIDs {
+			removedIngredients[id] = true
+		}
+		unselectedAccompaniments := make(map[uuid.UUID]bool)
+		for _, id := range item.CustomizationsInput.UnselectedAccompanimentIDs {
+			unselectedAccompaniments[id] = true
+		}
+		return filterCustomizations(allIngredients, allAccompaniments,
+			func(id uuid.UUID) bool { return !removedIngredients[id] },
+			func(id uuid.UUID) bool { return !unselectedAccompaniments[id] })
+	}
+
+	if item.Customizations.ActiveIngredients != nil || item.Customizations.SelectedAccompaniments != nil {
+		activeIngredients := make(map[uuid.UUID]bool)
+		for _, ing := range item.Customizations.ActiveIngredients {
+			activeIngredients[ing.ID] = true
+		}
+		selectedAccompaniments := make(map[uuid.UUID]bool)
+		for _, acc := range item.Customizations.SelectedAccompaniments {
+			selectedAccompaniments[acc.ID] = true
+		}
+		return filterCustomizations(allIngredients, allAccompaniments,
+			func(id uuid.UUID) bool { return activeIngredients[id] },
+			func(id uuid.UUID) bool { return selectedAccompaniments[id] })
+	}
+
+	return domain.Customizations{
+		ActiveIngredients:      allIngredients,
+		SelectedAccompaniments: allAccompaniments,
+	}
+}
+
+func filterCustomizations(allIngredients []domain.Ingredient, allAccompaniments []domain.Accompaniment, keepIngredient, keepAccompaniment func(uuid.UUID) bool) domain.Customizations {
+	customizations := domain.Customizations{
+		ActiveIngredients:      []domain.Ingredient{},
+		SelectedAccompaniments: []domain.Accompaniment{},
+	}
+	for _, ing := range allIngredients {
+		if keepIngredient(ing.ID) {
+			customizations.ActiveIngredients = append(customizations.ActiveIngredients, ing)
+		}
+	}
+	for _, acc := range allAccompaniments {
+		if keepAccompaniment(acc.ID) {
+			customizations.SelectedAccompaniments = append(customizations.SelectedAccompaniments, acc)
+		}
+	}
+	return customizations
+}
+
+// sameAccompaniments compara los acompañamientos seleccionados (por ID) de dos items
+func sameAccompaniments(a, b domain.Customizations) bool {
+	if len(a.SelectedAccompaniments) != len(b.SelectedAccompaniments) {
+		return false
+	}
+	ids := make(map[uuid.UUID]bool, len(a.SelectedAccompaniments))
+	for _, acc := range a.SelectedAccompaniments {
+		ids[acc.ID] = true
+	}
+	for _, acc := range b.SelectedAccompaniments {
+		if !ids[acc.ID] {
+			return false
+		}
+	}
+	return true
+}
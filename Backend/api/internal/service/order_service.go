@@ -4,8 +4,12 @@
 package service
 
 import (
+	"encoding/json"
 	"errors"
+	"fmt"
 	"log"
+	"strings"
+	"time"
 
 	"github.com/Hoxanfox/TurnyChain/Backend/api/internal/domain"
 	"github.com/Hoxanfox/TurnyChain/Backend/api/internal/repository"
@@ -17,20 +21,48 @@ type OrderService interface {
 	CreateOrder(waiterID uuid.UUID, tableNumber int, orderType string, deliveryAddress, deliveryPhone, deliveryNotes *string, items []domain.OrderItem) (*domain.Order, error)
 	GetOrders(userRole string, userID uuid.UUID, status string, myOrders string) ([]domain.Order, error)
 	GetOrderByID(orderID uuid.UUID) (*domain.Order, error)
-	UpdateOrderStatus(orderID, userID uuid.UUID, newStatus string) (*domain.Order, error)
-	UpdateOrderItems(orderID uuid.UUID, items []domain.OrderItem) (*domain.Order, error)
-	ManageOrderAsAdmin(orderID uuid.UUID, status *string, newWaiterID *uuid.UUID) (*domain.Order, error)
-	AddPaymentProof(orderID uuid.UUID, method string, proofPath string) (*domain.Order, error)
+	UpdateOrderStatus(orderID, userID uuid.UUID, userRole, newStatus string) (*domain.Order, error)
+	UpdateOrderItems(orderID, actorID uuid.UUID, items []domain.OrderItem) (*domain.Order, error)
+	UpdateOrderItemStatus(orderID, itemID, actorID uuid.UUID, userRole string, req domain.UpdateItemStatusRequest) (*domain.Order, error)
+	UpdateStationItemsStatus(orderID, stationID, actorID uuid.UUID, userRole string, req domain.UpdateItemStatusRequest) (*domain.Order, error)
+	ManageOrderAsAdmin(orderID, actorID uuid.UUID, userRole string, status *string, newWaiterID *uuid.UUID) (*domain.Order, error)
+	AddPaymentProof(orderID, actorID uuid.UUID, userRole, method, proofPath string) (*domain.Order, error)
+	GetOrderHistory(orderID uuid.UUID) ([]domain.OrderEvent, error)
+	GetOrderBalance(orderID uuid.UUID) (*domain.OrderBalance, error)
+	AddOrderPayment(orderID, actorID uuid.UUID, userRole string, req domain.CreateOrderPaymentRequest, proofPath *string) (*domain.OrderBalance, error)
+	SplitOrderEvenly(orderID uuid.UUID, ways int) (*domain.SplitEvenlyResponse, error)
+	ApplyCoupon(orderID, actorID uuid.UUID, userRole, code string) (*domain.OrderDiscount, *domain.Order, error)
+	ReviewDiscount(orderID, discountID, reviewerID uuid.UUID, approve bool) (*domain.Order, error)
+	GetOrderDiscounts(orderID uuid.UUID) ([]domain.OrderDiscount, error)
+	GetOrderNotarization(orderID uuid.UUID) (*domain.OrderNotarization, error)
+	RetryOrderNotarization(orderID uuid.UUID) (*domain.OrderNotarization, error)
+	VerifyOrderInvoice(orderID uuid.UUID) (*domain.InvoiceVerification, error)
+	CancelOrder(orderID, actorID uuid.UUID, userRole string, req domain.CancelOrderRequest) (*domain.OrderCancellation, *domain.Order, error)
+	VoidOrder(orderID, actorID uuid.UUID, userRole string, req domain.CancelOrderRequest) (*domain.OrderCancellation, *domain.Order, error)
+	GetOrderCancellation(orderID uuid.UUID) (*domain.OrderCancellation, error)
 }
 
+var (
+	// ErrOrderItemNotFound indica que el item no existe en la orden (o la estación no tiene items en ella)
+	ErrOrderItemNotFound = errors.New("order item not found")
+	// ErrInvalidItemStatus indica un estado de item desconocido o una transición no permitida
+	ErrInvalidItemStatus = errors.New("invalid item status transition")
+)
+
 type orderService struct {
 	orderRepo         repository.OrderRepository
 	tableRepo         repository.TableRepository
 	menuRepo          repository.MenuRepository
 	ingredientRepo    repository.IngredientRepository
 	accompanimentRepo repository.AccompanimentRepository
+	eventRepo         repository.OrderEventRepository
+	paymentRepo       repository.OrderPaymentRepository
+	serviceCharges    *repository.ServiceChargeRepository
+	promotions        *repository.PromotionRepository
+	cancellations     repository.OrderCancellationRepository
 	wsHub             *wshub.Hub
-	blockchain        BlockchainService
+	notarizer         OrderNotarizer
+	kitchenTickets    KitchenTicketPrinter
 }
 
 func NewOrderService(
@@ -39,8 +71,14 @@ func NewOrderService(
 	menuRepo repository.MenuRepository,
 	ingredientRepo repository.IngredientRepository,
 	accompanimentRepo repository.AccompanimentRepository,
+	eventRepo repository.OrderEventRepository,
+	paymentRepo repository.OrderPaymentRepository,
+	serviceCharges *repository.ServiceChargeRepository,
+	promotions *repository.PromotionRepository,
+	cancellations repository.OrderCancellationRepository,
 	wsHub *wshub.Hub,
-	bc BlockchainService,
+	notarizer OrderNotarizer,
+	kitchenTickets KitchenTicketPrinter,
 ) OrderService {
 	return &orderService{
 		orderRepo:         orderRepo,
@@ -48,8 +86,14 @@ func NewOrderService(
 		menuRepo:          menuRepo,
 		ingredientRepo:    ingredientRepo,
 		accompanimentRepo: accompanimentRepo,
+		eventRepo:         eventRepo,
+		paymentRepo:       paymentRepo,
+		serviceCharges:    serviceCharges,
+		promotions:        promotions,
+		cancellations:     cancellations,
 		wsHub:             wsHub,
-		blockchain:        bc,
+		notarizer:         notarizer,
+		kitchenTickets:    kitchenTickets,
 	}
 }
 
@@ -108,77 +152,59 @@ func (s *orderService) CreateOrder(waiterID uuid.UUID, tableNumber int, orderTyp
 		// Si es "mesa", respetar el valor que viene del frontend
 	}
 
-	// Procesar customizaciones para cada item
-	for i := range items {
-		// Obtener todos los ingredientes y acompañantes del menu item
-		allIngredients, allAccompaniments, err := s.menuRepo.GetMenuItemDetails(items[i].MenuItemID)
-		if err != nil {
-			log.Printf("⚠️ Error obteniendo detalles del menu item %s: %v", items[i].MenuItemID, err)
-			allIngredients = []domain.Ingredient{}
-			allAccompaniments = []domain.Accompaniment{}
-		}
-
-		if items[i].CustomizationsInput != nil {
-			// Crear mapas para búsqueda rápida de IDs removidos/no seleccionados
-			removedIngredientsMap := make(map[uuid.UUID]bool)
-			for _, id := range items[i].CustomizationsInput.RemovedIngredientIDs {
-				removedIngredientsMap[id] = true
-			}
-
-			unselectedAccompanimentsMap := make(map[uuid.UUID]bool)
-			for _, id := range items[i].CustomizationsInput.UnselectedAccompanimentIDs {
-				unselectedAccompanimentsMap[id] = true
-			}
-
-			// Filtrar ingredientes ACTIVOS (todos menos los removidos)
-			activeIngredients := []domain.Ingredient{}
-			for _, ingredient := range allIngredients {
-				if !removedIngredientsMap[ingredient.ID] {
-					activeIngredients = append(activeIngredients, ingredient)
-				}
-			}
-
-			// Filtrar acompañamientos SELECCIONADOS (todos menos los no seleccionados)
-			selectedAccompaniments := []domain.Accompaniment{}
-			for _, accompaniment := range allAccompaniments {
-				if !unselectedAccompanimentsMap[accompaniment.ID] {
-					selectedAccompaniments = append(selectedAccompaniments, accompaniment)
-				}
-			}
+	// 5. Precios y customizaciones desde el menú (nunca se confía en price_at_order del cliente)
+	subtotal, err := s.priceOrderItems(items, nil)
+	if err != nil {
+		return nil, err
+	}
 
-			// Construir el objeto Customizations con solo lo que SÍ lleva el plato
-			items[i].Customizations = domain.Customizations{
-				ActiveIngredients:      activeIngredients,
-				SelectedAccompaniments: selectedAccompaniments,
-			}
-		} else {
-			// Si no hay customizaciones, devolver todo (sin filtros)
-			items[i].Customizations = domain.Customizations{
-				ActiveIngredients:      allIngredients,
-				SelectedAccompaniments: allAccompaniments,
-			}
-		}
+	// 6. Promociones automáticas vigentes (happy hour, combos, descuentos por producto)
+	discounts, err := s.automaticDiscounts(items, subtotal, time.Now())
+	if err != nil {
+		return nil, err
 	}
 
-	var total float64
-	for _, item := range items {
-		total += item.PriceAtOrder * float64(item.Quantity)
+	// 7. Cargo por servicio según el tipo de orden (el porcentaje queda fijo en la orden)
+	serviceChargeRate, err := s.serviceCharges.GetPercentage(orderType)
+	if err != nil {
+		return nil, err
 	}
+	amounts := orderAmounts(items, subtotal, serviceChargeRate, discounts)
 
+	// Los IDs se asignan aquí para que el evento de creación se guarde junto con la orden
+	for i := range items {
+		items[i].ID = uuid.New()
+		items[i].Status = domain.ItemStatusPending
+	}
 	order := &domain.Order{
-		WaiterID:        waiterID,
-		TableID:         table.ID,
-		TableNumber:     table.TableNumber,
-		Status:          "pendiente_aprobacion",
-		Total:           total,
-		Items:           items,
-		OrderType:       orderType,
-		DeliveryAddress: deliveryAddress,
-		DeliveryPhone:   deliveryPhone,
-		DeliveryNotes:   deliveryNotes,
-	}
-
-	createdOrder, err := s.orderRepo.CreateOrder(order)
+		ID:                uuid.New(),
+		WaiterID:          waiterID,
+		TableID:           table.ID,
+		TableNumber:       table.TableNumber,
+		Status:            "pendiente_aprobacion",
+		Subtotal:          amounts.Subtotal,
+		Discount:          amounts.Discount,
+		ServiceChargeRate: serviceChargeRate,
+		ServiceCharge:     amounts.ServiceCharge,
+		Tax:               amounts.Tax,
+		TaxBreakdown:      amounts.TaxBreakdown,
+		Total:             amounts.Total,
+		Items:             items,
+		OrderType:         orderType,
+		DeliveryAddress:   deliveryAddress,
+		DeliveryPhone:     deliveryPhone,
+		DeliveryNotes:     deliveryNotes,
+	}
+
+	created := orderEvent(order.ID, &waiterID, domain.OrderEventCreated, nil, map[string]interface{}{
+		"status":       order.Status,
+		"table_number": order.TableNumber,
+		"order_type":   order.OrderType,
+		"discount":     order.Discount,
+		"total":        order.Total,
+		"items":        itemsSnapshot(order.Items),
+	})
+	createdOrder, err := s.orderRepo.CreateOrder(order, discounts, []domain.OrderEvent{created})
 	if err != nil {
 		return nil, err
 	}
@@ -208,54 +234,78 @@ func (s *orderService) GetOrderByID(orderID uuid.UUID) (*domain.Order, error) {
 	return s.orderRepo.GetOrderByID(orderID)
 }
 
-func (s *orderService) UpdateOrderStatus(orderID, userID uuid.UUID, newStatus string) (*domain.Order, error) {
+func (s *orderService) UpdateOrderStatus(orderID, userID uuid.UUID, userRole, newStatus string) (*domain.Order, error) {
 	log.Printf("📊 [Service] Actualizando orden %s a estado '%s'", orderID.String(), newStatus)
 
-	updatedOrder, err := s.orderRepo.UpdateOrderStatus(orderID, userID, newStatus)
+	if newStatus == StatusCancelled {
+		return nil, ErrCancelReasonRequired
+	}
+
+	currentOrder, err := s.orderRepo.GetOrderByID(orderID)
+	if err != nil {
+		return nil, err
+	}
+	if err := ValidateOrderTransition(currentOrder.Status, newStatus, userRole); err != nil {
+		log.Printf("⛔ [Service] %v", err)
+		return nil, err
+	}
+	if newStatus == StatusPaid {
+		if err := s.settleBeforePaid(currentOrder, userID); err != nil {
+			log.Printf("⛔ [Service] %v", err)
+			return nil, err
+		}
+	}
+
+	// Al aprobar, los items se suman a los contadores de popularidad. El evento guarda cuáles
+	// se sumaron para descontar exactamente esos si la orden se anula después.
+	newValue := map[string]interface{}{"status": newStatus}
+	if newStatus == StatusApproved {
+		newValue["counted_items"] = countedItems(currentOrder.Items)
+	}
+	changed := orderEvent(orderID, &userID, domain.OrderEventStatusChanged,
+		map[string]interface{}{"status": currentOrder.Status},
+		newValue)
+
+	updatedOrder, err := s.orderRepo.UpdateOrderStatus(orderID, userID, currentOrder.Status, newStatus, []domain.OrderEvent{changed})
 	if err != nil {
 		log.Printf("❌ [Service] Error actualizando estado: %v", err)
 		return nil, err
 	}
+	if updatedOrder.InvoiceNumber != nil && currentOrder.InvoiceNumber == nil {
+		log.Printf("🧾 [Service] Factura N° %d asignada a la orden %s", *updatedOrder.InvoiceNumber, orderID)
+	}
 
 	// --- INCREMENTAR ORDER_COUNT CUANDO SE APRUEBA LA ORDEN ---
-	if newStatus == "aprobado" {
-		// Obtener la orden completa con sus items
-		fullOrder, err := s.orderRepo.GetOrderByID(orderID)
-		if err != nil {
-			log.Printf("⚠️ No se pudo obtener la orden completa para incrementar contadores: %v", err)
-		} else {
-			// Incrementar el contador de cada item en la orden
-			go func(ord *domain.Order) {
-				for _, item := range ord.Items {
-					for i := 0; i < item.Quantity; i++ {
-						if err := s.menuRepo.IncrementOrderCount(item.MenuItemID); err != nil {
-							log.Printf("⚠️ Error incrementando contador para item %s: %v", item.MenuItemID, err)
-						}
+	if newStatus == StatusApproved {
+		// Incrementar el contador de cada item en la orden
+		go func(items []countedItem) {
+			for _, item := range items {
+				for i := 0; i < item.Quantity; i++ {
+					if err := s.menuRepo.IncrementOrderCount(item.MenuItemID); err != nil {
+						log.Printf("⚠️ Error incrementando contador para item %s: %v", item.MenuItemID, err)
 					}
 				}
-				log.Printf("✅ Contadores de popularidad actualizados para orden %s", ord.ID)
-			}(fullOrder)
+			}
+			log.Printf("✅ Contadores de popularidad actualizados para orden %s", orderID)
+		}(countedItems(currentOrder.Items))
+
+		// Imprimir los tickets de cocina en las estaciones con impresión automática
+		if s.kitchenTickets != nil {
+			go func() {
+				response, err := s.kitchenTickets.AutoPrintKitchenTickets(orderID)
+				if err != nil {
+					log.Printf("❌ Error en impresión automática de la orden %s: %v", orderID, err)
+					return
+				}
+				log.Printf("🖨️ Impresión automática de la orden %s: %s", orderID, response.Message)
+			}()
 		}
 	}
 	// ----------------------------------------------------------
 
 	// --- LÓGICA BLOCKCHAIN ---
-	if newStatus == "pagado" && s.blockchain != nil {
-		// IMPORTANTE: Obtener la orden COMPLETA con Items para la blockchain
-		fullOrder, err := s.orderRepo.GetOrderByID(orderID)
-		if err != nil {
-			log.Printf("⚠️ No se pudo obtener la orden completa para blockchain: %v", err)
-		} else {
-			// Ejecutar en goroutine para no bloquear al usuario
-			go func(ord *domain.Order) {
-				_, err := s.blockchain.NotarizeOrder(ord)
-				if err != nil {
-					log.Printf("❌ Error Blockchain: %v", err)
-				} else {
-					log.Printf("✅ Orden %s notarizada en blockchain correctamente", ord.ID)
-				}
-			}(fullOrder)
-		}
+	if newStatus == StatusPaid {
+		s.enqueueNotarization(orderID)
 	}
 	// -------------------------
 
@@ -289,44 +339,339 @@ func (s *orderService) UpdateOrderStatus(orderID, userID uuid.UUID, newStatus st
 	return updatedOrder, nil
 }
 
-func (s *orderService) UpdateOrderItems(orderID uuid.UUID, items []domain.OrderItem) (*domain.Order, error) {
-	var newTotal float64
-	for _, item := range items {
-		newTotal += item.PriceAtOrder * float64(item.Quantity)
+// UpdateOrderItems reemplaza los items de la orden y recalcula sus montos. Una orden
+// pagada, cancelada o con pagos registrados ya no se puede editar.
+func (s *orderService) UpdateOrderItems(orderID, actorID uuid.UUID, items []domain.OrderItem) (*domain.Order, error) {
+	// Versión anterior de la orden para calcular los cambios que van a cocina
+	previousOrder, err := s.openOrderWithoutPayments(orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Los items que ya existían conservan su ID y su estado de preparación; los nuevos reciben
+	// uno aquí (para registrarlos en el historial) y empiezan en "pending"
+	previousStatus := make(map[uuid.UUID]string)
+	for _, item := range previousOrder.Items {
+		previousStatus[item.ID] = item.Status
+	}
+	for i := range items {
+		status, exists := previousStatus[items[i].ID]
+		if !exists {
+			items[i].ID = uuid.New()
+			status = domain.ItemStatusPending
+		}
+		items[i].Status = status
 	}
 
-	err := s.orderRepo.UpdateOrderItems(orderID, items, newTotal)
+	subtotal, err := s.priceOrderItems(items, previousOrder.Items)
 	if err != nil {
 		return nil, err
 	}
 
+	amounts, discounts, reopened, err := s.recalculateOrder(previousOrder, items, subtotal)
+	if err != nil {
+		return nil, err
+	}
+	events := []domain.OrderEvent{orderEvent(orderID, &actorID, domain.OrderEventItemsUpdated,
+		map[string]interface{}{"discount": previousOrder.Discount, "total": previousOrder.Total, "items": itemsSnapshot(previousOrder.Items)},
+		map[string]interface{}{"discount": amounts.Discount, "total": amounts.Total, "items": itemsSnapshot(items)})}
+	events = append(events, couponApprovalEvents(orderID, actorID, reopened)...)
+	err = s.orderRepo.UpdateOrderItems(orderID, items, amounts, discounts, events)
+	if err != nil {
+		return nil, err
+	}
+	s.requestCouponApprovals(previousOrder, reopened)
+
 	updatedOrder, err := s.orderRepo.GetOrderByID(orderID)
 	if err != nil {
 		return nil, err
 	}
 
+	// Si la cocina ya recibió la orden, imprimir solo los cambios por estación
+	if s.kitchenTickets != nil && kitchenHasOrder(previousOrder.Status) {
+		go func() {
+			response, err := s.kitchenTickets.PrintItemChanges(previousOrder, updatedOrder)
+			if err != nil {
+				log.Printf("❌ Error imprimiendo cambios de la orden %s: %v", orderID, err)
+				return
+			}
+			log.Printf("🖨️ Cambios de la orden %s enviados a cocina: %s", orderID, response.Message)
+		}()
+	}
+
 	s.wsHub.BroadcastMessage("ORDER_ITEMS_UPDATED", updatedOrder)
 	return updatedOrder, nil
 }
 
-func (s *orderService) ManageOrderAsAdmin(orderID uuid.UUID, status *string, newWaiterID *uuid.UUID) (*domain.Order, error) {
+// UpdateOrderItemStatus cambia el estado de preparación de un item de la orden
+func (s *orderService) UpdateOrderItemStatus(orderID, itemID, actorID uuid.UUID, userRole string, req domain.UpdateItemStatusRequest) (*domain.Order, error) {
+	return s.updateItemsStatus(orderID, actorID, userRole, req, func(item domain.OrderItem) bool {
+		return item.ID == itemID
+	})
+}
+
+// UpdateStationItemsStatus cambia el estado de todos los items de una estación en la orden
+// (ej: el bar marca las bebidas como listas mientras la parrilla sigue cocinando).
+// Los items servidos o anulados no se modifican.
+func (s *orderService) UpdateStationItemsStatus(orderID, stationID, actorID uuid.UUID, userRole string, req domain.UpdateItemStatusRequest) (*domain.Order, error) {
+	return s.updateItemsStatus(orderID, actorID, userRole, req, func(item domain.OrderItem) bool {
+		return item.CategoryStationID != nil && *item.CategoryStationID == stationID &&
+			item.Status != domain.ItemStatusServed && item.Status != domain.ItemStatusVoided
+	})
+}
+
+// updateItemsStatus valida y aplica el nuevo estado a los items seleccionados, recalcula
+// el estado de la orden y notifica por WebSocket. Anular items exige motivo, solo lo hacen
+// el cajero o el admin y recalcula los montos de la orden (sin pagos registrados).
+func (s *orderService) updateItemsStatus(orderID, actorID uuid.UUID, userRole string, req domain.UpdateItemStatusRequest, match func(domain.OrderItem) bool) (*domain.Order, error) {
+	status := req.Status
+	if !domain.IsValidItemStatus(status) {
+		return nil, ErrInvalidItemStatus
+	}
+	voiding := status == domain.ItemStatusVoided
+	notes := strings.TrimSpace(req.Notes)
+	if voiding {
+		if userRole != RoleCashier && userRole != RoleAdmin {
+			return nil, fmt.Errorf("%w: el rol '%s' no puede anular items", ErrInvalidTransition, userRole)
+		}
+		if err := validateCancelReason(req.ReasonCode, notes); err != nil {
+			return nil, err
+		}
+	}
+
+	var order *domain.Order
+	var err error
+	if voiding {
+		// Anular un item baja el total: la orden no puede tener pagos registrados
+		order, err = s.openOrderWithoutPayments(orderID)
+	} else {
+		order, err = s.orderRepo.GetOrderByID(orderID)
+		if err == nil && (order.Status == StatusPaid || order.Status == StatusCancelled) {
+			err = fmt.Errorf("%w: estado '%s'", ErrOrderClosed, order.Status)
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var itemIDs []uuid.UUID
+	var previous []map[string]interface{}
+	for _, item := range order.Items {
+		if !match(item) || item.Status == status {
+			continue
+		}
+		if !domain.CanTransitionItem(item.Status, status) {
+			return nil, fmt.Errorf("%w: %s → %s (%s)", ErrInvalidItemStatus, item.Status, status, item.MenuItemName)
+		}
+		itemIDs = append(itemIDs, item.ID)
+		previous = append(previous, map[string]interface{}{"id": item.ID, "menu_item_name": item.MenuItemName, "status": item.Status})
+	}
+	if len(itemIDs) == 0 {
+		// Nada que cambiar: los items ya tienen ese estado o no existen
+		for _, item := range order.Items {
+			if match(item) {
+				return order, nil
+			}
+		}
+		return nil, ErrOrderItemNotFound
+	}
+
+	if voiding {
+		err = s.voidItems(order, actorID, itemIDs, previous, req.ReasonCode, notes)
+	} else {
+		err = s.orderRepo.UpdateOrderItemsStatus(orderID, itemIDs, status, []domain.OrderEvent{
+			orderEvent(orderID, &actorID, domain.OrderEventItemStatusChanged,
+				map[string]interface{}{"items": previous},
+				map[string]interface{}{"item_ids": itemIDs, "status": status}),
+		})
+	}
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("🍽️ [Service] %d item(s) de la orden %s → '%s'", len(itemIDs), orderID, status)
+
+	updatedOrder, err := s.orderRepo.GetOrderByID(orderID)
+	if err != nil {
+		return nil, err
+	}
+	if voiding {
+		s.wsHub.BroadcastMessage("ORDER_UPDATED", updatedOrder)
+	}
+
+	// Rollup: el estado de la orden refleja el progreso de sus items
+	rolledUp := rollupOrderStatus(updatedOrder.Status, updatedOrder.Items)
+	if rolledUp != updatedOrder.Status && ValidateOrderTransition(updatedOrder.Status, rolledUp, RoleSystem) == nil {
+		rolledUpEvent := orderEvent(orderID, nil, domain.OrderEventStatusChanged,
+			map[string]interface{}{"status": updatedOrder.Status},
+			map[string]interface{}{"status": rolledUp, "reason": "items"})
+		managedOrder, err := s.orderRepo.ManageOrder(orderID, updatedOrder.Status, map[string]interface{}{"status": rolledUp}, []domain.OrderEvent{rolledUpEvent})
+		if err != nil {
+			log.Printf("⚠️ [Service] No se pudo actualizar el estado de la orden %s a '%s': %v", orderID, rolledUp, err)
+		} else {
+			updatedOrder = managedOrder
+			s.wsHub.BroadcastMessage("ORDER_STATUS_UPDATED", updatedOrder)
+			log.Printf("📡 [Service] Orden %s pasó a '%s' por el estado de sus items", orderID, rolledUp)
+		}
+	}
+
+	changed := make([]domain.OrderItem, 0, len(itemIDs))
+	for _, item := range updatedOrder.Items {
+		for _, id := range itemIDs {
+			if item.ID == id {
+				changed = append(changed, item)
+			}
+		}
+	}
+	payload := map[string]interface{}{
+		"order_id":     updatedOrder.ID.String(),
+		"table_number": updatedOrder.TableNumber,
+		"status":       status,
+		"items":        changed,
+		"order":        updatedOrder,
+	}
+	s.wsHub.BroadcastMessage("ORDER_ITEMS_STATUS_UPDATED", payload)
+
+	// Aviso directo al mesero cuando hay items listos para llevar a la mesa
+	if status == domain.ItemStatusReady {
+		s.wsHub.BroadcastToUser(updatedOrder.WaiterID.String(), "ORDER_ITEMS_READY", payload)
+	}
+
+	return updatedOrder, nil
+}
+
+// voidItems anula los items y recalcula el subtotal, los descuentos y los impuestos de la
+// orden sin ellos. previous es el estado anterior de los items para el historial.
+func (s *orderService) voidItems(order *domain.Order, actorID uuid.UUID, itemIDs []uuid.UUID, previous []map[string]interface{}, reasonCode, notes string) error {
+	voided := make(map[uuid.UUID]bool, len(itemIDs))
+	for _, id := range itemIDs {
+		voided[id] = true
+	}
+	items := make([]domain.OrderItem, len(order.Items))
+	copy(items, order.Items)
+	for i := range items {
+		if voided[items[i].ID] {
+			items[i].Status = domain.ItemStatusVoided
+		}
+	}
+
+	amounts, discounts, reopened, err := s.recalculateOrder(order, items, itemsSubtotal(items))
+	if err != nil {
+		return err
+	}
+	events := []domain.OrderEvent{orderEvent(order.ID, &actorID, domain.OrderEventItemStatusChanged,
+		map[string]interface{}{"items": previous, "total": order.Total},
+		map[string]interface{}{"item_ids": itemIDs, "status": domain.ItemStatusVoided, "reason_code": reasonCode, "notes": notes, "total": amounts.Total})}
+	events = append(events, couponApprovalEvents(order.ID, actorID, reopened)...)
+	if err := s.orderRepo.VoidOrderItems(order.ID, itemIDs, amounts, discounts, events); err != nil {
+		return err
+	}
+	s.requestCouponApprovals(order, reopened)
+	return nil
+}
+
+// rollupOrderStatus calcula el estado de la orden a partir de sus items. Solo aplica
+// mientras la orden está en cocina; los items anulados no cuentan.
+func rollupOrderStatus(current string, items []domain.OrderItem) string {
+	switch current {
+	case StatusApproved, StatusInPreparation, StatusReadyToServe:
+	default:
+		return current
+	}
+
+	active, started, ready, served := 0, 0, 0, 0
+	for _, item := range items {
+		switch item.Status {
+		case domain.ItemStatusVoided:
+			continue
+		case domain.ItemStatusPreparing:
+			started++
+		case domain.ItemStatusReady:
+			started++
+			ready++
+		case domain.ItemStatusServed:
+			started++
+			served++
+		}
+		active++
+	}
+
+	switch {
+	case active == 0:
+		return current
+	case served == active:
+		return StatusDelivered
+	case ready+served == active:
+		return StatusReadyToServe
+	case started > 0:
+		return StatusInPreparation
+	}
+	return current
+}
+
+// kitchenHasOrder indica si los tickets de la orden ya se enviaron a cocina (orden aprobada y aún no cobrada)
+func kitchenHasOrder(status string) bool {
+	switch status {
+	case StatusApproved, StatusInPreparation, StatusReadyToServe, StatusDelivered:
+		return true
+	}
+	return false
+}
+
+func (s *orderService) ManageOrderAsAdmin(orderID, actorID uuid.UUID, userRole string, status *string, newWaiterID *uuid.UUID) (*domain.Order, error) {
+	currentOrder, err := s.orderRepo.GetOrderByID(orderID)
+	if err != nil {
+		return nil, err
+	}
+
 	updates := make(map[string]interface{})
 	if status != nil {
+		if *status == StatusCancelled {
+			return nil, ErrCancelReasonRequired
+		}
+		if err := ValidateOrderTransition(currentOrder.Status, *status, userRole); err != nil {
+			log.Printf("⛔ [Service] %v", err)
+			return nil, err
+		}
+		if *status == StatusPaid {
+			if err := s.settleBeforePaid(currentOrder, actorID); err != nil {
+				log.Printf("⛔ [Service] %v", err)
+				return nil, err
+			}
+		}
 		updates["status"] = *status
 	}
 	if newWaiterID != nil {
 		updates["waiter_id"] = *newWaiterID
 	}
 
-	managedOrder, err := s.orderRepo.ManageOrder(orderID, updates)
+	var events []domain.OrderEvent
+	if status != nil {
+		events = append(events, orderEvent(orderID, &actorID, domain.OrderEventStatusChanged,
+			map[string]interface{}{"status": currentOrder.Status},
+			map[string]interface{}{"status": *status}))
+	}
+	if newWaiterID != nil {
+		// El nombre del nuevo mesero lo resuelve la base al guardar; el historial guarda su ID
+		events = append(events, orderEvent(orderID, &actorID, domain.OrderEventWaiterChanged,
+			map[string]interface{}{"waiter_id": currentOrder.WaiterID, "waiter_name": currentOrder.WaiterName},
+			map[string]interface{}{"waiter_id": *newWaiterID}))
+	}
+
+	managedOrder, err := s.orderRepo.ManageOrder(orderID, currentOrder.Status, updates, events)
 	if err != nil {
 		return nil, err
 	}
+	if status != nil && *status == StatusPaid {
+		s.enqueueNotarization(orderID)
+	}
 	s.wsHub.BroadcastMessage("ORDER_MANAGED", managedOrder)
 	return managedOrder, nil
 }
 
-func (s *orderService) AddPaymentProof(orderID uuid.UUID, method string, proofPath string) (*domain.Order, error) {
+// AddPaymentProof registra el método y el comprobante y pasa la orden a 'por_verificar'. Una
+// orden que ya está por verificar puede reenviar el comprobante; desde cualquier otro estado
+// se valida la transición (una orden pagada o cancelada no se reabre).
+func (s *orderService) AddPaymentProof(orderID, actorID uuid.UUID, userRole, method, proofPath string) (*domain.Order, error) {
 	// Validar método
 	if method != "transferencia" && method != "efectivo" {
 		return nil, errors.New("método de pago inválido")
@@ -336,8 +681,28 @@ func (s *orderService) AddPaymentProof(orderID uuid.UUID, method string, proofPa
 	log.Printf("   - Método: %s", method)
 	log.Printf("   - Ruta comprobante: %s", proofPath)
 
-	// Delegar al repositorio. El repositorio pone el status en 'por_verificar' cuando corresponda.
-	order, err := s.orderRepo.AddPaymentProof(orderID, method, proofPath)
+	previousOrder, err := s.orderRepo.GetOrderByID(orderID)
+	if err != nil {
+		return nil, err
+	}
+	if previousOrder.Status != StatusPendingPayment {
+		if err := ValidateOrderTransition(previousOrder.Status, StatusPendingPayment, userRole); err != nil {
+			log.Printf("⛔ [Service] %v", err)
+			return nil, err
+		}
+	}
+
+	// Sin comprobante nuevo (efectivo) se conserva el anterior
+	newProofPath := previousOrder.PaymentProofPath
+	if proofPath != "" {
+		newProofPath = &proofPath
+	}
+	updated := orderEvent(orderID, &actorID, domain.OrderEventPaymentUpdated,
+		map[string]interface{}{"status": previousOrder.Status, "payment_method": previousOrder.PaymentMethod, "payment_proof_path": previousOrder.PaymentProofPath},
+		map[string]interface{}{"status": StatusPendingPayment, "payment_method": method, "payment_proof_path": newProofPath})
+
+	// Delegar al repositorio. El repositorio pone el status en 'por_verificar' si la orden sigue en el estado validado.
+	order, err := s.orderRepo.AddPaymentProof(orderID, previousOrder.Status, method, proofPath, []domain.OrderEvent{updated})
 	if err != nil {
 		log.Printf("❌ [Backend] Error al actualizar orden %s: %v", orderID.String(), err)
 		return nil, err
@@ -363,3 +728,46 @@ func (s *orderService) AddPaymentProof(orderID uuid.UUID, method string, proofPa
 
 	return order, nil
 }
+
+// GetOrderHistory obtiene el historial de cambios de una orden
+func (s *orderService) GetOrderHistory(orderID uuid.UUID) ([]domain.OrderEvent, error) {
+	if _, err := s.orderRepo.GetOrderByID(orderID); err != nil {
+		return nil, err
+	}
+	return s.eventRepo.GetByOrderID(orderID)
+}
+
+// orderEvent arma un evento del historial de la orden. actorID nil indica un cambio
+// automático del sistema. El repositorio lo guarda en la transacción del cambio que registra:
+// si el evento no se puede guardar, el cambio tampoco.
+func orderEvent(orderID uuid.UUID, actorID *uuid.UUID, eventType string, oldValue, newValue interface{}) domain.OrderEvent {
+	if actorID != nil && *actorID == uuid.Nil {
+		actorID = nil
+	}
+	event := domain.OrderEvent{OrderID: orderID, ActorID: actorID, EventType: eventType}
+	if oldValue != nil {
+		event.OldValue, _ = json.Marshal(oldValue)
+	}
+	if newValue != nil {
+		event.NewValue, _ = json.Marshal(newValue)
+	}
+	return event
+}
+
+// itemsSnapshot resume los items de la orden para el historial
+func itemsSnapshot(items []domain.OrderItem) []map[string]interface{} {
+	snapshot := make([]map[string]interface{}, 0, len(items))
+	for _, item := range items {
+		snapshot = append(snapshot, map[string]interface{}{
+			"id":             item.ID,
+			"menu_item_id":   item.MenuItemID,
+			"menu_item_name": item.MenuItemName,
+			"quantity":       item.Quantity,
+			"price_at_order": item.PriceAtOrder,
+			"notes":          item.Notes,
+			"is_takeout":     item.IsTakeout,
+			"status":         item.Status,
+		})
+	}
+	return snapshot
+}
@@ -0,0 +1,112 @@
+// =================================================================
+// Order State Machine
+// Transiciones permitidas entre estados de una orden y qué roles
+// pueden realizarlas
+// =================================================================
+package service
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Estados de una orden
+const (
+	StatusPendingApproval = "pendiente_aprobacion"
+	StatusReceived        = "recibido"
+	StatusApproved        = "aprobado"
+	StatusInPreparation   = "en_preparacion"
+	StatusReadyToServe    = "listo_para_servir"
+	StatusDelivered       = "entregado"
+	StatusPendingPayment  = "por_verificar"
+	StatusPaid            = "pagado"
+	StatusCancelled       = "cancelado"
+)
+
+// Roles que pueden cambiar el estado de una orden
+const (
+	RoleWaiter  = "mesero"
+	RoleCashier = "cajero"
+	RoleAdmin   = "admin"
+	// RoleAuditor solo lee: no cambia órdenes, consulta y exporta las facturas notarizadas
+	RoleAuditor = "auditor"
+	// RoleSystem se usa para cambios automáticos del backend (ej: rollup del estado de los items)
+	RoleSystem = "system"
+)
+
+// ErrInvalidTransition indica que el cambio de estado no está permitido (409)
+var ErrInvalidTransition = errors.New("transición de estado no permitida")
+
+// orderTransitions define, para cada estado, a qué estados puede pasar y qué roles
+// pueden hacerlo. El admin y el sistema pueden realizar cualquier transición definida.
+// Pasar a 'cancelado' solo se hace con motivo, por /cancel o /void (order_cancellation_service.go).
+var orderTransitions = map[string]map[string][]string{
+	StatusPendingApproval: {
+		StatusApproved:       {RoleCashier},
+		StatusReceived:       {RoleCashier},
+		StatusPendingPayment: {RoleWaiter, RoleCashier}, // Orden creada con pago anticipado (POST /orders/with-payment)
+		StatusCancelled:      {RoleWaiter, RoleCashier}, // Rechazo de la orden (POST /cancel)
+	},
+	StatusReceived: {
+		StatusApproved:      {RoleCashier},
+		StatusInPreparation: {RoleCashier},
+		StatusCancelled:     {RoleCashier}, // POST /cancel
+	},
+	StatusApproved: {
+		StatusInPreparation: {RoleCashier},
+		StatusReadyToServe:  {RoleCashier},
+		StatusDelivered:     {RoleWaiter, RoleCashier},
+		StatusCancelled:     {}, // Anulación (POST /void): solo el admin
+	},
+	StatusInPreparation: {
+		StatusReadyToServe: {RoleCashier},
+		StatusDelivered:    {RoleWaiter, RoleCashier},
+		StatusCancelled:    {},
+	},
+	StatusReadyToServe: {
+		StatusInPreparation: {RoleCashier}, // Un item volvió a preparación
+		StatusDelivered:     {RoleWaiter, RoleCashier},
+		StatusCancelled:     {},
+	},
+	StatusDelivered: {
+		StatusPendingPayment: {RoleWaiter, RoleCashier},
+		StatusPaid:           {RoleCashier},
+		StatusCancelled:      {},
+	},
+	StatusPendingPayment: {
+		StatusPaid:      {RoleCashier},
+		StatusDelivered: {RoleCashier}, // Comprobante rechazado
+		StatusCancelled: {},
+	},
+	StatusPaid:      {},
+	StatusCancelled: {},
+}
+
+// IsValidOrderStatus indica si el estado existe
+func IsValidOrderStatus(status string) bool {
+	_, ok := orderTransitions[status]
+	return ok
+}
+
+// ValidateOrderTransition verifica que la orden pueda pasar de from a to con el rol indicado.
+// Devuelve un error que envuelve ErrInvalidTransition con el motivo.
+func ValidateOrderTransition(from, to, role string) error {
+	if !IsValidOrderStatus(to) {
+		return fmt.Errorf("%w: estado desconocido '%s'", ErrInvalidTransition, to)
+	}
+
+	roles, ok := orderTransitions[from][to]
+	if !ok {
+		return fmt.Errorf("%w: de '%s' a '%s'", ErrInvalidTransition, from, to)
+	}
+
+	if role == RoleAdmin || role == RoleSystem {
+		return nil
+	}
+	for _, allowed := range roles {
+		if allowed == role {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: el rol '%s' no puede pasar la orden de '%s' a '%s'", ErrInvalidTransition, role, from, to)
+}
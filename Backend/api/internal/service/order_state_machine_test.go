@@ -0,0 +1,135 @@
+package service
+
+import (
+	"errors"
+	"testing"
+)
+
+var allOrderStatuses = []string{
+	StatusPendingApproval,
+	StatusReceived,
+	StatusApproved,
+	StatusInPreparation,
+	StatusReadyToServe,
+	StatusDelivered,
+	StatusPendingPayment,
+	StatusPaid,
+	StatusCancelled,
+}
+
+// expectedTransitions es la tabla de ESTADOS_ORDEN.md escrita a mano: qué roles (sin contar
+// admin y system) pueden pasar la orden de un estado a otro. Un par ausente no está permitido.
+var expectedTransitions = map[[2]string][]string{
+	{StatusPendingApproval, StatusApproved}:       {RoleCashier},
+	{StatusPendingApproval, StatusReceived}:       {RoleCashier},
+	{StatusPendingApproval, StatusPendingPayment}: {RoleWaiter, RoleCashier},
+	{StatusPendingApproval, StatusCancelled}:      {RoleWaiter, RoleCashier},
+
+	{StatusReceived, StatusApproved}:      {RoleCashier},
+	{StatusReceived, StatusInPreparation}: {RoleCashier},
+	{StatusReceived, StatusCancelled}:     {RoleCashier},
+
+	{StatusApproved, StatusInPreparation}: {RoleCashier},
+	{StatusApproved, StatusReadyToServe}:  {RoleCashier},
+	{StatusApproved, StatusDelivered}:     {RoleWaiter, RoleCashier},
+	{StatusApproved, StatusCancelled}:     {},
+
+	{StatusInPreparation, StatusReadyToServe}: {RoleCashier},
+	{StatusInPreparation, StatusDelivered}:    {RoleWaiter, RoleCashier},
+	{StatusInPreparation, StatusCancelled}:    {},
+
+	{StatusReadyToServe, StatusInPreparation}: {RoleCashier},
+	{StatusReadyToServe, StatusDelivered}:     {RoleWaiter, RoleCashier},
+	{StatusReadyToServe, StatusCancelled}:     {},
+
+	{StatusDelivered, StatusPendingPayment}: {RoleWaiter, RoleCashier},
+	{StatusDelivered, StatusPaid}:           {RoleCashier},
+	{StatusDelivered, StatusCancelled}:      {},
+
+	{StatusPendingPayment, StatusPaid}:      {RoleCashier},
+	{StatusPendingPayment, StatusDelivered}: {RoleCashier},
+	{StatusPendingPayment, StatusCancelled}: {},
+}
+
+func TestValidateOrderTransition(t *testing.T) {
+	roles := []string{RoleWaiter, RoleCashier, RoleAdmin, RoleSystem, RoleAuditor, "desconocido", ""}
+
+	for _, from := range allOrderStatuses {
+		for _, to := range allOrderStatuses {
+			allowedRoles, defined := expectedTransitions[[2]string{from, to}]
+			for _, role := range roles {
+				want := false
+				if defined {
+					want = role == RoleAdmin || role == RoleSystem || contains(allowedRoles, role)
+				}
+
+				err := ValidateOrderTransition(from, to, role)
+				if want && err != nil {
+					t.Errorf("%s → %s (%q): se esperaba permitida, error: %v", from, to, role, err)
+				}
+				if !want {
+					if err == nil {
+						t.Errorf("%s → %s (%q): se esperaba rechazada", from, to, role)
+					} else if !errors.Is(err, ErrInvalidTransition) {
+						t.Errorf("%s → %s (%q): el error no envuelve ErrInvalidTransition: %v", from, to, role, err)
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestValidateOrderTransitionFinalStates(t *testing.T) {
+	for _, from := range []string{StatusPaid, StatusCancelled} {
+		for _, to := range allOrderStatuses {
+			for _, role := range []string{RoleAdmin, RoleSystem} {
+				if err := ValidateOrderTransition(from, to, role); !errors.Is(err, ErrInvalidTransition) {
+					t.Errorf("%s → %s (%q): un estado final no debe reabrirse, error: %v", from, to, role, err)
+				}
+			}
+		}
+	}
+}
+
+func TestValidateOrderTransitionUnknownStates(t *testing.T) {
+	cases := []struct {
+		name     string
+		from, to string
+	}{
+		{"destino desconocido", StatusDelivered, "reembolsado"},
+		{"origen desconocido", "reembolsado", StatusPaid},
+		{"destino vacío", StatusPendingApproval, ""},
+		{"origen vacío", "", StatusApproved},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			for _, role := range []string{RoleWaiter, RoleCashier, RoleAdmin, RoleSystem} {
+				if err := ValidateOrderTransition(tc.from, tc.to, role); !errors.Is(err, ErrInvalidTransition) {
+					t.Errorf("%q → %q (%q): se esperaba ErrInvalidTransition, error: %v", tc.from, tc.to, role, err)
+				}
+			}
+		})
+	}
+}
+
+func TestIsValidOrderStatus(t *testing.T) {
+	for _, status := range allOrderStatuses {
+		if !IsValidOrderStatus(status) {
+			t.Errorf("'%s' debería ser un estado válido", status)
+		}
+	}
+	for _, status := range []string{"", "reembolsado", "PAGADO"} {
+		if IsValidOrderStatus(status) {
+			t.Errorf("'%s' no debería ser un estado válido", status)
+		}
+	}
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
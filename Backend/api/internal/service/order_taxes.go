@@ -0,0 +1,109 @@
+// =================================================================
+// Order Taxes
+// Impuestos de la orden según la tarifa de la categoría de cada item.
+// Los precios del menú pueden incluir el impuesto (por defecto) o no
+// =================================================================
+package service
+
+import (
+	"math/big"
+
+	"github.com/Hoxanfox/TurnyChain/Backend/api/internal/domain"
+	"github.com/google/uuid"
+)
+
+// pricesIncludeTax indica si los precios del menú ya incluyen el impuesto.
+// true: el impuesto se desglosa del total; false: se suma al total.
+var pricesIncludeTax = true
+
+// SetPricesIncludeTax configura si los precios incluyen el impuesto (se llama al arrancar)
+func SetPricesIncludeTax(included bool) {
+	pricesIncludeTax = included
+}
+
+// orderTaxes agrupa los items por tarifa y calcula la base y el impuesto de cada grupo.
+// El descuento de la orden se reparte entre las tarifas en proporción a su valor; el
+// último grupo se lleva los centavos sobrantes para que el reparto cuadre exacto.
+// Los items anulados no se cobran y no tienen impuesto.
+// Con precios que incluyen el impuesto, la base es neto × 100 / (100 + tarifa) y el
+// impuesto es la diferencia.
+func orderTaxes(items []domain.OrderItem, discount domain.Money) domain.TaxBreakdown {
+	breakdown := domain.TaxBreakdown{}
+	gross := make([]domain.Money, 0)
+	index := make(map[domain.Percent]int)
+	var totalGross domain.Money
+	for _, item := range items {
+		if item.Status == domain.ItemStatusVoided {
+			continue
+		}
+		i, ok := index[item.TaxRate]
+		if !ok {
+			i = len(breakdown)
+			index[item.TaxRate] = i
+			breakdown = append(breakdown, domain.TaxLine{Rate: item.TaxRate})
+			gross = append(gross, 0)
+		}
+		amount := item.PriceAtOrder.Mul(item.Quantity)
+		gross[i] = gross[i].Add(amount)
+		totalGross = totalGross.Add(amount)
+	}
+	if discount > totalGross {
+		discount = totalGross
+	}
+
+	currency := domain.DefaultCurrency()
+	remaining := discount
+	for i := range breakdown {
+		line := &breakdown[i]
+		share := remaining
+		if i < len(breakdown)-1 {
+			share = mulDiv(discount, gross[i], totalGross)
+		}
+		remaining = remaining.Sub(share)
+		net := gross[i].Sub(share)
+
+		switch {
+		case line.Rate == 0:
+			line.Base = net
+		case pricesIncludeTax:
+			base := mulDiv(net, domain.Money(maxPercent), domain.Money(maxPercent+line.Rate))
+			line.Tax = net.Sub(base).Round(currency)
+			line.Base = net.Sub(line.Tax)
+		default:
+			line.Base = net
+			line.Tax = net.ApplyPercent(line.Rate).Round(currency)
+		}
+	}
+	return breakdown
+}
+
+// mulDiv calcula a × b / c redondeando al centavo (mitad hacia arriba) sin desbordar int64
+func mulDiv(a, b, c domain.Money) domain.Money {
+	if c == 0 {
+		return 0
+	}
+	num := new(big.Int).Mul(big.NewInt(int64(a)), big.NewInt(int64(b)))
+	num.Mul(num, big.NewInt(2)).Add(num, big.NewInt(int64(c)))
+	num.Quo(num, new(big.Int).Mul(big.NewInt(int64(c)), big.NewInt(2)))
+	return domain.Money(num.Int64())
+}
+
+// itemsTax calcula el impuesto que se suma al cobrar solo algunos items (pagos divididos),
+// con discount como su parte del descuento de la orden. Con precios que incluyen el
+// impuesto no se suma nada.
+func itemsTax(order *domain.Order, itemIDs []uuid.UUID, discount domain.Money) domain.Money {
+	if pricesIncludeTax {
+		return 0
+	}
+	selected := make(map[uuid.UUID]bool, len(itemIDs))
+	for _, id := range itemIDs {
+		selected[id] = true
+	}
+	items := make([]domain.OrderItem, 0, len(itemIDs))
+	for _, item := range order.Items {
+		if selected[item.ID] {
+			items = append(items, item)
+		}
+	}
+	return orderTaxes(items, discount).Total()
+}
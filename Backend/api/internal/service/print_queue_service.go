@@ -0,0 +1,212 @@
+// =================================================================
+// Print Queue Service
+// Cola persistente de impresión con reintentos (backoff exponencial)
+// y failover a las demás impresoras activas de la estación
+// =================================================================
+package service
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/Hoxanfox/TurnyChain/Backend/api/internal/domain"
+	"github.com/Hoxanfox/TurnyChain/Backend/api/internal/repository"
+	wshub "github.com/Hoxanfox/TurnyChain/Backend/api/internal/websocket"
+	"github.com/google/uuid"
+)
+
+const (
+	printJobMaxAttempts  = 6
+	printJobBaseBackoff  = 5 * time.Second
+	printJobMaxBackoff   = 2 * time.Minute
+	printJobLease        = 2 * time.Minute // Tiempo reservado para procesar un trabajo
+	printQueuePollPeriod = 5 * time.Second
+	printQueueBatchSize  = 20
+)
+
+type PrintQueueService struct {
+	jobRepo     *repository.PrintJobRepository
+	printerRepo *repository.PrinterRepository
+	dispatcher  *PrinterDispatcher
+	wsHub       *wshub.Hub
+	wake        chan struct{}
+}
+
+func NewPrintQueueService(
+	jobRepo *repository.PrintJobRepository,
+	printerRepo *repository.PrinterRepository,
+	dispatcher *PrinterDispatcher,
+	wsHub *wshub.Hub,
+) *PrintQueueService {
+	return &PrintQueueService{
+		jobRepo:     jobRepo,
+		printerRepo: printerRepo,
+		dispatcher:  dispatcher,
+		wsHub:       wsHub,
+		wake:        make(chan struct{}, 1),
+	}
+}
+
+// Run procesa la cola en segundo plano. Se debe ejecutar en una goroutine.
+func (s *PrintQueueService) Run() {
+	ticker := time.NewTicker(printQueuePollPeriod)
+	defer ticker.Stop()
+
+	for {
+		s.processDue()
+		select {
+		case <-ticker.C:
+		case <-s.wake:
+		}
+	}
+}
+
+// processDue toma los trabajos vencidos y realiza un intento por cada uno
+func (s *PrintQueueService) processDue() {
+	jobs, err := s.jobRepo.ClaimDue(printQueueBatchSize, printJobLease)
+	if err != nil {
+		log.Printf("⚠️ [PrintQueue] Error obteniendo trabajos pendientes: %v", err)
+		return
+	}
+	for i := range jobs {
+		s.attempt(&jobs[i])
+	}
+}
+
+// Enqueue guarda el ticket en la cola y realiza el primer intento de inmediato.
+// Devuelve el trabajo con su estado tras ese intento.
+func (s *PrintQueueService) Enqueue(ticket domain.KitchenTicket) (*domain.PrintJob, error) {
+	// Se reserva el trabajo durante el primer intento para que el worker no lo duplique
+	job, err := s.jobRepo.Create(ticket, printJobMaxAttempts, time.Now().Add(printJobLease))
+	if err != nil {
+		return nil, fmt.Errorf("error al encolar ticket: %w", err)
+	}
+	s.attempt(job)
+	return job, nil
+}
+
+// attempt intenta imprimir el trabajo en las impresoras activas de su estación,
+// en orden, hasta que una lo acepte. Si todas fallan programa un reintento.
+func (s *PrintQueueService) attempt(job *domain.PrintJob) {
+	job.Attempts++
+
+	printers, err := s.printerRepo.GetByStationIDs([]uuid.UUID{job.StationID})
+	if err != nil {
+		s.scheduleRetry(job, nil, fmt.Sprintf("error al obtener impresoras: %v", err))
+		return
+	}
+	if len(printers) == 0 {
+		s.scheduleRetry(job, nil, "No hay impresoras configuradas para esta estación")
+		return
+	}
+
+	var errs []string
+	var lastPrinter *domain.Printer
+	for i := range printers {
+		printer := printers[i]
+		lastPrinter = &printer
+		if err := s.dispatcher.PrintKitchenTicket(printer, job.Ticket); err != nil {
+			log.Printf("❌ [PrintQueue] Error al imprimir en %s (%s): %v", printer.Name, job.StationName, err)
+			errs = append(errs, fmt.Sprintf("%s: %v", printer.Name, err))
+			continue
+		}
+
+		if err := s.jobRepo.MarkSent(job.ID, printer.ID, job.Attempts); err != nil {
+			log.Printf("⚠️ [PrintQueue] Ticket impreso pero no se pudo actualizar el trabajo %s: %v", job.ID, err)
+		}
+		if i > 0 {
+			log.Printf("🔁 [PrintQueue] Ticket de %s impreso en impresora de respaldo %s", job.StationName, printer.Name)
+		} else {
+			log.Printf("✅ [PrintQueue] Ticket enviado a %s (%s)", printer.Name, job.StationName)
+		}
+		s.refreshAndBroadcast(job)
+		return
+	}
+
+	s.scheduleRetry(job, &lastPrinter.ID, strings.Join(errs, "; "))
+}
+
+// scheduleRetry registra el fallo y programa el siguiente intento, o marca el trabajo
+// como fallido si se agotaron los reintentos
+func (s *PrintQueueService) scheduleRetry(job *domain.PrintJob, printerID *uuid.UUID, lastError string) {
+	if job.Attempts >= job.MaxAttempts {
+		if err := s.jobRepo.MarkFailed(job.ID, printerID, job.Attempts, lastError); err != nil {
+			log.Printf("⚠️ [PrintQueue] No se pudo marcar como fallido el trabajo %s: %v", job.ID, err)
+		}
+		log.Printf("🚨 [PrintQueue] Ticket de %s (orden %s) falló tras %d intentos: %s", job.StationName, job.Ticket.OrderNumber, job.Attempts, lastError)
+		s.refreshAndBroadcast(job)
+		return
+	}
+
+	next := time.Now().Add(printJobBackoff(job.Attempts))
+	if err := s.jobRepo.MarkRetry(job.ID, printerID, job.Attempts, lastError, next); err != nil {
+		log.Printf("⚠️ [PrintQueue] No se pudo programar el reintento del trabajo %s: %v", job.ID, err)
+	}
+	log.Printf("⏳ [PrintQueue] Reintento %d/%d de %s programado para %s", job.Attempts+1, job.MaxAttempts, job.StationName, next.Format("15:04:05"))
+	s.refreshAndBroadcast(job)
+}
+
+// refreshAndBroadcast recarga el trabajo desde la BD y notifica su estado por WebSocket
+func (s *PrintQueueService) refreshAndBroadcast(job *domain.PrintJob) {
+	updated, err := s.jobRepo.GetByID(job.ID)
+	if err != nil || updated == nil {
+		log.Printf("⚠️ [PrintQueue] No se pudo recargar el trabajo %s: %v", job.ID, err)
+		return
+	}
+	*job = *updated
+	s.wsHub.BroadcastMessage("PRINT_JOB_UPDATED", job)
+}
+
+// printJobBackoff calcula la espera antes del siguiente intento: 5s, 10s, 20s... hasta 2 min
+func printJobBackoff(attempts int) time.Duration {
+	backoff := printJobBaseBackoff
+	for i := 1; i < attempts && backoff < printJobMaxBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > printJobMaxBackoff {
+		backoff = printJobMaxBackoff
+	}
+	return backoff
+}
+
+// GetByOrderID obtiene los trabajos de impresión de una orden
+func (s *PrintQueueService) GetByOrderID(orderID uuid.UUID) ([]domain.PrintJob, error) {
+	return s.jobRepo.GetByOrderID(orderID)
+}
+
+// GetAll obtiene los últimos trabajos de impresión, opcionalmente filtrados por estado
+func (s *PrintQueueService) GetAll(status string) ([]domain.PrintJob, error) {
+	switch domain.PrintJobStatus(status) {
+	case "", domain.PrintJobQueued, domain.PrintJobSent, domain.PrintJobFailed:
+	default:
+		return nil, fmt.Errorf("estado inválido: %s", status)
+	}
+	return s.jobRepo.GetAll(status, 200)
+}
+
+// Retry vuelve a poner en cola un trabajo fallido y despierta al worker
+func (s *PrintQueueService) Retry(id uuid.UUID) (*domain.PrintJob, error) {
+	job, err := s.jobRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if job == nil {
+		return nil, fmt.Errorf("print job not found")
+	}
+	if job.Status == domain.PrintJobSent {
+		return nil, fmt.Errorf("el ticket ya fue impreso")
+	}
+
+	if err := s.jobRepo.Requeue(id); err != nil {
+		return nil, err
+	}
+	s.refreshAndBroadcast(job)
+
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+	return job, nil
+}
@@ -0,0 +1,137 @@
+// =================================================================
+// Printer Dispatcher
+// Pipeline único de impresión: codifica un documento según el tipo
+// de impresora y lo entrega (TCP o archivo PDF)
+// =================================================================
+package service
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Hoxanfox/TurnyChain/Backend/api/internal/domain"
+	"github.com/Hoxanfox/TurnyChain/Backend/api/internal/printing"
+)
+
+type PrinterDispatcher struct {
+	transport printing.Transport
+	pdfDir    string // Carpeta donde se guardan los documentos de impresoras tipo "pdf"
+}
+
+func NewPrinterDispatcher(pdfDir string) *PrinterDispatcher {
+	return &PrinterDispatcher{
+		transport: printing.NewTCPTransport(),
+		pdfDir:    pdfDir,
+	}
+}
+
+// PrintKitchenTicket arma el layout del ticket de cocina y lo envía a la impresora
+func (d *PrinterDispatcher) PrintKitchenTicket(printer domain.Printer, ticket domain.KitchenTicket) error {
+	doc := printing.KitchenTicketDocument(ticket, printing.DefaultColumns)
+	return d.PrintDocument(printer, doc, ticket.OrderID.String())
+}
+
+// PrintDocument envía un documento a una impresora específica según su tipo.
+// Para impresoras "pdf", el archivo se guarda en <pdfDir>/<folder>/.
+func (d *PrinterDispatcher) PrintDocument(printer domain.Printer, doc *printing.Document, folder string) error {
+	switch printer.PrinterType {
+	case domain.PrinterTypeESCPOS:
+		data := printing.EncodeESCPOS(doc)
+		log.Printf("📄 Enviando %d bytes ESC/POS a %s (%s:%d) | %s", len(data), printer.Name, printer.IPAddress, printer.Port, doc.Title)
+		return d.transport.Send(printer.IPAddress, printer.Port, data)
+	case domain.PrinterTypePDF:
+		path, err := d.savePDF(printer, doc, folder)
+		if err != nil {
+			return err
+		}
+		log.Printf("📄 PDF generado para %s | %s | Archivo: %s", printer.Name, doc.Title, path)
+		return nil
+	case domain.PrinterTypeRaw:
+		data := printing.EncodePlainText(doc)
+		log.Printf("📄 Enviando %d bytes de texto plano a %s (%s:%d) | %s", len(data), printer.Name, printer.IPAddress, printer.Port, doc.Title)
+		return d.transport.Send(printer.IPAddress, printer.Port, data)
+	default:
+		return fmt.Errorf("tipo de impresora no soportado: %s", printer.PrinterType)
+	}
+}
+
+// savePDF guarda el PDF en <pdfDir>/<folder>/<fecha>_<título>_<impresora>.pdf
+func (d *PrinterDispatcher) savePDF(printer domain.Printer, doc *printing.Document, folder string) (string, error) {
+	dir := filepath.Join(d.pdfDir, folder)
+	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		return "", fmt.Errorf("no se pudo crear la carpeta de tickets: %w", err)
+	}
+
+	fileName := fmt.Sprintf("%s_%s_%s.pdf", time.Now().Format("20060102-150405.000"), slugify(doc.Title), slugify(printer.Name))
+	path := filepath.Join(dir, fileName)
+	if err := os.WriteFile(path, printing.EncodePDF(doc), 0o644); err != nil {
+		return "", fmt.Errorf("no se pudo guardar el ticket PDF: %w", err)
+	}
+	return path, nil
+}
+
+// ListPDFs lista los PDFs generados en una subcarpeta (más recientes primero)
+func (d *PrinterDispatcher) ListPDFs(folder string) ([]domain.KitchenTicketFile, error) {
+	entries, err := os.ReadDir(filepath.Join(d.pdfDir, folder))
+	if os.IsNotExist(err) {
+		return []domain.KitchenTicketFile{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	files := make([]domain.KitchenTicketFile, 0, len(entries))
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".pdf" {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		files = append(files, domain.KitchenTicketFile{
+			FileName:  entry.Name(),
+			Size:      info.Size(),
+			CreatedAt: info.ModTime(),
+		})
+	}
+
+	sort.Slice(files, func(i, j int) bool { return files[i].CreatedAt.After(files[j].CreatedAt) })
+	return files, nil
+}
+
+// PDFPath devuelve la ruta en disco de un PDF de la subcarpeta
+func (d *PrinterDispatcher) PDFPath(folder, fileName string) (string, error) {
+	// Evitar path traversal: solo se acepta un nombre de archivo .pdf plano
+	if fileName != filepath.Base(fileName) || filepath.Ext(fileName) != ".pdf" {
+		return "", fmt.Errorf("nombre de archivo inválido")
+	}
+
+	path := filepath.Join(d.pdfDir, folder, fileName)
+	if _, err := os.Stat(path); err != nil {
+		return "", fmt.Errorf("ticket no encontrado")
+	}
+	return path, nil
+}
+
+// slugify genera un nombre seguro para archivos a partir de un texto libre
+func slugify(text string) string {
+	replacer := strings.NewReplacer("á", "a", "é", "e", "í", "i", "ó", "o", "ú", "u", "ñ", "n", "ü", "u")
+	text = replacer.Replace(strings.ToLower(text))
+
+	var b strings.Builder
+	for _, r := range text {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9':
+			b.WriteRune(r)
+		case b.Len() > 0 && !strings.HasSuffix(b.String(), "-"):
+			b.WriteByte('-')
+		}
+	}
+	return strings.Trim(b.String(), "-")
+}
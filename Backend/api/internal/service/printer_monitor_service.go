@@ -0,0 +1,100 @@
+// =================================================================
+// Printer Monitor Service
+// Verifica periódicamente la conectividad de las impresoras activas
+// =================================================================
+package service
+
+import (
+	"log"
+	"sync"
+	"time"
+
+	"github.com/Hoxanfox/TurnyChain/Backend/api/internal/domain"
+	"github.com/Hoxanfox/TurnyChain/Backend/api/internal/printing"
+	"github.com/Hoxanfox/TurnyChain/Backend/api/internal/repository"
+	wshub "github.com/Hoxanfox/TurnyChain/Backend/api/internal/websocket"
+)
+
+const (
+	printerProbeInterval = 30 * time.Second
+	printerProbeTimeout  = 2 * time.Second
+)
+
+type PrinterMonitorService struct {
+	printerRepo *repository.PrinterRepository
+	wsHub       *wshub.Hub
+}
+
+func NewPrinterMonitorService(printerRepo *repository.PrinterRepository, wsHub *wshub.Hub) *PrinterMonitorService {
+	return &PrinterMonitorService{printerRepo: printerRepo, wsHub: wsHub}
+}
+
+// Run verifica las impresoras cada printerProbeInterval. Se debe ejecutar en una goroutine.
+func (s *PrinterMonitorService) Run() {
+	ticker := time.NewTicker(printerProbeInterval)
+	defer ticker.Stop()
+
+	for {
+		s.probeAll()
+		<-ticker.C
+	}
+}
+
+// probeAll verifica en paralelo todas las impresoras de red activas
+func (s *PrinterMonitorService) probeAll() {
+	printers, err := s.printerRepo.GetAllActive()
+	if err != nil {
+		log.Printf("⚠️ [PrinterMonitor] Error obteniendo impresoras: %v", err)
+		return
+	}
+
+	var wg sync.WaitGroup
+	for _, printer := range printers {
+		// Las impresoras PDF no tienen conexión de red que verificar
+		if printer.PrinterType == domain.PrinterTypePDF {
+			continue
+		}
+		wg.Add(1)
+		go func(p domain.Printer) {
+			defer wg.Done()
+			s.probe(p)
+		}(printer)
+	}
+	wg.Wait()
+}
+
+// probe conecta con la impresora, guarda el resultado y notifica si cambió su estado
+func (s *PrinterMonitorService) probe(printer domain.Printer) {
+	checkedAt := time.Now()
+	latency, err := printing.Probe(printer.IPAddress, printer.Port, printerProbeTimeout)
+	s.RecordStatus(printer, latency, checkedAt, err)
+}
+
+// RecordStatus guarda el resultado de una conexión con la impresora (probeErr nil: en línea)
+// y notifica PRINTER_STATUS_CHANGED si cambió su estado. Lo usan el chequeo periódico y las
+// impresiones que confirman la conexión (p. ej. la página de prueba).
+func (s *PrinterMonitorService) RecordStatus(printer domain.Printer, latency time.Duration, checkedAt time.Time, probeErr error) {
+	online := probeErr == nil
+
+	if err := s.printerRepo.UpdateStatus(printer.ID, online, int(latency.Milliseconds()), checkedAt); err != nil {
+		log.Printf("⚠️ [PrinterMonitor] Error guardando estado de %s: %v", printer.Name, err)
+		return
+	}
+
+	// Solo se notifica cuando cambia el estado (o en el primer chequeo)
+	if printer.LastCheckedAt != nil && printer.IsOnline == online {
+		return
+	}
+
+	if online {
+		log.Printf("🟢 [PrinterMonitor] %s (%s:%d) en línea (%dms)", printer.Name, printer.IPAddress, printer.Port, latency.Milliseconds())
+	} else {
+		log.Printf("🔴 [PrinterMonitor] %s (%s:%d) fuera de línea: %v", printer.Name, printer.IPAddress, printer.Port, probeErr)
+	}
+
+	updated, err := s.printerRepo.GetByID(printer.ID)
+	if err != nil || updated == nil {
+		return
+	}
+	s.wsHub.BroadcastMessage("PRINTER_STATUS_CHANGED", updated)
+}
@@ -4,19 +4,27 @@
 package service
 
 import (
+	"errors"
 	"fmt"
+	"time"
 
 	"github.com/Hoxanfox/TurnyChain/Backend/api/internal/domain"
+	"github.com/Hoxanfox/TurnyChain/Backend/api/internal/printing"
 	"github.com/Hoxanfox/TurnyChain/Backend/api/internal/repository"
 	"github.com/google/uuid"
 )
 
+// ErrPrinterNotFound indica que la impresora no existe
+var ErrPrinterNotFound = errors.New("printer not found")
+
 type PrinterService struct {
-	repo *repository.PrinterRepository
+	repo       *repository.PrinterRepository
+	dispatcher *PrinterDispatcher
+	monitor    *PrinterMonitorService
 }
 
-func NewPrinterService(repo *repository.PrinterRepository) *PrinterService {
-	return &PrinterService{repo: repo}
+func NewPrinterService(repo *repository.PrinterRepository, dispatcher *PrinterDispatcher, monitor *PrinterMonitorService) *PrinterService {
+	return &PrinterService{repo: repo, dispatcher: dispatcher, monitor: monitor}
 }
 
 func (s *PrinterService) GetAll() ([]domain.Printer, error) {
@@ -33,7 +41,7 @@ func (s *PrinterService) GetByID(id uuid.UUID) (*domain.Printer, error) {
 		return nil, err
 	}
 	if printer == nil {
-		return nil, fmt.Errorf("printer not found")
+		return nil, ErrPrinterNotFound
 	}
 	return printer, nil
 }
@@ -63,3 +71,37 @@ func (s *PrinterService) Delete(id uuid.UUID) error {
 	}
 	return s.repo.Delete(id)
 }
+
+// TestPrint envía una página de prueba por el mismo pipeline que los tickets de cocina
+func (s *PrinterService) TestPrint(id uuid.UUID) (*domain.TestPrintResponse, error) {
+	printer, err := s.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	doc := printing.TestPageDocument(*printer, printing.DefaultColumns)
+	start := time.Now()
+	err = s.dispatcher.PrintDocument(*printer, doc, "tests")
+	elapsed := time.Since(start)
+
+	if err != nil {
+		return &domain.TestPrintResponse{
+			Success:   false,
+			Message:   fmt.Sprintf("No se pudo imprimir la página de prueba en %s", printer.Name),
+			Error:     err.Error(),
+			LatencyMs: elapsed.Milliseconds(),
+		}, nil
+	}
+
+	// Una impresión exitosa confirma que la impresora está en línea: se registra como un
+	// chequeo del monitor, que avisa si la impresora estaba fuera de línea
+	if printer.PrinterType != domain.PrinterTypePDF && s.monitor != nil {
+		s.monitor.RecordStatus(*printer, elapsed, time.Now(), nil)
+	}
+
+	return &domain.TestPrintResponse{
+		Success:   true,
+		Message:   fmt.Sprintf("Página de prueba enviada a %s", printer.Name),
+		LatencyMs: elapsed.Milliseconds(),
+	}, nil
+}
@@ -0,0 +1,200 @@
+// =================================================================
+// Promotion Service
+// =================================================================
+package service
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Hoxanfox/TurnyChain/Backend/api/internal/domain"
+	"github.com/Hoxanfox/TurnyChain/Backend/api/internal/repository"
+	wshub "github.com/Hoxanfox/TurnyChain/Backend/api/internal/websocket"
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrInvalidPromotion indica una promoción con datos incompletos o incoherentes
+	ErrInvalidPromotion = errors.New("promoción inválida")
+	// ErrPromotionNotFound indica que la promoción no existe
+	ErrPromotionNotFound = errors.New("promoción no encontrada")
+	// ErrDuplicateCoupon indica que otro cupón ya usa ese código
+	ErrDuplicateCoupon = errors.New("ya existe una promoción con ese cupón")
+)
+
+type PromotionService struct {
+	repo  *repository.PromotionRepository
+	wsHub *wshub.Hub
+}
+
+func NewPromotionService(repo *repository.PromotionRepository, wsHub *wshub.Hub) *PromotionService {
+	return &PromotionService{repo: repo, wsHub: wsHub}
+}
+
+func (s *PromotionService) GetAll() ([]domain.Promotion, error) {
+	return s.repo.GetAll()
+}
+
+func (s *PromotionService) GetByID(id uuid.UUID) (*domain.Promotion, error) {
+	promotion, err := s.repo.GetByID(id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrPromotionNotFound
+	}
+	return promotion, err
+}
+
+func (s *PromotionService) Create(req domain.PromotionRequest, createdBy uuid.UUID) (*domain.Promotion, error) {
+	promotion, err := s.buildPromotion(uuid.Nil, req)
+	if err != nil {
+		return nil, err
+	}
+	promotion.CreatedBy = &createdBy
+
+	created, err := s.repo.Create(promotion)
+	if err != nil {
+		return nil, err
+	}
+	s.wsHub.BroadcastMessage("PROMOTIONS_UPDATED", created)
+	return created, nil
+}
+
+func (s *PromotionService) Update(id uuid.UUID, req domain.PromotionRequest) (*domain.Promotion, error) {
+	promotion, err := s.buildPromotion(id, req)
+	if err != nil {
+		return nil, err
+	}
+	promotion.ID = id
+
+	updated, err := s.repo.Update(promotion)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrPromotionNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	s.wsHub.BroadcastMessage("PROMOTIONS_UPDATED", updated)
+	return updated, nil
+}
+
+func (s *PromotionService) Delete(id uuid.UUID) error {
+	err := s.repo.Delete(id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrPromotionNotFound
+	}
+	if err != nil {
+		return err
+	}
+	s.wsHub.BroadcastMessage("PROMOTIONS_UPDATED", map[string]string{"deleted_id": id.String()})
+	return nil
+}
+
+// buildPromotion valida el payload y arma la promoción. id es la promoción que se edita
+// (uuid.Nil al crear), para no confundir su propio cupón con un duplicado.
+func (s *PromotionService) buildPromotion(id uuid.UUID, req domain.PromotionRequest) (*domain.Promotion, error) {
+	invalid := func(reason string) error { return fmt.Errorf("%w: %s", ErrInvalidPromotion, reason) }
+
+	promotion := &domain.Promotion{
+		Name:        strings.TrimSpace(req.Name),
+		Description: req.Description,
+		Type:        req.Type,
+		Scope:       req.Scope,
+		TargetIDs:   req.TargetIDs,
+		DaysOfWeek:  req.DaysOfWeek,
+		StartTime:   req.StartTime,
+		EndTime:     req.EndTime,
+		StartsAt:    req.StartsAt,
+		EndsAt:      req.EndsAt,
+		MaxUses:     req.MaxUses,
+		IsActive:    req.IsActive == nil || *req.IsActive,
+	}
+	if promotion.TargetIDs == nil {
+		promotion.TargetIDs = []uuid.UUID{}
+	}
+	if promotion.DaysOfWeek == nil {
+		promotion.DaysOfWeek = []int{}
+	}
+
+	if promotion.Name == "" {
+		return nil, invalid("el nombre es obligatorio")
+	}
+	if !domain.IsValidPromotionType(promotion.Type) {
+		return nil, invalid(fmt.Sprintf("tipo '%s'", promotion.Type))
+	}
+
+	// Cada tipo usa solo su campo de valor
+	switch promotion.Type {
+	case domain.PromotionTypePercentage:
+		if req.Percentage <= 0 || req.Percentage > maxPercent {
+			return nil, invalid("el porcentaje debe estar entre 0 y 100")
+		}
+		promotion.Percentage = req.Percentage
+	case domain.PromotionTypeFixed:
+		if req.Amount <= 0 {
+			return nil, invalid("el monto debe ser mayor a cero")
+		}
+		promotion.Amount = req.Amount
+	case domain.PromotionTypeCombo:
+		if req.Amount <= 0 {
+			return nil, invalid("el precio del combo debe ser mayor a cero")
+		}
+		if len(promotion.TargetIDs) < 2 {
+			return nil, invalid("un combo necesita al menos dos productos")
+		}
+		promotion.Amount = req.Amount
+		promotion.Scope = domain.PromotionScopeMenuItem
+	}
+
+	if !domain.IsValidPromotionScope(promotion.Scope) {
+		return nil, invalid(fmt.Sprintf("alcance '%s'", promotion.Scope))
+	}
+	if promotion.Scope == domain.PromotionScopeOrder {
+		promotion.TargetIDs = []uuid.UUID{}
+	} else if len(promotion.TargetIDs) == 0 {
+		return nil, invalid("indique los productos o categorías de la promoción")
+	}
+
+	for _, day := range promotion.DaysOfWeek {
+		if day < 0 || day > 6 {
+			return nil, invalid("los días de la semana van de 0 (domingo) a 6 (sábado)")
+		}
+	}
+	if (promotion.StartTime == nil) != (promotion.EndTime == nil) {
+		return nil, invalid("indique hora de inicio y de fin")
+	}
+	// Las horas se guardan como HH:MM para poder compararlas como texto
+	for _, clock := range []**string{&promotion.StartTime, &promotion.EndTime} {
+		if *clock == nil {
+			continue
+		}
+		parsed, err := time.Parse("15:04", strings.TrimSpace(**clock))
+		if err != nil {
+			return nil, invalid(fmt.Sprintf("hora '%s', use HH:MM", **clock))
+		}
+		normalized := parsed.Format("15:04")
+		*clock = &normalized
+	}
+	if promotion.StartsAt != nil && promotion.EndsAt != nil && !promotion.StartsAt.Before(*promotion.EndsAt) {
+		return nil, invalid("la fecha de inicio debe ser anterior a la de fin")
+	}
+	if promotion.MaxUses != nil && *promotion.MaxUses < 1 {
+		return nil, invalid("el límite de usos debe ser mayor a cero")
+	}
+
+	if req.CouponCode != nil {
+		code := strings.ToUpper(strings.TrimSpace(*req.CouponCode))
+		if code != "" {
+			existing, err := s.repo.GetByCouponCode(code)
+			if err != nil && !errors.Is(err, sql.ErrNoRows) {
+				return nil, err
+			}
+			if err == nil && existing.ID != id {
+				return nil, fmt.Errorf("%w: %s", ErrDuplicateCoupon, code)
+			}
+			promotion.CouponCode = &code
+		}
+	}
+	return promotion, nil
+}
@@ -0,0 +1,176 @@
+// =================================================================
+// Receipt Service
+// Recibo del cliente en HTML/PDF y su impresión en la impresora de
+// una estación de caja
+// =================================================================
+package service
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/Hoxanfox/TurnyChain/Backend/api/internal/domain"
+	"github.com/Hoxanfox/TurnyChain/Backend/api/internal/printing"
+	"github.com/Hoxanfox/TurnyChain/Backend/api/internal/repository"
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrInvalidReceiptFormat indica un formato de recibo distinto de html o pdf
+	ErrInvalidReceiptFormat = errors.New("formato de recibo inválido")
+	// ErrNoCashierPrinter indica que no hay impresoras activas en estaciones de caja
+	ErrNoCashierPrinter = errors.New("no hay impresoras de caja configuradas")
+)
+
+// restaurantName es el nombre que encabeza los recibos
+var restaurantName = "TurnyChain"
+
+// explorerURL es la URL del explorador de bloques para ver una transacción (se le agrega
+// el hash). Vacía: el QR lleva los datos de verificación en lugar de un enlace.
+var explorerURL = ""
+
+// SetReceiptConfig configura el nombre del restaurante y el explorador de bloques (se llama al arrancar)
+func SetReceiptConfig(name, explorer string) {
+	if name != "" {
+		restaurantName = name
+	}
+	explorerURL = explorer
+}
+
+type ReceiptService struct {
+	orders      OrderService
+	printerRepo *repository.PrinterRepository
+	dispatcher  *PrinterDispatcher
+}
+
+func NewReceiptService(orders OrderService, printerRepo *repository.PrinterRepository, dispatcher *PrinterDispatcher) *ReceiptService {
+	return &ReceiptService{orders: orders, printerRepo: printerRepo, dispatcher: dispatcher}
+}
+
+// BuildReceipt reúne la orden, sus pagos y descuentos aplicados y los datos de verificación
+func (s *ReceiptService) BuildReceipt(orderID uuid.UUID) (*domain.Receipt, error) {
+	order, err := s.orders.GetOrderByID(orderID)
+	if err != nil {
+		return nil, err
+	}
+	balance, err := s.orders.GetOrderBalance(orderID)
+	if err != nil {
+		return nil, err
+	}
+	discounts, err := s.orders.GetOrderDiscounts(orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	applied := make([]domain.OrderDiscount, 0, len(discounts))
+	for _, discount := range discounts {
+		if discount.Status == domain.DiscountStatusApplied {
+			applied = append(applied, discount)
+		}
+	}
+
+	invoiceHash := domain.CreateBlockchainInvoice(order).Hash
+	return &domain.Receipt{
+		RestaurantName:   restaurantName,
+		Order:            order,
+		Payments:         balance.Payments,
+		Discounts:        applied,
+		PricesIncludeTax: pricesIncludeTax,
+		InvoiceHash:      invoiceHash,
+		VerificationData: verificationData(order, invoiceHash),
+		GeneratedAt:      time.Now(),
+	}, nil
+}
+
+// RenderReceipt genera el recibo en el formato pedido y devuelve el contenido y su Content-Type
+func (s *ReceiptService) RenderReceipt(orderID uuid.UUID, format string) ([]byte, string, error) {
+	if format != domain.ReceiptFormatHTML && format != domain.ReceiptFormatPDF {
+		return nil, "", fmt.Errorf("%w: '%s'", ErrInvalidReceiptFormat, format)
+	}
+
+	receipt, err := s.BuildReceipt(orderID)
+	if err != nil {
+		return nil, "", err
+	}
+	doc := printing.ReceiptDocument(*receipt, printing.DefaultColumns)
+
+	if format == domain.ReceiptFormatPDF {
+		return printing.EncodePDF(doc), "application/pdf", nil
+	}
+	return printing.EncodeHTML(doc), "text/html; charset=utf-8", nil
+}
+
+// PrintReceipt imprime el recibo en la impresora indicada o, sin printer_id, en la primera
+// impresora de caja que lo acepte (las que están en línea se intentan primero)
+func (s *ReceiptService) PrintReceipt(orderID uuid.UUID, req domain.PrintReceiptRequest) (*domain.PrintReceiptResponse, error) {
+	receipt, err := s.BuildReceipt(orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	var printers []domain.Printer
+	if req.PrinterID != nil {
+		printer, err := s.printerRepo.GetByID(*req.PrinterID)
+		if err != nil {
+			return nil, err
+		}
+		if printer == nil {
+			return nil, fmt.Errorf("printer not found")
+		}
+		printers = []domain.Printer{*printer}
+	} else {
+		printers, err = s.printerRepo.GetCashierPrinters()
+		if err != nil {
+			return nil, err
+		}
+		if len(printers) == 0 {
+			return nil, ErrNoCashierPrinter
+		}
+	}
+
+	doc := printing.ReceiptDocument(*receipt, printing.DefaultColumns)
+	// Las impresoras "pdf" guardan el recibo en la carpeta privada del dispatcher, separado
+	// por orden; el recibo solo se descarga autenticado con GET /api/orders/:id/receipt
+	folder := filepath.Join("receipts", orderID.String())
+	var errs []string
+	for _, printer := range printers {
+		if err := s.dispatcher.PrintDocument(printer, doc, folder); err != nil {
+			log.Printf("⚠️ No se pudo imprimir el recibo de la orden %s en %s: %v", orderID, printer.Name, err)
+			errs = append(errs, fmt.Sprintf("%s: %v", printer.Name, err))
+			continue
+		}
+		log.Printf("🧾 Recibo de la orden %s impreso en %s", orderID, printer.Name)
+		return &domain.PrintReceiptResponse{
+			Success:     true,
+			Message:     fmt.Sprintf("Recibo enviado a %s", printer.Name),
+			PrinterName: printer.Name,
+		}, nil
+	}
+
+	return &domain.PrintReceiptResponse{
+		Success: false,
+		Message: "No se pudo imprimir el recibo",
+		Error:   strings.Join(errs, "; "),
+	}, nil
+}
+
+// verificationData es el contenido del QR: el enlace al explorador si hay transacción y
+// explorador configurado, o los datos para verificar la factura contra la cadena
+func verificationData(order *domain.Order, invoiceHash string) string {
+	if order.BlockchainTxHash != nil && explorerURL != "" {
+		return explorerURL + *order.BlockchainTxHash
+	}
+
+	query := url.Values{}
+	query.Set("order", order.ID.String())
+	query.Set("invoice", invoiceHash)
+	if order.BlockchainTxHash != nil {
+		query.Set("tx", *order.BlockchainTxHash)
+	}
+	return "turnychain:verify?" + query.Encode()
+}
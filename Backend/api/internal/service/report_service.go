@@ -0,0 +1,87 @@
+// =================================================================
+// Report Service
+// =================================================================
+package service
+
+import (
+	"errors"
+	"time"
+
+	"github.com/Hoxanfox/TurnyChain/Backend/api/internal/domain"
+	"github.com/Hoxanfox/TurnyChain/Backend/api/internal/repository"
+	"github.com/google/uuid"
+)
+
+// ErrInvalidReportRange indica un rango de fechas vacío o invertido
+var ErrInvalidReportRange = errors.New("rango de fechas inválido")
+
+type ReportService struct {
+	repo *repository.ReportRepository
+}
+
+func NewReportService(repo *repository.ReportRepository) *ReportService {
+	return &ReportService{repo: repo}
+}
+
+// GetWaiterReport arma el reporte de ventas y propinas por mesero en [from, to).
+// Un mesero solo ve su propia fila; cajero y admin ven a todos.
+func (s *ReportService) GetWaiterReport(from, to time.Time, userID uuid.UUID, userRole string) (*domain.WaiterReportResponse, error) {
+	if !from.Before(to) {
+		return nil, ErrInvalidReportRange
+	}
+
+	var waiterID *uuid.UUID
+	if userRole == RoleWaiter {
+		waiterID = &userID
+	}
+
+	waiters, err := s.repo.GetWaiterReport(from, to, waiterID)
+	if err != nil {
+		return nil, err
+	}
+	return &domain.WaiterReportResponse{From: from, To: to, Waiters: waiters}, nil
+}
+
+// GetCancellationReport lista las órdenes canceladas y anuladas en [from, to) y las agrupa
+// por tipo y motivo. Un mesero solo ve las de sus órdenes.
+func (s *ReportService) GetCancellationReport(from, to time.Time, userID uuid.UUID, userRole string) (*domain.CancellationReportResponse, error) {
+	if !from.Before(to) {
+		return nil, ErrInvalidReportRange
+	}
+
+	var waiterID *uuid.UUID
+	if userRole == RoleWaiter {
+		waiterID = &userID
+	}
+
+	cancellations, err := s.repo.GetCancellations(from, to, waiterID)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &domain.CancellationReportResponse{
+		From:          from,
+		To:            to,
+		ByReason:      make([]domain.CancellationReasonSummary, 0),
+		Cancellations: cancellations,
+	}
+	index := make(map[string]int)
+	for _, c := range cancellations {
+		if c.Kind == domain.CancellationKindVoid {
+			report.Voided++
+		} else {
+			report.Cancelled++
+		}
+
+		key := c.Kind + "|" + c.ReasonCode
+		i, ok := index[key]
+		if !ok {
+			i = len(report.ByReason)
+			index[key] = i
+			report.ByReason = append(report.ByReason, domain.CancellationReasonSummary{Kind: c.Kind, ReasonCode: c.ReasonCode})
+		}
+		report.ByReason[i].Orders++
+		report.ByReason[i].Total = report.ByReason[i].Total.Add(c.Total)
+	}
+	return report, nil
+}
@@ -0,0 +1,50 @@
+// =================================================================
+// Service Charge Service
+// =================================================================
+package service
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/Hoxanfox/TurnyChain/Backend/api/internal/domain"
+	"github.com/Hoxanfox/TurnyChain/Backend/api/internal/repository"
+	wshub "github.com/Hoxanfox/TurnyChain/Backend/api/internal/websocket"
+)
+
+// ErrInvalidServiceCharge indica un tipo de orden desconocido o un porcentaje fuera de 0-100
+var ErrInvalidServiceCharge = errors.New("cargo por servicio inválido")
+
+// maxPercent es el 100% expresado en centésimas
+const maxPercent = domain.Percent(100 * domain.MoneyScale)
+
+type ServiceChargeService struct {
+	repo  *repository.ServiceChargeRepository
+	wsHub *wshub.Hub
+}
+
+func NewServiceChargeService(repo *repository.ServiceChargeRepository, wsHub *wshub.Hub) *ServiceChargeService {
+	return &ServiceChargeService{repo: repo, wsHub: wsHub}
+}
+
+func (s *ServiceChargeService) GetAll() ([]domain.ServiceChargeSetting, error) {
+	return s.repo.GetAll()
+}
+
+// Update cambia el porcentaje de un tipo de orden. Solo afecta a las órdenes nuevas:
+// cada orden guarda el porcentaje vigente al crearse.
+func (s *ServiceChargeService) Update(orderType string, percentage domain.Percent) (*domain.ServiceChargeSetting, error) {
+	if !domain.IsValidOrderType(orderType) {
+		return nil, fmt.Errorf("%w: tipo de orden '%s'", ErrInvalidServiceCharge, orderType)
+	}
+	if percentage < 0 || percentage > maxPercent {
+		return nil, fmt.Errorf("%w: el porcentaje debe estar entre 0 y 100", ErrInvalidServiceCharge)
+	}
+
+	setting, err := s.repo.Upsert(orderType, percentage)
+	if err != nil {
+		return nil, err
+	}
+	s.wsHub.BroadcastMessage("SERVICE_CHARGE_UPDATED", setting)
+	return setting, nil
+}
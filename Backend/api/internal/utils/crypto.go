@@ -5,38 +5,273 @@ import (
 	"crypto/cipher"
 	"crypto/rand"
 	"encoding/base64"
+	"errors"
+	"fmt"
 	"io"
 	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
 )
 
-// GetEncryptionKey obtiene la clave de entorno o usa una de fallback para dev
-func GetEncryptionKey() []byte {
-	key := os.Getenv("INVOICE_ENCRYPTION_KEY")
-	if len(key) != 32 {
-		// Clave de respaldo solo para desarrollo local
-		return []byte("12345678901234567890123456789012")
+const (
+	// DefaultEncryptionKeyID es el ID de la clave configurada en INVOICE_ENCRYPTION_KEY
+	DefaultEncryptionKeyID = "default"
+	// DevEncryptionKeyID es el ID de la clave de respaldo, que solo se carga en modo desarrollo
+	DevEncryptionKeyID = "dev"
+
+	devEncryptionKey = "12345678901234567890123456789012"
+)
+
+var (
+	// ErrEncryptionKeysNotLoaded indica que no se cargaron las claves al arrancar
+	ErrEncryptionKeysNotLoaded = errors.New("claves de cifrado de facturas no cargadas")
+
+	keyIDPattern = regexp.MustCompile(`^[A-Za-z0-9_-]{1,32}$`)
+
+	keyRingMu sync.RWMutex
+	keyRing   *KeyRing
+)
+
+// KeyRing guarda las claves AES-256 de las facturas por ID. Se cifra siempre con la clave
+// activa; las demás se conservan para descifrar las facturas ya notarizadas con ellas.
+type KeyRing struct {
+	activeID string
+	keys     map[string][]byte
+}
+
+// NewKeyRing crea el llavero. Cada clave debe tener 32 bytes y activeID debe estar entre ellas.
+func NewKeyRing(activeID string, keys map[string][]byte) (*KeyRing, error) {
+	ring := &KeyRing{activeID: activeID, keys: make(map[string][]byte, len(keys))}
+	for id, key := range keys {
+		if !keyIDPattern.MatchString(id) {
+			return nil, fmt.Errorf("ID de clave inválido: %q (letras, números, - y _, máximo 32)", id)
+		}
+		if len(key) != 32 {
+			return nil, fmt.Errorf("la clave %q tiene %d bytes y debe tener 32", id, len(key))
+		}
+		ring.keys[id] = key
+	}
+	if _, ok := ring.keys[activeID]; !ok {
+		return nil, fmt.Errorf("la clave activa %q no está configurada", activeID)
 	}
-	return []byte(key)
+	return ring, nil
 }
 
-// Encrypt encripta texto usando AES-GCM y devuelve Base64
-func Encrypt(text string) (string, error) {
-	key := GetEncryptionKey()
+// LoadEncryptionKeys arma el llavero desde el entorno:
+//   - INVOICE_ENCRYPTION_KEYS: claves versionadas "id:clave,id:clave"
+//   - INVOICE_ENCRYPTION_KEY: clave única (sin versión), con el ID "default"
+//   - INVOICE_ENCRYPTION_KEY_ID: clave activa; por defecto la primera de
+//     INVOICE_ENCRYPTION_KEYS o, si no hay, "default"
+//
+// Una clave es un texto de 32 bytes o "base64:" seguido de 32 bytes en Base64. Sin claves
+// devuelve error, salvo en modo desarrollo, donde se usa la clave de respaldo "dev" (que en
+// desarrollo también se carga junto a las configuradas para leer facturas viejas).
+func LoadEncryptionKeys(devMode bool) (*KeyRing, error) {
+	keys := make(map[string][]byte)
+	activeID := os.Getenv("INVOICE_ENCRYPTION_KEY_ID")
+
+	if spec := strings.TrimSpace(os.Getenv("INVOICE_ENCRYPTION_KEYS")); spec != "" {
+		for _, entry := range strings.Split(spec, ",") {
+			id, value, ok := strings.Cut(strings.TrimSpace(entry), ":")
+			if !ok {
+				return nil, fmt.Errorf("INVOICE_ENCRYPTION_KEYS: %q no tiene el formato id:clave", entry)
+			}
+			if _, exists := keys[id]; exists {
+				return nil, fmt.Errorf("INVOICE_ENCRYPTION_KEYS: la clave %q está repetida", id)
+			}
+			key, err := parseEncryptionKey(value)
+			if err != nil {
+				return nil, fmt.Errorf("INVOICE_ENCRYPTION_KEYS: clave %q: %v", id, err)
+			}
+			keys[id] = key
+			if activeID == "" {
+				activeID = id
+			}
+		}
+	}
+
+	if value := os.Getenv("INVOICE_ENCRYPTION_KEY"); value != "" {
+		if _, exists := keys[DefaultEncryptionKeyID]; exists {
+			return nil, fmt.Errorf("INVOICE_ENCRYPTION_KEYS no puede usar el ID %q si está INVOICE_ENCRYPTION_KEY", DefaultEncryptionKeyID)
+		}
+		key, err := parseEncryptionKey(value)
+		if err != nil {
+			return nil, fmt.Errorf("INVOICE_ENCRYPTION_KEY: %v", err)
+		}
+		keys[DefaultEncryptionKeyID] = key
+		if activeID == "" {
+			activeID = DefaultEncryptionKeyID
+		}
+	}
+
+	if len(keys) == 0 && !devMode {
+		return nil, errors.New("no hay claves de cifrado de facturas: configura INVOICE_ENCRYPTION_KEY o INVOICE_ENCRYPTION_KEYS")
+	}
+	if devMode {
+		if _, exists := keys[DevEncryptionKeyID]; !exists {
+			keys[DevEncryptionKeyID] = []byte(devEncryptionKey)
+		}
+		if activeID == "" {
+			activeID = DevEncryptionKeyID
+		}
+	}
+	return NewKeyRing(activeID, keys)
+}
+
+// parseEncryptionKey acepta 32 bytes en texto plano o "base64:" + 32 bytes en Base64
+func parseEncryptionKey(value string) ([]byte, error) {
+	if encoded, ok := strings.CutPrefix(value, "base64:"); ok {
+		key, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("base64 inválido: %v", err)
+		}
+		value = string(key)
+	}
+	if len(value) != 32 {
+		return nil, fmt.Errorf("tiene %d bytes y debe tener 32", len(value))
+	}
+	return []byte(value), nil
+}
+
+// SetKeyRing define el llavero que usan Encrypt y Decrypt
+func SetKeyRing(ring *KeyRing) {
+	keyRingMu.Lock()
+	defer keyRingMu.Unlock()
+	keyRing = ring
+}
+
+func currentKeyRing() (*KeyRing, error) {
+	keyRingMu.RLock()
+	defer keyRingMu.RUnlock()
+	if keyRing == nil {
+		return nil, ErrEncryptionKeysNotLoaded
+	}
+	return keyRing, nil
+}
+
+// ActiveKeyID devuelve el ID de la clave con la que se cifra
+func (r *KeyRing) ActiveKeyID() string {
+	return r.activeID
+}
+
+// KeyIDs devuelve los IDs de las claves cargadas, ordenados
+func (r *KeyRing) KeyIDs() []string {
+	ids := make([]string, 0, len(r.keys))
+	for id := range r.keys {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// Encrypt cifra con la clave activa usando AES-GCM y devuelve "id:Base64(nonce + texto
+// cifrado)". El ID de la clave va como dato autenticado, así no se puede cambiar el prefijo.
+func (r *KeyRing) Encrypt(text string) (string, error) {
+	gcm, err := newGCM(r.keys[r.activeID])
+	if err != nil {
+		return "", err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err = io.ReadFull(rand.Reader, nonce); err != nil {
+		return "", err
+	}
+
+	ciphertext := gcm.Seal(nonce, nonce, []byte(text), []byte(r.activeID))
+	return r.activeID + ":" + base64.StdEncoding.EncodeToString(ciphertext), nil
+}
+
+// Decrypt descifra el texto generado por Encrypt con la clave de su ID. El formato anterior
+// (Base64 sin ID, como las facturas ya notarizadas en la cadena) se prueba con cada clave.
+func (r *KeyRing) Decrypt(encoded string) (string, error) {
+	keyID, payload, versioned := strings.Cut(encoded, ":")
+	if !versioned {
+		return r.decryptLegacy(encoded)
+	}
+
+	key, ok := r.keys[keyID]
+	if !ok {
+		return "", fmt.Errorf("la clave %q no está configurada", keyID)
+	}
+	data, err := base64.StdEncoding.DecodeString(payload)
+	if err != nil {
+		return "", err
+	}
+	return open(key, data, []byte(keyID))
+}
+
+// decryptLegacy descifra el formato sin ID probando primero la clave activa y luego las demás
+func (r *KeyRing) decryptLegacy(encoded string) (string, error) {
+	data, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return "", err
+	}
+
+	ids := append([]string{r.activeID}, r.KeyIDs()...)
+	for i, id := range ids {
+		if i > 0 && id == r.activeID {
+			continue
+		}
+		if plaintext, err := open(r.keys[id], data, nil); err == nil {
+			return plaintext, nil
+		}
+	}
+	return "", errors.New("ninguna de las claves configuradas descifra el texto")
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
 	c, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(c)
+}
+
+// open separa el nonce del texto cifrado con AES-GCM y lo descifra
+func open(key, data, additionalData []byte) (string, error) {
+	gcm, err := newGCM(key)
 	if err != nil {
 		return "", err
 	}
 
-	gcm, err := cipher.NewGCM(c)
+	if len(data) < gcm.NonceSize() {
+		return "", errors.New("texto cifrado demasiado corto")
+	}
+
+	nonce, ciphertext := data[:gcm.NonceSize()], data[gcm.NonceSize():]
+	plaintext, err := gcm.Open(nil, nonce, ciphertext, additionalData)
 	if err != nil {
 		return "", err
 	}
+	return string(plaintext), nil
+}
 
-	nonce := make([]byte, gcm.NonceSize())
-	if _, err = io.ReadFull(rand.Reader, nonce); err != nil {
+// Encrypt cifra con la clave activa del llavero cargado al arrancar
+func Encrypt(text string) (string, error) {
+	ring, err := currentKeyRing()
+	if err != nil {
 		return "", err
 	}
+	return ring.Encrypt(text)
+}
 
-	ciphertext := gcm.Seal(nonce, nonce, []byte(text), nil)
-	return base64.StdEncoding.EncodeToString(ciphertext), nil
+// Decrypt descifra con el llavero cargado al arrancar
+func Decrypt(encoded string) (string, error) {
+	ring, err := currentKeyRing()
+	if err != nil {
+		return "", err
+	}
+	return ring.Decrypt(encoded)
+}
+
+// CiphertextKeyID devuelve el ID de la clave con la que se cifró el texto ("" en el formato
+// anterior, sin ID)
+func CiphertextKeyID(encoded string) string {
+	keyID, _, ok := strings.Cut(encoded, ":")
+	if !ok {
+		return ""
+	}
+	return keyID
 }
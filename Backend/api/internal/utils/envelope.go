@@ -0,0 +1,93 @@
+package utils
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// EnvelopeAlgorithm describe el cifrado de SealEnvelope
+const EnvelopeAlgorithm = "RSA-OAEP-256+A256GCM"
+
+// minEnvelopeKeyBits es el tamaño mínimo de la llave RSA de un auditor
+const minEnvelopeKeyBits = 2048
+
+// ParsePublicKey lee una llave pública RSA en PEM ("PUBLIC KEY" o "RSA PUBLIC KEY") y
+// devuelve su huella: SHA-256 en hex de la llave en DER (PKIX)
+func ParsePublicKey(pemData string) (*rsa.PublicKey, string, error) {
+	block, _ := pem.Decode([]byte(pemData))
+	if block == nil {
+		return nil, "", errors.New("la llave no está en formato PEM")
+	}
+
+	var publicKey *rsa.PublicKey
+	switch block.Type {
+	case "PUBLIC KEY":
+		parsed, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return nil, "", fmt.Errorf("llave pública inválida: %v", err)
+		}
+		key, ok := parsed.(*rsa.PublicKey)
+		if !ok {
+			return nil, "", errors.New("la llave pública debe ser RSA")
+		}
+		publicKey = key
+	case "RSA PUBLIC KEY":
+		key, err := x509.ParsePKCS1PublicKey(block.Bytes)
+		if err != nil {
+			return nil, "", fmt.Errorf("llave pública inválida: %v", err)
+		}
+		publicKey = key
+	default:
+		return nil, "", fmt.Errorf("bloque PEM %q no es una llave pública", block.Type)
+	}
+
+	if publicKey.N.BitLen() < minEnvelopeKeyBits {
+		return nil, "", fmt.Errorf("la llave RSA tiene %d bits y debe tener al menos %d", publicKey.N.BitLen(), minEnvelopeKeyBits)
+	}
+
+	der, err := x509.MarshalPKIXPublicKey(publicKey)
+	if err != nil {
+		return nil, "", err
+	}
+	fingerprint := sha256.Sum256(der)
+	return publicKey, hex.EncodeToString(fingerprint[:]), nil
+}
+
+// SealEnvelope cifra plaintext con AES-256-GCM bajo una clave aleatoria de un solo uso y
+// cifra esa clave con RSA-OAEP (SHA-256) para publicKey. Solo el dueño de la llave privada
+// puede abrirlo.
+func SealEnvelope(publicKey *rsa.PublicKey, plaintext []byte) (encryptedKey, nonce, ciphertext []byte, err error) {
+	dataKey := make([]byte, 32)
+	if _, err = io.ReadFull(rand.Reader, dataKey); err != nil {
+		return nil, nil, nil, err
+	}
+
+	c, err := aes.NewCipher(dataKey)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	gcm, err := cipher.NewGCM(c)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	nonce = make([]byte, gcm.NonceSize())
+	if _, err = io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, nil, nil, err
+	}
+	ciphertext = gcm.Seal(nil, nonce, plaintext, nil)
+
+	encryptedKey, err = rsa.EncryptOAEP(sha256.New(), rand.Reader, publicKey, dataKey, nil)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return encryptedKey, nonce, ciphertext, nil
+}
@@ -0,0 +1,75 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// MerkleLeaf convierte el hash SHA-256 (hex) de una factura en la hoja del árbol:
+// keccak256 de sus 32 bytes, igual que espera InvoiceNotary.verifyProof
+func MerkleLeaf(invoiceHash string) (common.Hash, error) {
+	raw, err := hex.DecodeString(invoiceHash)
+	if err != nil || len(raw) != 32 {
+		return common.Hash{}, fmt.Errorf("hash de factura inválido: %q", invoiceHash)
+	}
+	return crypto.Keccak256Hash(raw), nil
+}
+
+// BuildMerkleTree calcula la raíz del árbol y la prueba de cada hoja (en el mismo orden).
+// Los pares se ordenan antes de hashearse y un nodo sin pareja sube tal cual al nivel
+// siguiente, así la prueba no depende de la posición de la hoja.
+func BuildMerkleTree(leaves []common.Hash) (common.Hash, [][]common.Hash) {
+	proofs := make([][]common.Hash, len(leaves))
+	if len(leaves) == 0 {
+		return common.Hash{}, proofs
+	}
+
+	// positions[i] es la posición de la hoja i en el nivel actual
+	positions := make([]int, len(leaves))
+	for i := range positions {
+		positions[i] = i
+	}
+
+	level := leaves
+	for len(level) > 1 {
+		next := make([]common.Hash, 0, (len(level)+1)/2)
+		for i := 0; i < len(level); i += 2 {
+			if i+1 == len(level) {
+				next = append(next, level[i])
+				continue
+			}
+			next = append(next, hashPair(level[i], level[i+1]))
+		}
+
+		for leaf, pos := range positions {
+			sibling := pos ^ 1
+			if sibling < len(level) {
+				proofs[leaf] = append(proofs[leaf], level[sibling])
+			}
+			positions[leaf] = pos / 2
+		}
+		level = next
+	}
+	return level[0], proofs
+}
+
+// VerifyMerkleProof comprueba que la hoja pertenece al árbol con la raíz dada
+func VerifyMerkleProof(leaf common.Hash, proof []common.Hash, root common.Hash) bool {
+	computed := leaf
+	for _, sibling := range proof {
+		computed = hashPair(computed, sibling)
+	}
+	return computed == root
+}
+
+// hashPair hashea dos nodos ordenados (keccak256 del menor seguido del mayor)
+func hashPair(a, b common.Hash) common.Hash {
+	if bytes.Compare(a[:], b[:]) > 0 {
+		a, b = b, a
+	}
+	return crypto.Keccak256Hash(a[:], b[:])
+}
@@ -0,0 +1,93 @@
+package utils
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// testLeaves arma n hojas distintas a partir de su índice
+func testLeaves(n int) []common.Hash {
+	leaves := make([]common.Hash, n)
+	for i := range leaves {
+		leaves[i] = crypto.Keccak256Hash([]byte(fmt.Sprintf("factura-%d", i)))
+	}
+	return leaves
+}
+
+func TestBuildMerkleTreeProofs(t *testing.T) {
+	cases := []struct {
+		leaves int
+		// largo esperado de la prueba de cada hoja (las que suben sin pareja tienen pruebas más cortas)
+		proofLens []int
+	}{
+		{leaves: 1, proofLens: []int{0}},
+		{leaves: 2, proofLens: []int{1, 1}},
+		{leaves: 3, proofLens: []int{2, 2, 1}},
+		{leaves: 5, proofLens: []int{3, 3, 3, 3, 1}},
+		{leaves: 8, proofLens: []int{3, 3, 3, 3, 3, 3, 3, 3}},
+	}
+	for _, tc := range cases {
+		t.Run(fmt.Sprintf("%d hojas", tc.leaves), func(t *testing.T) {
+			leaves := testLeaves(tc.leaves)
+			root, proofs := BuildMerkleTree(leaves)
+			if len(proofs) != len(leaves) {
+				t.Fatalf("se generaron %d pruebas para %d hojas", len(proofs), len(leaves))
+			}
+
+			for i, leaf := range leaves {
+				if len(proofs[i]) != tc.proofLens[i] {
+					t.Errorf("hoja %d: prueba de largo %d, se esperaba %d", i, len(proofs[i]), tc.proofLens[i])
+				}
+				if !VerifyMerkleProof(leaf, proofs[i], root) {
+					t.Errorf("hoja %d: la prueba no verifica contra la raíz", i)
+				}
+
+				// Una hoja alterada no debe verificar con la prueba original
+				changed := leaf
+				changed[0] ^= 0xff
+				if VerifyMerkleProof(changed, proofs[i], root) {
+					t.Errorf("hoja %d: una hoja alterada verificó contra la raíz", i)
+				}
+
+				// Tampoco si se altera cualquiera de los hermanos de la prueba
+				for j := range proofs[i] {
+					proof := append([]common.Hash(nil), proofs[i]...)
+					proof[j][31] ^= 0x01
+					if VerifyMerkleProof(leaf, proof, root) {
+						t.Errorf("hoja %d: verificó con el hermano %d alterado", i, j)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestBuildMerkleTreeOddNodePromotion(t *testing.T) {
+	leaves := testLeaves(5)
+	root, proofs := BuildMerkleTree(leaves)
+
+	// La quinta hoja no tiene pareja: sube sin hashearse hasta emparejarse con el resto del árbol
+	left := hashPair(hashPair(leaves[0], leaves[1]), hashPair(leaves[2], leaves[3]))
+	if want := hashPair(left, leaves[4]); root != want {
+		t.Fatalf("raíz = %s, se esperaba %s", root.Hex(), want.Hex())
+	}
+	if len(proofs[4]) != 1 || proofs[4][0] != left {
+		t.Errorf("la prueba de la hoja promovida debería ser solo el subárbol izquierdo, es %v", proofs[4])
+	}
+
+	// Con una sola hoja, la raíz es la propia hoja
+	single := testLeaves(1)
+	if root, _ := BuildMerkleTree(single); root != single[0] {
+		t.Errorf("raíz de una hoja = %s, se esperaba la hoja %s", root.Hex(), single[0].Hex())
+	}
+}
+
+func TestBuildMerkleTreeEmpty(t *testing.T) {
+	root, proofs := BuildMerkleTree(nil)
+	if root != (common.Hash{}) || len(proofs) != 0 {
+		t.Errorf("un árbol vacío debería dar la raíz cero y ninguna prueba, dio %s y %d pruebas", root.Hex(), len(proofs))
+	}
+}
@@ -19,9 +19,10 @@ type Message struct {
 
 // ClientInfo almacena información adicional del cliente
 type ClientInfo struct {
-	Conn   *websocket.Conn
-	UserID string
-	Role   string
+	Conn      *websocket.Conn
+	UserID    string
+	Role      string
+	StationID string // Solo para pantallas de cocina (KDS) suscritas a una estación
 }
 
 // Hub mantiene el conjunto de clientes activos.
@@ -108,3 +109,47 @@ func (h *Hub) BroadcastToRole(role string, msgType string, payload interface{})
 	}
 	log.Printf("📡 BroadcastToRole: Enviando mensaje tipo '%s' a %d clientes con rol '%s'", msgType, sentCount, role)
 }
+
+// BroadcastToStation envía un mensaje solo a las pantallas suscritas a una estación (KDS).
+func (h *Hub) BroadcastToStation(stationID string, msgType string, payload interface{}) {
+	sentCount := h.sendWhere(msgType, payload, func(clientInfo *ClientInfo) bool {
+		return clientInfo.StationID == stationID
+	})
+	log.Printf("📡 BroadcastToStation: Enviando mensaje tipo '%s' a %d pantallas de la estación '%s'", msgType, sentCount, stationID)
+}
+
+// BroadcastToUser envía un mensaje solo a las conexiones de un usuario específico.
+func (h *Hub) BroadcastToUser(userID string, msgType string, payload interface{}) {
+	sentCount := h.sendWhere(msgType, payload, func(clientInfo *ClientInfo) bool {
+		return clientInfo.UserID == userID
+	})
+	log.Printf("📡 BroadcastToUser: Enviando mensaje tipo '%s' a %d conexiones del usuario '%s'", msgType, sentCount, userID)
+}
+
+// sendWhere envía un mensaje a los clientes que cumplen el filtro y devuelve cuántos lo recibieron.
+func (h *Hub) sendWhere(msgType string, payload interface{}, match func(*ClientInfo) bool) int {
+	message := Message{
+		Type:    msgType,
+		Payload: payload,
+	}
+	jsonMessage, err := json.Marshal(message)
+	if err != nil {
+		log.Println("❌ Error al convertir mensaje a JSON:", err)
+		return 0
+	}
+
+	sentCount := 0
+	for conn, clientInfo := range h.clients {
+		if !match(clientInfo) {
+			continue
+		}
+		if err := conn.WriteMessage(websocket.TextMessage, jsonMessage); err != nil {
+			log.Printf("❌ Error al enviar mensaje a cliente %s (role: %s): %v", clientInfo.UserID, clientInfo.Role, err)
+			h.Unregister <- conn
+			conn.Close()
+		} else {
+			sentCount++
+		}
+	}
+	return sentCount
+}
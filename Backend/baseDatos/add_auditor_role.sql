@@ -0,0 +1,25 @@
+-- Migración: Rol auditor y llaves públicas de auditores
+-- Fecha: 2026-10-18
+--
+-- El auditor lee y exporta las facturas notarizadas descifradas (GET /api/audit/invoices).
+-- Con una llave registrada en auditor_keys puede recibirlas cifradas solo para él.
+
+ALTER TABLE "users" DROP CONSTRAINT IF EXISTS "users_role_check";
+ALTER TABLE "users" ADD CONSTRAINT "users_role_check" CHECK (role IN ('mesero', 'cajero', 'admin', 'auditor'));
+
+CREATE TABLE IF NOT EXISTS "auditor_keys" (
+  "user_id" uuid PRIMARY KEY REFERENCES "users"("id") ON DELETE CASCADE,
+  "public_key" text NOT NULL,
+  "fingerprint" varchar(64) NOT NULL, -- SHA-256 de la llave (DER)
+  "granted_by" uuid REFERENCES "users"("id") ON DELETE SET NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  "updated_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+DROP TRIGGER IF EXISTS set_timestamp ON auditor_keys;
+CREATE TRIGGER set_timestamp
+BEFORE UPDATE ON auditor_keys
+FOR EACH ROW
+EXECUTE PROCEDURE trigger_set_timestamp();
+
+CREATE INDEX IF NOT EXISTS "order_notarizations_confirmed_at_idx" ON "order_notarizations" ("confirmed_at");
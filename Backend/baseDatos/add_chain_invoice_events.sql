@@ -0,0 +1,35 @@
+-- Migración: Indexador de eventos InvoiceSecured
+-- Fecha: 2026-10-18
+--
+-- El backend sigue los logs del contrato desde NOTARY_INDEXER_START_BLOCK y guarda cada
+-- evento InvoiceSecured, para comparar las órdenes pagadas con lo que hay en la cadena.
+
+CREATE TABLE IF NOT EXISTS "chain_invoice_events" (
+  "id" uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+  "source" varchar(100) NOT NULL,
+  "order_id" uuid, -- NULL si no se pudo saber de qué orden es
+  "order_id_hash" varchar(66) NOT NULL, -- Topic indexado: keccak256 del ID de la orden
+  "tx_hash" varchar(66) NOT NULL,
+  "log_index" integer NOT NULL,
+  "block_number" bigint NOT NULL,
+  "block_hash" varchar(66) NOT NULL,
+  "block_timestamp" timestamptz NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  UNIQUE ("source", "tx_hash", "log_index")
+);
+
+CREATE TABLE IF NOT EXISTS "chain_indexer_state" (
+  "source" varchar(100) PRIMARY KEY,
+  "last_block" bigint NOT NULL,
+  "last_block_hash" varchar(66) NOT NULL,
+  "updated_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+DROP TRIGGER IF EXISTS set_timestamp ON chain_indexer_state;
+CREATE TRIGGER set_timestamp
+BEFORE UPDATE ON chain_indexer_state
+FOR EACH ROW
+EXECUTE PROCEDURE trigger_set_timestamp();
+
+CREATE INDEX IF NOT EXISTS "chain_invoice_events_source_block_number_idx" ON "chain_invoice_events" ("source", "block_number");
+CREATE INDEX IF NOT EXISTS "chain_invoice_events_order_id_idx" ON "chain_invoice_events" ("order_id");
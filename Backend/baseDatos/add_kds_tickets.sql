@@ -0,0 +1,23 @@
+-- Migración: Pantallas de cocina por estación (kds_tickets)
+-- Fecha: 2026-10-18
+
+CREATE TABLE IF NOT EXISTS "kds_tickets" (
+  "id" uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+  "order_id" uuid NOT NULL REFERENCES "orders"("id") ON DELETE CASCADE,
+  "station_id" uuid NOT NULL REFERENCES "stations"("id") ON DELETE CASCADE,
+  "ticket" jsonb NOT NULL,
+  "status" varchar(20) NOT NULL DEFAULT 'new' CHECK (status IN ('new', 'in_progress', 'ready')),
+  "started_at" timestamptz,
+  "ready_at" timestamptz,
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  "updated_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+DROP TRIGGER IF EXISTS set_timestamp ON kds_tickets;
+CREATE TRIGGER set_timestamp
+BEFORE UPDATE ON kds_tickets
+FOR EACH ROW
+EXECUTE PROCEDURE trigger_set_timestamp();
+
+CREATE INDEX IF NOT EXISTS kds_tickets_station_id_status_idx ON "kds_tickets" ("station_id", "status");
+CREATE INDEX IF NOT EXISTS kds_tickets_order_id_idx ON "kds_tickets" ("order_id");
@@ -0,0 +1,44 @@
+-- Migración: Montos en punto fijo (domain.Money)
+-- Fecha: 2026-10-18
+--
+-- Las columnas de dinero ya son numeric(10, 2), así que los valores guardados son exactos.
+-- Lo que pudo quedar mal es el total de órdenes calculado con float64 en el backend
+-- (diferencias de un centavo frente a la suma de sus items). Esta migración:
+--   1. Lista las órdenes cuyo total no coincide con la suma de sus items.
+--   2. Recalcula el total SOLO de las órdenes que aún no se cobraron.
+--   3. Agrega CHECKs para impedir montos negativos.
+-- Las órdenes pagadas o canceladas no se tocan: se revisan a mano con el reporte del paso 1.
+
+-- 1. Reporte de diferencias
+SELECT o.id, o.status, o.total, SUM(oi.price_at_order * oi.quantity) AS items_total,
+       o.total - SUM(oi.price_at_order * oi.quantity) AS difference
+FROM orders o
+JOIN order_items oi ON oi.order_id = o.id
+GROUP BY o.id, o.status, o.total
+HAVING o.total <> SUM(oi.price_at_order * oi.quantity)
+ORDER BY o.created_at;
+
+-- 2. Corregir órdenes abiertas
+UPDATE orders o
+SET total = sums.items_total
+FROM (
+  SELECT order_id, ROUND(SUM(price_at_order * quantity), 2) AS items_total
+  FROM order_items
+  GROUP BY order_id
+) sums
+WHERE sums.order_id = o.id
+  AND o.total <> sums.items_total
+  AND o.status NOT IN ('pagado', 'cancelado', 'por_verificar');
+
+-- 3. Montos no negativos
+ALTER TABLE "menu_items" DROP CONSTRAINT IF EXISTS menu_items_price_check;
+ALTER TABLE "menu_items" ADD CONSTRAINT menu_items_price_check CHECK (price >= 0);
+
+ALTER TABLE "accompaniments" DROP CONSTRAINT IF EXISTS accompaniments_price_check;
+ALTER TABLE "accompaniments" ADD CONSTRAINT accompaniments_price_check CHECK (price >= 0);
+
+ALTER TABLE "orders" DROP CONSTRAINT IF EXISTS orders_total_check;
+ALTER TABLE "orders" ADD CONSTRAINT orders_total_check CHECK (total >= 0);
+
+ALTER TABLE "order_items" DROP CONSTRAINT IF EXISTS order_items_price_at_order_check;
+ALTER TABLE "order_items" ADD CONSTRAINT order_items_price_at_order_check CHECK (price_at_order >= 0);
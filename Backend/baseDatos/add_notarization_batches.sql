@@ -0,0 +1,36 @@
+-- Migración: Notarización por lotes con raíces Merkle
+-- Fecha: 2026-10-18
+--
+-- En modo batch (NOTARIZATION_MODE=batch) las facturas pagadas se agrupan en lotes: una
+-- sola transacción ancla la raíz Merkle de sus hashes y cada orden guarda su prueba.
+
+CREATE TABLE IF NOT EXISTS "notarization_batches" (
+  "id" uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+  "merkle_root" varchar(66) UNIQUE NOT NULL,
+  "size" integer NOT NULL CHECK (size > 0),
+  "status" varchar(20) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'sent', 'confirmed', 'failed')),
+  "tx_hash" varchar(66),
+  "nonce" bigint,
+  "block_number" bigint,
+  "attempts" integer NOT NULL DEFAULT 0,
+  "last_error" text,
+  "next_attempt_at" timestamptz NOT NULL DEFAULT (now()),
+  "sent_at" timestamptz,
+  "confirmed_at" timestamptz,
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  "updated_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+DROP TRIGGER IF EXISTS set_timestamp ON notarization_batches;
+CREATE TRIGGER set_timestamp
+BEFORE UPDATE ON notarization_batches
+FOR EACH ROW
+EXECUTE PROCEDURE trigger_set_timestamp();
+
+ALTER TABLE "order_notarizations" ADD COLUMN IF NOT EXISTS "batch_id" uuid REFERENCES "notarization_batches"("id") ON DELETE SET NULL;
+ALTER TABLE "order_notarizations" ADD COLUMN IF NOT EXISTS "merkle_root" varchar(66);
+ALTER TABLE "order_notarizations" ADD COLUMN IF NOT EXISTS "merkle_proof" text[];
+ALTER TABLE "order_notarizations" ADD COLUMN IF NOT EXISTS "encrypted_invoice" text;
+
+CREATE INDEX IF NOT EXISTS "order_notarizations_batch_id_idx" ON "order_notarizations" ("batch_id");
+CREATE INDEX IF NOT EXISTS "notarization_batches_status_next_attempt_at_idx" ON "notarization_batches" ("status", "next_attempt_at");
@@ -0,0 +1,16 @@
+-- Migración: Outbox de notarización
+-- Fecha: 2026-10-18
+--
+-- order_notarizations pasa a ser un outbox que procesa un worker con reintentos:
+-- next_attempt_at programa el próximo envío (pending) o consulta del recibo (sent) y
+-- nonce guarda el nonce de la wallet usado en la transacción.
+-- Las órdenes pagadas sin notarización se agregan solas al arrancar el backend.
+
+ALTER TABLE "order_notarizations" ADD COLUMN IF NOT EXISTS "nonce" bigint;
+ALTER TABLE "order_notarizations" ADD COLUMN IF NOT EXISTS "next_attempt_at" timestamptz NOT NULL DEFAULT (now());
+
+DROP INDEX IF EXISTS "order_notarizations_status_idx";
+CREATE INDEX IF NOT EXISTS "order_notarizations_status_next_attempt_at_idx" ON "order_notarizations" ("status", "next_attempt_at");
+
+-- Las fallidas antes del outbox (p. ej. sin servicio blockchain) vuelven a la cola
+UPDATE order_notarizations SET status = 'pending', attempts = 0, next_attempt_at = now() WHERE status = 'failed';
@@ -0,0 +1,12 @@
+-- Migración: Backends de notarización
+-- Fecha: 2026-10-18
+--
+-- Las facturas se pueden notarizar en Ethereum, en un log local encadenado por hashes o en
+-- memoria (NOTARY_BACKEND). backend guarda con cuál se envió cada transacción.
+
+ALTER TABLE "order_notarizations" ADD COLUMN IF NOT EXISTS "backend" varchar(20);
+ALTER TABLE "notarization_batches" ADD COLUMN IF NOT EXISTS "backend" varchar(20);
+
+-- Hasta ahora solo existía Ethereum
+UPDATE order_notarizations SET backend = 'ethereum' WHERE tx_hash IS NOT NULL AND backend IS NULL;
+UPDATE notarization_batches SET backend = 'ethereum' WHERE tx_hash IS NOT NULL AND backend IS NULL;
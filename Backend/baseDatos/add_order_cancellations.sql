@@ -0,0 +1,22 @@
+-- Migración: Cancelación y anulación de órdenes con motivo
+-- Fecha: 2026-10-18
+--
+-- POST /api/orders/:id/cancel (antes de cocina) y POST /api/orders/:id/void (después, solo admin)
+-- registran el motivo en order_cancellations. Los tickets KDS abiertos de una orden anulada
+-- pasan a 'voided'. Las órdenes canceladas antes de esta migración no tienen motivo registrado.
+
+CREATE TABLE IF NOT EXISTS "order_cancellations" (
+  "order_id" uuid PRIMARY KEY REFERENCES "orders"("id") ON DELETE CASCADE,
+  "kind" varchar(10) NOT NULL CHECK (kind IN ('cancel', 'void')),
+  "reason_code" varchar(30) NOT NULL CHECK (reason_code IN ('customer_left', 'order_error', 'duplicate', 'out_of_stock', 'customer_complaint', 'other')),
+  "notes" text,
+  "previous_status" varchar(30) NOT NULL, -- Estado de la orden al cancelarla
+  "total" numeric(10, 2) NOT NULL, -- Total de la orden al cancelarla
+  "cancelled_by" uuid REFERENCES "users"("id") ON DELETE SET NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE INDEX IF NOT EXISTS "order_cancellations_created_at_idx" ON "order_cancellations" ("created_at");
+
+ALTER TABLE "kds_tickets" DROP CONSTRAINT IF EXISTS "kds_tickets_status_check";
+ALTER TABLE "kds_tickets" ADD CONSTRAINT "kds_tickets_status_check" CHECK (status IN ('new', 'in_progress', 'ready', 'voided'));
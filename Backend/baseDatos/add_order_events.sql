@@ -0,0 +1,14 @@
+-- Migración: Historial de cambios de las órdenes (order_events)
+-- Fecha: 2026-10-18
+
+CREATE TABLE IF NOT EXISTS "order_events" (
+  "id" uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+  "order_id" uuid NOT NULL REFERENCES "orders"("id") ON DELETE CASCADE,
+  "actor_id" uuid REFERENCES "users"("id") ON DELETE SET NULL,
+  "event_type" varchar(30) NOT NULL,
+  "old_value" jsonb,
+  "new_value" jsonb,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE INDEX IF NOT EXISTS order_events_order_id_created_at_idx ON "order_events" ("order_id", "created_at");
@@ -0,0 +1,10 @@
+-- Migración: Estado de preparación por item (order_items.status)
+-- Fecha: 2026-10-18
+
+ALTER TABLE order_items ADD COLUMN IF NOT EXISTS "status" varchar(20) NOT NULL DEFAULT 'pending'
+  CHECK (status IN ('pending', 'preparing', 'ready', 'served', 'voided'));
+
+-- Las órdenes ya entregadas o cobradas tienen todos sus items servidos
+UPDATE order_items oi SET status = 'served'
+FROM orders o
+WHERE o.id = oi.order_id AND o.status IN ('entregado', 'por_verificar', 'pagado');
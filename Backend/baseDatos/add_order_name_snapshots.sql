@@ -0,0 +1,18 @@
+-- Migración: Nombres del mesero y de los productos guardados con la orden, y orden fijo de los items
+-- Fecha: 2026-10-18
+-- La factura notarizada se arma con estos datos: renombrar un usuario o un producto ya no
+-- hace que las órdenes pagadas aparezcan como alteradas al verificarlas.
+
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS "waiter_name" varchar(100);
+ALTER TABLE order_items ADD COLUMN IF NOT EXISTS "menu_item_name" varchar(255);
+-- Los items existentes se numeran en el orden en que están guardados
+ALTER TABLE order_items ADD COLUMN IF NOT EXISTS "line_number" bigserial;
+
+-- Las órdenes existentes toman los nombres actuales
+UPDATE orders o SET waiter_name = u.username
+FROM users u
+WHERE u.id = o.waiter_id AND o.waiter_name IS NULL;
+
+UPDATE order_items oi SET menu_item_name = mi.name
+FROM menu_items mi
+WHERE mi.id = oi.menu_item_id AND oi.menu_item_name IS NULL;
@@ -0,0 +1,45 @@
+-- Migración: Registro de notarización de facturas
+-- Fecha: 2026-10-18
+--
+-- order_notarizations: estado de la notarización de cada orden (pending, sent, confirmed,
+-- failed), transacción, bloque, hash de la factura e intentos.
+-- Reemplaza a orders.blockchain_tx_hash (add_receipts.sql): las transacciones ya guardadas
+-- pasan a la nueva tabla como 'sent' (sin bloque ni hash de factura conocidos) y la
+-- columna se elimina.
+
+CREATE TABLE IF NOT EXISTS "order_notarizations" (
+  "id" uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+  "order_id" uuid UNIQUE NOT NULL REFERENCES "orders"("id") ON DELETE CASCADE,
+  "status" varchar(20) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'sent', 'confirmed', 'failed')),
+  "invoice_hash" varchar(64) NOT NULL,
+  "tx_hash" varchar(66),
+  "block_number" bigint,
+  "attempts" integer NOT NULL DEFAULT 0,
+  "last_error" text,
+  "sent_at" timestamptz,
+  "confirmed_at" timestamptz,
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  "updated_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE INDEX IF NOT EXISTS "order_notarizations_status_idx" ON "order_notarizations" ("status");
+
+DROP TRIGGER IF EXISTS set_timestamp ON order_notarizations;
+CREATE TRIGGER set_timestamp
+BEFORE UPDATE ON order_notarizations
+FOR EACH ROW
+EXECUTE PROCEDURE trigger_set_timestamp();
+
+-- Transacciones guardadas en la orden
+DO $$
+BEGIN
+  IF EXISTS (SELECT 1 FROM information_schema.columns WHERE table_name = 'orders' AND column_name = 'blockchain_tx_hash') THEN
+    INSERT INTO order_notarizations (order_id, status, invoice_hash, tx_hash, attempts, sent_at)
+    SELECT id, 'sent', '', blockchain_tx_hash, 1, updated_at
+    FROM orders
+    WHERE blockchain_tx_hash IS NOT NULL
+    ON CONFLICT (order_id) DO NOTHING;
+
+    ALTER TABLE "orders" DROP COLUMN "blockchain_tx_hash";
+  END IF;
+END $$;
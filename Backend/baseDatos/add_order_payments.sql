@@ -0,0 +1,27 @@
+-- Migración: Pagos parciales de las órdenes (order_payments)
+-- Fecha: 2026-10-18
+
+-- Pagos de una orden: varios pagos parciales de métodos mixtos (cuentas divididas).
+-- item_ids: items cubiertos cuando se divide por items; vacío = abono al total
+CREATE TABLE IF NOT EXISTS "order_payments" (
+  "id" uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+  "order_id" uuid NOT NULL REFERENCES "orders"("id") ON DELETE CASCADE,
+  "amount" numeric(10, 2) NOT NULL CHECK (amount > 0),
+  "method" varchar(20) NOT NULL CHECK (method IN ('efectivo', 'transferencia', 'tarjeta')),
+  "proof_path" text,
+  "item_ids" uuid[] NOT NULL DEFAULT '{}',
+  "received_by" uuid REFERENCES "users"("id") ON DELETE SET NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE INDEX IF NOT EXISTS order_payments_order_id_idx ON "order_payments" ("order_id");
+
+-- Las órdenes ya pagadas quedan con un único pago por su total, con el método y comprobante que tenían
+INSERT INTO order_payments (order_id, amount, method, proof_path, received_by, created_at)
+SELECT o.id, o.total,
+       CASE WHEN o.payment_method IN ('efectivo', 'transferencia', 'tarjeta') THEN o.payment_method ELSE 'efectivo' END,
+       o.payment_proof_path, o.cashier_id, o.updated_at
+FROM orders o
+WHERE o.status = 'pagado'
+  AND o.total > 0
+  AND NOT EXISTS (SELECT 1 FROM order_payments p WHERE p.order_id = o.id);
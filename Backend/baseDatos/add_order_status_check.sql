@@ -0,0 +1,8 @@
+-- Migración: Restringir orders.status a los estados de la máquina de estados
+-- Fecha: 2026-10-18
+
+ALTER TABLE orders DROP CONSTRAINT IF EXISTS orders_status_check;
+ALTER TABLE orders ADD CONSTRAINT orders_status_check CHECK (status IN (
+  'pendiente_aprobacion', 'recibido', 'aprobado', 'en_preparacion', 'listo_para_servir',
+  'entregado', 'por_verificar', 'pagado', 'cancelado'
+));
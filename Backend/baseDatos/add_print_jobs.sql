@@ -0,0 +1,27 @@
+-- Migración: Cola persistente de impresión (print_jobs)
+-- Fecha: 2026-10-18
+
+CREATE TABLE IF NOT EXISTS "print_jobs" (
+  "id" uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+  "order_id" uuid NOT NULL REFERENCES "orders"("id") ON DELETE CASCADE,
+  "station_id" uuid NOT NULL REFERENCES "stations"("id") ON DELETE CASCADE,
+  "printer_id" uuid REFERENCES "printers"("id") ON DELETE SET NULL,
+  "ticket" jsonb NOT NULL,
+  "status" varchar(20) NOT NULL DEFAULT 'queued' CHECK (status IN ('queued', 'sent', 'failed')),
+  "attempts" integer NOT NULL DEFAULT 0,
+  "max_attempts" integer NOT NULL DEFAULT 6,
+  "last_error" text,
+  "next_attempt_at" timestamptz NOT NULL DEFAULT (now()),
+  "sent_at" timestamptz,
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  "updated_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+DROP TRIGGER IF EXISTS set_timestamp ON print_jobs;
+CREATE TRIGGER set_timestamp
+BEFORE UPDATE ON print_jobs
+FOR EACH ROW
+EXECUTE PROCEDURE trigger_set_timestamp();
+
+CREATE INDEX IF NOT EXISTS print_jobs_order_id_idx ON "print_jobs" ("order_id");
+CREATE INDEX IF NOT EXISTS print_jobs_status_next_attempt_at_idx ON "print_jobs" ("status", "next_attempt_at");
@@ -0,0 +1,7 @@
+-- Migración: Estado de conectividad de impresoras (monitor de salud)
+-- Fecha: 2026-10-18
+
+ALTER TABLE printers ADD COLUMN IF NOT EXISTS "is_online" boolean NOT NULL DEFAULT false;
+ALTER TABLE printers ADD COLUMN IF NOT EXISTS "last_seen_at" timestamptz;
+ALTER TABLE printers ADD COLUMN IF NOT EXISTS "last_checked_at" timestamptz;
+ALTER TABLE printers ADD COLUMN IF NOT EXISTS "latency_ms" integer;
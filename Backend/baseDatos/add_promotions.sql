@@ -0,0 +1,66 @@
+-- Migración: Promociones, happy hours, combos y cupones
+-- Fecha: 2026-10-18
+--
+-- orders: total = subtotal - discount + service_charge.
+-- Las órdenes existentes quedan sin descuento.
+
+ALTER TABLE "orders" ADD COLUMN IF NOT EXISTS "discount" numeric(10, 2) NOT NULL DEFAULT 0 CHECK (discount >= 0);
+
+-- Promociones: descuentos por porcentaje o monto fijo (orden, productos o categorías),
+-- combos y cupones. Sin coupon_code se aplican solas dentro de su ventana (happy hour)
+CREATE TABLE IF NOT EXISTS "promotions" (
+  "id" uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+  "name" varchar(100) NOT NULL,
+  "description" text,
+  "type" varchar(20) NOT NULL CHECK (type IN ('percentage', 'fixed', 'combo')),
+  "scope" varchar(20) NOT NULL DEFAULT 'order' CHECK (scope IN ('order', 'menu_item', 'category')),
+  "percentage" numeric(5, 2) NOT NULL DEFAULT 0 CHECK (percentage >= 0 AND percentage <= 100),
+  -- fixed: monto a descontar; combo: precio del combo
+  "amount" numeric(10, 2) NOT NULL DEFAULT 0 CHECK (amount >= 0),
+  -- Productos o categorías según scope; en combos, los productos del combo
+  "target_ids" uuid[] NOT NULL DEFAULT '{}',
+  "coupon_code" varchar(50), -- Único sin distinguir mayúsculas (índice UPPER)
+  -- Ventana de tiempo: días (0 = domingo) y horario; si end_time < start_time cruza la medianoche
+  "days_of_week" smallint[] NOT NULL DEFAULT '{}',
+  "start_time" time,
+  "end_time" time,
+  "starts_at" timestamptz,
+  "ends_at" timestamptz,
+  "max_uses" integer CHECK (max_uses > 0),
+  "is_active" boolean NOT NULL DEFAULT true,
+  "created_by" uuid REFERENCES "users"("id") ON DELETE SET NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  "updated_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+-- Descuentos aplicados a cada orden (auditoría: quién lo aplicó y quién lo aprobó).
+-- applied_by NULL = promoción automática. Conserva nombre y monto si se borra la promoción
+CREATE TABLE IF NOT EXISTS "order_discounts" (
+  "id" uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+  "order_id" uuid NOT NULL REFERENCES "orders"("id") ON DELETE CASCADE,
+  "promotion_id" uuid REFERENCES "promotions"("id") ON DELETE SET NULL,
+  "promotion_name" varchar(100) NOT NULL,
+  "coupon_code" varchar(50),
+  "amount" numeric(10, 2) NOT NULL CHECK (amount >= 0),
+  "status" varchar(20) NOT NULL DEFAULT 'applied' CHECK (status IN ('applied', 'pending_approval', 'rejected')),
+  "applied_by" uuid REFERENCES "users"("id") ON DELETE SET NULL,
+  "approved_by" uuid REFERENCES "users"("id") ON DELETE SET NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  "updated_at" timestamptz NOT NULL DEFAULT (now()),
+  UNIQUE ("order_id", "promotion_id")
+);
+
+CREATE INDEX IF NOT EXISTS order_discounts_order_id_idx ON "order_discounts" ("order_id");
+CREATE UNIQUE INDEX IF NOT EXISTS promotions_coupon_code_upper_idx ON "promotions" (UPPER("coupon_code"));
+
+DROP TRIGGER IF EXISTS set_timestamp ON promotions;
+CREATE TRIGGER set_timestamp
+BEFORE UPDATE ON promotions
+FOR EACH ROW
+EXECUTE PROCEDURE trigger_set_timestamp();
+
+DROP TRIGGER IF EXISTS set_timestamp ON order_discounts;
+CREATE TRIGGER set_timestamp
+BEFORE UPDATE ON order_discounts
+FOR EACH ROW
+EXECUTE PROCEDURE trigger_set_timestamp();
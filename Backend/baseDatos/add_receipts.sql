@@ -0,0 +1,9 @@
+-- Migración: Recibos de clientes
+-- Fecha: 2026-10-18
+--
+-- stations.is_cashier: estaciones de caja, sus impresoras reciben los recibos.
+-- orders.blockchain_tx_hash: transacción que notarizó la factura (se muestra en el
+-- recibo junto al QR de verificación). Las órdenes existentes quedan sin hash.
+
+ALTER TABLE "stations" ADD COLUMN IF NOT EXISTS "is_cashier" boolean NOT NULL DEFAULT false;
+ALTER TABLE "orders" ADD COLUMN IF NOT EXISTS "blockchain_tx_hash" varchar(66);
@@ -0,0 +1,45 @@
+-- Migración: Cargo por servicio y propinas
+-- Fecha: 2026-10-18
+--
+-- orders: total = subtotal + service_charge; la propina (tip) se guarda aparte.
+-- order_payments: cada pago puede llevar propina, atribuida al mesero de la orden.
+-- Las órdenes existentes quedan con subtotal = total y sin cargo por servicio.
+
+-- 1. Montos de la orden
+ALTER TABLE "orders" ADD COLUMN IF NOT EXISTS "subtotal" numeric(10, 2) NOT NULL DEFAULT 0 CHECK (subtotal >= 0);
+ALTER TABLE "orders" ADD COLUMN IF NOT EXISTS "service_charge_rate" numeric(5, 2) NOT NULL DEFAULT 0 CHECK (service_charge_rate >= 0);
+ALTER TABLE "orders" ADD COLUMN IF NOT EXISTS "service_charge" numeric(10, 2) NOT NULL DEFAULT 0 CHECK (service_charge >= 0);
+ALTER TABLE "orders" ADD COLUMN IF NOT EXISTS "tip" numeric(10, 2) NOT NULL DEFAULT 0 CHECK (tip >= 0);
+
+UPDATE orders SET subtotal = total WHERE subtotal = 0 AND service_charge = 0 AND total > 0;
+
+-- 2. Propinas en los pagos (un pago puede ser solo propina)
+ALTER TABLE "order_payments" ADD COLUMN IF NOT EXISTS "tip" numeric(10, 2) NOT NULL DEFAULT 0 CHECK (tip >= 0);
+ALTER TABLE "order_payments" ADD COLUMN IF NOT EXISTS "tip_waiter_id" uuid REFERENCES "users"("id") ON DELETE SET NULL;
+
+ALTER TABLE "order_payments" DROP CONSTRAINT IF EXISTS order_payments_amount_check;
+ALTER TABLE "order_payments" ADD CONSTRAINT order_payments_amount_check CHECK (amount >= 0);
+
+ALTER TABLE "order_payments" DROP CONSTRAINT IF EXISTS order_payments_amount_or_tip_check;
+ALTER TABLE "order_payments" ADD CONSTRAINT order_payments_amount_or_tip_check CHECK (amount > 0 OR tip > 0);
+
+CREATE INDEX IF NOT EXISTS order_payments_tip_waiter_id_created_at_idx ON "order_payments" ("tip_waiter_id", "created_at");
+
+-- 3. Porcentaje de cargo por servicio por tipo de orden
+CREATE TABLE IF NOT EXISTS "service_charge_settings" (
+  "order_type" varchar(20) PRIMARY KEY CHECK (order_type IN ('mesa', 'llevar', 'domicilio')),
+  "percentage" numeric(5, 2) NOT NULL DEFAULT 0 CHECK (percentage >= 0 AND percentage <= 100),
+  "updated_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+DROP TRIGGER IF EXISTS set_timestamp ON service_charge_settings;
+CREATE TRIGGER set_timestamp
+BEFORE UPDATE ON service_charge_settings
+FOR EACH ROW
+EXECUTE PROCEDURE trigger_set_timestamp();
+
+INSERT INTO service_charge_settings (order_type, percentage) VALUES
+('mesa', 0),
+('llevar', 0),
+('domicilio', 0)
+ON CONFLICT (order_type) DO NOTHING;
@@ -0,0 +1,4 @@
+-- Migración: Impresión automática de tickets por estación
+-- Fecha: 2026-10-18
+
+ALTER TABLE stations ADD COLUMN IF NOT EXISTS "auto_print" boolean NOT NULL DEFAULT true;
@@ -0,0 +1,87 @@
+-- Migración: Impuestos por categoría y numeración de facturas
+-- Fecha: 2026-10-18
+--
+-- categories.tax_rate: impuesto de los productos de la categoría (0 por defecto).
+-- order_items.tax_rate: tarifa vigente al agregar el item a la orden.
+-- orders: tax, tax_breakdown, order_number (consecutivo interno) e invoice_number
+-- (consecutivo fiscal sin huecos que se asigna al pasar a 'pagado').
+-- Las órdenes existentes quedan sin impuesto; las ya pagadas reciben número de factura
+-- en el orden en que se pagaron.
+
+-- 1. Impuesto por categoría y por item
+ALTER TABLE "categories" ADD COLUMN IF NOT EXISTS "tax_rate" numeric(5, 2) NOT NULL DEFAULT 0 CHECK (tax_rate >= 0 AND tax_rate <= 100);
+ALTER TABLE "order_items" ADD COLUMN IF NOT EXISTS "tax_rate" numeric(5, 2) NOT NULL DEFAULT 0 CHECK (tax_rate >= 0);
+
+-- 2. Impuesto de la orden
+ALTER TABLE "orders" ADD COLUMN IF NOT EXISTS "tax" numeric(10, 2) NOT NULL DEFAULT 0 CHECK (tax >= 0);
+ALTER TABLE "orders" ADD COLUMN IF NOT EXISTS "tax_breakdown" jsonb NOT NULL DEFAULT '[]';
+
+-- Los backfills no deben cambiar updated_at (es la fecha de la factura)
+ALTER TABLE "orders" DISABLE TRIGGER set_timestamp;
+
+-- 3. Consecutivo interno (tickets de cocina), numerando las órdenes existentes por fecha
+ALTER TABLE "orders" ADD COLUMN IF NOT EXISTS "order_number" bigint;
+CREATE SEQUENCE IF NOT EXISTS orders_order_number_seq OWNED BY orders.order_number;
+
+WITH numbered AS (
+  SELECT id, ROW_NUMBER() OVER (ORDER BY created_at, id) AS n
+  FROM orders
+  WHERE order_number IS NULL
+)
+UPDATE orders o
+SET order_number = numbered.n + (SELECT COALESCE(MAX(order_number), 0) FROM orders)
+FROM numbered
+WHERE o.id = numbered.id;
+
+SELECT setval('orders_order_number_seq', (SELECT COALESCE(MAX(order_number), 0) + 1 FROM orders), false);
+ALTER TABLE "orders" ALTER COLUMN "order_number" SET DEFAULT nextval('orders_order_number_seq');
+ALTER TABLE "orders" ALTER COLUMN "order_number" SET NOT NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS orders_order_number_key ON "orders" ("order_number");
+
+-- 4. Numeración de facturas
+CREATE TABLE IF NOT EXISTS "invoice_sequences" (
+  "name" varchar(30) PRIMARY KEY,
+  "last_number" bigint NOT NULL DEFAULT 0 CHECK (last_number >= 0)
+);
+INSERT INTO invoice_sequences (name, last_number) VALUES ('invoice', 0)
+ON CONFLICT (name) DO NOTHING;
+
+ALTER TABLE "orders" ADD COLUMN IF NOT EXISTS "invoice_number" bigint;
+CREATE UNIQUE INDEX IF NOT EXISTS orders_invoice_number_key ON "orders" ("invoice_number");
+
+-- Órdenes ya pagadas: numerarlas en el orden en que se pagaron
+WITH numbered AS (
+  SELECT id, ROW_NUMBER() OVER (ORDER BY updated_at, id) AS n
+  FROM orders
+  WHERE status = 'pagado' AND invoice_number IS NULL
+)
+UPDATE orders o
+SET invoice_number = numbered.n + (SELECT last_number FROM invoice_sequences WHERE name = 'invoice')
+FROM numbered
+WHERE o.id = numbered.id;
+
+UPDATE invoice_sequences
+SET last_number = (SELECT COALESCE(MAX(invoice_number), 0) FROM orders)
+WHERE name = 'invoice';
+
+ALTER TABLE "orders" ENABLE TRIGGER set_timestamp;
+
+-- Asigna el siguiente número de factura cuando la orden pasa a 'pagado'. El UPDATE del
+-- contador bloquea su fila hasta el commit, así dos cobros simultáneos no repiten número
+CREATE OR REPLACE FUNCTION trigger_assign_invoice_number()
+RETURNS TRIGGER AS $$
+BEGIN
+  IF NEW.status = 'pagado' AND NEW.invoice_number IS NULL THEN
+    UPDATE invoice_sequences SET last_number = last_number + 1
+    WHERE name = 'invoice'
+    RETURNING last_number INTO NEW.invoice_number;
+  END IF;
+  RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS assign_invoice_number ON orders;
+CREATE TRIGGER assign_invoice_number
+BEFORE UPDATE OF status ON orders
+FOR EACH ROW
+EXECUTE PROCEDURE trigger_assign_invoice_number();
@@ -3,14 +3,14 @@
 -- =================================================================
 
 -- Borrar tablas antiguas si existen para un reinicio limpio
-DROP TABLE IF EXISTS "order_items", "orders", "menu_item_ingredients", "menu_item_accompaniments", "menu_items", "categories", "printers", "stations", "ingredients", "accompaniments", "tables", "users" CASCADE;
+DROP TABLE IF EXISTS "order_cancellations", "chain_invoice_events", "chain_indexer_state", "auditor_keys", "order_notarizations", "notarization_batches", "invoice_sequences", "order_discounts", "promotions", "service_charge_settings", "order_payments", "order_events", "kds_tickets", "print_jobs", "order_items", "orders", "menu_item_ingredients", "menu_item_accompaniments", "menu_items", "categories", "printers", "stations", "ingredients", "accompaniments", "tables", "users" CASCADE;
 
 -- Tabla para usuarios y roles
 CREATE TABLE "users" (
   "id" uuid PRIMARY KEY DEFAULT gen_random_uuid(),
   "username" varchar(100) UNIQUE NOT NULL,
   "password_hash" text NOT NULL,
-  "role" varchar(20) NOT NULL CHECK (role IN ('mesero', 'cajero', 'admin')),
+  "role" varchar(20) NOT NULL CHECK (role IN ('mesero', 'cajero', 'admin', 'auditor')),
   "is_active" boolean NOT NULL DEFAULT true,
   "created_at" timestamptz NOT NULL DEFAULT (now())
 );
@@ -28,6 +28,9 @@ CREATE TABLE "stations" (
   "name" varchar(100) UNIQUE NOT NULL,
   "description" text,
   "is_active" boolean NOT NULL DEFAULT true,
+  "auto_print" boolean NOT NULL DEFAULT true,
+  -- Estación de caja: sus impresoras reciben los recibos de los clientes
+  "is_cashier" boolean NOT NULL DEFAULT false,
   "created_at" timestamptz NOT NULL DEFAULT (now())
 );
 
@@ -39,6 +42,10 @@ CREATE TABLE "printers" (
   "printer_type" varchar(20) NOT NULL DEFAULT 'escpos' CHECK (printer_type IN ('escpos', 'pdf', 'raw')),
   "station_id" uuid NOT NULL REFERENCES "stations"("id") ON DELETE CASCADE,
   "is_active" boolean NOT NULL DEFAULT true,
+  "is_online" boolean NOT NULL DEFAULT false,
+  "last_seen_at" timestamptz,
+  "last_checked_at" timestamptz,
+  "latency_ms" integer,
   "created_at" timestamptz NOT NULL DEFAULT (now())
 );
 
@@ -46,7 +53,9 @@ CREATE TABLE "printers" (
 CREATE TABLE "categories" (
   "id" uuid PRIMARY KEY DEFAULT gen_random_uuid(),
   "name" varchar(100) UNIQUE NOT NULL,
-  "station_id" uuid REFERENCES "stations"("id")
+  "station_id" uuid REFERENCES "stations"("id"),
+  -- Impuesto de los productos de la categoría (19.00 = 19%)
+  "tax_rate" numeric(5, 2) NOT NULL DEFAULT 0 CHECK (tax_rate >= 0 AND tax_rate <= 100)
 );
 
 CREATE TABLE "ingredients" (
@@ -57,7 +66,7 @@ CREATE TABLE "ingredients" (
 CREATE TABLE "accompaniments" (
   "id" uuid PRIMARY KEY DEFAULT gen_random_uuid(),
   "name" varchar(100) UNIQUE NOT NULL,
-  "price" numeric(10, 2) NOT NULL DEFAULT 0
+  "price" numeric(10, 2) NOT NULL DEFAULT 0 CHECK (price >= 0)
 );
 
 -- Tabla de ítems del menú refactorizada
@@ -65,7 +74,7 @@ CREATE TABLE "menu_items" (
   "id" uuid PRIMARY KEY DEFAULT gen_random_uuid(),
   "name" varchar(255) NOT NULL,
   "description" text,
-  "price" numeric(10, 2) NOT NULL,
+  "price" numeric(10, 2) NOT NULL CHECK (price >= 0),
   "category_id" uuid NOT NULL REFERENCES "categories"("id"),
   "is_available" boolean NOT NULL DEFAULT true,
   "order_count" integer NOT NULL DEFAULT 0
@@ -89,11 +98,26 @@ CREATE TABLE "menu_item_accompaniments" (
 CREATE TABLE "orders" (
   "id" uuid PRIMARY KEY DEFAULT gen_random_uuid(),
   "waiter_id" uuid NOT NULL REFERENCES "users"("id"),
+  -- Nombre del mesero al crear o reasignar la orden (la factura no cambia si se renombra el usuario)
+  "waiter_name" varchar(100),
   "cashier_id" uuid REFERENCES "users"("id"),
   "table_id" uuid NOT NULL REFERENCES "tables"("id"),
   "table_number" integer NOT NULL,
-  "status" varchar(30) NOT NULL DEFAULT 'pendiente_aprobacion',
-  "total" numeric(10, 2) NOT NULL,
+  "status" varchar(30) NOT NULL DEFAULT 'pendiente_aprobacion' CHECK (status IN ('pendiente_aprobacion', 'recibido', 'aprobado', 'en_preparacion', 'listo_para_servir', 'entregado', 'por_verificar', 'pagado', 'cancelado')),
+  -- Consecutivo interno de la orden (tickets de cocina: ORD-001)
+  "order_number" bigserial UNIQUE,
+  -- Consecutivo fiscal sin huecos, lo asigna el trigger assign_invoice_number al pasar a 'pagado'
+  "invoice_number" bigint UNIQUE,
+  -- Montos: total = subtotal - discount + service_charge (+ tax si los precios no lo incluyen).
+  -- La propina (tip) va aparte y no cuenta en el total
+  "subtotal" numeric(10, 2) NOT NULL DEFAULT 0 CHECK (subtotal >= 0),
+  "discount" numeric(10, 2) NOT NULL DEFAULT 0 CHECK (discount >= 0),
+  "service_charge_rate" numeric(5, 2) NOT NULL DEFAULT 0 CHECK (service_charge_rate >= 0),
+  "service_charge" numeric(10, 2) NOT NULL DEFAULT 0 CHECK (service_charge >= 0),
+  "tip" numeric(10, 2) NOT NULL DEFAULT 0 CHECK (tip >= 0),
+  "tax" numeric(10, 2) NOT NULL DEFAULT 0 CHECK (tax >= 0),
+  "tax_breakdown" jsonb NOT NULL DEFAULT '[]', -- [{rate, base, tax}] por tarifa
+  "total" numeric(10, 2) NOT NULL CHECK (total >= 0),
   -- Tipo de orden: mesa (permite híbridos), llevar (todo empacado), domicilio (todo empacado + dirección)
   "order_type" varchar(20) NOT NULL DEFAULT 'mesa' CHECK (order_type IN ('mesa', 'llevar', 'domicilio')),
   -- Campos opcionales para domicilio (solo cuando order_type = 'domicilio')
@@ -112,11 +136,229 @@ CREATE TABLE "order_items" (
   "id" uuid PRIMARY KEY DEFAULT gen_random_uuid(),
   "order_id" uuid NOT NULL REFERENCES "orders"("id") ON DELETE CASCADE,
   "menu_item_id" uuid NOT NULL REFERENCES "menu_items"("id"),
+  -- Nombre del producto al pedirlo (la factura no cambia si se renombra el producto)
+  "menu_item_name" varchar(255),
+  -- Orden de los items en la orden y en la factura
+  "line_number" bigserial,
   "quantity" integer NOT NULL CHECK (quantity > 0),
-  "price_at_order" numeric(10, 2) NOT NULL,
+  "price_at_order" numeric(10, 2) NOT NULL CHECK (price_at_order >= 0),
+  "tax_rate" numeric(5, 2) NOT NULL DEFAULT 0 CHECK (tax_rate >= 0), -- Impuesto de la categoría al agregar el item
   "notes" text,
   "customizations" jsonb,
-  "is_takeout" boolean NOT NULL DEFAULT false
+  "is_takeout" boolean NOT NULL DEFAULT false,
+  -- Estado de preparación del item (independiente del estado de la orden)
+  "status" varchar(20) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'preparing', 'ready', 'served', 'voided'))
+);
+
+-- Cola persistente de impresión de tickets de cocina
+CREATE TABLE "print_jobs" (
+  "id" uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+  "order_id" uuid NOT NULL REFERENCES "orders"("id") ON DELETE CASCADE,
+  "station_id" uuid NOT NULL REFERENCES "stations"("id") ON DELETE CASCADE,
+  -- Última impresora usada (la que imprimió o la última que falló)
+  "printer_id" uuid REFERENCES "printers"("id") ON DELETE SET NULL,
+  "ticket" jsonb NOT NULL,
+  "status" varchar(20) NOT NULL DEFAULT 'queued' CHECK (status IN ('queued', 'sent', 'failed')),
+  "attempts" integer NOT NULL DEFAULT 0,
+  "max_attempts" integer NOT NULL DEFAULT 6,
+  "last_error" text,
+  "next_attempt_at" timestamptz NOT NULL DEFAULT (now()),
+  "sent_at" timestamptz,
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  "updated_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+-- Tickets mostrados en las pantallas de cocina (KDS) por estación
+CREATE TABLE "kds_tickets" (
+  "id" uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+  "order_id" uuid NOT NULL REFERENCES "orders"("id") ON DELETE CASCADE,
+  "station_id" uuid NOT NULL REFERENCES "stations"("id") ON DELETE CASCADE,
+  "ticket" jsonb NOT NULL,
+  "status" varchar(20) NOT NULL DEFAULT 'new' CHECK (status IN ('new', 'in_progress', 'ready', 'voided')), -- voided: la orden se anuló
+  "started_at" timestamptz,
+  "ready_at" timestamptz,
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  "updated_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+-- Historial de cambios de las órdenes (auditoría). actor_id NULL = cambio automático del sistema
+CREATE TABLE "order_events" (
+  "id" uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+  "order_id" uuid NOT NULL REFERENCES "orders"("id") ON DELETE CASCADE,
+  "actor_id" uuid REFERENCES "users"("id") ON DELETE SET NULL,
+  "event_type" varchar(30) NOT NULL,
+  "old_value" jsonb,
+  "new_value" jsonb,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+-- Pagos de una orden: varios pagos parciales de métodos mixtos (cuentas divididas).
+-- item_ids: items cubiertos cuando se divide por items; vacío = abono al total
+CREATE TABLE "order_payments" (
+  "id" uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+  "order_id" uuid NOT NULL REFERENCES "orders"("id") ON DELETE CASCADE,
+  "amount" numeric(10, 2) NOT NULL CHECK (amount >= 0),
+  -- Propina (no cuenta para el saldo) y el mesero al que se atribuye
+  "tip" numeric(10, 2) NOT NULL DEFAULT 0 CHECK (tip >= 0),
+  "tip_waiter_id" uuid REFERENCES "users"("id") ON DELETE SET NULL,
+  "method" varchar(20) NOT NULL CHECK (method IN ('efectivo', 'transferencia', 'tarjeta')),
+  "proof_path" text,
+  "item_ids" uuid[] NOT NULL DEFAULT '{}',
+  "received_by" uuid REFERENCES "users"("id") ON DELETE SET NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  CONSTRAINT order_payments_amount_or_tip_check CHECK (amount > 0 OR tip > 0)
+);
+
+-- Porcentaje de cargo por servicio por tipo de orden (cada orden guarda el vigente al crearse)
+CREATE TABLE "service_charge_settings" (
+  "order_type" varchar(20) PRIMARY KEY CHECK (order_type IN ('mesa', 'llevar', 'domicilio')),
+  "percentage" numeric(5, 2) NOT NULL DEFAULT 0 CHECK (percentage >= 0 AND percentage <= 100),
+  "updated_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+-- Promociones: descuentos por porcentaje o monto fijo (orden, productos o categorías),
+-- combos y cupones. Sin coupon_code se aplican solas dentro de su ventana (happy hour)
+CREATE TABLE "promotions" (
+  "id" uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+  "name" varchar(100) NOT NULL,
+  "description" text,
+  "type" varchar(20) NOT NULL CHECK (type IN ('percentage', 'fixed', 'combo')),
+  "scope" varchar(20) NOT NULL DEFAULT 'order' CHECK (scope IN ('order', 'menu_item', 'category')),
+  "percentage" numeric(5, 2) NOT NULL DEFAULT 0 CHECK (percentage >= 0 AND percentage <= 100),
+  -- fixed: monto a descontar; combo: precio del combo
+  "amount" numeric(10, 2) NOT NULL DEFAULT 0 CHECK (amount >= 0),
+  -- Productos o categorías según scope; en combos, los productos del combo
+  "target_ids" uuid[] NOT NULL DEFAULT '{}',
+  "coupon_code" varchar(50), -- Único sin distinguir mayúsculas (índice UPPER)
+  -- Ventana de tiempo: días (0 = domingo) y horario; si end_time < start_time cruza la medianoche
+  "days_of_week" smallint[] NOT NULL DEFAULT '{}',
+  "start_time" time,
+  "end_time" time,
+  "starts_at" timestamptz,
+  "ends_at" timestamptz,
+  "max_uses" integer CHECK (max_uses > 0),
+  "is_active" boolean NOT NULL DEFAULT true,
+  "created_by" uuid REFERENCES "users"("id") ON DELETE SET NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  "updated_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+-- Descuentos aplicados a cada orden (auditoría: quién lo aplicó y quién lo aprobó).
+-- applied_by NULL = promoción automática. Conserva nombre y monto si se borra la promoción
+CREATE TABLE "order_discounts" (
+  "id" uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+  "order_id" uuid NOT NULL REFERENCES "orders"("id") ON DELETE CASCADE,
+  "promotion_id" uuid REFERENCES "promotions"("id") ON DELETE SET NULL,
+  "promotion_name" varchar(100) NOT NULL,
+  "coupon_code" varchar(50),
+  "amount" numeric(10, 2) NOT NULL CHECK (amount >= 0),
+  "status" varchar(20) NOT NULL DEFAULT 'applied' CHECK (status IN ('applied', 'pending_approval', 'rejected')),
+  "applied_by" uuid REFERENCES "users"("id") ON DELETE SET NULL,
+  "approved_by" uuid REFERENCES "users"("id") ON DELETE SET NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  "updated_at" timestamptz NOT NULL DEFAULT (now()),
+  UNIQUE ("order_id", "promotion_id")
+);
+
+-- Contador de la numeración de facturas. Se incrementa dentro de la misma transacción
+-- que marca la orden como pagada: si falla, el número no se consume (sin huecos)
+CREATE TABLE "invoice_sequences" (
+  "name" varchar(30) PRIMARY KEY,
+  "last_number" bigint NOT NULL DEFAULT 0 CHECK (last_number >= 0)
+);
+
+-- Lotes de notarización (modo batch): una sola transacción ancla la raíz Merkle de los
+-- hashes de varias facturas. Mismo ciclo que order_notarizations
+CREATE TABLE "notarization_batches" (
+  "id" uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+  "merkle_root" varchar(66) UNIQUE NOT NULL,
+  "size" integer NOT NULL CHECK (size > 0),
+  "status" varchar(20) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'sent', 'confirmed', 'failed')),
+  "tx_hash" varchar(66),
+  "nonce" bigint,
+  "backend" varchar(20), -- Backend de notarización de tx_hash (ethereum, file, memory)
+  "block_number" bigint,
+  "attempts" integer NOT NULL DEFAULT 0,
+  "last_error" text,
+  "next_attempt_at" timestamptz NOT NULL DEFAULT (now()),
+  "sent_at" timestamptz,
+  "confirmed_at" timestamptz,
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  "updated_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+-- Notarización de la factura de cada orden en blockchain: qué transacción la certifica.
+-- Funciona como outbox: pending (por enviar o reintentar) → sent (tx enviada, se consulta
+-- su recibo) → confirmed (minada) | failed (se agotaron los reintentos)
+CREATE TABLE "order_notarizations" (
+  "id" uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+  "order_id" uuid UNIQUE NOT NULL REFERENCES "orders"("id") ON DELETE CASCADE,
+  "status" varchar(20) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'sent', 'confirmed', 'failed')),
+  "invoice_hash" varchar(64) NOT NULL, -- Hash de la BlockchainInvoice notarizada
+  "tx_hash" varchar(66),
+  "nonce" bigint, -- Nonce de la wallet usado en tx_hash
+  "backend" varchar(20), -- Backend de notarización de tx_hash (ethereum, file, memory)
+  "block_number" bigint,
+  "attempts" integer NOT NULL DEFAULT 0,
+  "last_error" text,
+  "next_attempt_at" timestamptz NOT NULL DEFAULT (now()), -- Próximo envío o consulta del recibo
+  "sent_at" timestamptz,
+  "confirmed_at" timestamptz,
+  "batch_id" uuid REFERENCES "notarization_batches"("id") ON DELETE SET NULL, -- Lote que ancla la factura (modo batch)
+  "merkle_root" varchar(66), -- Raíz del lote
+  "merkle_proof" text[], -- Prueba Merkle de la factura contra merkle_root
+  "encrypted_invoice" text, -- Factura cifrada incluida en el lote (no va a la cadena)
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  "updated_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+-- Llave pública de cada auditor (RSA, PEM). Las exportaciones con envelope=true cifran cada
+-- factura con una clave propia envuelta con esta llave, sin compartir la clave maestra
+CREATE TABLE "auditor_keys" (
+  "user_id" uuid PRIMARY KEY REFERENCES "users"("id") ON DELETE CASCADE,
+  "public_key" text NOT NULL,
+  "fingerprint" varchar(64) NOT NULL, -- SHA-256 de la llave (DER)
+  "granted_by" uuid REFERENCES "users"("id") ON DELETE SET NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  "updated_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+-- Eventos InvoiceSecured leídos de la cadena por el indexador. source identifica el backend
+-- (y el contrato): cada fuente tiene su propio índice. order_id no tiene FK porque un
+-- evento puede no corresponder a ninguna orden local
+CREATE TABLE "chain_invoice_events" (
+  "id" uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+  "source" varchar(100) NOT NULL,
+  "order_id" uuid, -- NULL si no se pudo saber de qué orden es
+  "order_id_hash" varchar(66) NOT NULL, -- Topic indexado: keccak256 del ID de la orden
+  "tx_hash" varchar(66) NOT NULL,
+  "log_index" integer NOT NULL,
+  "block_number" bigint NOT NULL,
+  "block_hash" varchar(66) NOT NULL,
+  "block_timestamp" timestamptz NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  UNIQUE ("source", "tx_hash", "log_index")
+);
+
+-- Último bloque indexado de cada fuente y su hash (para detectar reorganizaciones)
+CREATE TABLE "chain_indexer_state" (
+  "source" varchar(100) PRIMARY KEY,
+  "last_block" bigint NOT NULL,
+  "last_block_hash" varchar(66) NOT NULL,
+  "updated_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+-- Cancelaciones y anulaciones de órdenes, una por orden y con motivo obligatorio.
+-- cancel: antes de llegar a cocina; void: después, con tickets de anulación a las estaciones
+CREATE TABLE "order_cancellations" (
+  "order_id" uuid PRIMARY KEY REFERENCES "orders"("id") ON DELETE CASCADE,
+  "kind" varchar(10) NOT NULL CHECK (kind IN ('cancel', 'void')),
+  "reason_code" varchar(30) NOT NULL CHECK (reason_code IN ('customer_left', 'order_error', 'duplicate', 'out_of_stock', 'customer_complaint', 'other')),
+  "notes" text,
+  "previous_status" varchar(30) NOT NULL, -- Estado de la orden al cancelarla
+  "total" numeric(10, 2) NOT NULL, -- Total de la orden al cancelarla
+  "cancelled_by" uuid REFERENCES "users"("id") ON DELETE SET NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
 );
 
 -- =================================================================
@@ -138,6 +380,70 @@ BEFORE UPDATE ON orders
 FOR EACH ROW
 EXECUTE PROCEDURE trigger_set_timestamp();
 
+CREATE TRIGGER set_timestamp
+BEFORE UPDATE ON print_jobs
+FOR EACH ROW
+EXECUTE PROCEDURE trigger_set_timestamp();
+
+CREATE TRIGGER set_timestamp
+BEFORE UPDATE ON kds_tickets
+FOR EACH ROW
+EXECUTE PROCEDURE trigger_set_timestamp();
+
+CREATE TRIGGER set_timestamp
+BEFORE UPDATE ON service_charge_settings
+FOR EACH ROW
+EXECUTE PROCEDURE trigger_set_timestamp();
+
+CREATE TRIGGER set_timestamp
+BEFORE UPDATE ON promotions
+FOR EACH ROW
+EXECUTE PROCEDURE trigger_set_timestamp();
+
+CREATE TRIGGER set_timestamp
+BEFORE UPDATE ON order_discounts
+FOR EACH ROW
+EXECUTE PROCEDURE trigger_set_timestamp();
+
+CREATE TRIGGER set_timestamp
+BEFORE UPDATE ON order_notarizations
+FOR EACH ROW
+EXECUTE PROCEDURE trigger_set_timestamp();
+
+CREATE TRIGGER set_timestamp
+BEFORE UPDATE ON notarization_batches
+FOR EACH ROW
+EXECUTE PROCEDURE trigger_set_timestamp();
+
+CREATE TRIGGER set_timestamp
+BEFORE UPDATE ON auditor_keys
+FOR EACH ROW
+EXECUTE PROCEDURE trigger_set_timestamp();
+
+CREATE TRIGGER set_timestamp
+BEFORE UPDATE ON chain_indexer_state
+FOR EACH ROW
+EXECUTE PROCEDURE trigger_set_timestamp();
+
+-- Asigna el siguiente número de factura cuando la orden pasa a 'pagado'. El UPDATE del
+-- contador bloquea su fila hasta el commit, así dos cobros simultáneos no repiten número
+CREATE OR REPLACE FUNCTION trigger_assign_invoice_number()
+RETURNS TRIGGER AS $$
+BEGIN
+  IF NEW.status = 'pagado' AND NEW.invoice_number IS NULL THEN
+    UPDATE invoice_sequences SET last_number = last_number + 1
+    WHERE name = 'invoice'
+    RETURNING last_number INTO NEW.invoice_number;
+  END IF;
+  RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER assign_invoice_number
+BEFORE UPDATE OF status ON orders
+FOR EACH ROW
+EXECUTE PROCEDURE trigger_assign_invoice_number();
+
 
 -- =================================================================
 -- ÍNDICES Y DATOS DE PRUEBA (SEED DATA)
@@ -150,6 +456,31 @@ CREATE INDEX ON "menu_items" ("order_count");
 CREATE INDEX ON "menu_items" ("category_id");
 CREATE INDEX ON "printers" ("station_id");
 CREATE INDEX ON "categories" ("station_id");
+CREATE INDEX ON "print_jobs" ("order_id");
+CREATE INDEX ON "print_jobs" ("status", "next_attempt_at");
+CREATE INDEX ON "kds_tickets" ("station_id", "status");
+CREATE INDEX ON "kds_tickets" ("order_id");
+CREATE INDEX ON "order_events" ("order_id", "created_at");
+CREATE INDEX ON "order_payments" ("order_id");
+CREATE INDEX ON "order_payments" ("tip_waiter_id", "created_at");
+CREATE INDEX ON "order_discounts" ("order_id");
+CREATE INDEX ON "order_notarizations" ("status", "next_attempt_at");
+CREATE INDEX ON "order_notarizations" ("batch_id");
+CREATE INDEX ON "order_notarizations" ("confirmed_at");
+CREATE INDEX ON "chain_invoice_events" ("source", "block_number");
+CREATE INDEX ON "chain_invoice_events" ("order_id");
+CREATE INDEX ON "notarization_batches" ("status", "next_attempt_at");
+CREATE INDEX ON "order_cancellations" ("created_at");
+CREATE UNIQUE INDEX ON "promotions" (UPPER("coupon_code"));
+
+-- Numeración de facturas (la primera será la 1)
+INSERT INTO invoice_sequences (name, last_number) VALUES ('invoice', 0);
+
+-- Cargo por servicio (0% por defecto; se ajusta desde PUT /api/service-charges/:orderType)
+INSERT INTO service_charge_settings (order_type, percentage) VALUES
+('mesa', 0),
+('llevar', 0),
+('domicilio', 0);
 
 -- Insertar usuarios (Contraseña para todos: 1234)
 -- Hash generado con Costo 10 (Go Default)
@@ -173,12 +504,17 @@ INSERT INTO stations (id, name, description) VALUES
 ('e03e6f2b-2250-4630-8a2e-8a3d2a1f9d03', 'Parrilla', 'Preparación de carnes a la parrilla'),
 ('e04e6f2b-2250-4630-8a2e-8a3d2a1f9d04', 'Postres', 'Preparación de postres y dulces');
 
+-- Estación de caja (recibos de clientes, sin tickets de cocina)
+INSERT INTO stations (id, name, description, auto_print, is_cashier) VALUES
+('e05e6f2b-2250-4630-8a2e-8a3d2a1f9d05', 'Caja', 'Recibos de clientes', false, true);
+
 -- Insertar impresoras de ejemplo
 INSERT INTO printers (id, name, ip_address, port, printer_type, station_id) VALUES
 ('p01e6f2b-2250-4630-8a2e-8a3d2a1f9e01', 'Impresora Cocina 1', '192.168.1.101', 9100, 'escpos', 'e01e6f2b-2250-4630-8a2e-8a3d2a1f9d01'),
 ('p02e6f2b-2250-4630-8a2e-8a3d2a1f9e02', 'Impresora Bar 1', '192.168.1.102', 9100, 'escpos', 'e02e6f2b-2250-4630-8a2e-8a3d2a1f9d02'),
 ('p03e6f2b-2250-4630-8a2e-8a3d2a1f9e03', 'Impresora Parrilla 1', '192.168.1.103', 9100, 'escpos', 'e03e6f2b-2250-4630-8a2e-8a3d2a1f9d03'),
-('p04e6f2b-2250-4630-8a2e-8a3d2a1f9e04', 'Impresora Postres 1', '192.168.1.104', 9100, 'escpos', 'e04e6f2b-2250-4630-8a2e-8a3d2a1f9d04');
+('p04e6f2b-2250-4630-8a2e-8a3d2a1f9e04', 'Impresora Postres 1', '192.168.1.104', 9100, 'escpos', 'e04e6f2b-2250-4630-8a2e-8a3d2a1f9d04'),
+('p05e6f2b-2250-4630-8a2e-8a3d2a1f9e05', 'Impresora Caja 1', '192.168.1.105', 9100, 'escpos', 'e05e6f2b-2250-4630-8a2e-8a3d2a1f9d05');
 
 -- Insertar categorías, ingredientes y acompañantes con UUIDs válidos
 INSERT INTO categories (id, name, station_id) VALUES
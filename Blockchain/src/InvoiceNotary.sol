@@ -20,6 +20,18 @@ contract InvoiceNotary {
         string encryptedData 
     );
 
+    /**
+     * @dev Evento que se emite cuando se ancla un lote de facturas.
+     * - merkleRoot: Raíz del árbol Merkle de los hashes de las facturas del lote (indexada).
+     * - batchSize: Cantidad de facturas del lote.
+     * - timestamp: La fecha y hora exacta del bloque (inmutable).
+     */
+    event BatchAnchored(
+        bytes32 indexed merkleRoot,
+        uint256 batchSize,
+        uint256 timestamp
+    );
+
     // Dirección del dueño del contrato (tu backend wallet)
     address public owner;
 
@@ -44,6 +56,32 @@ contract InvoiceNotary {
         emit InvoiceSecured(_orderId, block.timestamp, _encryptedData);
     }
     
+    /**
+     * @dev Ancla un lote de facturas con una sola transacción. Cada factura se prueba
+     * después con su prueba Merkle contra esta raíz (ver verifyProof).
+     * @param _merkleRoot Raíz del árbol Merkle del lote.
+     * @param _batchSize Cantidad de facturas del lote.
+     */
+    function anchorBatch(bytes32 _merkleRoot, uint256 _batchSize) public onlyOwner {
+        emit BatchAnchored(_merkleRoot, _batchSize, block.timestamp);
+    }
+
+    /**
+     * @dev Verifica que una hoja pertenece al árbol con la raíz dada. Los pares se ordenan
+     * antes de hashearse (keccak256), así la prueba no necesita la posición de la hoja.
+     * La hoja es keccak256 del hash SHA-256 de la factura.
+     */
+    function verifyProof(bytes32[] calldata _proof, bytes32 _root, bytes32 _leaf) public pure returns (bool) {
+        bytes32 computed = _leaf;
+        for (uint256 i = 0; i < _proof.length; i++) {
+            bytes32 sibling = _proof[i];
+            computed = computed < sibling
+                ? keccak256(abi.encodePacked(computed, sibling))
+                : keccak256(abi.encodePacked(sibling, computed));
+        }
+        return computed == _root;
+    }
+
     /**
      * @dev Permite transferir el control del contrato a otra wallet en caso de emergencia.
      */
@@ -9,6 +9,16 @@ import OrderDetailModal from '../../shared/orders/components/OrderDetailModal.ts
 import OrderGridView from '../../shared/orders/components/OrderGridView.tsx';
 import type { Order } from '../../../types/orders';
 
+// Motivos aceptados por POST /api/orders/:id/cancel y /void
+const CANCEL_REASONS = [
+  { code: 'customer_left', label: 'Cliente se retiró' },
+  { code: 'order_error', label: 'Error en la orden' },
+  { code: 'duplicate', label: 'Orden duplicada' },
+  { code: 'out_of_stock', label: 'Producto agotado' },
+  { code: 'customer_complaint', label: 'Queja del cliente' },
+  { code: 'other', label: 'Otro' },
+];
+
 const OrderManagement: React.FC = () => {
   const dispatch = useDispatch<AppDispatch>();
   const { activeOrders, status } = useSelector((state: RootState) => state.orders);
@@ -45,10 +55,21 @@ const OrderManagement: React.FC = () => {
     }, {} as Record<number, Order[]>);
   }, [activeOrders]);
 
-  const handleCancel = (orderId: string) => {
-    if (window.confirm('¿Estás seguro de que quieres cancelar esta orden?')) {
-      dispatch(cancelOrderAsAdmin(orderId));
+  const handleCancel = (order: Order) => {
+    const options = CANCEL_REASONS.map((reason, i) => `${i + 1}. ${reason.label}`).join('\n');
+    const choice = window.prompt(`Motivo de la cancelación de la orden:\n${options}`);
+    if (choice === null) return;
+    const reason = CANCEL_REASONS[Number(choice) - 1];
+    if (!reason) {
+      window.alert('Motivo inválido');
+      return;
+    }
+    let notes: string | undefined;
+    if (reason.code === 'other') {
+      notes = window.prompt('Describe el motivo:')?.trim();
+      if (!notes) return;
     }
+    dispatch(cancelOrderAsAdmin({ order, reasonCode: reason.code, notes }));
   };
 
   return (
@@ -85,7 +106,7 @@ const OrderManagement: React.FC = () => {
               renderActions={(order) => (
                 <>
                   <button onClick={() => setSelectedOrderId(order.id)} className="w-full text-center px-3 py-2 bg-gray-200 rounded-md hover:bg-gray-300">Ver Detalle</button>
-                  <button onClick={() => handleCancel(order.id)} className="w-full text-center px-3 py-2 bg-red-500 text-white rounded-md hover:bg-red-600">Cancelar Orden</button>
+                  <button onClick={() => handleCancel(order)} className="w-full text-center px-3 py-2 bg-red-500 text-white rounded-md hover:bg-red-600">Cancelar Orden</button>
                 </>
               )}
             />
@@ -91,6 +91,13 @@ export const manageOrderAsAdmin = async (orderId: string, updates: { status?: st
     return response.data;
 };
 
+// Cancela (antes de cocina) o anula (después, solo admin) una orden con su motivo
+export const cancelOrder = async (orderId: string, kind: 'cancel' | 'void', reason: { reason_code: string, notes?: string }, token: string): Promise<Order> => {
+    const config = { headers: { Authorization: `Bearer ${token}` } };
+    const response = await axios.post(`${API_URL}/${orderId}/${kind}`, reason, config);
+    return response.data.order;
+};
+
 export const uploadPaymentProof = async (orderId: string, file: File, method: string, token: string): Promise<Order> => {
   console.log('🔄 [Frontend] Enviando comprobante de pago:', {
     orderId,
@@ -2,7 +2,7 @@
 // ARCHIVO 3: /src/features/orders/ordersSlice.ts
 // =================================================================
 import { createSlice, createAsyncThunk, type PayloadAction } from '@reduxjs/toolkit';
-import { createOrder, getOrders, getOrderDetails, updateOrderStatus, manageOrderAsAdmin } from './ordersAPI.ts';
+import { createOrder, getOrders, getOrderDetails, updateOrderStatus, cancelOrder } from './ordersAPI.ts';
 import type { Order, NewOrderPayload } from '../../../../types/orders.ts';
 import type { RootState } from '../../../../app/store.ts';
 
@@ -78,10 +78,12 @@ export const changeOrderStatus = createAsyncThunk('orders/changeStatus', async (
     catch (error: any) { return rejectWithValue(error.response?.data?.error); }
 });
 
-export const cancelOrderAsAdmin = createAsyncThunk('orders/cancelAsAdmin', async (orderId: string, { getState, rejectWithValue }) => {
+// Las órdenes que no han llegado a cocina se cancelan; las demás se anulan (tickets de anulación)
+export const cancelOrderAsAdmin = createAsyncThunk('orders/cancelAsAdmin', async ({ order, reasonCode, notes }: { order: Order, reasonCode: string, notes?: string }, { getState, rejectWithValue }) => {
     const token = (getState() as RootState).auth.token;
     if (!token) return rejectWithValue('No se encontró el token');
-    try { return await manageOrderAsAdmin(orderId, { status: 'cancelado' }, token); }
+    const kind = order.status === 'pendiente_aprobacion' || order.status === 'recibido' ? 'cancel' : 'void';
+    try { return await cancelOrder(order.id, kind, { reason_code: reasonCode, notes }, token); }
     catch (error: any) { return rejectWithValue(error.response?.data?.error); }
 });
 